@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"server/src/api"
+	"server/src/cli"
 	"server/src/config"
 	"server/src/utils"
 	"server/src/worker"
@@ -19,6 +20,32 @@ func main() {
 		panic(err)
 	}
 	logger := utils.NewLogger(logrus.InfoLevel, false, cfg.Logger.File)
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case cli.ImportESCOCommand:
+			if err := cli.RunImportESCO(cfg, logger, os.Args[2:]); err != nil {
+				logger.Fatal(err)
+			}
+			return
+		case cli.ReplayESCOCommand:
+			if err := cli.RunReplayESCO(cfg, logger, os.Args[2:]); err != nil {
+				logger.Fatal(err)
+			}
+			return
+		case cli.ImportSpreadsheetCommand:
+			if err := cli.RunImportSpreadsheet(cfg, logger, os.Args[2:]); err != nil {
+				logger.Fatal(err)
+			}
+			return
+		case cli.SeedCategoriesCommand:
+			if err := cli.RunSeedCategories(cfg, logger, os.Args[2:]); err != nil {
+				logger.Fatal(err)
+			}
+			return
+		}
+	}
+
 	errC, err := run(cfg, logger)
 	if err != nil {
 		logger.Error(err, "Error while starting runner")
@@ -0,0 +1,23 @@
+-- +goose Up
+
+-- Create sync_jobs table
+CREATE TABLE sync_jobs (
+    id INTEGER PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    client_id TEXT NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING',
+    token TEXT,
+    total_dates INTEGER NOT NULL DEFAULT 0,
+    processed_dates INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX idx_sync_jobs_status ON sync_jobs(status, created_at);
+CREATE INDEX idx_sync_jobs_client_id ON sync_jobs(client_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS sync_jobs;
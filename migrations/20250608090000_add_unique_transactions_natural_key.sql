@@ -0,0 +1,53 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Transactions without a type are stored with an empty type so they take part in the unique key
+UPDATE transactions SET transaction_type = '' WHERE transaction_type IS NULL;
+ALTER TABLE transactions ALTER COLUMN transaction_type SET DEFAULT '';
+ALTER TABLE transactions ALTER COLUMN transaction_type SET NOT NULL;
+
+-- Rows of the same day and type are separate trades, so they are added up into the first live row
+-- stored before removing the rest. The price is the average of the trades weighted by their units,
+-- as the sync does when it stores them. Deleted rows are removed without adding them up.
+WITH duplicates AS (
+    SELECT
+        (ARRAY_AGG(id ORDER BY COALESCE(deleted, FALSE), id))[1] AS kept_id,
+        SUM(units) FILTER (WHERE NOT COALESCE(deleted, FALSE)) AS units,
+        SUM(total_value) FILTER (WHERE NOT COALESCE(deleted, FALSE)) AS total_value,
+        SUM(ABS(units) * price_per_unit) FILTER (WHERE NOT COALESCE(deleted, FALSE))
+            / NULLIF(SUM(ABS(units)) FILTER (WHERE NOT COALESCE(deleted, FALSE) AND price_per_unit IS NOT NULL), 0) AS price_per_unit
+    FROM transactions
+    WHERE asset_id IS NOT NULL
+    GROUP BY client_id, asset_id, date, transaction_type
+    HAVING COUNT(*) > 1
+)
+UPDATE transactions t
+SET units = COALESCE(d.units, t.units),
+    total_value = COALESCE(d.total_value, t.total_value),
+    price_per_unit = COALESCE(d.price_per_unit, t.price_per_unit)
+FROM duplicates d
+WHERE t.id = d.kept_id;
+
+DELETE FROM transactions t
+USING transactions d
+WHERE t.client_id = d.client_id
+AND t.asset_id = d.asset_id
+AND t.date = d.date
+AND t.transaction_type = d.transaction_type
+AND (COALESCE(d.deleted, FALSE), d.id) < (COALESCE(t.deleted, FALSE), t.id);
+
+-- Add unique constraint so transactions can be upserted on their natural key
+ALTER TABLE transactions
+ADD CONSTRAINT unique_client_asset_date_type UNIQUE (client_id, asset_id, date, transaction_type);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE transactions
+DROP CONSTRAINT IF EXISTS unique_client_asset_date_type;
+ALTER TABLE transactions ALTER COLUMN transaction_type DROP NOT NULL;
+ALTER TABLE transactions ALTER COLUMN transaction_type DROP DEFAULT;
+
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+
+-- Create sync_runs table to audit every sync executed by the sync service
+CREATE TABLE sync_runs (
+    id INTEGER PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    client_id TEXT NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    triggered_by TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'RUNNING',
+    dates_synced INTEGER NOT NULL DEFAULT 0,
+    holdings_count INTEGER NOT NULL DEFAULT 0,
+    transactions_count INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX idx_sync_runs_client_id ON sync_runs(client_id, started_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS sync_runs;
@@ -0,0 +1,7 @@
+-- +goose Up
+
+-- Forced sync jobs re-sync every date of their range, replacing the stored data
+ALTER TABLE sync_jobs ADD COLUMN force BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE sync_jobs DROP COLUMN IF EXISTS force;
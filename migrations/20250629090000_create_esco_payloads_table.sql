@@ -0,0 +1,18 @@
+-- +goose Up
+
+-- Create esco_payloads table to archive the raw body of every ESCO response
+CREATE TABLE esco_payloads (
+    id INTEGER PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    endpoint TEXT NOT NULL,
+    account_id TEXT NOT NULL,
+    cid TEXT,
+    start_date DATE,
+    end_date DATE,
+    payload BYTEA NOT NULL,
+    fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_esco_payloads_account ON esco_payloads(account_id, endpoint, start_date, fetched_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS esco_payloads;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Execution details of boletos: gross amount before fees and each market fee component
+ALTER TABLE transactions ADD COLUMN gross_value NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN exchange_fees NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN market_fees NUMERIC NOT NULL DEFAULT 0;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS market_fees;
+ALTER TABLE transactions DROP COLUMN IF EXISTS exchange_fees;
+ALTER TABLE transactions DROP COLUMN IF EXISTS gross_value;
+
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Rules mapping ESCO denominations to asset categories, replacing the static denominaciones.csv
+CREATE TABLE category_rules (
+    id INTEGER PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    category_id INTEGER NOT NULL REFERENCES asset_categories(id) ON DELETE CASCADE,
+    match_type TEXT NOT NULL CHECK (match_type IN ('exact', 'prefix', 'regex')),
+    pattern TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (match_type, pattern)
+);
+
+CREATE INDEX idx_category_rules_category_id ON category_rules(category_id);
+
+-- The denomination an asset was classified by, and whether its category was assigned by hand
+-- so syncs do not overwrite it
+ALTER TABLE assets ADD COLUMN category_key TEXT NOT NULL DEFAULT '';
+ALTER TABLE assets ADD COLUMN category_locked BOOLEAN NOT NULL DEFAULT FALSE;
+
+INSERT INTO asset_categories (name, description) VALUES ('S / C', 'Sin clasificar') ON CONFLICT (name) DO NOTHING;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+ALTER TABLE assets DROP COLUMN IF EXISTS category_locked;
+ALTER TABLE assets DROP COLUMN IF EXISTS category_key;
+DROP TABLE IF EXISTS category_rules;
+
+-- +goose StatementEnd
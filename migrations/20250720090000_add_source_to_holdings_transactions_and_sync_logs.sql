@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Broker the data was synced from. Everything stored so far came from ESCO.
+ALTER TABLE holdings ADD COLUMN source TEXT NOT NULL DEFAULT 'esco';
+ALTER TABLE transactions ADD COLUMN source TEXT NOT NULL DEFAULT 'esco';
+ALTER TABLE sync_logs ADD COLUMN source TEXT NOT NULL DEFAULT 'esco';
+-- Jobs without a source sync from the default one
+ALTER TABLE sync_jobs ADD COLUMN source TEXT NOT NULL DEFAULT '';
+
+-- An account can hold the same asset at more than one broker, so the source is part of the natural keys
+ALTER TABLE holdings DROP CONSTRAINT IF EXISTS holdings_client_id_asset_id_date_key;
+ALTER TABLE holdings
+ADD CONSTRAINT unique_client_source_asset_date UNIQUE (client_id, source, asset_id, date);
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS unique_client_asset_date_type;
+ALTER TABLE transactions
+ADD CONSTRAINT unique_client_source_asset_date_type UNIQUE (client_id, source, asset_id, date, transaction_type);
+
+ALTER TABLE sync_logs DROP CONSTRAINT IF EXISTS unique_client_sync_date;
+ALTER TABLE sync_logs
+ADD CONSTRAINT unique_client_source_sync_date UNIQUE (client_id, source, sync_date);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Data synced from other brokers cannot be told apart once the source is dropped
+DELETE FROM holdings WHERE source <> 'esco';
+DELETE FROM transactions WHERE source <> 'esco';
+DELETE FROM sync_logs WHERE source <> 'esco';
+
+ALTER TABLE sync_logs DROP CONSTRAINT IF EXISTS unique_client_source_sync_date;
+ALTER TABLE sync_logs
+ADD CONSTRAINT unique_client_sync_date UNIQUE (client_id, sync_date);
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS unique_client_source_asset_date_type;
+ALTER TABLE transactions
+ADD CONSTRAINT unique_client_asset_date_type UNIQUE (client_id, asset_id, date, transaction_type);
+
+ALTER TABLE holdings DROP CONSTRAINT IF EXISTS unique_client_source_asset_date;
+ALTER TABLE holdings
+ADD CONSTRAINT holdings_client_id_asset_id_date_key UNIQUE (client_id, asset_id, date);
+
+ALTER TABLE sync_jobs DROP COLUMN IF EXISTS source;
+ALTER TABLE sync_logs DROP COLUMN IF EXISTS source;
+ALTER TABLE transactions DROP COLUMN IF EXISTS source;
+ALTER TABLE holdings DROP COLUMN IF EXISTS source;
+
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Client owning a manually tracked asset. Broker instruments are shared and have no owner.
+ALTER TABLE assets ADD COLUMN client_id TEXT;
+CREATE INDEX idx_assets_client_id ON assets(client_id) WHERE client_id IS NOT NULL;
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+-- Manual assets cannot be told apart from broker instruments once the owner is dropped
+DELETE FROM holdings WHERE source = 'manual';
+DELETE FROM transactions WHERE source = 'manual';
+DELETE FROM assets WHERE client_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_assets_client_id;
+ALTER TABLE assets DROP COLUMN IF EXISTS client_id;
+
+-- +goose StatementEnd
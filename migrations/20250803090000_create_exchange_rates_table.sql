@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Pesos per dollar of the exchange rates reports can be converted with that BCRA does not publish,
+-- like the MEP and CCL dollars
+CREATE TABLE exchange_rates (
+    id INTEGER PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    currency TEXT NOT NULL,
+    date DATE NOT NULL,
+    rate DOUBLE PRECISION NOT NULL CHECK (rate > 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (currency, date)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS exchange_rates;
+
+-- +goose StatementEnd
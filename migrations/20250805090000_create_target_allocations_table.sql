@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Target weights of the categories the performance of reports is attributed against, grouped by the name of
+-- the allocation. The benchmark, a BCRA variable or a stored series, measures the target return of the
+-- category, the return of the category in the portfolio when empty.
+CREATE TABLE target_allocations (
+    id INTEGER PRIMARY KEY GENERATED ALWAYS AS IDENTITY,
+    name TEXT NOT NULL,
+    category TEXT NOT NULL,
+    weight DOUBLE PRECISION NOT NULL CHECK (weight >= 0),
+    benchmark TEXT NOT NULL DEFAULT '',
+    benchmark_kind TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (name, category)
+);
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+
+DROP TABLE IF EXISTS target_allocations;
+
+-- +goose StatementEnd
@@ -0,0 +1,7 @@
+-- +goose Up
+
+-- Sync jobs run with the ESCO service account token, the token of the user submitting them is not kept
+ALTER TABLE sync_jobs DROP COLUMN IF EXISTS token;
+
+-- +goose Down
+ALTER TABLE sync_jobs ADD COLUMN token TEXT;
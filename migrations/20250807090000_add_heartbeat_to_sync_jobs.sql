@@ -0,0 +1,8 @@
+-- +goose Up
+
+-- Running sync jobs refresh their heartbeat after every chunk, jobs whose heartbeat is older than the
+-- lease timeout were left by a worker that stopped and are requeued
+ALTER TABLE sync_jobs ADD COLUMN heartbeat_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE sync_jobs DROP COLUMN IF EXISTS heartbeat_at;
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"server/src/clients/esco"
+	"server/src/models"
 	"server/src/schemas"
 	"server/src/services"
 	"server/src/utils"
@@ -20,8 +21,11 @@ type AccountsControllerI interface {
 	GetAccountStateDateRange(ctx context.Context, token, id string, startDate, endDate time.Time, interval time.Duration) (*schemas.AccountState, error)
 	GetLiquidacionesDateRange(ctx context.Context, token, id string, startDate, endDate time.Time) (*schemas.AccountState, error)
 	GetBoletosDateRange(ctx context.Context, token, id string, startDate, endDate time.Time) (*schemas.AccountState, error)
-	GetMultiAccountStateByCategoryDateRange(ctx context.Context, token string, ids []string, startDate, endDate time.Time, interval time.Duration) (*schemas.AccountStateByCategory, error)
-	SyncAccount(ctx context.Context, token, accountID string, startDate, endDate time.Time) (*schemas.AccountState, error)
+	GetMultiAccountStateByCategoryDateRange(ctx context.Context, token string, ids []string, startDate, endDate time.Time, interval time.Duration, transactionTypes []string) (*schemas.AccountStateByCategory, error)
+	BulkSyncAccounts(ctx context.Context, token string, req *schemas.BulkSyncRequest) (*schemas.BulkSyncResponse, error)
+	SubmitSyncJob(ctx context.Context, accountID string, startDate, endDate time.Time, force bool) (*schemas.SyncJobResponse, error)
+	GetSyncJob(ctx context.Context, jobID int) (*schemas.SyncJobResponse, error)
+	GetSyncRuns(ctx context.Context, accountID string, limit int) ([]*schemas.SyncRunResponse, error)
 }
 
 type AccountsController struct {
@@ -29,14 +33,16 @@ type AccountsController struct {
 	ESCOService    services.ESCOServiceI
 	SyncService    services.SyncServiceI
 	AccountService services.AccountServiceI
+	SyncJobService services.SyncJobServiceI
 }
 
-func NewAccountsController(escoClient esco.ESCOServiceClientI, escoService services.ESCOServiceI, syncService services.SyncServiceI, accountService services.AccountServiceI) *AccountsController {
+func NewAccountsController(escoClient esco.ESCOServiceClientI, escoService services.ESCOServiceI, syncService services.SyncServiceI, accountService services.AccountServiceI, syncJobService services.SyncJobServiceI) *AccountsController {
 	return &AccountsController{
 		ESCOClient:     escoClient,
 		ESCOService:    escoService,
 		SyncService:    syncService,
 		AccountService: accountService,
+		SyncJobService: syncJobService,
 	}
 }
 
@@ -44,7 +50,7 @@ func (c *AccountsController) GetAllAccounts(ctx context.Context, token, filter s
 	if filter == "" {
 		filter = "*"
 	}
-	accs, err := c.ESCOClient.BuscarCuentas(token, filter)
+	accs, err := c.ESCOClient.BuscarCuentas(ctx, token, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -75,8 +81,8 @@ func (c *AccountsController) GetBoletosDateRange(ctx context.Context, token, id
 	return c.ESCOService.GetBoletosDateRange(ctx, token, id, startDate, endDate)
 }
 
-func (c *AccountsController) GetMultiAccountStateByCategoryDateRange(ctx context.Context, token string, ids []string, startDate, endDate time.Time, interval time.Duration) (*schemas.AccountStateByCategory, error) {
-	return c.AccountService.GetMultiAccountStateByCategory(ctx, ids, startDate, endDate, interval)
+func (c *AccountsController) GetMultiAccountStateByCategoryDateRange(ctx context.Context, token string, ids []string, startDate, endDate time.Time, interval time.Duration, transactionTypes []string) (*schemas.AccountStateByCategory, error) {
+	return c.AccountService.GetMultiAccountStateByCategory(ctx, ids, startDate, endDate, interval, transactionTypes, nil)
 }
 
 func (c *AccountsController) GetCtaCteConsolidadoDateRange(ctx context.Context, token, id string, startDate, endDate time.Time) (*schemas.AccountState, error) {
@@ -85,7 +91,7 @@ func (c *AccountsController) GetCtaCteConsolidadoDateRange(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
-	instrumentos, err := c.ESCOClient.GetCtaCteConsolidado(token, account.ID, account.FI, strconv.Itoa(account.N), "0", startDate, endDate, false)
+	instrumentos, err := c.ESCOClient.GetCtaCteConsolidado(ctx, token, account.ID, account.FI, strconv.Itoa(account.N), "0", startDate, endDate, false)
 	if err != nil {
 		return nil, err
 	}
@@ -416,20 +422,128 @@ func generateCategoryAssets(
 	return categoryAssets
 }
 
-// SyncAccount syncs account data for a given account ID and date range
-func (c *AccountsController) SyncAccount(ctx context.Context, token, accountID string, startDate, endDate time.Time) (*schemas.AccountState, error) {
+// BulkSyncAccounts queues a sync job for the requested accounts plus every account matching the filter.
+// An account that cannot be queued is reported in its result without aborting the others. The worker
+// runs the jobs, at most worker.syncJobs.maxConcurrentJobs at a time, and each one is tracked by its job.
+func (c *AccountsController) BulkSyncAccounts(ctx context.Context, token string, req *schemas.BulkSyncRequest) (*schemas.BulkSyncResponse, error) {
+	if req.Source != "" && !c.SyncService.HasSource(req.Source) {
+		return nil, utils.BadRequest(fmt.Sprintf("unknown source %s", req.Source))
+	}
+	ctx = utils.WithSyncSource(ctx, req.Source)
+
+	accountIDs := make([]string, 0, len(req.AccountIDs))
+	seen := make(map[string]bool)
+	for _, id := range req.AccountIDs {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			accountIDs = append(accountIDs, id)
+		}
+	}
+
+	if req.Filter != "" {
+		accounts, err := c.GetAllAccounts(ctx, token, req.Filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, account := range accounts {
+			if !seen[account.ID] {
+				seen[account.ID] = true
+				accountIDs = append(accountIDs, account.ID)
+			}
+		}
+	}
+
+	if len(accountIDs) == 0 {
+		return nil, utils.BadRequest("no accounts to sync")
+	}
+
+	logger := utils.LoggerFromContext(ctx)
+	response := &schemas.BulkSyncResponse{Total: len(accountIDs), Results: make([]schemas.BulkSyncResult, 0, len(accountIDs))}
+	for _, accountID := range accountIDs {
+		result := schemas.BulkSyncResult{AccountID: accountID}
+		job, err := c.SyncJobService.SubmitSyncJob(ctx, accountID, req.StartDate.ToTime(), req.EndDate.ToTime(), req.Force)
+		if err != nil {
+			logger.Errorf("Error queuing sync job for account %s: %v", accountID, err)
+			result.Error = err.Error()
+			response.Failed++
+		} else {
+			result.JobID = job.ID
+			response.Queued++
+		}
+		response.Results = append(response.Results, result)
+	}
+	return response, nil
+}
 
-	// Use syncService to sync the data
-	err := c.SyncService.SyncDataFromAccount(ctx, token, accountID, startDate, endDate)
+// SubmitSyncJob queues a sync of the account data for the given date range.
+// Forced jobs re-sync dates already synced, replacing the stored data.
+func (c *AccountsController) SubmitSyncJob(ctx context.Context, accountID string, startDate, endDate time.Time, force bool) (*schemas.SyncJobResponse, error) {
+	job, err := c.SyncJobService.SubmitSyncJob(ctx, accountID, startDate, endDate, force)
 	if err != nil {
 		return nil, err
 	}
+	return syncJobToResponse(job), nil
+}
 
-	// Get the synced account state
-	accountState, err := c.ESCOService.GetAccountStateWithTransactions(ctx, token, accountID, startDate, endDate, time.Hour*24)
+// GetSyncJob returns the status and progress of a sync job
+func (c *AccountsController) GetSyncJob(ctx context.Context, jobID int) (*schemas.SyncJobResponse, error) {
+	job, err := c.SyncJobService.GetSyncJob(ctx, jobID)
 	if err != nil {
 		return nil, err
 	}
+	return syncJobToResponse(job), nil
+}
+
+// GetSyncRuns returns the latest sync runs of the account, most recent first
+func (c *AccountsController) GetSyncRuns(ctx context.Context, accountID string, limit int) ([]*schemas.SyncRunResponse, error) {
+	runs, err := c.SyncService.GetSyncRuns(ctx, accountID, limit)
+	if err != nil {
+		return nil, err
+	}
+	response := make([]*schemas.SyncRunResponse, len(runs))
+	for i, run := range runs {
+		response[i] = syncRunToResponse(run)
+	}
+	return response, nil
+}
 
-	return accountState, nil
+func syncRunToResponse(run *models.SyncRun) *schemas.SyncRunResponse {
+	response := &schemas.SyncRunResponse{
+		ID:                run.ID,
+		AccountID:         run.ClientID,
+		StartDate:         schemas.Date{Time: run.StartDate},
+		EndDate:           schemas.Date{Time: run.EndDate},
+		TriggeredBy:       run.TriggeredBy,
+		Status:            string(run.Status),
+		DatesSynced:       run.DatesSynced,
+		HoldingsCount:     run.HoldingsCount,
+		TransactionsCount: run.TransactionsCount,
+		StartedAt:         run.StartedAt,
+		FinishedAt:        run.FinishedAt,
+	}
+	if run.Error != nil {
+		response.Error = *run.Error
+	}
+	return response
+}
+
+func syncJobToResponse(job *models.SyncJob) *schemas.SyncJobResponse {
+	response := &schemas.SyncJobResponse{
+		ID:             job.ID,
+		AccountID:      job.ClientID,
+		StartDate:      schemas.Date{Time: job.StartDate},
+		EndDate:        schemas.Date{Time: job.EndDate},
+		Status:         string(job.Status),
+		Force:          job.Force,
+		Source:         job.Source,
+		TotalDates:     job.TotalDates,
+		ProcessedDates: job.ProcessedDates,
+		CreatedAt:      job.CreatedAt,
+		StartedAt:      job.StartedAt,
+		FinishedAt:     job.FinishedAt,
+	}
+	if job.Error != nil {
+		response.Error = *job.Error
+	}
+	return response
 }
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"context"
+	"server/src/schemas"
+	"server/src/services"
+	"time"
+)
+
+type BenchmarksControllerI interface {
+	GetBenchmarkNames(ctx context.Context) ([]string, error)
+	GetBenchmarkValues(ctx context.Context, name string, startDate, endDate time.Time) ([]*schemas.BenchmarkValueResponse, error)
+	SetBenchmarkValues(ctx context.Context, name string, req []schemas.BenchmarkValueRequest) ([]*schemas.BenchmarkValueResponse, error)
+	DeleteBenchmarkValue(ctx context.Context, name string, date time.Time) error
+}
+
+type BenchmarksController struct {
+	BenchmarkService services.BenchmarkServiceI
+}
+
+func NewBenchmarksController(benchmarkService services.BenchmarkServiceI) *BenchmarksController {
+	return &BenchmarksController{BenchmarkService: benchmarkService}
+}
+
+func (c *BenchmarksController) GetBenchmarkNames(ctx context.Context) ([]string, error) {
+	return c.BenchmarkService.GetSeriesNames(ctx)
+}
+
+func (c *BenchmarksController) GetBenchmarkValues(ctx context.Context, name string, startDate, endDate time.Time) ([]*schemas.BenchmarkValueResponse, error) {
+	return c.BenchmarkService.GetValues(ctx, name, startDate, endDate)
+}
+
+func (c *BenchmarksController) SetBenchmarkValues(ctx context.Context, name string, req []schemas.BenchmarkValueRequest) ([]*schemas.BenchmarkValueResponse, error) {
+	return c.BenchmarkService.SetValues(ctx, name, req)
+}
+
+func (c *BenchmarksController) DeleteBenchmarkValue(ctx context.Context, name string, date time.Time) error {
+	return c.BenchmarkService.DeleteValue(ctx, name, date)
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"context"
+	"server/src/schemas"
+	"server/src/services"
+)
+
+type CategoriesControllerI interface {
+	GetCategories(ctx context.Context) ([]*schemas.CategoryResponse, error)
+	CreateCategory(ctx context.Context, req *schemas.CategoryRequest) (*schemas.CategoryResponse, error)
+	UpdateCategory(ctx context.Context, id int, req *schemas.CategoryRequest) (*schemas.CategoryResponse, error)
+	DeleteCategory(ctx context.Context, id int) error
+	GetCategoryRules(ctx context.Context) ([]*schemas.CategoryRuleResponse, error)
+	CreateCategoryRule(ctx context.Context, req *schemas.CategoryRuleRequest) (*schemas.CategoryRuleResponse, error)
+	UpdateCategoryRule(ctx context.Context, id int, req *schemas.CategoryRuleRequest) (*schemas.CategoryRuleResponse, error)
+	DeleteCategoryRule(ctx context.Context, id int) error
+	GetUnclassifiedAssets(ctx context.Context) ([]*schemas.AssetCategoryResponse, error)
+	SetAssetCategory(ctx context.Context, assetID int, req *schemas.AssetCategoryRequest) (*schemas.AssetCategoryResponse, error)
+}
+
+type CategoriesController struct {
+	CategoryService services.CategoryServiceI
+}
+
+func NewCategoriesController(categoryService services.CategoryServiceI) *CategoriesController {
+	return &CategoriesController{CategoryService: categoryService}
+}
+
+func (c *CategoriesController) GetCategories(ctx context.Context) ([]*schemas.CategoryResponse, error) {
+	return c.CategoryService.GetCategories(ctx)
+}
+
+func (c *CategoriesController) CreateCategory(ctx context.Context, req *schemas.CategoryRequest) (*schemas.CategoryResponse, error) {
+	return c.CategoryService.CreateCategory(ctx, req)
+}
+
+func (c *CategoriesController) UpdateCategory(ctx context.Context, id int, req *schemas.CategoryRequest) (*schemas.CategoryResponse, error) {
+	return c.CategoryService.UpdateCategory(ctx, id, req)
+}
+
+func (c *CategoriesController) DeleteCategory(ctx context.Context, id int) error {
+	return c.CategoryService.DeleteCategory(ctx, id)
+}
+
+func (c *CategoriesController) GetCategoryRules(ctx context.Context) ([]*schemas.CategoryRuleResponse, error) {
+	return c.CategoryService.GetRules(ctx)
+}
+
+func (c *CategoriesController) CreateCategoryRule(ctx context.Context, req *schemas.CategoryRuleRequest) (*schemas.CategoryRuleResponse, error) {
+	return c.CategoryService.CreateRule(ctx, req)
+}
+
+func (c *CategoriesController) UpdateCategoryRule(ctx context.Context, id int, req *schemas.CategoryRuleRequest) (*schemas.CategoryRuleResponse, error) {
+	return c.CategoryService.UpdateRule(ctx, id, req)
+}
+
+func (c *CategoriesController) DeleteCategoryRule(ctx context.Context, id int) error {
+	return c.CategoryService.DeleteRule(ctx, id)
+}
+
+// GetUnclassifiedAssets returns the assets no category rule matches
+func (c *CategoriesController) GetUnclassifiedAssets(ctx context.Context) ([]*schemas.AssetCategoryResponse, error) {
+	return c.CategoryService.GetUnclassifiedAssets(ctx)
+}
+
+func (c *CategoriesController) SetAssetCategory(ctx context.Context, assetID int, req *schemas.AssetCategoryRequest) (*schemas.AssetCategoryResponse, error) {
+	return c.CategoryService.SetAssetCategory(ctx, assetID, req)
+}
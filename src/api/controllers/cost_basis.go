@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"context"
+	"server/src/schemas"
+	"server/src/services"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type CostBasisControllerI interface {
+	GetPnL(ctx context.Context, clientIDs []string, date time.Time, method string) (*schemas.PnLReport, error)
+	GenerateXLSXPnL(ctx context.Context, clientIDs []string, date time.Time, method string) (*excelize.File, error)
+}
+
+type CostBasisController struct {
+	CostBasisService services.CostBasisServiceI
+}
+
+func NewCostBasisController(costBasisService services.CostBasisServiceI) *CostBasisController {
+	return &CostBasisController{CostBasisService: costBasisService}
+}
+
+// GetPnL returns the cost basis of the assets of the accounts with their realized and unrealized profit or loss
+func (c *CostBasisController) GetPnL(ctx context.Context, clientIDs []string, date time.Time, method string) (*schemas.PnLReport, error) {
+	return c.CostBasisService.GetPnL(ctx, clientIDs, date, method)
+}
+
+func (c *CostBasisController) GenerateXLSXPnL(ctx context.Context, clientIDs []string, date time.Time, method string) (*excelize.File, error) {
+	report, err := c.CostBasisService.GetPnL(ctx, clientIDs, date, method)
+	if err != nil {
+		return nil, err
+	}
+	return c.CostBasisService.GenerateXLSXPnL(ctx, report)
+}
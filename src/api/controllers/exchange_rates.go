@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"context"
+	"server/src/schemas"
+	"server/src/services"
+	"time"
+)
+
+type ExchangeRatesControllerI interface {
+	GetExchangeRates(ctx context.Context, currency string, startDate, endDate time.Time) ([]*schemas.ExchangeRateResponse, error)
+	SetExchangeRates(ctx context.Context, currency string, req []schemas.ExchangeRateRequest) ([]*schemas.ExchangeRateResponse, error)
+	DeleteExchangeRate(ctx context.Context, currency string, date time.Time) error
+}
+
+type ExchangeRatesController struct {
+	ExchangeRateService services.ExchangeRateServiceI
+}
+
+func NewExchangeRatesController(exchangeRateService services.ExchangeRateServiceI) *ExchangeRatesController {
+	return &ExchangeRatesController{ExchangeRateService: exchangeRateService}
+}
+
+func (c *ExchangeRatesController) GetExchangeRates(ctx context.Context, currency string, startDate, endDate time.Time) ([]*schemas.ExchangeRateResponse, error) {
+	return c.ExchangeRateService.GetRates(ctx, currency, startDate, endDate)
+}
+
+func (c *ExchangeRatesController) SetExchangeRates(ctx context.Context, currency string, req []schemas.ExchangeRateRequest) ([]*schemas.ExchangeRateResponse, error) {
+	return c.ExchangeRateService.SetRates(ctx, currency, req)
+}
+
+func (c *ExchangeRatesController) DeleteExchangeRate(ctx context.Context, currency string, date time.Time) error {
+	return c.ExchangeRateService.DeleteRate(ctx, currency, date)
+}
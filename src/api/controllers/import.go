@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"context"
+	"server/src/schemas"
+	"server/src/services"
+	"time"
+)
+
+type ImportControllerI interface {
+	ImportESCOExport(ctx context.Context, accountID string, exportType services.ESCOExportType, data []byte, date *time.Time) (*schemas.ESCOImportResponse, error)
+	ImportSpreadsheet(ctx context.Context, accountID, source string, format services.SpreadsheetFormat, data []byte, mapping *schemas.SpreadsheetMapping, dryRun bool) (*schemas.SpreadsheetImportResponse, error)
+}
+
+type ImportController struct {
+	ImportService            services.ImportServiceI
+	SpreadsheetImportService services.SpreadsheetImportServiceI
+}
+
+func NewImportController(importService services.ImportServiceI, spreadsheetImportService services.SpreadsheetImportServiceI) *ImportController {
+	return &ImportController{ImportService: importService, SpreadsheetImportService: spreadsheetImportService}
+}
+
+// ImportESCOExport stores the content of a raw ESCO export as the account data
+func (c *ImportController) ImportESCOExport(ctx context.Context, accountID string, exportType services.ESCOExportType, data []byte, date *time.Time) (*schemas.ESCOImportResponse, error) {
+	return c.ImportService.ImportESCOExport(ctx, accountID, exportType, data, date)
+}
+
+// ImportSpreadsheet stores the holdings or transactions of a CSV or XLSX file as the account data
+func (c *ImportController) ImportSpreadsheet(ctx context.Context, accountID, source string, format services.SpreadsheetFormat, data []byte, mapping *schemas.SpreadsheetMapping, dryRun bool) (*schemas.SpreadsheetImportResponse, error) {
+	return c.SpreadsheetImportService.ImportSpreadsheet(ctx, accountID, source, format, data, mapping, dryRun)
+}
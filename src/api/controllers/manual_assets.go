@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"context"
+	"server/src/schemas"
+	"server/src/services"
+	"time"
+)
+
+type ManualAssetsControllerI interface {
+	GetManualAssets(ctx context.Context, accountID string) ([]*schemas.ManualAssetResponse, error)
+	CreateManualAsset(ctx context.Context, accountID string, req *schemas.ManualAssetRequest) (*schemas.ManualAssetResponse, error)
+	UpdateManualAsset(ctx context.Context, accountID string, assetID int, req *schemas.ManualAssetRequest) (*schemas.ManualAssetResponse, error)
+	DeleteManualAsset(ctx context.Context, accountID string, assetID int) error
+	SetManualAssetValuation(ctx context.Context, accountID string, assetID int, req *schemas.ManualValuationRequest) (*schemas.ManualAssetResponse, error)
+	DeleteManualAssetValuation(ctx context.Context, accountID string, assetID int, date time.Time) error
+	AddManualAssetCashFlow(ctx context.Context, accountID string, assetID int, req *schemas.ManualCashFlowRequest) (*schemas.ManualAssetResponse, error)
+	DeleteManualAssetCashFlows(ctx context.Context, accountID string, assetID int, date time.Time) error
+}
+
+type ManualAssetsController struct {
+	ManualAssetService services.ManualAssetServiceI
+}
+
+func NewManualAssetsController(manualAssetService services.ManualAssetServiceI) *ManualAssetsController {
+	return &ManualAssetsController{ManualAssetService: manualAssetService}
+}
+
+func (c *ManualAssetsController) GetManualAssets(ctx context.Context, accountID string) ([]*schemas.ManualAssetResponse, error) {
+	return c.ManualAssetService.GetManualAssets(ctx, accountID)
+}
+
+func (c *ManualAssetsController) CreateManualAsset(ctx context.Context, accountID string, req *schemas.ManualAssetRequest) (*schemas.ManualAssetResponse, error) {
+	return c.ManualAssetService.CreateManualAsset(ctx, accountID, req)
+}
+
+func (c *ManualAssetsController) UpdateManualAsset(ctx context.Context, accountID string, assetID int, req *schemas.ManualAssetRequest) (*schemas.ManualAssetResponse, error) {
+	return c.ManualAssetService.UpdateManualAsset(ctx, accountID, assetID, req)
+}
+
+func (c *ManualAssetsController) DeleteManualAsset(ctx context.Context, accountID string, assetID int) error {
+	return c.ManualAssetService.DeleteManualAsset(ctx, accountID, assetID)
+}
+
+func (c *ManualAssetsController) SetManualAssetValuation(ctx context.Context, accountID string, assetID int, req *schemas.ManualValuationRequest) (*schemas.ManualAssetResponse, error) {
+	return c.ManualAssetService.SetValuation(ctx, accountID, assetID, req)
+}
+
+func (c *ManualAssetsController) DeleteManualAssetValuation(ctx context.Context, accountID string, assetID int, date time.Time) error {
+	return c.ManualAssetService.DeleteValuation(ctx, accountID, assetID, date)
+}
+
+func (c *ManualAssetsController) AddManualAssetCashFlow(ctx context.Context, accountID string, assetID int, req *schemas.ManualCashFlowRequest) (*schemas.ManualAssetResponse, error) {
+	return c.ManualAssetService.AddCashFlow(ctx, accountID, assetID, req)
+}
+
+func (c *ManualAssetsController) DeleteManualAssetCashFlows(ctx context.Context, accountID string, assetID int, date time.Time) error {
+	return c.ManualAssetService.DeleteCashFlows(ctx, accountID, assetID, date)
+}
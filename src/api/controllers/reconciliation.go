@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"context"
+	"server/src/schemas"
+	"server/src/services"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type ReconciliationControllerI interface {
+	GetReconciliation(ctx context.Context, clientID string, startDate, endDate time.Time, tolerance float64) (*schemas.ReconciliationReport, error)
+	GenerateXLSXReconciliation(ctx context.Context, clientID string, startDate, endDate time.Time, tolerance float64) (*excelize.File, error)
+}
+
+type ReconciliationController struct {
+	ReconciliationService services.ReconciliationServiceI
+}
+
+func NewReconciliationController(reconciliationService services.ReconciliationServiceI) *ReconciliationController {
+	return &ReconciliationController{ReconciliationService: reconciliationService}
+}
+
+// GetReconciliation returns the per asset discrepancies between the stored holdings and transactions
+func (c *ReconciliationController) GetReconciliation(ctx context.Context, clientID string, startDate, endDate time.Time, tolerance float64) (*schemas.ReconciliationReport, error) {
+	return c.ReconciliationService.Reconcile(ctx, clientID, startDate, endDate, tolerance)
+}
+
+func (c *ReconciliationController) GenerateXLSXReconciliation(ctx context.Context, clientID string, startDate, endDate time.Time, tolerance float64) (*excelize.File, error) {
+	report, err := c.ReconciliationService.Reconcile(ctx, clientID, startDate, endDate, tolerance)
+	if err != nil {
+		return nil, err
+	}
+	return c.ReconciliationService.GenerateXLSXReconciliation(ctx, report)
+}
@@ -6,15 +6,16 @@ import (
 	"server/src/clients/esco"
 	"server/src/schemas"
 	"server/src/services"
+	"server/src/utils"
 	"time"
 
 	"github.com/xuri/excelize/v2"
 )
 
 type ReportsControllerI interface {
-	GetReport(ctx context.Context, clientIDs []string, variablesWithValuations map[string]*schemas.VariableWithValuationResponse, startDate, endDate time.Time, interval time.Duration) (*schemas.AccountsReports, error)
-	GenerateXLSXReportFromClientIDs(ctx context.Context, clientIDs []string, variablesWithValuations map[string]*schemas.VariableWithValuationResponse, startDate, endDate time.Time, interval time.Duration) (*excelize.File, error)
-	GeneratePDFReportFromClientIDs(ctx context.Context, clientIDs []string, variablesWithValuations map[string]*schemas.VariableWithValuationResponse, startDate, endDate time.Time, interval time.Duration) ([]byte, error)
+	GetReport(ctx context.Context, clientIDs []string, variablesWithValuations map[string]*schemas.VariableWithValuationResponse, startDate, endDate time.Time, interval time.Duration, transactionTypes []string, returnMethod, currency string, benchmarks []schemas.BenchmarkRequest, riskFreeRateID, targetAllocation string) (*schemas.AccountsReports, error)
+	GenerateXLSXReportFromClientIDs(ctx context.Context, clientIDs []string, variablesWithValuations map[string]*schemas.VariableWithValuationResponse, startDate, endDate time.Time, interval time.Duration, transactionTypes []string, returnMethod, currency string, benchmarks []schemas.BenchmarkRequest, riskFreeRateID, targetAllocation string) (*excelize.File, error)
+	GeneratePDFReportFromClientIDs(ctx context.Context, clientIDs []string, variablesWithValuations map[string]*schemas.VariableWithValuationResponse, startDate, endDate time.Time, interval time.Duration, transactionTypes []string, returnMethod, currency string, benchmarks []schemas.BenchmarkRequest, riskFreeRateID, targetAllocation string) ([]byte, error)
 }
 
 type ReportsController struct {
@@ -23,6 +24,12 @@ type ReportsController struct {
 	ReportService       services.ReportServiceI
 	ReportParserService services.ReportParserServiceI
 	AccountService      services.AccountServiceI
+	ExchangeRateService services.ExchangeRateServiceI
+	BenchmarkService    services.BenchmarkServiceI
+	// TargetAllocationService loads the allocations the returns are attributed against
+	TargetAllocationService services.TargetAllocationServiceI
+	// RiskFreeRateID is the rate the risk of peso reports is measured against when the request names none
+	RiskFreeRateID string
 }
 
 func NewReportsController(
@@ -31,13 +38,21 @@ func NewReportsController(
 	reportService services.ReportServiceI,
 	reportParserService services.ReportParserServiceI,
 	accountService services.AccountServiceI,
+	exchangeRateService services.ExchangeRateServiceI,
+	benchmarkService services.BenchmarkServiceI,
+	targetAllocationService services.TargetAllocationServiceI,
+	riskFreeRateID string,
 ) *ReportsController {
 	return &ReportsController{
-		ESCOClient:          escoClient,
-		BCRAClient:          bcraClient,
-		ReportService:       reportService,
-		ReportParserService: reportParserService,
-		AccountService:      accountService,
+		ESCOClient:              escoClient,
+		BCRAClient:              bcraClient,
+		ReportService:           reportService,
+		ReportParserService:     reportParserService,
+		AccountService:          accountService,
+		ExchangeRateService:     exchangeRateService,
+		BenchmarkService:        benchmarkService,
+		TargetAllocationService: targetAllocationService,
+		RiskFreeRateID:          riskFreeRateID,
 	}
 }
 
@@ -47,9 +62,27 @@ func (rc *ReportsController) GetReport(
 	variablesWithValuations map[string]*schemas.VariableWithValuationResponse,
 	startDate, endDate time.Time,
 	interval time.Duration,
+	transactionTypes []string,
+	returnMethod, currency string,
+	benchmarks []schemas.BenchmarkRequest,
+	riskFreeRateID, targetAllocation string,
 ) (*schemas.AccountsReports, error) {
+	if currency == "" {
+		currency = services.ReportCurrencyARS
+	}
+	// Holdings and transactions are converted with the rate of their date, the official one (A3500) unless
+	// the report is in MEP or CCL dollars
+	var officialRates []schemas.VariableValuation
+	if a3500, exists := variablesWithValuations["USD A3500"]; exists && a3500 != nil {
+		officialRates = a3500.Valuations
+	}
+	converter, err := rc.ExchangeRateService.GetConverter(ctx, currency, officialRates, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build account state from client ID using existing AccountService
-	accountStateByCategory, err := rc.AccountService.GetMultiAccountStateByCategory(ctx, clientIDs, startDate, endDate, interval)
+	accountStateByCategory, err := rc.AccountService.GetMultiAccountStateByCategory(ctx, clientIDs, startDate, endDate, interval, transactionTypes, converter)
 	if err != nil {
 		return nil, err
 	}
@@ -60,12 +93,101 @@ func (rc *ReportsController) GetReport(
 		return nil, err
 	}
 	accountReports.ReferenceVariables = &variablesWithValuations
+	if returnMethod != "" {
+		accountReports.ReturnMethod = returnMethod
+	}
+	accountReports.Currency = currency
+	// Inflation only measures the loss of value of the pesos
+	if currency == services.ReportCurrencyARS {
+		rc.ReportService.CalculateRealReturns(accountReports, variablesWithValuations["Inflacion Mensual"])
+	}
+	if len(benchmarks) > 0 {
+		// Loaded from two months earlier so monthly rates have the rate of the month before the report
+		// and levels the last one before it
+		benchmarkSeries, err := rc.BenchmarkService.GetBenchmarkSeries(ctx, benchmarks, startDate.AddDate(0, -2, 0), endDate)
+		if err != nil {
+			return nil, err
+		}
+		rc.ReportService.CalculateBenchmarks(accountReports, benchmarkSeries)
+	}
+	if targetAllocation != "" {
+		if err := rc.calculateAttribution(ctx, accountReports, targetAllocation, startDate, endDate); err != nil {
+			return nil, err
+		}
+	}
+
+	riskFreeRates, err := rc.getRiskFreeRates(ctx, riskFreeRateID, currency, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	var riskFreeValuations []schemas.VariableValuation
+	if riskFreeRates != nil {
+		accountReports.RiskFreeRateID = riskFreeRates.ID
+		riskFreeValuations = riskFreeRates.Valuations
+	}
+	rc.ReportService.CalculateRiskMetrics(accountReports, riskFreeValuations)
 	return accountReports, nil
 }
 
-func (rc *ReportsController) GenerateXLSXReportFromClientIDs(ctx context.Context, clientIDs []string, variablesWithValuations map[string]*schemas.VariableWithValuationResponse, startDate, endDate time.Time, interval time.Duration) (*excelize.File, error) {
+// calculateAttribution attributes the returns of the report against the target allocation, loading the
+// benchmarks of its categories
+func (rc *ReportsController) calculateAttribution(ctx context.Context, accountReports *schemas.AccountsReports, targetAllocation string, startDate, endDate time.Time) error {
+	target, err := rc.TargetAllocationService.GetTargetAllocation(ctx, targetAllocation)
+	if err != nil {
+		return err
+	}
+
+	var categories []string
+	var benchmarks []schemas.BenchmarkRequest
+	for _, category := range target.Categories {
+		if category.Benchmark != "" {
+			categories = append(categories, category.Category)
+			benchmarks = append(benchmarks, schemas.BenchmarkRequest{ID: category.Benchmark, Kind: category.BenchmarkKind})
+		}
+	}
+	categoryBenchmarks := make(map[string]schemas.BenchmarkSeries)
+	if len(benchmarks) > 0 {
+		// Loaded from earlier like the benchmarks of the report
+		benchmarkSeries, err := rc.BenchmarkService.GetBenchmarkSeries(ctx, benchmarks, startDate.AddDate(0, -2, 0), endDate)
+		if err != nil {
+			return err
+		}
+		for i, series := range benchmarkSeries {
+			categoryBenchmarks[categories[i]] = series
+		}
+	}
+
+	rc.ReportService.CalculateAttribution(accountReports, target, categoryBenchmarks)
+	return nil
+}
+
+// getRiskFreeRates loads the rate the risk of the report is measured against, the requested one or,
+// as it is a peso rate, the configured one for peso reports. Failing to load the configured rate only
+// leaves the report without risk-free rate.
+func (rc *ReportsController) getRiskFreeRates(ctx context.Context, riskFreeRateID, currency string, startDate, endDate time.Time) (*schemas.BenchmarkSeries, error) {
+	requested := riskFreeRateID != ""
+	if !requested {
+		if currency != services.ReportCurrencyARS || rc.RiskFreeRateID == "" {
+			return nil, nil
+		}
+		riskFreeRateID = rc.RiskFreeRateID
+	}
+
+	// Loaded from before the report so its first days have a rate
+	series, err := rc.BenchmarkService.GetBenchmarkSeries(ctx, []schemas.BenchmarkRequest{{ID: riskFreeRateID, Kind: services.BenchmarkKindAnnual}}, startDate.AddDate(0, 0, -15), endDate)
+	if err != nil {
+		if requested {
+			return nil, err
+		}
+		utils.LoggerFromContext(ctx).Warnf("Measuring risk without risk-free rate, error loading rate %s: %v", riskFreeRateID, err)
+		return nil, nil
+	}
+	return &series[0], nil
+}
+
+func (rc *ReportsController) GenerateXLSXReportFromClientIDs(ctx context.Context, clientIDs []string, variablesWithValuations map[string]*schemas.VariableWithValuationResponse, startDate, endDate time.Time, interval time.Duration, transactionTypes []string, returnMethod, currency string, benchmarks []schemas.BenchmarkRequest, riskFreeRateID, targetAllocation string) (*excelize.File, error) {
 	// Get the report data
-	accountsReport, err := rc.GetReport(ctx, clientIDs, variablesWithValuations, startDate, endDate, interval)
+	accountsReport, err := rc.GetReport(ctx, clientIDs, variablesWithValuations, startDate, endDate, interval, transactionTypes, returnMethod, currency, benchmarks, riskFreeRateID, targetAllocation)
 	if err != nil {
 		return nil, err
 	}
@@ -80,9 +202,9 @@ func (rc *ReportsController) GenerateXLSXReportFromClientIDs(ctx context.Context
 	return rc.ReportService.GenerateXLSXReport(ctx, dataframes)
 }
 
-func (rc *ReportsController) GeneratePDFReportFromClientIDs(ctx context.Context, clientIDs []string, variablesWithValuations map[string]*schemas.VariableWithValuationResponse, startDate, endDate time.Time, interval time.Duration) ([]byte, error) {
+func (rc *ReportsController) GeneratePDFReportFromClientIDs(ctx context.Context, clientIDs []string, variablesWithValuations map[string]*schemas.VariableWithValuationResponse, startDate, endDate time.Time, interval time.Duration, transactionTypes []string, returnMethod, currency string, benchmarks []schemas.BenchmarkRequest, riskFreeRateID, targetAllocation string) ([]byte, error) {
 	// Get the report data
-	accountsReport, err := rc.GetReport(ctx, clientIDs, variablesWithValuations, startDate, endDate, interval)
+	accountsReport, err := rc.GetReport(ctx, clientIDs, variablesWithValuations, startDate, endDate, interval, transactionTypes, returnMethod, currency, benchmarks, riskFreeRateID, targetAllocation)
 	if err != nil {
 		return nil, err
 	}
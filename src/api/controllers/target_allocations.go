@@ -0,0 +1,38 @@
+package controllers
+
+import (
+	"context"
+	"server/src/schemas"
+	"server/src/services"
+)
+
+type TargetAllocationsControllerI interface {
+	GetTargetAllocationNames(ctx context.Context) ([]string, error)
+	GetTargetAllocation(ctx context.Context, name string) (*schemas.TargetAllocationResponse, error)
+	SetTargetAllocation(ctx context.Context, name string, req *schemas.TargetAllocationRequest) (*schemas.TargetAllocationResponse, error)
+	DeleteTargetAllocation(ctx context.Context, name string) error
+}
+
+type TargetAllocationsController struct {
+	TargetAllocationService services.TargetAllocationServiceI
+}
+
+func NewTargetAllocationsController(targetAllocationService services.TargetAllocationServiceI) *TargetAllocationsController {
+	return &TargetAllocationsController{TargetAllocationService: targetAllocationService}
+}
+
+func (c *TargetAllocationsController) GetTargetAllocationNames(ctx context.Context) ([]string, error) {
+	return c.TargetAllocationService.GetTargetAllocationNames(ctx)
+}
+
+func (c *TargetAllocationsController) GetTargetAllocation(ctx context.Context, name string) (*schemas.TargetAllocationResponse, error) {
+	return c.TargetAllocationService.GetTargetAllocation(ctx, name)
+}
+
+func (c *TargetAllocationsController) SetTargetAllocation(ctx context.Context, name string, req *schemas.TargetAllocationRequest) (*schemas.TargetAllocationResponse, error) {
+	return c.TargetAllocationService.SetTargetAllocation(ctx, name, req)
+}
+
+func (c *TargetAllocationsController) DeleteTargetAllocation(ctx context.Context, name string) error {
+	return c.TargetAllocationService.DeleteTargetAllocation(ctx, name)
+}
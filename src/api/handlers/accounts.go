@@ -3,9 +3,13 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"server/src/models"
 	"server/src/schemas"
+	"server/src/services"
 	"server/src/utils"
+	"strconv"
 	"strings"
 	"time"
 
@@ -13,6 +17,8 @@ import (
 	"github.com/go-chi/jwtauth"
 )
 
+const defaultSyncRunsLimit = 50
+
 func (h *Handler) GetAllAccounts(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
 	defer cancel()
@@ -70,6 +76,12 @@ func (h *Handler) GetAccountState(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	transactionTypes, err := parseTransactionTypes(r)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
 	var accountState *schemas.AccountStateByCategory
 	if dateStr != "" {
 		date, err = time.Parse(utils.ShortDashDateLayout, dateStr)
@@ -78,7 +90,7 @@ func (h *Handler) GetAccountState(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		date = (date.Add(26 * time.Hour)).In(location)
-		accountState, err = h.AccountsController.GetMultiAccountStateByCategoryDateRange(ctx, token, ids, date, date, interval.ToDuration())
+		accountState, err = h.AccountsController.GetMultiAccountStateByCategoryDateRange(ctx, token, ids, date, date, interval.ToDuration(), transactionTypes)
 	} else if startDateStr != "" && endDateStr != "" {
 		startDate, err = time.Parse(utils.ShortDashDateLayout, startDateStr)
 		if err != nil {
@@ -93,7 +105,7 @@ func (h *Handler) GetAccountState(w http.ResponseWriter, r *http.Request) {
 		//Set +26 hours since we use ARG timezone (UTC-3)
 		startDate = (startDate.Add(26 * time.Hour)).In(location)
 		endDate = (endDate.Add(26 * time.Hour)).In(location)
-		accountState, err = h.AccountsController.GetMultiAccountStateByCategoryDateRange(ctx, token, ids, startDate, endDate, interval.ToDuration())
+		accountState, err = h.AccountsController.GetMultiAccountStateByCategoryDateRange(ctx, token, ids, startDate, endDate, interval.ToDuration(), transactionTypes)
 	}
 
 	if err != nil {
@@ -104,8 +116,13 @@ func (h *Handler) GetAccountState(w http.ResponseWriter, r *http.Request) {
 	h.respond(w, r, accountState, 200)
 }
 
-// SyncAccount handles the POST request to sync account data
+// SyncAccount handles the POST request to sync account data.
+// The sync is queued as a job and its ID is returned so the client can poll its progress.
 func (h *Handler) SyncAccount(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
 	var syncRequest schemas.SyncRequest
 	if err := json.NewDecoder(r.Body).Decode(&syncRequest); err != nil {
 		h.HandleErrors(w, err)
@@ -130,19 +147,159 @@ func (h *Handler) SyncAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get token from request header
+	// The job runs with the service account token, but only authenticated callers may queue it
+	if jwtauth.TokenFromHeader(r) == "" {
+		h.HandleErrors(w, utils.NewHTTPError(http.StatusUnauthorized, "auth token not detected"))
+		return
+	}
+
+	ctx = utils.WithSyncSource(ctx, syncRequest.Source)
+	job, err := h.AccountsController.SubmitSyncJob(ctx, syncRequest.AccountID, syncRequest.StartDate.ToTime(), syncRequest.EndDate.ToTime(), syncRequest.Force)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, job, http.StatusAccepted)
+}
+
+// BulkSyncAccounts handles the POST request to sync several accounts at once.
+// A sync job is queued for every account and their IDs are returned so the client can poll them.
+// It responds 207 when some accounts could not be queued, with the error of each one.
+func (h *Handler) BulkSyncAccounts(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 20*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	var bulkSyncRequest schemas.BulkSyncRequest
+	if err := json.NewDecoder(r.Body).Decode(&bulkSyncRequest); err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	// Validate request
+	if len(bulkSyncRequest.AccountIDs) == 0 && bulkSyncRequest.Filter == "" {
+		h.HandleErrors(w, utils.BadRequest("accountIDs or filter is required"))
+		return
+	}
+	if bulkSyncRequest.StartDate.IsZero() {
+		h.HandleErrors(w, utils.BadRequest("startDate is required"))
+		return
+	}
+	if bulkSyncRequest.EndDate.IsZero() {
+		h.HandleErrors(w, utils.BadRequest("endDate is required"))
+		return
+	}
+	if bulkSyncRequest.EndDate.Before(bulkSyncRequest.StartDate.ToTime()) {
+		h.HandleErrors(w, utils.BadRequest("endDate must be after startDate"))
+		return
+	}
+
 	token := jwtauth.TokenFromHeader(r)
 	if token == "" {
 		h.HandleErrors(w, utils.NewHTTPError(http.StatusBadRequest, "empty token detected"))
 		return
 	}
 
-	// Call controller to sync account
-	accountState, err := h.AccountsController.SyncAccount(context.Background(), token, syncRequest.AccountID, syncRequest.StartDate.ToTime(), syncRequest.EndDate.ToTime())
+	response, err := h.AccountsController.BulkSyncAccounts(ctx, token, &bulkSyncRequest)
 	if err != nil {
 		h.HandleErrors(w, err)
 		return
 	}
 
-	h.respond(w, r, accountState, http.StatusOK)
+	status := http.StatusAccepted
+	if response.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	h.respond(w, r, response, status)
+}
+
+// GetSyncJob handles the GET request to check the status of a sync job
+func (h *Handler) GetSyncJob(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	jobID, err := strconv.Atoi(chi.URLParam(r, "jobID"))
+	if err != nil {
+		h.HandleErrors(w, utils.BadRequest("invalid job id"))
+		return
+	}
+
+	job, err := h.AccountsController.GetSyncJob(ctx, jobID)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, job, http.StatusOK)
+}
+
+// GetSyncRuns handles the GET request to list the sync run history of an account
+func (h *Handler) GetSyncRuns(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	accountID := chi.URLParam(r, "ids")
+	if accountID == "" || strings.Contains(accountID, ",") {
+		h.HandleErrors(w, utils.BadRequest("a single account id is required"))
+		return
+	}
+
+	limit := defaultSyncRunsLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			h.HandleErrors(w, utils.BadRequest("limit must be a positive integer"))
+			return
+		}
+	}
+
+	runs, err := h.AccountsController.GetSyncRuns(ctx, accountID, limit)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, runs, http.StatusOK)
+}
+
+// parseTransactionTypes reads the comma-separated transactionType query parameter,
+// returning nil when it is not set so every transaction is included
+func parseTransactionTypes(r *http.Request) ([]string, error) {
+	transactionTypesStr := r.URL.Query().Get("transactionType")
+	if transactionTypesStr == "" {
+		return nil, nil
+	}
+	transactionTypes := strings.Split(transactionTypesStr, ",")
+	for _, transactionType := range transactionTypes {
+		if !models.IsValidTransactionType(transactionType) {
+			return nil, utils.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("invalid transactionType %q, expected one of %s", transactionType, strings.Join(models.TransactionTypes, ", ")))
+		}
+	}
+	return transactionTypes, nil
+}
+
+// parseReturnMethod reads the method the report returns are presented with, time-weighted by default
+func parseReturnMethod(r *http.Request) (string, error) {
+	returnMethod := strings.ToLower(r.URL.Query().Get("returnMethod"))
+	if returnMethod == "" {
+		return services.ReturnMethodTWR, nil
+	}
+	if !services.IsValidReturnMethod(returnMethod) {
+		return "", utils.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("invalid returnMethod %q, expected one of %s, %s", returnMethod, services.ReturnMethodTWR, services.ReturnMethodMWR))
+	}
+	return returnMethod, nil
+}
+
+// parseReportCurrency reads the currency the report values are expressed in, pesos by default
+func parseReportCurrency(r *http.Request) (string, error) {
+	currency := strings.ToUpper(r.URL.Query().Get("currency"))
+	if currency == "" {
+		return services.ReportCurrencyARS, nil
+	}
+	if !services.IsValidReportCurrency(currency) {
+		return "", utils.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("invalid currency %q, expected one of %s", currency, strings.Join(services.ReportCurrencies, ", ")))
+	}
+	return currency, nil
 }
@@ -8,6 +8,7 @@ import (
 	"server/src/api/controllers"
 	"server/src/clients/bcra"
 	"server/src/clients/esco"
+	"server/src/config"
 	"server/src/services"
 	"server/src/utils"
 
@@ -16,14 +17,23 @@ import (
 )
 
 type Handler struct {
-	Logger                   *logrus.Logger
-	Controller               controllers.IController
-	AccountsController       controllers.AccountsControllerI
-	ReportsController        controllers.ReportsControllerI
-	ReportScheduleController controllers.ReportScheduleControllerI
+	Logger                      *logrus.Logger
+	Controller                  controllers.IController
+	AccountsController          controllers.AccountsControllerI
+	ReportsController           controllers.ReportsControllerI
+	ReportScheduleController    controllers.ReportScheduleControllerI
+	ReconciliationController    controllers.ReconciliationControllerI
+	ImportController            controllers.ImportControllerI
+	CategoriesController        controllers.CategoriesControllerI
+	ManualAssetsController      controllers.ManualAssetsControllerI
+	ExchangeRatesController     controllers.ExchangeRatesControllerI
+	BenchmarksController        controllers.BenchmarksControllerI
+	TargetAllocationsController controllers.TargetAllocationsControllerI
+	CostBasisController         controllers.CostBasisControllerI
 }
 
 func NewHandler(
+	cfg *config.Config,
 	logger *logrus.Logger,
 	db *pgxpool.Pool,
 	escoClient esco.ESCOServiceClientI,
@@ -31,9 +41,19 @@ func NewHandler(
 	escoService services.ESCOServiceI,
 	syncService services.SyncServiceI,
 	accountService services.AccountServiceI,
+	syncJobService services.SyncJobServiceI,
+	reconciliationService services.ReconciliationServiceI,
+	importService services.ImportServiceI,
+	categoryService services.CategoryServiceI,
+	spreadsheetImportService services.SpreadsheetImportServiceI,
+	manualAssetService services.ManualAssetServiceI,
+	exchangeRateService services.ExchangeRateServiceI,
+	benchmarkService services.BenchmarkServiceI,
+	targetAllocationService services.TargetAllocationServiceI,
+	costBasisService services.CostBasisServiceI,
 ) (*Handler, error) {
 	controller := controllers.NewController(escoClient, bcraClient)
-	accountsController := controllers.NewAccountsController(escoClient, escoService, syncService, accountService)
+	accountsController := controllers.NewAccountsController(escoClient, escoService, syncService, accountService, syncJobService)
 
 	// Create report service
 	reportService := services.NewReportService()
@@ -41,14 +61,30 @@ func NewHandler(
 	// Create report parser service
 	reportParserService := services.NewReportParserService()
 
-	reportsController := controllers.NewReportsController(escoClient, bcraClient, reportService, reportParserService, accountService)
+	reportsController := controllers.NewReportsController(escoClient, bcraClient, reportService, reportParserService, accountService, exchangeRateService, benchmarkService, targetAllocationService, cfg.Reports.RiskFreeRateID)
 	reportScheduleController := controllers.NewReportScheduleController(db)
+	reconciliationController := controllers.NewReconciliationController(reconciliationService)
+	importController := controllers.NewImportController(importService, spreadsheetImportService)
+	categoriesController := controllers.NewCategoriesController(categoryService)
+	manualAssetsController := controllers.NewManualAssetsController(manualAssetService)
+	exchangeRatesController := controllers.NewExchangeRatesController(exchangeRateService)
+	benchmarksController := controllers.NewBenchmarksController(benchmarkService)
+	targetAllocationsController := controllers.NewTargetAllocationsController(targetAllocationService)
+	costBasisController := controllers.NewCostBasisController(costBasisService)
 	return &Handler{
-		Logger:                   logger,
-		Controller:               controller,
-		AccountsController:       accountsController,
-		ReportsController:        reportsController,
-		ReportScheduleController: reportScheduleController,
+		Logger:                      logger,
+		Controller:                  controller,
+		AccountsController:          accountsController,
+		ReportsController:           reportsController,
+		ReportScheduleController:    reportScheduleController,
+		ReconciliationController:    reconciliationController,
+		ImportController:            importController,
+		CategoriesController:        categoriesController,
+		ManualAssetsController:      manualAssetsController,
+		ExchangeRatesController:     exchangeRatesController,
+		BenchmarksController:        benchmarksController,
+		TargetAllocationsController: targetAllocationsController,
+		CostBasisController:         costBasisController,
 	}, nil
 }
 
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"server/src/schemas"
+	"server/src/services"
+	"server/src/utils"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetBenchmarkNames handles the GET request to list the custom series stored to compare reports against
+func (h *Handler) GetBenchmarkNames(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	names, err := h.BenchmarksController.GetBenchmarkNames(ctx)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, names, http.StatusOK)
+}
+
+// GetBenchmarkValues handles the GET request to list the values of a custom series.
+// The range defaults to the last year.
+func (h *Handler) GetBenchmarkValues(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	endDate := time.Now().UTC()
+	if endDateStr := r.URL.Query().Get("endDate"); endDateStr != "" {
+		var err error
+		if endDate, err = time.Parse(utils.ShortDashDateLayout, endDateStr); err != nil {
+			h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+			return
+		}
+	}
+	startDate := endDate.AddDate(-1, 0, 0)
+	if startDateStr := r.URL.Query().Get("startDate"); startDateStr != "" {
+		var err error
+		if startDate, err = time.Parse(utils.ShortDashDateLayout, startDateStr); err != nil {
+			h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+			return
+		}
+	}
+
+	values, err := h.BenchmarksController.GetBenchmarkValues(ctx, chi.URLParam(r, "name"), startDate, endDate)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, values, http.StatusOK)
+}
+
+// SetBenchmarkValues stores the values of a custom series, replacing the ones of the same dates
+func (h *Handler) SetBenchmarkValues(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	var req []schemas.BenchmarkValueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleErrors(w, utils.BadRequest(err.Error()))
+		return
+	}
+
+	values, err := h.BenchmarksController.SetBenchmarkValues(ctx, chi.URLParam(r, "name"), req)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, values, http.StatusOK)
+}
+
+// DeleteBenchmarkValue deletes the value of a custom series on the date of the route
+func (h *Handler) DeleteBenchmarkValue(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	date, err := time.Parse(utils.ShortDashDateLayout, chi.URLParam(r, "date"))
+	if err != nil {
+		h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+		return
+	}
+
+	if err := h.BenchmarksController.DeleteBenchmarkValue(ctx, chi.URLParam(r, "name"), date); err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, nil, http.StatusNoContent)
+}
+
+// parseBenchmarks reads the series the report is compared against, a comma separated list of BCRA
+// variable IDs or names of stored series, each optionally followed by its kind, e.g. 5,27:monthly,FONDO
+func parseBenchmarks(r *http.Request) ([]schemas.BenchmarkRequest, error) {
+	benchmarksStr := r.URL.Query().Get("benchmarks")
+	if benchmarksStr == "" {
+		return nil, nil
+	}
+	var benchmarks []schemas.BenchmarkRequest
+	for _, benchmarkStr := range strings.Split(benchmarksStr, ",") {
+		id, kind, _ := strings.Cut(strings.TrimSpace(benchmarkStr), ":")
+		if id == "" {
+			return nil, utils.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("invalid benchmarks %q, expected a comma separated list of IDs", benchmarksStr))
+		}
+		kind = strings.ToLower(kind)
+		if kind == "" {
+			kind = services.BenchmarkKindLevel
+		}
+		if !services.IsValidBenchmarkKind(kind) {
+			return nil, utils.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("invalid benchmark kind %q, expected one of %s", kind, strings.Join(services.BenchmarkKinds, ", ")))
+		}
+		benchmarks = append(benchmarks, schemas.BenchmarkRequest{ID: id, Kind: kind})
+	}
+	return benchmarks, nil
+}
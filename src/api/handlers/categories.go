@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"server/src/schemas"
+	"server/src/utils"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetCategories handles the GET request to list the asset categories
+func (h *Handler) GetCategories(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	categories, err := h.CategoriesController.GetCategories(ctx)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, categories, http.StatusOK)
+}
+
+// CreateCategory creates a new asset category
+func (h *Handler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	var req schemas.CategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleErrors(w, utils.BadRequest(err.Error()))
+		return
+	}
+
+	category, err := h.CategoriesController.CreateCategory(ctx, &req)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, category, http.StatusCreated)
+}
+
+// UpdateCategory renames or describes an existing asset category
+func (h *Handler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+		return
+	}
+
+	var req schemas.CategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleErrors(w, utils.BadRequest(err.Error()))
+		return
+	}
+
+	category, err := h.CategoriesController.UpdateCategory(ctx, id, &req)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, category, http.StatusOK)
+}
+
+// DeleteCategory deletes an asset category without assets
+func (h *Handler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+		return
+	}
+
+	if err := h.CategoriesController.DeleteCategory(ctx, id); err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, nil, http.StatusNoContent)
+}
+
+// GetCategoryRules handles the GET request to list the rules assigning categories to denominations
+func (h *Handler) GetCategoryRules(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	rules, err := h.CategoriesController.GetCategoryRules(ctx)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, rules, http.StatusOK)
+}
+
+// CreateCategoryRule creates a category rule and reclassifies the stored assets
+func (h *Handler) CreateCategoryRule(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	var req schemas.CategoryRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleErrors(w, utils.BadRequest(err.Error()))
+		return
+	}
+
+	rule, err := h.CategoriesController.CreateCategoryRule(ctx, &req)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, rule, http.StatusCreated)
+}
+
+// UpdateCategoryRule updates a category rule and reclassifies the stored assets
+func (h *Handler) UpdateCategoryRule(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+		return
+	}
+
+	var req schemas.CategoryRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleErrors(w, utils.BadRequest(err.Error()))
+		return
+	}
+
+	rule, err := h.CategoriesController.UpdateCategoryRule(ctx, id, &req)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, rule, http.StatusOK)
+}
+
+// DeleteCategoryRule deletes a category rule and reclassifies the stored assets
+func (h *Handler) DeleteCategoryRule(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+		return
+	}
+
+	if err := h.CategoriesController.DeleteCategoryRule(ctx, id); err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, nil, http.StatusNoContent)
+}
+
+// GetUnclassifiedAssets handles the GET request to list the assets without a category
+func (h *Handler) GetUnclassifiedAssets(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	assets, err := h.CategoriesController.GetUnclassifiedAssets(ctx)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, assets, http.StatusOK)
+}
+
+// SetAssetCategory assigns the category of an asset by hand. Reports read the category
+// from the assets table, so the change is reflected without syncing the accounts again.
+func (h *Handler) SetAssetCategory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+		return
+	}
+
+	var req schemas.AssetCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleErrors(w, utils.BadRequest(err.Error()))
+		return
+	}
+
+	asset, err := h.CategoriesController.SetAssetCategory(ctx, id, &req)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, asset, http.StatusOK)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"server/src/services"
+	"server/src/utils"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetPnL handles the GET request for the cost basis of the assets of the accounts, with the profit or loss
+// realized by their sales and the one of the units held. It is measured up to date, today by default, with
+// the FIFO method unless method=average. It is returned as JSON, or as an XLSX file when format=XLSX.
+func (h *Handler) GetPnL(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+	location, _ := time.LoadLocation("America/Argentina/Buenos_Aires")
+
+	ids := strings.Split(chi.URLParam(r, "ids"), ",")
+	if len(ids) == 0 || ids[0] == "" {
+		h.HandleErrors(w, utils.BadRequest("missing account ids"))
+		return
+	}
+
+	date := time.Now().In(location)
+	if dateStr := r.URL.Query().Get("date"); dateStr != "" {
+		parsed, err := time.Parse(utils.ShortDashDateLayout, dateStr)
+		if err != nil {
+			h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+			return
+		}
+		// Set +26 hours since we use ARG timezone (UTC-3)
+		date = (parsed.Add(26 * time.Hour)).In(location)
+	}
+
+	method := strings.ToLower(r.URL.Query().Get("method"))
+	if method == "" {
+		method = services.CostBasisMethodFIFO
+	}
+	if !services.IsValidCostBasisMethod(method) {
+		h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("invalid method %q, expected one of %s, %s", method, services.CostBasisMethodFIFO, services.CostBasisMethodAverage)))
+		return
+	}
+
+	if r.URL.Query().Get("format") == "XLSX" {
+		xlsxFile, err := h.CostBasisController.GenerateXLSXPnL(ctx, ids, date, method)
+		if err != nil {
+			h.HandleErrors(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", "attachment; filename=pnl.xlsx")
+
+		err = xlsxFile.Write(w)
+		if err != nil {
+			h.HandleErrors(w, err)
+			return
+		}
+		return
+	}
+
+	report, err := h.CostBasisController.GetPnL(ctx, ids, date, method)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, report, http.StatusOK)
+}
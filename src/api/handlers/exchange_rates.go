@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"server/src/schemas"
+	"server/src/utils"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetExchangeRates handles the GET request to list the stored rates of a dollar (MEP or CCL).
+// The range defaults to the last year.
+func (h *Handler) GetExchangeRates(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	endDate := time.Now().UTC()
+	if endDateStr := r.URL.Query().Get("endDate"); endDateStr != "" {
+		var err error
+		if endDate, err = time.Parse(utils.ShortDashDateLayout, endDateStr); err != nil {
+			h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+			return
+		}
+	}
+	startDate := endDate.AddDate(-1, 0, 0)
+	if startDateStr := r.URL.Query().Get("startDate"); startDateStr != "" {
+		var err error
+		if startDate, err = time.Parse(utils.ShortDashDateLayout, startDateStr); err != nil {
+			h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+			return
+		}
+	}
+
+	rates, err := h.ExchangeRatesController.GetExchangeRates(ctx, chi.URLParam(r, "currency"), startDate, endDate)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, rates, http.StatusOK)
+}
+
+// SetExchangeRates stores the rates of a dollar, replacing the ones of the same dates
+func (h *Handler) SetExchangeRates(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	var req []schemas.ExchangeRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleErrors(w, utils.BadRequest(err.Error()))
+		return
+	}
+
+	rates, err := h.ExchangeRatesController.SetExchangeRates(ctx, chi.URLParam(r, "currency"), req)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, rates, http.StatusOK)
+}
+
+// DeleteExchangeRate deletes the rate of a dollar on the date of the route
+func (h *Handler) DeleteExchangeRate(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	date, err := time.Parse(utils.ShortDashDateLayout, chi.URLParam(r, "date"))
+	if err != nil {
+		h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+		return
+	}
+
+	if err := h.ExchangeRatesController.DeleteExchangeRate(ctx, chi.URLParam(r, "currency"), date); err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, nil, http.StatusNoContent)
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"server/src/schemas"
+	"server/src/services"
+	"server/src/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const maxImportFileSize = 32 << 20
+
+// ImportESCOExport handles the multipart POST request to import a raw ESCO JSON export.
+// The form expects the export "type" (estado, boletos, liquidaciones or ctacte), the "file" and,
+// for estado de cuenta exports, the "date" the holdings were requested for.
+func (h *Handler) ImportESCOExport(w http.ResponseWriter, r *http.Request) {
+	ctx := utils.WithLogger(r.Context(), h.Logger)
+
+	accountID := chi.URLParam(r, "ids")
+	if accountID == "" || strings.Contains(accountID, ",") {
+		h.HandleErrors(w, utils.BadRequest("a single account id is required"))
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxImportFileSize); err != nil {
+		h.HandleErrors(w, utils.BadRequest(err.Error()))
+		return
+	}
+
+	exportType := services.ESCOExportType(r.FormValue("type"))
+	if exportType == "" {
+		h.HandleErrors(w, utils.BadRequest("type is required"))
+		return
+	}
+
+	var date *time.Time
+	if dateStr := r.FormValue("date"); dateStr != "" {
+		parsedDate, err := time.Parse(utils.ShortDashDateLayout, dateStr)
+		if err != nil {
+			h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+			return
+		}
+		date = &parsedDate
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		h.HandleErrors(w, utils.BadRequest("file is required"))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	response, err := h.ImportController.ImportESCOExport(ctx, accountID, exportType, data, date)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, response, http.StatusOK)
+}
+
+// ImportSpreadsheet handles the multipart POST request to import holdings or transactions from a CSV
+// or XLSX file. The form expects the "file", its column "mapping" as JSON and optionally the "format"
+// (inferred from the file extension when empty), the broker "source" and "dryRun" to only validate it.
+// Sources synced from a broker API or used by manual assets are rejected.
+func (h *Handler) ImportSpreadsheet(w http.ResponseWriter, r *http.Request) {
+	ctx := utils.WithLogger(r.Context(), h.Logger)
+
+	accountID := chi.URLParam(r, "ids")
+	if accountID == "" || strings.Contains(accountID, ",") {
+		h.HandleErrors(w, utils.BadRequest("a single account id is required"))
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxImportFileSize); err != nil {
+		h.HandleErrors(w, utils.BadRequest(err.Error()))
+		return
+	}
+
+	var mapping schemas.SpreadsheetMapping
+	if err := json.Unmarshal([]byte(r.FormValue("mapping")), &mapping); err != nil {
+		h.HandleErrors(w, utils.BadRequest("mapping must be a valid JSON column mapping"))
+		return
+	}
+
+	dryRun := false
+	if dryRunStr := r.FormValue("dryRun"); dryRunStr != "" {
+		parsedDryRun, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			h.HandleErrors(w, utils.BadRequest("dryRun must be a boolean"))
+			return
+		}
+		dryRun = parsedDryRun
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.HandleErrors(w, utils.BadRequest("file is required"))
+		return
+	}
+	defer file.Close()
+
+	format := services.SpreadsheetFormat(strings.ToLower(r.FormValue("format")))
+	if format == "" {
+		format = services.SpreadsheetFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), "."))
+	}
+	if format != services.SpreadsheetCSV && format != services.SpreadsheetXLSX {
+		h.HandleErrors(w, utils.BadRequest("format must be csv or xlsx"))
+		return
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	response, err := h.ImportController.ImportSpreadsheet(ctx, accountID, r.FormValue("source"), format, data, &mapping, dryRun)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, response, http.StatusOK)
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"server/src/schemas"
+	"server/src/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// manualAssetParams returns the single account id and the manual asset id of the route
+func manualAssetParams(r *http.Request) (string, int, error) {
+	accountID := chi.URLParam(r, "ids")
+	if accountID == "" || strings.Contains(accountID, ",") {
+		return "", 0, utils.BadRequest("a single account id is required")
+	}
+	assetID, err := strconv.Atoi(chi.URLParam(r, "assetID"))
+	if err != nil {
+		return "", 0, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	}
+	return accountID, assetID, nil
+}
+
+// GetManualAssets handles the GET request to list the assets an account tracks by hand
+func (h *Handler) GetManualAssets(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	accountID := chi.URLParam(r, "ids")
+	if accountID == "" || strings.Contains(accountID, ",") {
+		h.HandleErrors(w, utils.BadRequest("a single account id is required"))
+		return
+	}
+
+	assets, err := h.ManualAssetsController.GetManualAssets(ctx, accountID)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, assets, http.StatusOK)
+}
+
+// CreateManualAsset creates an asset the account holds outside of the brokers
+func (h *Handler) CreateManualAsset(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	accountID := chi.URLParam(r, "ids")
+	if accountID == "" || strings.Contains(accountID, ",") {
+		h.HandleErrors(w, utils.BadRequest("a single account id is required"))
+		return
+	}
+
+	var req schemas.ManualAssetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleErrors(w, utils.BadRequest(err.Error()))
+		return
+	}
+
+	asset, err := h.ManualAssetsController.CreateManualAsset(ctx, accountID, &req)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, asset, http.StatusCreated)
+}
+
+// UpdateManualAsset renames or recategorizes a manual asset
+func (h *Handler) UpdateManualAsset(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	accountID, assetID, err := manualAssetParams(r)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	var req schemas.ManualAssetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleErrors(w, utils.BadRequest(err.Error()))
+		return
+	}
+
+	asset, err := h.ManualAssetsController.UpdateManualAsset(ctx, accountID, assetID, &req)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, asset, http.StatusOK)
+}
+
+// DeleteManualAsset deletes a manual asset with its valuations and cash flows
+func (h *Handler) DeleteManualAsset(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	accountID, assetID, err := manualAssetParams(r)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	if err := h.ManualAssetsController.DeleteManualAsset(ctx, accountID, assetID); err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, nil, http.StatusNoContent)
+}
+
+// SetManualAssetValuation records the value of a manual asset on a date
+func (h *Handler) SetManualAssetValuation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	accountID, assetID, err := manualAssetParams(r)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	var req schemas.ManualValuationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleErrors(w, utils.BadRequest(err.Error()))
+		return
+	}
+
+	asset, err := h.ManualAssetsController.SetManualAssetValuation(ctx, accountID, assetID, &req)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, asset, http.StatusOK)
+}
+
+// DeleteManualAssetValuation deletes the valuation of a manual asset on the date of the route
+func (h *Handler) DeleteManualAssetValuation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	accountID, assetID, err := manualAssetParams(r)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+	date, err := time.Parse(utils.ShortDashDateLayout, chi.URLParam(r, "date"))
+	if err != nil {
+		h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+		return
+	}
+
+	if err := h.ManualAssetsController.DeleteManualAssetValuation(ctx, accountID, assetID, date); err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, nil, http.StatusNoContent)
+}
+
+// AddManualAssetCashFlow records money put into or taken out of a manual asset
+func (h *Handler) AddManualAssetCashFlow(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	accountID, assetID, err := manualAssetParams(r)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	var req schemas.ManualCashFlowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleErrors(w, utils.BadRequest(err.Error()))
+		return
+	}
+
+	asset, err := h.ManualAssetsController.AddManualAssetCashFlow(ctx, accountID, assetID, &req)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, asset, http.StatusCreated)
+}
+
+// DeleteManualAssetCashFlows deletes the cash flows of a manual asset on the date of the route
+func (h *Handler) DeleteManualAssetCashFlows(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	accountID, assetID, err := manualAssetParams(r)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+	date, err := time.Parse(utils.ShortDashDateLayout, chi.URLParam(r, "date"))
+	if err != nil {
+		h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+		return
+	}
+
+	if err := h.ManualAssetsController.DeleteManualAssetCashFlows(ctx, accountID, assetID, date); err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, nil, http.StatusNoContent)
+}
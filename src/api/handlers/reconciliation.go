@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"server/src/services"
+	"server/src/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetReconciliation handles the GET request to reconcile the stored holdings and transactions of an account.
+// The discrepancies are returned as JSON, or as an XLSX file when format=XLSX.
+func (h *Handler) GetReconciliation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	accountID := chi.URLParam(r, "ids")
+	if accountID == "" || strings.Contains(accountID, ",") {
+		h.HandleErrors(w, utils.BadRequest("a single account id is required"))
+		return
+	}
+
+	startDate, err := time.Parse(utils.ShortDashDateLayout, r.URL.Query().Get("startDate"))
+	if err != nil {
+		h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+		return
+	}
+	endDate, err := time.Parse(utils.ShortDashDateLayout, r.URL.Query().Get("endDate"))
+	if err != nil {
+		h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
+		return
+	}
+	if endDate.Before(startDate) {
+		h.HandleErrors(w, utils.BadRequest("endDate must be after startDate"))
+		return
+	}
+
+	tolerance := services.DefaultReconciliationTolerance
+	if toleranceStr := r.URL.Query().Get("tolerance"); toleranceStr != "" {
+		tolerance, err = strconv.ParseFloat(toleranceStr, 64)
+		if err != nil || tolerance < 0 {
+			h.HandleErrors(w, utils.BadRequest("tolerance must be a non negative number"))
+			return
+		}
+	}
+
+	if r.URL.Query().Get("format") == "XLSX" {
+		xlsxFile, err := h.ReconciliationController.GenerateXLSXReconciliation(ctx, accountID, startDate, endDate, tolerance)
+		if err != nil {
+			h.HandleErrors(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", "attachment; filename=reconciliation.xlsx")
+
+		err = xlsxFile.Write(w)
+		if err != nil {
+			h.HandleErrors(w, err)
+			return
+		}
+		return
+	}
+
+	report, err := h.ReconciliationController.GetReconciliation(ctx, accountID, startDate, endDate, tolerance)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, report, http.StatusOK)
+}
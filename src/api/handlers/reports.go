@@ -52,6 +52,35 @@ func (h *Handler) GetReportByIDs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	transactionTypes, err := parseTransactionTypes(r)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	returnMethod, err := parseReturnMethod(r)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	currency, err := parseReportCurrency(r)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	benchmarks, err := parseBenchmarks(r)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	// The BCRA variable or stored series the risk is measured against, the configured one when empty
+	riskFreeRateID := strings.TrimSpace(r.URL.Query().Get("riskFreeRate"))
+	// The target allocation the returns are attributed against, none when empty
+	targetAllocation := strings.TrimSpace(r.URL.Query().Get("targetAllocation"))
+
 	startDate, err := time.Parse(utils.ShortDashDateLayout, startDateStr)
 	if err != nil {
 		h.Logger.Warning(err)
@@ -78,7 +107,7 @@ func (h *Handler) GetReportByIDs(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get report data
-	accountsReports, err := h.ReportsController.GetReport(ctx, ids, referenceVariables, startDate, endDate, interval.ToDuration())
+	accountsReports, err := h.ReportsController.GetReport(ctx, ids, referenceVariables, startDate, endDate, interval.ToDuration(), transactionTypes, returnMethod, currency, benchmarks, riskFreeRateID, targetAllocation)
 	if err != nil {
 		h.Logger.Warning(err)
 		h.HandleErrors(w, err)
@@ -123,6 +152,35 @@ func (h *Handler) GetReportFileByIDs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	transactionTypes, err := parseTransactionTypes(r)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	returnMethod, err := parseReturnMethod(r)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	currency, err := parseReportCurrency(r)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	benchmarks, err := parseBenchmarks(r)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	// The BCRA variable or stored series the risk is measured against, the configured one when empty
+	riskFreeRateID := strings.TrimSpace(r.URL.Query().Get("riskFreeRate"))
+	// The target allocation the returns are attributed against, none when empty
+	targetAllocation := strings.TrimSpace(r.URL.Query().Get("targetAllocation"))
+
 	startDate, err := time.Parse(utils.ShortDashDateLayout, startDateStr)
 	if err != nil {
 		h.HandleErrors(w, utils.NewHTTPError(http.StatusUnprocessableEntity, err.Error()))
@@ -148,7 +206,7 @@ func (h *Handler) GetReportFileByIDs(w http.ResponseWriter, r *http.Request) {
 
 	// Generate file based on format
 	if format == "XLSX" {
-		xlsxFile, err := h.ReportsController.GenerateXLSXReportFromClientIDs(ctx, ids, referenceVariables, startDate, endDate, interval.ToDuration())
+		xlsxFile, err := h.ReportsController.GenerateXLSXReportFromClientIDs(ctx, ids, referenceVariables, startDate, endDate, interval.ToDuration(), transactionTypes, returnMethod, currency, benchmarks, riskFreeRateID, targetAllocation)
 		if err != nil {
 			h.HandleErrors(w, err)
 			return
@@ -163,7 +221,7 @@ func (h *Handler) GetReportFileByIDs(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	} else {
-		pdfData, err := h.ReportsController.GeneratePDFReportFromClientIDs(ctx, ids, referenceVariables, startDate, endDate, interval.ToDuration())
+		pdfData, err := h.ReportsController.GeneratePDFReportFromClientIDs(ctx, ids, referenceVariables, startDate, endDate, interval.ToDuration(), transactionTypes, returnMethod, currency, benchmarks, riskFreeRateID, targetAllocation)
 		if err != nil {
 			h.HandleErrors(w, err)
 			return
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"server/src/schemas"
+	"server/src/utils"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// GetTargetAllocationNames handles the GET request to list the target allocations reports can be attributed against
+func (h *Handler) GetTargetAllocationNames(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	names, err := h.TargetAllocationsController.GetTargetAllocationNames(ctx)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, names, http.StatusOK)
+}
+
+// GetTargetAllocation handles the GET request for the weights of the categories of a target allocation
+func (h *Handler) GetTargetAllocation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	allocation, err := h.TargetAllocationsController.GetTargetAllocation(ctx, chi.URLParam(r, "name"))
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, allocation, http.StatusOK)
+}
+
+// SetTargetAllocation stores the weights of the categories of a target allocation, replacing the previous ones
+func (h *Handler) SetTargetAllocation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	var req schemas.TargetAllocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.HandleErrors(w, utils.BadRequest(err.Error()))
+		return
+	}
+
+	allocation, err := h.TargetAllocationsController.SetTargetAllocation(ctx, chi.URLParam(r, "name"), &req)
+	if err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, allocation, http.StatusOK)
+}
+
+func (h *Handler) DeleteTargetAllocation(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	ctx = utils.WithLogger(ctx, h.Logger)
+
+	if err := h.TargetAllocationsController.DeleteTargetAllocation(ctx, chi.URLParam(r, "name")); err != nil {
+		h.HandleErrors(w, err)
+		return
+	}
+
+	h.respond(w, r, nil, http.StatusNoContent)
+}
@@ -38,7 +38,7 @@ func NewServer(cfg *config.Config, logger *logrus.Logger) (*Server, error) {
 	}
 
 	// Initialize Clients
-	escoClient, err := esco.NewClient(cfg, redis)
+	escoClient, err := esco.NewClient(cfg, redis, repositories.NewESCOPayloadRepository(db))
 	if err != nil {
 		return nil, err
 	}
@@ -53,20 +53,48 @@ func NewServer(cfg *config.Config, logger *logrus.Logger) (*Server, error) {
 	holdingRepository := repositories.NewHoldingRepository(db)
 	transactionRepository := repositories.NewTransactionRepository(db)
 	syncLogRepository := repositories.NewSyncLogRepository(db)
+	syncJobRepository := repositories.NewSyncJobRepository(db)
+	syncRunRepository := repositories.NewSyncRunRepository(db)
+	categoryRuleRepository := repositories.NewCategoryRuleRepository(db)
+	exchangeRateRepository := repositories.NewExchangeRateRepository(db)
+	benchmarkValueRepository := repositories.NewBenchmarkValueRepository(db)
+	targetAllocationRepository := repositories.NewTargetAllocationRepository(db)
 
 	// Initialize Services
-	escoService := services.NewESCOService(escoClient)
+	categoryResolver := services.NewCategoryRuleResolver(categoryRuleRepository, services.MapCategoryResolver(escoClient.GetCategoryMap()), 0)
+	escoService := services.NewESCOService(escoClient, categoryResolver)
 	syncService := services.NewSyncService(
+		db,
 		holdingRepository,
 		transactionRepository,
 		assetRepository,
 		assetCategoryRepository,
 		syncLogRepository,
-		escoService,
+		syncRunRepository,
+		services.NewESCOBrokerSource(escoService),
 	)
 	accountService := services.NewAccountService(holdingRepository, transactionRepository, assetRepository)
+	// Jobs only run in the worker, the token manager is used to reject them when it could not run them
+	tokenManager := services.NewESCOTokenManager(
+		escoClient,
+		redis,
+		cfg.ExternalClients.ESCO.ServiceAccount.Username,
+		cfg.ExternalClients.ESCO.ServiceAccount.Password,
+		cfg.ExternalClients.ESCO.ServiceAccount.RefreshBefore,
+	)
+	syncJobService := services.NewSyncJobService(syncJobRepository, syncService, tokenManager, cfg.Worker.SyncJobs.ChunkDays, cfg.Worker.SyncJobs.LeaseTimeout)
+	reconciliationService := services.NewReconciliationService(holdingRepository, transactionRepository, assetRepository)
+	importService := services.NewImportService(escoService, syncService)
+	categoryService := services.NewCategoryService(assetCategoryRepository, categoryRuleRepository, assetRepository, categoryResolver)
+	spreadsheetImportService := services.NewSpreadsheetImportService(db, assetRepository, assetCategoryRepository, holdingRepository, transactionRepository, syncService)
+	manualAssetService := services.NewManualAssetService(db, assetRepository, assetCategoryRepository, holdingRepository, transactionRepository)
+	exchangeRateService := services.NewExchangeRateService(exchangeRateRepository)
+	benchmarkService := services.NewBenchmarkService(bcraClient, benchmarkValueRepository)
+	targetAllocationService := services.NewTargetAllocationService(db, targetAllocationRepository)
+	costBasisService := services.NewCostBasisService(holdingRepository, transactionRepository, assetRepository)
 
 	handler, err := handlers.NewHandler(
+		cfg,
 		logger,
 		db,
 		escoClient,
@@ -74,6 +102,16 @@ func NewServer(cfg *config.Config, logger *logrus.Logger) (*Server, error) {
 		escoService,
 		syncService,
 		accountService,
+		syncJobService,
+		reconciliationService,
+		importService,
+		categoryService,
+		spreadsheetImportService,
+		manualAssetService,
+		exchangeRateService,
+		benchmarkService,
+		targetAllocationService,
+		costBasisService,
 	)
 	if err != nil {
 		return nil, err
@@ -114,6 +152,57 @@ func (s *Server) InitRoutes() {
 		r.Get("/", s.Handler.GetAllAccounts)
 		r.Get("/{ids}", s.Handler.GetAccountState)
 		r.Post("/sync", s.Handler.SyncAccount)
+		r.Post("/sync/bulk", s.Handler.BulkSyncAccounts)
+		r.Get("/sync/{jobID}", s.Handler.GetSyncJob)
+		r.Get("/{ids}/sync-runs", s.Handler.GetSyncRuns)
+		r.Get("/{ids}/reconciliation", s.Handler.GetReconciliation)
+		r.Get("/{ids}/pnl", s.Handler.GetPnL)
+		r.Post("/{ids}/import", s.Handler.ImportESCOExport)
+		r.Post("/{ids}/import/spreadsheet", s.Handler.ImportSpreadsheet)
+		r.Get("/{ids}/manual-assets", s.Handler.GetManualAssets)
+		r.Post("/{ids}/manual-assets", s.Handler.CreateManualAsset)
+		r.Put("/{ids}/manual-assets/{assetID}", s.Handler.UpdateManualAsset)
+		r.Delete("/{ids}/manual-assets/{assetID}", s.Handler.DeleteManualAsset)
+		r.Post("/{ids}/manual-assets/{assetID}/valuations", s.Handler.SetManualAssetValuation)
+		r.Delete("/{ids}/manual-assets/{assetID}/valuations/{date}", s.Handler.DeleteManualAssetValuation)
+		r.Post("/{ids}/manual-assets/{assetID}/cash-flows", s.Handler.AddManualAssetCashFlow)
+		r.Delete("/{ids}/manual-assets/{assetID}/cash-flows/{date}", s.Handler.DeleteManualAssetCashFlows)
+	})
+
+	s.Router.Route("/api/categories", func(r chi.Router) {
+		r.Get("/", s.Handler.GetCategories)
+		r.Post("/", s.Handler.CreateCategory)
+		r.Put("/{id}", s.Handler.UpdateCategory)
+		r.Delete("/{id}", s.Handler.DeleteCategory)
+		r.Get("/rules", s.Handler.GetCategoryRules)
+		r.Post("/rules", s.Handler.CreateCategoryRule)
+		r.Put("/rules/{id}", s.Handler.UpdateCategoryRule)
+		r.Delete("/rules/{id}", s.Handler.DeleteCategoryRule)
+	})
+
+	s.Router.Route("/api/assets", func(r chi.Router) {
+		r.Get("/unclassified", s.Handler.GetUnclassifiedAssets)
+		r.Put("/{id}/category", s.Handler.SetAssetCategory)
+	})
+
+	s.Router.Route("/api/exchange-rates", func(r chi.Router) {
+		r.Get("/{currency}", s.Handler.GetExchangeRates)
+		r.Put("/{currency}", s.Handler.SetExchangeRates)
+		r.Delete("/{currency}/{date}", s.Handler.DeleteExchangeRate)
+	})
+
+	s.Router.Route("/api/benchmarks", func(r chi.Router) {
+		r.Get("/", s.Handler.GetBenchmarkNames)
+		r.Get("/{name}", s.Handler.GetBenchmarkValues)
+		r.Put("/{name}", s.Handler.SetBenchmarkValues)
+		r.Delete("/{name}/{date}", s.Handler.DeleteBenchmarkValue)
+	})
+
+	s.Router.Route("/api/target-allocations", func(r chi.Router) {
+		r.Get("/", s.Handler.GetTargetAllocationNames)
+		r.Get("/{name}", s.Handler.GetTargetAllocation)
+		r.Put("/{name}", s.Handler.SetTargetAllocation)
+		r.Delete("/{name}", s.Handler.DeleteTargetAllocation)
 	})
 
 	s.Router.Route("/api/variables", func(r chi.Router) {
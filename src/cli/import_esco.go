@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"server/src/clients/esco"
+	"server/src/config"
+	"server/src/database"
+	"server/src/repositories"
+	"server/src/services"
+	"server/src/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const ImportESCOCommand = "import-esco"
+
+// RunImportESCO imports a raw ESCO JSON export without a live ESCO session.
+//
+// Usage: import-esco -account <id> -type <estado|boletos|liquidaciones|ctacte> -file <path> [-date YYYY-MM-DD]
+func RunImportESCO(cfg *config.Config, logger *logrus.Logger, args []string) error {
+	flags := flag.NewFlagSet(ImportESCOCommand, flag.ContinueOnError)
+	accountID := flags.String("account", "", "account id to import the data into")
+	exportType := flags.String("type", "", "export type: estado, boletos, liquidaciones or ctacte")
+	filePath := flags.String("file", "", "path to the ESCO JSON export")
+	dateStr := flags.String("date", "", "requested date of the holdings (YYYY-MM-DD), required for estado exports")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *accountID == "" || *exportType == "" || *filePath == "" {
+		flags.Usage()
+		return fmt.Errorf("account, type and file are required")
+	}
+
+	var date *time.Time
+	if *dateStr != "" {
+		parsedDate, err := time.Parse(utils.ShortDashDateLayout, *dateStr)
+		if err != nil {
+			return fmt.Errorf("invalid date %s: %w", *dateStr, err)
+		}
+		date = &parsedDate
+	}
+
+	data, err := os.ReadFile(*filePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", *filePath, err)
+	}
+
+	db, err := database.SetupDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Parsing only needs the fallback category map, so the client is built without a cache handler
+	escoClient, err := esco.NewClient(cfg, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	categoryResolver := services.NewCategoryRuleResolver(repositories.NewCategoryRuleRepository(db), services.MapCategoryResolver(escoClient.GetCategoryMap()), 0)
+	escoService := services.NewESCOService(escoClient, categoryResolver)
+	syncService := services.NewSyncService(
+		db,
+		repositories.NewHoldingRepository(db),
+		repositories.NewTransactionRepository(db),
+		repositories.NewAssetRepository(db),
+		repositories.NewAssetCategoryRepository(db),
+		repositories.NewSyncLogRepository(db),
+		repositories.NewSyncRunRepository(db),
+		services.NewESCOBrokerSource(escoService),
+	)
+	importService := services.NewImportService(escoService, syncService)
+
+	ctx := utils.WithLogger(context.Background(), logger)
+	result, err := importService.ImportESCOExport(ctx, *accountID, services.ESCOExportType(*exportType), data, date)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Imported %d holdings and %d transactions of %d assets for account %s on %d dates",
+		result.Holdings, result.Transactions, result.Assets, result.AccountID, len(result.Dates))
+	return nil
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"server/src/clients/esco"
+	"server/src/config"
+	"server/src/database"
+	"server/src/repositories"
+	"server/src/schemas"
+	"server/src/services"
+	"server/src/utils"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const ImportSpreadsheetCommand = "import-spreadsheet"
+
+// RunImportSpreadsheet imports the holdings or transactions of a CSV or XLSX file using a JSON column mapping.
+//
+// Usage: import-spreadsheet -account <id> -file <path> -mapping <mapping.json> [-source <broker>] [-dry-run]
+func RunImportSpreadsheet(cfg *config.Config, logger *logrus.Logger, args []string) error {
+	flags := flag.NewFlagSet(ImportSpreadsheetCommand, flag.ContinueOnError)
+	accountID := flags.String("account", "", "account id to import the data into")
+	filePath := flags.String("file", "", "path to the CSV or XLSX file")
+	mappingPath := flags.String("mapping", "", "path to the JSON column mapping of the file")
+	source := flags.String("source", "", "broker the file comes from, spreadsheet when empty")
+	dryRun := flags.Bool("dry-run", false, "only validate the rows without storing them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *accountID == "" || *filePath == "" || *mappingPath == "" {
+		flags.Usage()
+		return fmt.Errorf("account, file and mapping are required")
+	}
+
+	format := services.SpreadsheetFormat(strings.TrimPrefix(strings.ToLower(filepath.Ext(*filePath)), "."))
+	if format != services.SpreadsheetCSV && format != services.SpreadsheetXLSX {
+		return fmt.Errorf("unsupported file %s, expected a .csv or .xlsx file", *filePath)
+	}
+
+	mappingData, err := os.ReadFile(*mappingPath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", *mappingPath, err)
+	}
+	var mapping schemas.SpreadsheetMapping
+	if err := json.Unmarshal(mappingData, &mapping); err != nil {
+		return fmt.Errorf("error parsing mapping %s: %w", *mappingPath, err)
+	}
+
+	data, err := os.ReadFile(*filePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", *filePath, err)
+	}
+
+	db, err := database.SetupDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// The sync service only tells which broker sources are synced, so the client is built without a cache handler
+	escoClient, err := esco.NewClient(cfg, nil, nil)
+	if err != nil {
+		return err
+	}
+	holdingRepository := repositories.NewHoldingRepository(db)
+	transactionRepository := repositories.NewTransactionRepository(db)
+	assetRepository := repositories.NewAssetRepository(db)
+	assetCategoryRepository := repositories.NewAssetCategoryRepository(db)
+	syncService := services.NewSyncService(
+		db,
+		holdingRepository,
+		transactionRepository,
+		assetRepository,
+		assetCategoryRepository,
+		repositories.NewSyncLogRepository(db),
+		repositories.NewSyncRunRepository(db),
+		services.NewESCOBrokerSource(services.NewESCOService(escoClient, nil)),
+	)
+	spreadsheetImportService := services.NewSpreadsheetImportService(
+		db,
+		assetRepository,
+		assetCategoryRepository,
+		holdingRepository,
+		transactionRepository,
+		syncService,
+	)
+
+	ctx := utils.WithLogger(context.Background(), logger)
+	result, err := spreadsheetImportService.ImportSpreadsheet(ctx, *accountID, *source, format, data, &mapping, *dryRun)
+	if err != nil {
+		return err
+	}
+	for _, rowError := range result.Errors {
+		logger.Warnf("Row %d %s: %s", rowError.Row, rowError.Column, rowError.Message)
+	}
+	logger.Infof("Imported %d of %d rows as %d holdings and %d transactions of %d assets for account %s (dry run: %t)",
+		result.ImportedRows, result.Rows, result.Holdings, result.Transactions, result.Assets, result.AccountID, result.DryRun)
+	return nil
+}
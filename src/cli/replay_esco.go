@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"server/src/clients/esco"
+	"server/src/config"
+	"server/src/database"
+	"server/src/repositories"
+	"server/src/services"
+	"server/src/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const ReplayESCOCommand = "replay-esco"
+
+// RunReplayESCO re-runs the ESCO parsers against the archived payloads of an account and
+// replaces the stored data with the result, without calling ESCO.
+//
+// Usage: replay-esco -account <id> -start YYYY-MM-DD -end YYYY-MM-DD [-dry-run]
+func RunReplayESCO(cfg *config.Config, logger *logrus.Logger, args []string) error {
+	flags := flag.NewFlagSet(ReplayESCOCommand, flag.ContinueOnError)
+	accountID := flags.String("account", "", "account id whose payloads are replayed")
+	startStr := flags.String("start", "", "first date to replay (YYYY-MM-DD)")
+	endStr := flags.String("end", "", "date to replay until, exclusive (YYYY-MM-DD)")
+	dryRun := flags.Bool("dry-run", false, "parse the payloads without storing the result")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *accountID == "" || *startStr == "" || *endStr == "" {
+		flags.Usage()
+		return fmt.Errorf("account, start and end are required")
+	}
+
+	startDate, err := time.Parse(utils.ShortDashDateLayout, *startStr)
+	if err != nil {
+		return fmt.Errorf("invalid start date %s: %w", *startStr, err)
+	}
+	endDate, err := time.Parse(utils.ShortDashDateLayout, *endStr)
+	if err != nil {
+		return fmt.Errorf("invalid end date %s: %w", *endStr, err)
+	}
+	if !startDate.Before(endDate) {
+		return fmt.Errorf("start date must be before end date")
+	}
+
+	db, err := database.SetupDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// Parsing only needs the fallback category map, so the client is built without a cache handler or archiver
+	escoClient, err := esco.NewClient(cfg, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	categoryResolver := services.NewCategoryRuleResolver(repositories.NewCategoryRuleRepository(db), services.MapCategoryResolver(escoClient.GetCategoryMap()), 0)
+	escoService := services.NewESCOService(escoClient, categoryResolver)
+	syncService := services.NewSyncService(
+		db,
+		repositories.NewHoldingRepository(db),
+		repositories.NewTransactionRepository(db),
+		repositories.NewAssetRepository(db),
+		repositories.NewAssetCategoryRepository(db),
+		repositories.NewSyncLogRepository(db),
+		repositories.NewSyncRunRepository(db),
+		services.NewESCOBrokerSource(escoService),
+	)
+	replayService := services.NewReplayService(repositories.NewESCOPayloadRepository(db), escoService, syncService)
+
+	ctx := utils.WithLogger(context.Background(), logger)
+	result, err := replayService.ReplayESCOPayloads(ctx, *accountID, startDate, endDate, *dryRun)
+	if err != nil {
+		return err
+	}
+	action := "Replayed"
+	if !result.Stored {
+		action = "Parsed"
+	}
+	logger.Infof("%s %d payloads into %d holdings and %d transactions of %d assets for account %s on %d dates",
+		action, result.Payloads, result.Holdings, result.Transactions, result.Assets, result.AccountID, len(result.Dates))
+	return nil
+}
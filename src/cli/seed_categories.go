@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"server/src/config"
+	"server/src/database"
+	"server/src/repositories"
+	"server/src/services"
+	"server/src/utils"
+
+	"github.com/sirupsen/logrus"
+)
+
+const SeedCategoriesCommand = "seed-categories"
+
+// RunSeedCategories stores an exact category rule for every denomination of a denominaciones CSV,
+// creating the missing categories and reclassifying the stored assets.
+//
+// Usage: seed-categories [-file denominaciones.csv]
+func RunSeedCategories(cfg *config.Config, logger *logrus.Logger, args []string) error {
+	flags := flag.NewFlagSet(SeedCategoriesCommand, flag.ContinueOnError)
+	filePath := flags.String("file", cfg.ExternalClients.ESCO.CategoryMapFile, "CSV file mapping denominations to categories")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *filePath == "" {
+		flags.Usage()
+		return fmt.Errorf("file is required")
+	}
+
+	categoryMap, err := utils.CSVToMap(*filePath)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", *filePath, err)
+	}
+
+	db, err := database.SetupDB(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	categoryRuleRepository := repositories.NewCategoryRuleRepository(db)
+	categoryService := services.NewCategoryService(
+		repositories.NewAssetCategoryRepository(db),
+		categoryRuleRepository,
+		repositories.NewAssetRepository(db),
+		services.NewCategoryRuleResolver(categoryRuleRepository, nil, 0),
+	)
+
+	ctx := utils.WithLogger(context.Background(), logger)
+	seeded, err := categoryService.SeedRules(ctx, *categoryMap)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Seeded %d category rules from %s", seeded, *filePath)
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"server/src/config"
 	"server/src/utils"
 	"server/src/utils/requests"
@@ -25,7 +26,7 @@ type BCRAServiceClient struct {
 
 // NewClient creates a new instance of BCRAServiceClient
 func NewClient(cfg *config.Config) (*BCRAServiceClient, error) {
-	api := requests.NewExternalAPIService(&tls.Config{InsecureSkipVerify: true})
+	api := requests.NewExternalAPIService(&tls.Config{InsecureSkipVerify: true}, cfg.ExternalClients.HTTP)
 	cache := utils.NewCache[GetVariablesResponse]()
 	return &BCRAServiceClient{
 		API:            api,
@@ -43,12 +44,16 @@ func (c *BCRAServiceClient) GetVariables(ctx context.Context) (*GetVariablesResp
 	endpoint := fmt.Sprintf("%s/estadisticas/v3.0/monetarias", c.BaseURL)
 
 	// Make the GET request
-	resp, err := c.API.Get(endpoint, "", nil)
+	resp, err := c.API.Get(ctx, endpoint, "", nil)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, utils.NewHTTPError(resp.StatusCode, fmt.Sprintf("failed to retrieve bcra variables: %s", resp.Status))
+	}
+
 	// Save the response and get the response bytes for further processing
 	// responseBody, err := utils.SaveResponseToFile(resp.Body, "variables_response.json")
 	responseBody, err := io.ReadAll(resp.Body)
@@ -70,12 +75,16 @@ func (c *BCRAServiceClient) GetVariablesPorFecha(ctx context.Context, id string,
 	endpoint := fmt.Sprintf("%s/estadisticas/v3.0/monetarias/%s?desde=%s&hasta=%s", c.BaseURL, id, fechaDesde, fechaHasta)
 
 	// Make the GET request
-	resp, err := c.API.Get(endpoint, "", nil)
+	resp, err := c.API.Get(ctx, endpoint, "", nil)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, utils.NewHTTPError(resp.StatusCode, fmt.Sprintf("failed to retrieve bcra variables: %s", resp.Status))
+	}
+
 	responseBody, err := io.ReadAll(resp.Body)
 	// Save the response and get the response bytes for further processing
 	// responseBody, err := utils.SaveResponseToFile(resp.Body, fmt.Sprintf("%s_%s_%s_response.json", id, fechaDesde, fechaHasta))
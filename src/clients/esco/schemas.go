@@ -109,6 +109,7 @@ type Boleto struct {
 	F   string   `json:"F"`
 	FL  string   `json:"FL"`
 	O   string   `json:"O"`
+	TO  string   `json:"TO"`
 	C   float64  `json:"C"`
 	PR  float64  `json:"PR"`
 	B   float64  `json:"B"`
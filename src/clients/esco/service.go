@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"server/src/config"
+	"server/src/models"
 	"server/src/schemas"
 	"server/src/utils"
 	redis_utils "server/src/utils/redis"
@@ -18,16 +19,32 @@ import (
 )
 
 type ESCOServiceClientI interface {
-	PostToken(_ context.Context, username, password string) (*schemas.TokenResponse, error)
-	BuscarCuentas(token, filter string) ([]CuentaSchema, error)
-	GetCuentaDetalle(token, cid string) (*CuentaDetalleSchema, error)
-	GetEstadoCuenta(token, cid, fid, nncc, tf string, date time.Time, refreshCache bool) ([]EstadoCuentaSchema, error)
-	GetLiquidaciones(token, cid, fid, nncc, tf string, startDate, endDate time.Time, refreshCache bool) ([]Liquidacion, error)
-	GetBoletos(token, cid, fid, nncc, tf string, startDate, endDate time.Time, refreshCache bool) ([]Boleto, error)
-	GetCtaCteConsolidado(token, cid, fid, nncc, tf string, startDate, endDate time.Time, refreshCache bool) ([]Instrumentos, error)
+	PostToken(ctx context.Context, username, password string) (*schemas.TokenResponse, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*schemas.TokenResponse, error)
+	BuscarCuentas(ctx context.Context, token, filter string) ([]CuentaSchema, error)
+	GetCuentaDetalle(ctx context.Context, token, cid string) (*CuentaDetalleSchema, error)
+	GetEstadoCuenta(ctx context.Context, token, cid, fid, nncc, tf string, date time.Time, refreshCache bool) ([]EstadoCuentaSchema, error)
+	GetLiquidaciones(ctx context.Context, token, cid, fid, nncc, tf string, startDate, endDate time.Time, refreshCache bool) ([]Liquidacion, error)
+	GetBoletos(ctx context.Context, token, cid, fid, nncc, tf string, startDate, endDate time.Time, refreshCache bool) ([]Boleto, error)
+	GetCtaCteConsolidado(ctx context.Context, token, cid, fid, nncc, tf string, startDate, endDate time.Time, refreshCache bool) ([]Instrumentos, error)
 	GetCategoryMap() map[string]string
 }
 
+// Endpoint names the raw responses are archived under
+const (
+	EndpointBuscarCuentas        = "BuscarCuentas"
+	EndpointGetCuentaDetalle     = "GetCuentaDetalle"
+	EndpointGetEstadoCuenta      = "GetEstadoCuenta"
+	EndpointGetLiquidaciones     = "GetLiquidaciones"
+	EndpointGetBoletos           = "GetBoletos"
+	EndpointGetCtaCteConsolidado = "GetCtaCteConsolidado"
+)
+
+// PayloadArchiver keeps the raw body of the ESCO responses
+type PayloadArchiver interface {
+	Create(ctx context.Context, payload *models.ESCOPayload) error
+}
+
 // ESCOServiceClient is a struct that uses ExternalAPIService to interact with the ESCO API
 type ESCOServiceClient struct {
 	API          *requests.ExternalAPIService
@@ -35,11 +52,16 @@ type ESCOServiceClient struct {
 	TokenURL     string
 	CategoryMap  *map[string]string
 	CacheHandler utils.CacheHandlerI
+	Archiver     PayloadArchiver
 }
 
-// NewClient creates a new instance of ESCOServiceClient
-func NewClient(cfg *config.Config, cacheHandler utils.CacheHandlerI) (*ESCOServiceClient, error) {
-	api := requests.NewExternalAPIService(nil)
+// NewClient creates a new instance of ESCOServiceClient.
+// The archiver is only used when payload archiving is enabled in the config.
+func NewClient(cfg *config.Config, cacheHandler utils.CacheHandlerI, archiver PayloadArchiver) (*ESCOServiceClient, error) {
+	api := requests.NewExternalAPIService(nil, cfg.ExternalClients.HTTP)
+	if !cfg.ExternalClients.ESCO.ArchivePayloads {
+		archiver = nil
+	}
 	categoryMap, err := utils.CSVToMap(cfg.ExternalClients.ESCO.CategoryMapFile)
 	if err != nil {
 		return nil, err
@@ -50,11 +72,12 @@ func NewClient(cfg *config.Config, cacheHandler utils.CacheHandlerI) (*ESCOServi
 		TokenURL:     cfg.ExternalClients.ESCO.TokenURL,
 		CategoryMap:  categoryMap,
 		CacheHandler: cacheHandler,
+		Archiver:     archiver,
 	}, nil
 }
 
 // GetToken retrieves and sets the token for the external service
-func (s *ESCOServiceClient) PostToken(_ context.Context, username, password string) (*schemas.TokenResponse, error) {
+func (s *ESCOServiceClient) PostToken(ctx context.Context, username, password string) (*schemas.TokenResponse, error) {
 
 	data := url.Values{}
 	data.Set("grant_type", "password")
@@ -62,15 +85,28 @@ func (s *ESCOServiceClient) PostToken(_ context.Context, username, password stri
 	data.Set("password", password)
 	data.Set("client_id", "Unisync")
 
-	req, err := http.NewRequest("POST", s.TokenURL, bytes.NewBufferString(data.Encode()))
+	return s.postTokenForm(ctx, data)
+}
+
+// RefreshToken exchanges a refresh token for a new access token without resending the credentials
+func (s *ESCOServiceClient) RefreshToken(ctx context.Context, refreshToken string) (*schemas.TokenResponse, error) {
+	data := url.Values{}
+	data.Set("grant_type", "refresh_token")
+	data.Set("refresh_token", refreshToken)
+	data.Set("client_id", "Unisync")
+
+	return s.postTokenForm(ctx, data)
+}
+
+func (s *ESCOServiceClient) postTokenForm(ctx context.Context, data url.Values) (*schemas.TokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", s.TokenURL, bytes.NewBufferString(data.Encode()))
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := s.API.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +133,7 @@ func (s *ESCOServiceClient) PostToken(_ context.Context, username, password stri
 }
 
 // BuscarCuentas retrieves all accounts matching filter
-func (s *ESCOServiceClient) BuscarCuentas(token, filter string) ([]CuentaSchema, error) {
+func (s *ESCOServiceClient) BuscarCuentas(ctx context.Context, token, filter string) ([]CuentaSchema, error) {
 	var result []CuentaSchema
 	body := map[string]string{
 		"Filtro": filter,
@@ -105,7 +141,7 @@ func (s *ESCOServiceClient) BuscarCuentas(token, filter string) ([]CuentaSchema,
 
 	headers := map[string]string{}
 
-	resp, err := s.API.PostWithHeaders(s.BaseURL+"/BuscarCuentas", token, body, headers)
+	resp, err := s.API.PostWithHeaders(ctx, s.BaseURL+"/BuscarCuentas", token, body, headers)
 	if err != nil {
 		return nil, err
 	}
@@ -117,6 +153,7 @@ func (s *ESCOServiceClient) BuscarCuentas(token, filter string) ([]CuentaSchema,
 	if err != nil {
 		return nil, err
 	}
+	s.archivePayload(ctx, EndpointBuscarCuentas, filter, "", nil, nil, responseBody)
 
 	err = json.Unmarshal(responseBody, &result)
 	if err != nil {
@@ -127,7 +164,7 @@ func (s *ESCOServiceClient) BuscarCuentas(token, filter string) ([]CuentaSchema,
 }
 
 // GetCuentaDetalle retrieves detailed account information
-func (s *ESCOServiceClient) GetCuentaDetalle(token, cid string) (*CuentaDetalleSchema, error) {
+func (s *ESCOServiceClient) GetCuentaDetalle(ctx context.Context, token, cid string) (*CuentaDetalleSchema, error) {
 	var result = new(CuentaDetalleSchema)
 	body := map[string]string{
 		"CID_P": cid,
@@ -135,7 +172,7 @@ func (s *ESCOServiceClient) GetCuentaDetalle(token, cid string) (*CuentaDetalleS
 
 	headers := map[string]string{}
 
-	resp, err := s.API.PostWithHeaders(s.BaseURL+"/GetCuentaDetalle", token, body, headers)
+	resp, err := s.API.PostWithHeaders(ctx, s.BaseURL+"/GetCuentaDetalle", token, body, headers)
 	if err != nil {
 		return nil, err
 	}
@@ -147,6 +184,7 @@ func (s *ESCOServiceClient) GetCuentaDetalle(token, cid string) (*CuentaDetalleS
 	if err != nil {
 		return nil, err
 	}
+	s.archivePayload(ctx, EndpointGetCuentaDetalle, cid, cid, nil, nil, responseBody)
 
 	err = json.Unmarshal(responseBody, &result)
 	if err != nil {
@@ -156,7 +194,7 @@ func (s *ESCOServiceClient) GetCuentaDetalle(token, cid string) (*CuentaDetalleS
 }
 
 // GetEstadoCuenta retrieves the account status information
-func (s *ESCOServiceClient) GetEstadoCuenta(token, cid, fid, nncc, tf string, date time.Time, refreshCache bool) ([]EstadoCuentaSchema, error) {
+func (s *ESCOServiceClient) GetEstadoCuenta(ctx context.Context, token, cid, fid, nncc, tf string, date time.Time, refreshCache bool) ([]EstadoCuentaSchema, error) {
 	var result []EstadoCuentaSchema
 	if !refreshCache {
 		err := s.GetCachedData(&result, "estado-cuenta", nncc, tf, date.Format("2006-01-02"))
@@ -173,7 +211,7 @@ func (s *ESCOServiceClient) GetEstadoCuenta(token, cid, fid, nncc, tf string, da
 	}
 
 	url := s.BaseURL + "/GetEstadoCuenta"
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -194,8 +232,7 @@ func (s *ESCOServiceClient) GetEstadoCuenta(token, cid, fid, nncc, tf string, da
 	// Add bearer token
 	req.Header.Set("Authorization", "Bearer "+token)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := s.API.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -211,6 +248,7 @@ func (s *ESCOServiceClient) GetEstadoCuenta(token, cid, fid, nncc, tf string, da
 	if err != nil {
 		return nil, err
 	}
+	s.archivePayload(ctx, EndpointGetEstadoCuenta, nncc, cid, &date, &date, body)
 
 	err = json.Unmarshal(body, &result)
 	if err != nil {
@@ -224,7 +262,7 @@ func (s *ESCOServiceClient) GetEstadoCuenta(token, cid, fid, nncc, tf string, da
 }
 
 // GetEstadoCuenta retrieves the account status information
-func (s *ESCOServiceClient) GetLiquidaciones(token, cid, fid, nncc, tf string, startDate, endDate time.Time, refreshCache bool) ([]Liquidacion, error) {
+func (s *ESCOServiceClient) GetLiquidaciones(ctx context.Context, token, cid, fid, nncc, tf string, startDate, endDate time.Time, refreshCache bool) ([]Liquidacion, error) {
 	var result []Liquidacion
 	if !refreshCache {
 		err := s.GetCachedData(&result, "liquidaciones", nncc, tf, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
@@ -241,7 +279,7 @@ func (s *ESCOServiceClient) GetLiquidaciones(token, cid, fid, nncc, tf string, s
 	}
 
 	url := s.BaseURL + "/GetLiquidaciones"
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -263,8 +301,7 @@ func (s *ESCOServiceClient) GetLiquidaciones(token, cid, fid, nncc, tf string, s
 	// Add bearer token
 	req.Header.Set("Authorization", "Bearer "+token)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := s.API.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -280,6 +317,7 @@ func (s *ESCOServiceClient) GetLiquidaciones(token, cid, fid, nncc, tf string, s
 	if err != nil {
 		return nil, err
 	}
+	s.archivePayload(ctx, EndpointGetLiquidaciones, nncc, cid, &startDate, &endDate, body)
 
 	err = json.Unmarshal(body, &result)
 	if err != nil {
@@ -292,7 +330,7 @@ func (s *ESCOServiceClient) GetLiquidaciones(token, cid, fid, nncc, tf string, s
 	return result, nil
 }
 
-func (s *ESCOServiceClient) GetBoletos(token, cid, fid, nncc, tf string, startDate, endDate time.Time, refreshCache bool) ([]Boleto, error) {
+func (s *ESCOServiceClient) GetBoletos(ctx context.Context, token, cid, fid, nncc, tf string, startDate, endDate time.Time, refreshCache bool) ([]Boleto, error) {
 	var result []Boleto
 	if !refreshCache {
 		err := s.GetCachedData(&result, "boletos", nncc, tf, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
@@ -309,7 +347,7 @@ func (s *ESCOServiceClient) GetBoletos(token, cid, fid, nncc, tf string, startDa
 	}
 
 	url := s.BaseURL + "/GetBoletos"
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -331,8 +369,7 @@ func (s *ESCOServiceClient) GetBoletos(token, cid, fid, nncc, tf string, startDa
 	// Add bearer token
 	req.Header.Set("Authorization", "Bearer "+token)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := s.API.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -348,6 +385,7 @@ func (s *ESCOServiceClient) GetBoletos(token, cid, fid, nncc, tf string, startDa
 	if err != nil {
 		return nil, err
 	}
+	s.archivePayload(ctx, EndpointGetBoletos, nncc, cid, &startDate, &endDate, body)
 
 	err = json.Unmarshal(body, &result)
 	if err != nil {
@@ -360,7 +398,7 @@ func (s *ESCOServiceClient) GetBoletos(token, cid, fid, nncc, tf string, startDa
 	return result, nil
 }
 
-func (s *ESCOServiceClient) GetCtaCteConsolidado(token, cid, fid, nncc, tf string, startDate, endDate time.Time, refreshCache bool) ([]Instrumentos, error) {
+func (s *ESCOServiceClient) GetCtaCteConsolidado(ctx context.Context, token, cid, fid, nncc, tf string, startDate, endDate time.Time, refreshCache bool) ([]Instrumentos, error) {
 	var result []Instrumentos
 	if !refreshCache {
 		err := s.GetCachedData(&result, "cteCteConsolidado", nncc, tf, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
@@ -377,7 +415,7 @@ func (s *ESCOServiceClient) GetCtaCteConsolidado(token, cid, fid, nncc, tf strin
 	}
 
 	url := s.BaseURL + "/GetCtaCteConsolidado"
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -400,8 +438,7 @@ func (s *ESCOServiceClient) GetCtaCteConsolidado(token, cid, fid, nncc, tf strin
 	// Add bearer token
 	req.Header.Set("Authorization", "Bearer "+token)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := s.API.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -417,6 +454,7 @@ func (s *ESCOServiceClient) GetCtaCteConsolidado(token, cid, fid, nncc, tf strin
 	if err != nil {
 		return nil, err
 	}
+	s.archivePayload(ctx, EndpointGetCtaCteConsolidado, nncc, cid, &startDate, &endDate, body)
 
 	err = json.Unmarshal(body, &result)
 	if err != nil {
@@ -429,6 +467,27 @@ func (s *ESCOServiceClient) GetCtaCteConsolidado(token, cid, fid, nncc, tf strin
 	return result, nil
 }
 
+// archivePayload stores the raw response body. Failures are only logged, archiving never fails a fetch.
+// Tokens are never archived since their responses carry credentials.
+func (s *ESCOServiceClient) archivePayload(ctx context.Context, endpoint, accountID, cid string, startDate, endDate *time.Time, body []byte) {
+	if s.Archiver == nil {
+		return
+	}
+	payload := &models.ESCOPayload{
+		Endpoint:  endpoint,
+		AccountID: accountID,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Payload:   body,
+	}
+	if cid != "" {
+		payload.CID = &cid
+	}
+	if err := s.Archiver.Create(ctx, payload); err != nil {
+		utils.LoggerFromContext(ctx).Warnf("Error archiving esco %s payload for account %s: %v", endpoint, accountID, err)
+	}
+}
+
 func (s *ESCOServiceClient) GetCachedData(target interface{}, keys ...string) error {
 	key, err := redis_utils.GenerateUUID(keys...)
 	if err != nil {
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -11,6 +12,8 @@ type Config struct {
 	Databases       DatabasesConfig      `mapstructure:"databases"`
 	ExternalClients ExternalClientConfig `mapstructure:"externalClients"`
 	Logger          LoggerConfig         `mapstructure:"logger"`
+	Worker          WorkerConfig         `mapstructure:"worker"`
+	Reports         ReportsConfig        `mapstructure:"reports"`
 }
 
 type ServiceType string
@@ -49,14 +52,37 @@ type RedisConfig struct {
 }
 
 type ExternalClientConfig struct {
-	ESCO ESCOConfig `mapstructure:"esco"`
-	BCRA BCRAConfig `mapstructure:"bcra"`
+	ESCO ESCOConfig       `mapstructure:"esco"`
+	BCRA BCRAConfig       `mapstructure:"bcra"`
+	HTTP HTTPClientConfig `mapstructure:"http"`
+}
+
+// HTTPClientConfig tunes the shared client used for every external API call.
+// Zero values fall back to the defaults in the requests package.
+type HTTPClientConfig struct {
+	Timeout             time.Duration `mapstructure:"timeout"`
+	MaxIdleConnsPerHost int           `mapstructure:"maxIdleConnsPerHost"`
+	MaxRetries          int           `mapstructure:"maxRetries"`
+	BaseBackoff         time.Duration `mapstructure:"baseBackoff"`
+	MaxBackoff          time.Duration `mapstructure:"maxBackoff"`
+	BreakerThreshold    int           `mapstructure:"breakerThreshold"`
+	BreakerCooldown     time.Duration `mapstructure:"breakerCooldown"`
 }
 
 type ESCOConfig struct {
-	BaseURL         string `mapstructure:"baseUrl"`
-	TokenURL        string `mapstructure:"tokenUrl"`
-	CategoryMapFile string `mapstructure:"categoryMapFile"`
+	BaseURL         string               `mapstructure:"baseUrl"`
+	TokenURL        string               `mapstructure:"tokenUrl"`
+	CategoryMapFile string               `mapstructure:"categoryMapFile"`
+	ServiceAccount  ESCOServiceAccConfig `mapstructure:"serviceAccount"`
+	// ArchivePayloads keeps the raw body of every ESCO response in the esco_payloads table
+	ArchivePayloads bool `mapstructure:"archivePayloads"`
+}
+
+type ESCOServiceAccConfig struct {
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// RefreshBefore is how long before expiry the cached token is renewed
+	RefreshBefore time.Duration `mapstructure:"refreshBefore"`
 }
 
 type BCRAConfig struct {
@@ -68,6 +94,32 @@ type LoggerConfig struct {
 	File  string `mapstructure:"file"`
 }
 
+type WorkerConfig struct {
+	SyncJobs        SyncJobsConfig        `mapstructure:"syncJobs"`
+	IncrementalSync IncrementalSyncConfig `mapstructure:"incrementalSync"`
+}
+
+type IncrementalSyncConfig struct {
+	Cron         string   `mapstructure:"cron"`
+	BackfillDays int      `mapstructure:"backfillDays"`
+	Accounts     []string `mapstructure:"accounts"`
+}
+
+// SyncJobsConfig sets how the worker runs sync jobs. MaxConcurrentJobs is the size of the pool the
+// jobs run in, including the one queued per account by a bulk sync.
+type SyncJobsConfig struct {
+	PollInterval      time.Duration `mapstructure:"pollInterval"`
+	ChunkDays         int           `mapstructure:"chunkDays"`
+	MaxConcurrentJobs int           `mapstructure:"maxConcurrentJobs"`
+	// LeaseTimeout is how long a running job may go without a heartbeat before another worker requeues it
+	LeaseTimeout time.Duration `mapstructure:"leaseTimeout"`
+}
+
+type ReportsConfig struct {
+	// RiskFreeRateID is the BCRA variable, a nominal annual rate, the risk of peso reports is measured against
+	RiskFreeRateID string `mapstructure:"riskFreeRateID"`
+}
+
 // LoadConfig loads the base appsettings file and the environment-specific settings file.
 func LoadConfig(path string, environment string) (*Config, error) {
 	var cfg Config
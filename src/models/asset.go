@@ -2,16 +2,27 @@ package models
 
 import "time"
 
+// Asset is an instrument held by the clients. CategoryKey is the ESCO denomination its category
+// was resolved from, and CategoryLocked is set when the category was assigned by hand so syncs keep it.
+// ClientID is only set for the assets a client tracks by hand, outside of the brokers.
 type Asset struct {
-	ID         int        `db:"id"`
-	ExternalID string     `db:"external_id"`
-	Name       string     `db:"name"`
-	AssetType  string     `db:"asset_type"`
-	CategoryID int        `db:"category_id"`
-	Currency   string     `db:"currency"`
-	CreatedAt  time.Time  `db:"created_at"`
-	Deleted    bool       `db:"deleted"`
-	DeletedAt  *time.Time `db:"deleted_at"`
+	ID             int        `db:"id"`
+	ExternalID     string     `db:"external_id"`
+	Name           string     `db:"name"`
+	AssetType      string     `db:"asset_type"`
+	CategoryID     int        `db:"category_id"`
+	Currency       string     `db:"currency"`
+	CategoryKey    string     `db:"category_key"`
+	CategoryLocked bool       `db:"category_locked"`
+	ClientID       string     `db:"client_id"`
+	CreatedAt      time.Time  `db:"created_at"`
+	Deleted        bool       `db:"deleted"`
+	DeletedAt      *time.Time `db:"deleted_at"`
+}
+
+// IsManual reports whether the asset is tracked by hand by a client
+func (a *Asset) IsManual() bool {
+	return a.ClientID != ""
 }
 
 type AssetWithCategory struct {
@@ -21,6 +32,8 @@ type AssetWithCategory struct {
 	AssetType           string     `db:"asset_type"`
 	CategoryID          int        `db:"category_id"`
 	Currency            string     `db:"currency"`
+	CategoryKey         string     `db:"category_key"`
+	CategoryLocked      bool       `db:"category_locked"`
 	CategoryName        string     `db:"category_name"`
 	CategoryDescription string     `db:"category_description"`
 	CreatedAt           time.Time  `db:"created_at"`
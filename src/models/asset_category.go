@@ -2,6 +2,9 @@ package models
 
 import "time"
 
+// UnclassifiedCategory is the category of the assets no category rule matches
+const UnclassifiedCategory = "S / C"
+
 type AssetCategory struct {
 	ID          int        `db:"id"`
 	Name        string     `db:"name"`
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// BenchmarkValue is the value on Date of the custom series Name reports can be compared against
+type BenchmarkValue struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	Date      time.Time `db:"date"`
+	Value     float64   `db:"value"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Ways a category rule pattern is matched against an ESCO denomination
+const (
+	CategoryRuleMatchExact  = "exact"
+	CategoryRuleMatchPrefix = "prefix"
+	CategoryRuleMatchRegex  = "regex"
+)
+
+// CategoryRule assigns the category to the assets whose denomination matches its pattern
+type CategoryRule struct {
+	ID           int       `db:"id"`
+	CategoryID   int       `db:"category_id"`
+	CategoryName string    `db:"category_name"`
+	MatchType    string    `db:"match_type"`
+	Pattern      string    `db:"pattern"`
+	CreatedAt    time.Time `db:"created_at"`
+}
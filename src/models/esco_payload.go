@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ESCOPayload is the raw body of an ESCO response, kept for audit and to replay the parsers.
+// The dates are the requested range and are empty for endpoints that do not take one.
+type ESCOPayload struct {
+	ID        int        `db:"id"`
+	Endpoint  string     `db:"endpoint"`
+	AccountID string     `db:"account_id"`
+	CID       *string    `db:"cid"`
+	StartDate *time.Time `db:"start_date"`
+	EndDate   *time.Time `db:"end_date"`
+	Payload   []byte     `db:"payload"`
+	FetchedAt time.Time  `db:"fetched_at"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// ExchangeRate is the amount of pesos a dollar of Currency (e.g. MEP or CCL) is worth on Date
+type ExchangeRate struct {
+	ID        int       `db:"id"`
+	Currency  string    `db:"currency"`
+	Date      time.Time `db:"date"`
+	Rate      float64   `db:"rate"`
+	CreatedAt time.Time `db:"created_at"`
+}
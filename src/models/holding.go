@@ -7,6 +7,7 @@ import (
 type Holding struct {
 	ID        int        `db:"id"`
 	ClientID  string     `db:"client_id"`
+	Source    string     `db:"source"`
 	AssetID   int        `db:"asset_id"`
 	Units     float64    `db:"units"`
 	Value     float64    `db:"value"`
@@ -0,0 +1,10 @@
+package models
+
+// Sources are the brokers holdings and transactions are synced from
+const (
+	SourceESCO = "esco"
+	// SourceSpreadsheet tags the data imported from files when no broker is named
+	SourceSpreadsheet = "spreadsheet"
+	// SourceManual tags the valuations and cash flows of the assets tracked by hand
+	SourceManual = "manual"
+)
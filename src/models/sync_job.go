@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+type SyncJobStatus string
+
+const (
+	SyncJobPending   SyncJobStatus = "PENDING"
+	SyncJobRunning   SyncJobStatus = "RUNNING"
+	SyncJobCompleted SyncJobStatus = "COMPLETED"
+	SyncJobFailed    SyncJobStatus = "FAILED"
+)
+
+type SyncJob struct {
+	ID             int           `db:"id"`
+	ClientID       string        `db:"client_id"`
+	StartDate      time.Time     `db:"start_date"`
+	EndDate        time.Time     `db:"end_date"`
+	Status         SyncJobStatus `db:"status"`
+	Force          bool          `db:"force"`
+	Source         string        `db:"source"`
+	TotalDates     int           `db:"total_dates"`
+	ProcessedDates int           `db:"processed_dates"`
+	Error          *string       `db:"error"`
+	CreatedAt      time.Time     `db:"created_at"`
+	StartedAt      *time.Time    `db:"started_at"`
+	FinishedAt     *time.Time    `db:"finished_at"`
+}
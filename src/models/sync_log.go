@@ -5,6 +5,7 @@ import "time"
 type SyncLog struct {
 	ID        int       `db:"id"`
 	ClientID  string    `db:"client_id"`
+	Source    string    `db:"source"`
 	SyncDate  time.Time `db:"sync_date"`
 	CreatedAt time.Time `db:"created_at"`
 }
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type SyncRunStatus string
+
+const (
+	SyncRunRunning   SyncRunStatus = "RUNNING"
+	SyncRunCompleted SyncRunStatus = "COMPLETED"
+	SyncRunFailed    SyncRunStatus = "FAILED"
+)
+
+type SyncRun struct {
+	ID                int           `db:"id"`
+	ClientID          string        `db:"client_id"`
+	StartDate         time.Time     `db:"start_date"`
+	EndDate           time.Time     `db:"end_date"`
+	TriggeredBy       string        `db:"triggered_by"`
+	Status            SyncRunStatus `db:"status"`
+	DatesSynced       int           `db:"dates_synced"`
+	HoldingsCount     int           `db:"holdings_count"`
+	TransactionsCount int           `db:"transactions_count"`
+	Error             *string       `db:"error"`
+	StartedAt         time.Time     `db:"started_at"`
+	FinishedAt        *time.Time    `db:"finished_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// TargetAllocation is the target Weight, as a percentage, of a category in the allocation Name. Benchmark
+// is the BCRA variable or stored series measuring the target return of the category, read as BenchmarkKind.
+type TargetAllocation struct {
+	ID            int       `db:"id"`
+	Name          string    `db:"name"`
+	Category      string    `db:"category"`
+	Weight        float64   `db:"weight"`
+	Benchmark     string    `db:"benchmark"`
+	BenchmarkKind string    `db:"benchmark_kind"`
+	CreatedAt     time.Time `db:"created_at"`
+}
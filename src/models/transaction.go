@@ -4,13 +4,54 @@ import (
 	"time"
 )
 
+// Transaction types assigned during sync from the ESCO operation descriptions.
+// Transactions that cannot be classified are stored with an empty type.
+const (
+	TransactionTypeBuy          = "buy"
+	TransactionTypeSell         = "sell"
+	TransactionTypeCoupon       = "coupon"
+	TransactionTypeDividend     = "dividend"
+	TransactionTypeAmortization = "amortization"
+	TransactionTypeSubscription = "subscription"
+	TransactionTypeRedemption   = "redemption"
+	TransactionTypeFee          = "fee"
+	TransactionTypeTransfer     = "transfer"
+)
+
+// TransactionTypes lists every transaction type, in the order they are reported
+var TransactionTypes = []string{
+	TransactionTypeBuy,
+	TransactionTypeSell,
+	TransactionTypeCoupon,
+	TransactionTypeDividend,
+	TransactionTypeAmortization,
+	TransactionTypeSubscription,
+	TransactionTypeRedemption,
+	TransactionTypeFee,
+	TransactionTypeTransfer,
+}
+
+// IsValidTransactionType reports whether transactionType is one of TransactionTypes
+func IsValidTransactionType(transactionType string) bool {
+	for _, t := range TransactionTypes {
+		if t == transactionType {
+			return true
+		}
+	}
+	return false
+}
+
 type Transaction struct {
 	ID              int        `db:"id"`
 	ClientID        string     `db:"client_id"`
+	Source          string     `db:"source"`
 	AssetID         int        `db:"asset_id"`
 	TransactionType string     `db:"transaction_type"`
 	Units           float64    `db:"units"`
 	PricePerUnit    float64    `db:"price_per_unit"`
+	GrossValue      float64    `db:"gross_value"`
+	ExchangeFees    float64    `db:"exchange_fees"`
+	MarketFees      float64    `db:"market_fees"`
 	TotalValue      float64    `db:"total_value"`
 	Date            time.Time  `db:"date"`
 	CreatedAt       time.Time  `db:"created_at"`
@@ -15,6 +15,8 @@ type AssetCategoryRepository interface {
 	GetByName(ctx context.Context, name string) (*models.AssetCategory, error)
 
 	Create(ctx context.Context, ac *models.AssetCategory, tx pgx.Tx) error
+	Update(ctx context.Context, ac *models.AssetCategory) error
+	Delete(ctx context.Context, id int) error
 }
 
 type assetCategoryRepo struct {
@@ -26,7 +28,7 @@ func NewAssetCategoryRepository(db *pgxpool.Pool) AssetCategoryRepository {
 }
 
 func (r *assetCategoryRepo) GetAll(ctx context.Context) ([]models.AssetCategory, error) {
-	rows, err := r.db.Query(ctx, `SELECT id, name, description FROM asset_categories`)
+	rows, err := r.db.Query(ctx, `SELECT id, name, COALESCE(description, '') FROM asset_categories ORDER BY name`)
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +48,7 @@ func (r *assetCategoryRepo) GetAll(ctx context.Context) ([]models.AssetCategory,
 
 func (r *assetCategoryRepo) GetByID(ctx context.Context, id int) (*models.AssetCategory, error) {
 	rows, err := r.db.Query(ctx,
-		`SELECT id, name, description FROM asset_categories WHERE id = $1`, id,
+		`SELECT id, name, COALESCE(description, '') FROM asset_categories WHERE id = $1`, id,
 	)
 	if err != nil {
 		return nil, err
@@ -67,7 +69,7 @@ func (r *assetCategoryRepo) GetByID(ctx context.Context, id int) (*models.AssetC
 
 func (r *assetCategoryRepo) GetByName(ctx context.Context, name string) (*models.AssetCategory, error) {
 	rows, err := r.db.Query(ctx,
-		`SELECT id, name, description FROM asset_categories WHERE name = $1`, name,
+		`SELECT id, name, COALESCE(description, '') FROM asset_categories WHERE name = $1`, name,
 	)
 	if err != nil {
 		return nil, err
@@ -117,3 +119,17 @@ func (r *assetCategoryRepo) Create(ctx context.Context, ac *models.AssetCategory
 	// Use the provided transaction
 	return tx.QueryRow(ctx, query, ac.Name, ac.Description).Scan(&ac.ID)
 }
+
+func (r *assetCategoryRepo) Update(ctx context.Context, ac *models.AssetCategory) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE asset_categories SET name = $2, description = $3 WHERE id = $1`,
+		ac.ID, ac.Name, ac.Description,
+	)
+	return err
+}
+
+// Delete removes the category along with its rules
+func (r *assetCategoryRepo) Delete(ctx context.Context, id int) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM asset_categories WHERE id = $1`, id)
+	return err
+}
@@ -2,6 +2,8 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"server/src/models"
 
@@ -13,8 +15,15 @@ type AssetRepository interface {
 	GetAll(ctx context.Context) ([]models.Asset, error)
 	GetByID(ctx context.Context, id int) (*models.Asset, error)
 	GetByIDs(ctx context.Context, ids []int) ([]models.Asset, error)
+	GetByExternalID(ctx context.Context, externalID string) (*models.Asset, error)
+	GetByClientID(ctx context.Context, clientID string) ([]models.Asset, error)
 	GetWithCategories(ctx context.Context) ([]models.AssetWithCategory, error)
+	GetByCategoryName(ctx context.Context, categoryName string) ([]models.AssetWithCategory, error)
+	CountByCategoryID(ctx context.Context, categoryID int) (int, error)
 	Create(ctx context.Context, asset *models.Asset, tx pgx.Tx) error
+	SetCategory(ctx context.Context, id, categoryID int, locked bool) error
+	Update(ctx context.Context, asset *models.Asset) error
+	Delete(ctx context.Context, id int, tx pgx.Tx) error
 }
 
 type assetRepo struct {
@@ -26,7 +35,7 @@ func NewAssetRepository(db *pgxpool.Pool) AssetRepository {
 }
 
 func (r *assetRepo) GetAll(ctx context.Context) ([]models.Asset, error) {
-	rows, err := r.db.Query(ctx, `SELECT id, external_id, name, asset_type, category_id, currency FROM assets`)
+	rows, err := r.db.Query(ctx, `SELECT id, external_id, name, asset_type, category_id, currency, category_key, category_locked, COALESCE(client_id, '') FROM assets`)
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +44,7 @@ func (r *assetRepo) GetAll(ctx context.Context) ([]models.Asset, error) {
 	var assets []models.Asset
 	for rows.Next() {
 		var asset models.Asset
-		if err := rows.Scan(&asset.ID, &asset.ExternalID, &asset.Name, &asset.AssetType, &asset.CategoryID, &asset.Currency); err != nil {
+		if err := rows.Scan(&asset.ID, &asset.ExternalID, &asset.Name, &asset.AssetType, &asset.CategoryID, &asset.Currency, &asset.CategoryKey, &asset.CategoryLocked, &asset.ClientID); err != nil {
 			return nil, err
 		}
 		assets = append(assets, asset)
@@ -45,8 +54,8 @@ func (r *assetRepo) GetAll(ctx context.Context) ([]models.Asset, error) {
 
 func (r *assetRepo) GetByID(ctx context.Context, id int) (*models.Asset, error) {
 	var asset models.Asset
-	err := r.db.QueryRow(ctx, `SELECT id, external_id, name, asset_type, category_id, currency FROM assets WHERE id = $1`, id).
-		Scan(&asset.ID, &asset.ExternalID, &asset.Name, &asset.AssetType, &asset.CategoryID, &asset.Currency)
+	err := r.db.QueryRow(ctx, `SELECT id, external_id, name, asset_type, category_id, currency, category_key, category_locked, COALESCE(client_id, ''), deleted FROM assets WHERE id = $1`, id).
+		Scan(&asset.ID, &asset.ExternalID, &asset.Name, &asset.AssetType, &asset.CategoryID, &asset.Currency, &asset.CategoryKey, &asset.CategoryLocked, &asset.ClientID, &asset.Deleted)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +67,7 @@ func (r *assetRepo) GetByIDs(ctx context.Context, ids []int) ([]models.Asset, er
 		return []models.Asset{}, nil
 	}
 
-	query := `SELECT id, external_id, name, asset_type, category_id, currency FROM assets WHERE id = ANY($1)`
+	query := `SELECT id, external_id, name, asset_type, category_id, currency, category_key, category_locked, COALESCE(client_id, '') FROM assets WHERE id = ANY($1)`
 	rows, err := r.db.Query(ctx, query, ids)
 	if err != nil {
 		return nil, err
@@ -68,7 +77,7 @@ func (r *assetRepo) GetByIDs(ctx context.Context, ids []int) ([]models.Asset, er
 	var assets []models.Asset
 	for rows.Next() {
 		var asset models.Asset
-		if err := rows.Scan(&asset.ID, &asset.ExternalID, &asset.Name, &asset.AssetType, &asset.CategoryID, &asset.Currency); err != nil {
+		if err := rows.Scan(&asset.ID, &asset.ExternalID, &asset.Name, &asset.AssetType, &asset.CategoryID, &asset.Currency, &asset.CategoryKey, &asset.CategoryLocked, &asset.ClientID); err != nil {
 			return nil, err
 		}
 		assets = append(assets, asset)
@@ -76,16 +85,63 @@ func (r *assetRepo) GetByIDs(ctx context.Context, ids []int) ([]models.Asset, er
 	return assets, rows.Err()
 }
 
-func (r *assetRepo) GetWithCategories(ctx context.Context) ([]models.AssetWithCategory, error) {
-	query := `
+// GetByExternalID returns the asset with the broker id, nil when it is not stored
+func (r *assetRepo) GetByExternalID(ctx context.Context, externalID string) (*models.Asset, error) {
+	var asset models.Asset
+	err := r.db.QueryRow(ctx, `SELECT id, external_id, name, asset_type, category_id, currency, category_key, category_locked, COALESCE(client_id, '') FROM assets WHERE external_id = $1`, externalID).
+		Scan(&asset.ID, &asset.ExternalID, &asset.Name, &asset.AssetType, &asset.CategoryID, &asset.Currency, &asset.CategoryKey, &asset.CategoryLocked, &asset.ClientID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// GetByClientID returns the assets the client tracks by hand
+func (r *assetRepo) GetByClientID(ctx context.Context, clientID string) ([]models.Asset, error) {
+	rows, err := r.db.Query(ctx, `SELECT id, external_id, name, asset_type, category_id, currency, category_key, category_locked, COALESCE(client_id, '') FROM assets
+		WHERE client_id = $1 AND deleted = FALSE
+		ORDER BY id`, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	assets := []models.Asset{}
+	for rows.Next() {
+		var asset models.Asset
+		if err := rows.Scan(&asset.ID, &asset.ExternalID, &asset.Name, &asset.AssetType, &asset.CategoryID, &asset.Currency, &asset.CategoryKey, &asset.CategoryLocked, &asset.ClientID); err != nil {
+			return nil, err
+		}
+		assets = append(assets, asset)
+	}
+	return assets, rows.Err()
+}
+
+const assetWithCategoryQuery = `
 		SELECT
-			a.id, a.external_id, a.name, a.asset_type, a.category_id, a.currency,
+			a.id, a.external_id, a.name, a.asset_type, a.category_id, a.currency, a.category_key, a.category_locked,
 			ac.name as category_name, ac.description as category_description
 		FROM assets a
-		LEFT JOIN asset_categories ac ON a.category_id = ac.id
-		ORDER BY a.external_id`
+		LEFT JOIN asset_categories ac ON a.category_id = ac.id`
+
+func (r *assetRepo) GetWithCategories(ctx context.Context) ([]models.AssetWithCategory, error) {
+	return r.queryWithCategories(ctx, assetWithCategoryQuery+`
+		ORDER BY a.external_id`)
+}
+
+// GetByCategoryName returns the assets of the category, including the ones without category
+// when categoryName is the unclassified category
+func (r *assetRepo) GetByCategoryName(ctx context.Context, categoryName string) ([]models.AssetWithCategory, error) {
+	return r.queryWithCategories(ctx, assetWithCategoryQuery+`
+		WHERE ac.name = $1 OR (ac.id IS NULL AND $1::text = $2::text)
+		ORDER BY a.external_id`, categoryName, models.UnclassifiedCategory)
+}
 
-	rows, err := r.db.Query(ctx, query)
+func (r *assetRepo) queryWithCategories(ctx context.Context, query string, args ...interface{}) ([]models.AssetWithCategory, error) {
+	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -94,10 +150,14 @@ func (r *assetRepo) GetWithCategories(ctx context.Context) ([]models.AssetWithCa
 	var assets []models.AssetWithCategory
 	for rows.Next() {
 		var asset models.AssetWithCategory
+		var categoryID *int
 		var categoryName, categoryDescription *string
-		if err := rows.Scan(&asset.ID, &asset.ExternalID, &asset.Name, &asset.AssetType, &asset.CategoryID, &asset.Currency, &categoryName, &categoryDescription); err != nil {
+		if err := rows.Scan(&asset.ID, &asset.ExternalID, &asset.Name, &asset.AssetType, &categoryID, &asset.Currency, &asset.CategoryKey, &asset.CategoryLocked, &categoryName, &categoryDescription); err != nil {
 			return nil, err
 		}
+		if categoryID != nil {
+			asset.CategoryID = *categoryID
+		}
 		if categoryName != nil {
 			asset.CategoryName = *categoryName
 		}
@@ -109,15 +169,23 @@ func (r *assetRepo) GetWithCategories(ctx context.Context) ([]models.AssetWithCa
 	return assets, rows.Err()
 }
 
+func (r *assetRepo) CountByCategoryID(ctx context.Context, categoryID int) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM assets WHERE category_id = $1`, categoryID).Scan(&count)
+	return count, err
+}
+
+// Create upserts the asset by its external ID. A category assigned by hand is kept.
 func (r *assetRepo) Create(ctx context.Context, asset *models.Asset, tx pgx.Tx) error {
 	query := `
-		INSERT INTO assets (external_id, name, asset_type, category_id, currency)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO assets (external_id, name, asset_type, category_id, currency, category_key, client_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
 		ON CONFLICT (external_id) DO UPDATE SET
 			name = EXCLUDED.name,
 			asset_type = EXCLUDED.asset_type,
-			category_id = EXCLUDED.category_id,
-			currency = EXCLUDED.currency
+			category_id = CASE WHEN assets.category_locked THEN assets.category_id ELSE EXCLUDED.category_id END,
+			currency = EXCLUDED.currency,
+			category_key = COALESCE(NULLIF(EXCLUDED.category_key, ''), assets.category_key)
 		RETURNING id`
 
 	var err error
@@ -134,7 +202,7 @@ func (r *assetRepo) Create(ctx context.Context, asset *models.Asset, tx pgx.Tx)
 		}()
 
 		err = tx.QueryRow(ctx, query,
-			asset.ExternalID, asset.Name, asset.AssetType, asset.CategoryID, asset.Currency,
+			asset.ExternalID, asset.Name, asset.AssetType, asset.CategoryID, asset.Currency, asset.CategoryKey, asset.ClientID,
 		).Scan(&asset.ID)
 
 		if err != nil {
@@ -146,6 +214,37 @@ func (r *assetRepo) Create(ctx context.Context, asset *models.Asset, tx pgx.Tx)
 
 	// Use the provided transaction
 	return tx.QueryRow(ctx, query,
-		asset.ExternalID, asset.Name, asset.AssetType, asset.CategoryID, asset.Currency,
+		asset.ExternalID, asset.Name, asset.AssetType, asset.CategoryID, asset.Currency, asset.CategoryKey,
 	).Scan(&asset.ID)
 }
+
+// SetCategory assigns the category of the asset. Locked categories are not overwritten by syncs.
+func (r *assetRepo) SetCategory(ctx context.Context, id, categoryID int, locked bool) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE assets SET category_id = $2, category_locked = $3 WHERE id = $1`,
+		id, categoryID, locked,
+	)
+	return err
+}
+
+// Update stores the name, type, category and currency of the asset
+func (r *assetRepo) Update(ctx context.Context, asset *models.Asset) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE assets SET name = $2, asset_type = $3, category_id = $4, currency = $5 WHERE id = $1`,
+		asset.ID, asset.Name, asset.AssetType, asset.CategoryID, asset.Currency,
+	)
+	return err
+}
+
+// Delete flags the asset as deleted
+func (r *assetRepo) Delete(ctx context.Context, id int, tx pgx.Tx) error {
+	query := `UPDATE assets SET deleted = TRUE, deleted_at = $2 WHERE id = $1 AND deleted = FALSE`
+
+	var err error
+	if tx != nil {
+		_, err = tx.Exec(ctx, query, id, time.Now())
+	} else {
+		_, err = r.db.Exec(ctx, query, id, time.Now())
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"server/src/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type BenchmarkValueRepository interface {
+	GetNames(ctx context.Context) ([]string, error)
+	GetByName(ctx context.Context, name string, startDate, endDate time.Time) ([]models.BenchmarkValue, error)
+	GetLatestBeforeDate(ctx context.Context, name string, date time.Time) (*models.BenchmarkValue, error)
+	Create(ctx context.Context, value *models.BenchmarkValue, tx pgx.Tx) error
+	Delete(ctx context.Context, name string, date time.Time) error
+}
+
+type benchmarkValueRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewBenchmarkValueRepository(db *pgxpool.Pool) BenchmarkValueRepository {
+	return &benchmarkValueRepo{db: db}
+}
+
+const benchmarkValueColumns = `id, name, date, value, created_at`
+
+func scanBenchmarkValue(row pgx.Row) (*models.BenchmarkValue, error) {
+	var value models.BenchmarkValue
+	if err := row.Scan(&value.ID, &value.Name, &value.Date, &value.Value, &value.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// GetNames returns the names of the stored series, sorted
+func (r *benchmarkValueRepo) GetNames(ctx context.Context) ([]string, error) {
+	rows, err := r.db.Query(ctx, `SELECT DISTINCT name FROM benchmark_values ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// GetByName returns the values of the series between startDate and endDate, oldest first
+func (r *benchmarkValueRepo) GetByName(ctx context.Context, name string, startDate, endDate time.Time) ([]models.BenchmarkValue, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+benchmarkValueColumns+`
+		FROM benchmark_values
+		WHERE name = $1 AND date BETWEEN $2 AND $3
+		ORDER BY date`,
+		name, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make([]models.BenchmarkValue, 0)
+	for rows.Next() {
+		value, err := scanBenchmarkValue(rows)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, *value)
+	}
+	return values, rows.Err()
+}
+
+// GetLatestBeforeDate returns the last value of the series before date, nil when there is none
+func (r *benchmarkValueRepo) GetLatestBeforeDate(ctx context.Context, name string, date time.Time) (*models.BenchmarkValue, error) {
+	value, err := scanBenchmarkValue(r.db.QueryRow(ctx, `
+		SELECT `+benchmarkValueColumns+`
+		FROM benchmark_values
+		WHERE name = $1 AND date < $2
+		ORDER BY date DESC
+		LIMIT 1`,
+		name, date.Format("2006-01-02")))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return value, err
+}
+
+// Create stores the value, replacing the value of the series on the same date
+func (r *benchmarkValueRepo) Create(ctx context.Context, value *models.BenchmarkValue, tx pgx.Tx) error {
+	query := `
+		INSERT INTO benchmark_values (name, date, value)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (name, date) DO UPDATE SET value = EXCLUDED.value
+		RETURNING id, created_at`
+
+	date := value.Date.Format("2006-01-02")
+	if tx != nil {
+		return tx.QueryRow(ctx, query, value.Name, date, value.Value).Scan(&value.ID, &value.CreatedAt)
+	}
+	return r.db.QueryRow(ctx, query, value.Name, date, value.Value).Scan(&value.ID, &value.CreatedAt)
+}
+
+func (r *benchmarkValueRepo) Delete(ctx context.Context, name string, date time.Time) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM benchmark_values WHERE name = $1 AND date = $2`, name, date.Format("2006-01-02"))
+	return err
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"server/src/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CategoryRuleRepository interface {
+	GetAll(ctx context.Context) ([]*models.CategoryRule, error)
+	GetByID(ctx context.Context, id int) (*models.CategoryRule, error)
+	Create(ctx context.Context, rule *models.CategoryRule, tx pgx.Tx) error
+	Update(ctx context.Context, rule *models.CategoryRule) error
+	Delete(ctx context.Context, id int) error
+}
+
+type categoryRuleRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewCategoryRuleRepository(db *pgxpool.Pool) CategoryRuleRepository {
+	return &categoryRuleRepo{db: db}
+}
+
+const categoryRuleColumns = `r.id, r.category_id, ac.name, r.match_type, r.pattern, r.created_at`
+
+func scanCategoryRule(row pgx.Row) (*models.CategoryRule, error) {
+	var rule models.CategoryRule
+	err := row.Scan(
+		&rule.ID,
+		&rule.CategoryID,
+		&rule.CategoryName,
+		&rule.MatchType,
+		&rule.Pattern,
+		&rule.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetAll returns every rule with the name of its category, in creation order
+func (r *categoryRuleRepo) GetAll(ctx context.Context) ([]*models.CategoryRule, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+categoryRuleColumns+`
+		FROM category_rules r
+		JOIN asset_categories ac ON r.category_id = ac.id
+		ORDER BY r.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make([]*models.CategoryRule, 0)
+	for rows.Next() {
+		rule, err := scanCategoryRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (r *categoryRuleRepo) GetByID(ctx context.Context, id int) (*models.CategoryRule, error) {
+	rule, err := scanCategoryRule(r.db.QueryRow(ctx, `
+		SELECT `+categoryRuleColumns+`
+		FROM category_rules r
+		JOIN asset_categories ac ON r.category_id = ac.id
+		WHERE r.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return rule, err
+}
+
+// Create stores the rule, replacing the category of an existing rule with the same pattern
+func (r *categoryRuleRepo) Create(ctx context.Context, rule *models.CategoryRule, tx pgx.Tx) error {
+	query := `
+		INSERT INTO category_rules (category_id, match_type, pattern)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (match_type, pattern) DO UPDATE SET category_id = EXCLUDED.category_id
+		RETURNING id, created_at`
+
+	if tx != nil {
+		return tx.QueryRow(ctx, query, rule.CategoryID, rule.MatchType, rule.Pattern).Scan(&rule.ID, &rule.CreatedAt)
+	}
+	return r.db.QueryRow(ctx, query, rule.CategoryID, rule.MatchType, rule.Pattern).Scan(&rule.ID, &rule.CreatedAt)
+}
+
+func (r *categoryRuleRepo) Update(ctx context.Context, rule *models.CategoryRule) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE category_rules SET category_id = $2, match_type = $3, pattern = $4 WHERE id = $1`,
+		rule.ID, rule.CategoryID, rule.MatchType, rule.Pattern,
+	)
+	return err
+}
+
+func (r *categoryRuleRepo) Delete(ctx context.Context, id int) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM category_rules WHERE id = $1`, id)
+	return err
+}
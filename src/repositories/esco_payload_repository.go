@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"server/src/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ESCOPayloadRepository interface {
+	Create(ctx context.Context, payload *models.ESCOPayload) error
+	GetLatestByAccount(ctx context.Context, accountID string, endpoints []string, startDate, endDate time.Time) ([]*models.ESCOPayload, error)
+}
+
+type escoPayloadRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewESCOPayloadRepository(db *pgxpool.Pool) ESCOPayloadRepository {
+	return &escoPayloadRepo{db: db}
+}
+
+const escoPayloadColumns = `id, endpoint, account_id, cid, start_date, end_date, payload, fetched_at`
+
+func scanESCOPayload(row pgx.Row) (*models.ESCOPayload, error) {
+	var payload models.ESCOPayload
+	err := row.Scan(
+		&payload.ID,
+		&payload.Endpoint,
+		&payload.AccountID,
+		&payload.CID,
+		&payload.StartDate,
+		&payload.EndDate,
+		&payload.Payload,
+		&payload.FetchedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &payload, nil
+}
+
+// Create archives the payload, setting its ID and fetch time
+func (r *escoPayloadRepo) Create(ctx context.Context, payload *models.ESCOPayload) error {
+	return r.db.QueryRow(ctx, `
+		INSERT INTO esco_payloads (endpoint, account_id, cid, start_date, end_date, payload)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, fetched_at`,
+		payload.Endpoint, payload.AccountID, payload.CID, payload.StartDate, payload.EndDate, payload.Payload,
+	).Scan(&payload.ID, &payload.FetchedAt)
+}
+
+// GetLatestByAccount returns the last payload fetched for every endpoint and requested range
+// of the account starting between startDate and endDate (exclusive), most recent first
+func (r *escoPayloadRepo) GetLatestByAccount(ctx context.Context, accountID string, endpoints []string, startDate, endDate time.Time) ([]*models.ESCOPayload, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+escoPayloadColumns+`
+		FROM (
+			SELECT DISTINCT ON (endpoint, start_date, end_date) `+escoPayloadColumns+`
+			FROM esco_payloads
+			WHERE account_id = $1 AND endpoint = ANY($2) AND start_date >= $3 AND start_date < $4
+			ORDER BY endpoint, start_date, end_date, fetched_at DESC, id DESC
+		) latest
+		ORDER BY fetched_at DESC, id DESC`,
+		accountID, endpoints, startDate, endDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payloads := make([]*models.ESCOPayload, 0)
+	for rows.Next() {
+		payload, err := scanESCOPayload(rows)
+		if err != nil {
+			return nil, err
+		}
+		payloads = append(payloads, payload)
+	}
+	return payloads, rows.Err()
+}
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"server/src/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ExchangeRateRepository interface {
+	GetByCurrency(ctx context.Context, currency string, startDate, endDate time.Time) ([]models.ExchangeRate, error)
+	GetLatestBeforeDate(ctx context.Context, currency string, date time.Time) (*models.ExchangeRate, error)
+	Create(ctx context.Context, rate *models.ExchangeRate, tx pgx.Tx) error
+	Delete(ctx context.Context, currency string, date time.Time) error
+}
+
+type exchangeRateRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewExchangeRateRepository(db *pgxpool.Pool) ExchangeRateRepository {
+	return &exchangeRateRepo{db: db}
+}
+
+const exchangeRateColumns = `id, currency, date, rate, created_at`
+
+func scanExchangeRate(row pgx.Row) (*models.ExchangeRate, error) {
+	var rate models.ExchangeRate
+	if err := row.Scan(&rate.ID, &rate.Currency, &rate.Date, &rate.Rate, &rate.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// GetByCurrency returns the rates of the currency between startDate and endDate, oldest first
+func (r *exchangeRateRepo) GetByCurrency(ctx context.Context, currency string, startDate, endDate time.Time) ([]models.ExchangeRate, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+exchangeRateColumns+`
+		FROM exchange_rates
+		WHERE currency = $1 AND date BETWEEN $2 AND $3
+		ORDER BY date`,
+		currency, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := make([]models.ExchangeRate, 0)
+	for rows.Next() {
+		rate, err := scanExchangeRate(rows)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, *rate)
+	}
+	return rates, rows.Err()
+}
+
+// GetLatestBeforeDate returns the last rate of the currency before date, nil when there is none
+func (r *exchangeRateRepo) GetLatestBeforeDate(ctx context.Context, currency string, date time.Time) (*models.ExchangeRate, error) {
+	rate, err := scanExchangeRate(r.db.QueryRow(ctx, `
+		SELECT `+exchangeRateColumns+`
+		FROM exchange_rates
+		WHERE currency = $1 AND date < $2
+		ORDER BY date DESC
+		LIMIT 1`,
+		currency, date.Format("2006-01-02")))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return rate, err
+}
+
+// Create stores the rate, replacing the rate of the currency on the same date
+func (r *exchangeRateRepo) Create(ctx context.Context, rate *models.ExchangeRate, tx pgx.Tx) error {
+	query := `
+		INSERT INTO exchange_rates (currency, date, rate)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (currency, date) DO UPDATE SET rate = EXCLUDED.rate
+		RETURNING id, created_at`
+
+	date := rate.Date.Format("2006-01-02")
+	if tx != nil {
+		return tx.QueryRow(ctx, query, rate.Currency, date, rate.Rate).Scan(&rate.ID, &rate.CreatedAt)
+	}
+	return r.db.QueryRow(ctx, query, rate.Currency, date, rate.Rate).Scan(&rate.ID, &rate.CreatedAt)
+}
+
+func (r *exchangeRateRepo) Delete(ctx context.Context, currency string, date time.Time) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM exchange_rates WHERE currency = $1 AND date = $2`, currency, date.Format("2006-01-02"))
+	return err
+}
@@ -15,7 +15,12 @@ type HoldingRepository interface {
 	GetByClientIDs(ctx context.Context, clientIDs []string, startDate, endDate time.Time) ([]models.Holding, error)
 	GetGroupedByCategoryAndDate(ctx context.Context, clientIDs []string, startDate, endDate time.Time) (map[string]map[string]float64, error)
 	GetTotalByDate(ctx context.Context, clientIDs []string, startDate, endDate time.Time) (map[string]float64, error)
+	GetByAssetID(ctx context.Context, clientID string, assetID int) ([]models.Holding, error)
+	GetLatestBeforeDate(ctx context.Context, clientIDs []string, source string, date time.Time) ([]models.Holding, error)
+	GetLatestByClientIDs(ctx context.Context, clientIDs []string, date time.Time) ([]models.Holding, error)
 	Create(ctx context.Context, h *models.Holding, tx pgx.Tx) error
+	SoftDeleteByClientID(ctx context.Context, clientID, source string, startDate, endDate time.Time, tx pgx.Tx) error
+	SoftDeleteByAssetID(ctx context.Context, clientID string, assetID int, date *time.Time, tx pgx.Tx) error
 }
 
 type holdingRepo struct {
@@ -28,9 +33,9 @@ func NewHoldingRepository(db *pgxpool.Pool) HoldingRepository {
 
 func (r *holdingRepo) GetByClientID(ctx context.Context, clientID string, startDate, endDate time.Time) ([]models.Holding, error) {
 	rows, err := r.db.Query(ctx,
-		`SELECT id, client_id, asset_id, units, value, date, created_at, deleted, deleted_at
+		`SELECT id, client_id, source, asset_id, units, value, date, created_at, deleted, deleted_at
 		FROM holdings
-		WHERE client_id = $1 AND date BETWEEN $2 AND $3
+		WHERE client_id = $1 AND date BETWEEN $2 AND $3 AND deleted = FALSE
 		ORDER BY date DESC`,
 		clientID, startDate, endDate)
 	if err != nil {
@@ -43,7 +48,7 @@ func (r *holdingRepo) GetByClientID(ctx context.Context, clientID string, startD
 		var h models.Holding
 		var date, createdAt time.Time
 		var deletedAt *time.Time
-		if err := rows.Scan(&h.ID, &h.ClientID, &h.AssetID, &h.Units, &h.Value, &date, &createdAt, &h.Deleted, &deletedAt); err != nil {
+		if err := rows.Scan(&h.ID, &h.ClientID, &h.Source, &h.AssetID, &h.Units, &h.Value, &date, &createdAt, &h.Deleted, &deletedAt); err != nil {
 			return nil, err
 		}
 		h.Date = date
@@ -60,9 +65,9 @@ func (r *holdingRepo) GetByClientIDs(ctx context.Context, clientIDs []string, st
 	}
 
 	// Build the query with proper placeholders
-	query := `SELECT h.id, h.client_id, h.asset_id, h.units, h.value, h.date, h.created_at, h.deleted, h.deleted_at
+	query := `SELECT h.id, h.client_id, h.source, h.asset_id, h.units, h.value, h.date, h.created_at, h.deleted, h.deleted_at
 		FROM holdings h
-		WHERE h.client_id = ANY($1) AND h.date BETWEEN $2 AND $3
+		WHERE h.client_id = ANY($1) AND h.date BETWEEN $2 AND $3 AND h.deleted = FALSE
 		ORDER BY h.date DESC`
 
 	rows, err := r.db.Query(ctx, query, clientIDs, startDate, endDate)
@@ -76,7 +81,7 @@ func (r *holdingRepo) GetByClientIDs(ctx context.Context, clientIDs []string, st
 		var h models.Holding
 		var date, createdAt time.Time
 		var deletedAt *time.Time
-		if err := rows.Scan(&h.ID, &h.ClientID, &h.AssetID, &h.Units, &h.Value, &date, &createdAt, &h.Deleted, &deletedAt); err != nil {
+		if err := rows.Scan(&h.ID, &h.ClientID, &h.Source, &h.AssetID, &h.Units, &h.Value, &date, &createdAt, &h.Deleted, &deletedAt); err != nil {
 			return nil, err
 		}
 		h.Date = date
@@ -100,7 +105,7 @@ func (r *holdingRepo) GetGroupedByCategoryAndDate(ctx context.Context, clientIDs
 		FROM holdings h
 		JOIN assets a ON h.asset_id = a.id
 		JOIN asset_categories ac ON a.category_id = ac.id
-		WHERE h.client_id = ANY($1) AND h.date BETWEEN $2 AND $3
+		WHERE h.client_id = ANY($1) AND h.date BETWEEN $2 AND $3 AND h.deleted = FALSE
 		GROUP BY ac.name, DATE(h.date)
 		ORDER BY ac.name, DATE(h.date)`
 
@@ -138,7 +143,7 @@ func (r *holdingRepo) GetTotalByDate(ctx context.Context, clientIDs []string, st
 			DATE(h.date) as date,
 			SUM(h.value) as total_value
 		FROM holdings h
-		WHERE h.client_id = ANY($1) AND h.date BETWEEN $2 AND $3
+		WHERE h.client_id = ANY($1) AND h.date BETWEEN $2 AND $3 AND h.deleted = FALSE
 		GROUP BY DATE(h.date)
 		ORDER BY DATE(h.date)`
 
@@ -162,13 +167,71 @@ func (r *holdingRepo) GetTotalByDate(ctx context.Context, clientIDs []string, st
 	return result, rows.Err()
 }
 
+// GetByAssetID returns the client holdings of the asset, oldest first
+func (r *holdingRepo) GetByAssetID(ctx context.Context, clientID string, assetID int) ([]models.Holding, error) {
+	return r.query(ctx,
+		`SELECT id, client_id, source, asset_id, units, value, date, created_at, deleted, deleted_at
+		FROM holdings
+		WHERE client_id = $1 AND asset_id = $2 AND deleted = FALSE
+		ORDER BY date`,
+		clientID, assetID)
+}
+
+// GetLatestBeforeDate returns the last holding stored from source before date of every asset of the clients
+func (r *holdingRepo) GetLatestBeforeDate(ctx context.Context, clientIDs []string, source string, date time.Time) ([]models.Holding, error) {
+	if len(clientIDs) == 0 {
+		return []models.Holding{}, nil
+	}
+
+	return r.query(ctx,
+		`SELECT DISTINCT ON (client_id, asset_id) id, client_id, source, asset_id, units, value, date, created_at, deleted, deleted_at
+		FROM holdings
+		WHERE client_id = ANY($1) AND source = $2 AND date < $3 AND deleted = FALSE
+		ORDER BY client_id, asset_id, date DESC`,
+		clientIDs, source, date)
+}
+
+// GetLatestByClientIDs returns the last holding up to date of every asset of the clients, whatever its source
+func (r *holdingRepo) GetLatestByClientIDs(ctx context.Context, clientIDs []string, date time.Time) ([]models.Holding, error) {
+	if len(clientIDs) == 0 {
+		return []models.Holding{}, nil
+	}
+
+	return r.query(ctx,
+		`SELECT DISTINCT ON (client_id, asset_id) id, client_id, source, asset_id, units, value, date, created_at, deleted, deleted_at
+		FROM holdings
+		WHERE client_id = ANY($1) AND date <= $2 AND deleted = FALSE
+		ORDER BY client_id, asset_id, date DESC`,
+		clientIDs, date)
+}
+
+func (r *holdingRepo) query(ctx context.Context, query string, args ...interface{}) ([]models.Holding, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holdings := []models.Holding{}
+	for rows.Next() {
+		var h models.Holding
+		if err := rows.Scan(&h.ID, &h.ClientID, &h.Source, &h.AssetID, &h.Units, &h.Value, &h.Date, &h.CreatedAt, &h.Deleted, &h.DeletedAt); err != nil {
+			return nil, err
+		}
+		holdings = append(holdings, h)
+	}
+	return holdings, rows.Err()
+}
+
 func (r *holdingRepo) Create(ctx context.Context, h *models.Holding, tx pgx.Tx) error {
 	query := `
-		INSERT INTO holdings (client_id, asset_id, units, value, date)
-		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (client_id, asset_id, date) DO UPDATE SET
+		INSERT INTO holdings (client_id, source, asset_id, units, value, date)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (client_id, source, asset_id, date) DO UPDATE SET
 			units = EXCLUDED.units,
-			value = EXCLUDED.value
+			value = EXCLUDED.value,
+			deleted = FALSE,
+			deleted_at = NULL
 		RETURNING id`
 
 	var err error
@@ -185,7 +248,7 @@ func (r *holdingRepo) Create(ctx context.Context, h *models.Holding, tx pgx.Tx)
 		}()
 
 		err = tx.QueryRow(ctx, query,
-			h.ClientID, h.AssetID, h.Units, h.Value, h.Date,
+			h.ClientID, h.Source, h.AssetID, h.Units, h.Value, h.Date,
 		).Scan(&h.ID)
 
 		if err != nil {
@@ -197,6 +260,38 @@ func (r *holdingRepo) Create(ctx context.Context, h *models.Holding, tx pgx.Tx)
 
 	// Use the provided transaction
 	return tx.QueryRow(ctx, query,
-		h.ClientID, h.AssetID, h.Units, h.Value, h.Date,
+		h.ClientID, h.Source, h.AssetID, h.Units, h.Value, h.Date,
 	).Scan(&h.ID)
 }
+
+// SoftDeleteByClientID flags the client holdings synced from source between startDate and endDate (inclusive) as deleted
+func (r *holdingRepo) SoftDeleteByClientID(ctx context.Context, clientID, source string, startDate, endDate time.Time, tx pgx.Tx) error {
+	query := `
+		UPDATE holdings
+		SET deleted = TRUE, deleted_at = $5
+		WHERE client_id = $1 AND source = $2 AND date BETWEEN $3 AND $4 AND deleted = FALSE`
+
+	var err error
+	if tx != nil {
+		_, err = tx.Exec(ctx, query, clientID, source, startDate, endDate, time.Now())
+	} else {
+		_, err = r.db.Exec(ctx, query, clientID, source, startDate, endDate, time.Now())
+	}
+	return err
+}
+
+// SoftDeleteByAssetID flags the client holdings of the asset as deleted, only the ones of date when given
+func (r *holdingRepo) SoftDeleteByAssetID(ctx context.Context, clientID string, assetID int, date *time.Time, tx pgx.Tx) error {
+	query := `
+		UPDATE holdings
+		SET deleted = TRUE, deleted_at = $4
+		WHERE client_id = $1 AND asset_id = $2 AND ($3::date IS NULL OR date = $3) AND deleted = FALSE`
+
+	var err error
+	if tx != nil {
+		_, err = tx.Exec(ctx, query, clientID, assetID, date, time.Now())
+	} else {
+		_, err = r.db.Exec(ctx, query, clientID, assetID, date, time.Now())
+	}
+	return err
+}
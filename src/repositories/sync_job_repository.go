@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"server/src/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SyncJobRepository interface {
+	Create(ctx context.Context, job *models.SyncJob) error
+	GetByID(ctx context.Context, id int) (*models.SyncJob, error)
+	ClaimNextPending(ctx context.Context) (*models.SyncJob, error)
+	UpdateProgress(ctx context.Context, id int, totalDates, processedDates int) error
+	MarkCompleted(ctx context.Context, id int) error
+	MarkFailed(ctx context.Context, id int, errMessage string) error
+	RequeueRunning(ctx context.Context, staleBefore time.Time) (int64, error)
+}
+
+type syncJobRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSyncJobRepository(db *pgxpool.Pool) SyncJobRepository {
+	return &syncJobRepo{db: db}
+}
+
+const syncJobColumns = `id, client_id, start_date, end_date, status, force, source, total_dates, processed_dates, error, created_at, started_at, finished_at`
+
+func scanSyncJob(row pgx.Row) (*models.SyncJob, error) {
+	var job models.SyncJob
+	err := row.Scan(
+		&job.ID,
+		&job.ClientID,
+		&job.StartDate,
+		&job.EndDate,
+		&job.Status,
+		&job.Force,
+		&job.Source,
+		&job.TotalDates,
+		&job.ProcessedDates,
+		&job.Error,
+		&job.CreatedAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *syncJobRepo) Create(ctx context.Context, job *models.SyncJob) error {
+	if job.Status == "" {
+		job.Status = models.SyncJobPending
+	}
+	return r.db.QueryRow(ctx, `
+		INSERT INTO sync_jobs (client_id, start_date, end_date, status, force, source)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`,
+		job.ClientID, job.StartDate, job.EndDate, job.Status, job.Force, job.Source,
+	).Scan(&job.ID, &job.CreatedAt)
+}
+
+func (r *syncJobRepo) GetByID(ctx context.Context, id int) (*models.SyncJob, error) {
+	job, err := scanSyncJob(r.db.QueryRow(ctx, `SELECT `+syncJobColumns+` FROM sync_jobs WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return job, err
+}
+
+// ClaimNextPending atomically moves the oldest pending job to RUNNING and returns it.
+// Concurrent workers skip rows already locked by another claim, so each job runs once.
+func (r *syncJobRepo) ClaimNextPending(ctx context.Context) (*models.SyncJob, error) {
+	job, err := scanSyncJob(r.db.QueryRow(ctx, `
+		UPDATE sync_jobs
+		SET status = $1, started_at = $2, heartbeat_at = $2
+		WHERE id = (
+			SELECT id
+			FROM sync_jobs
+			WHERE status = $3
+			ORDER BY created_at ASC
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+syncJobColumns,
+		models.SyncJobRunning, time.Now(), models.SyncJobPending,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return job, err
+}
+
+// UpdateProgress stores the progress of a running job and refreshes its heartbeat
+func (r *syncJobRepo) UpdateProgress(ctx context.Context, id int, totalDates, processedDates int) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE sync_jobs
+		SET total_dates = $2, processed_dates = $3, heartbeat_at = $4
+		WHERE id = $1`,
+		id, totalDates, processedDates, time.Now(),
+	)
+	return err
+}
+
+func (r *syncJobRepo) MarkCompleted(ctx context.Context, id int) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE sync_jobs
+		SET status = $2, finished_at = $3
+		WHERE id = $1`,
+		id, models.SyncJobCompleted, time.Now(),
+	)
+	return err
+}
+
+func (r *syncJobRepo) MarkFailed(ctx context.Context, id int, errMessage string) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE sync_jobs
+		SET status = $2, finished_at = $3, error = $4
+		WHERE id = $1`,
+		id, models.SyncJobFailed, time.Now(), errMessage,
+	)
+	return err
+}
+
+// RequeueRunning moves the RUNNING jobs whose heartbeat is older than staleBefore back to PENDING so
+// they are claimed again, returning how many were moved. Jobs still running in another worker keep
+// refreshing their heartbeat, so they are left alone.
+func (r *syncJobRepo) RequeueRunning(ctx context.Context, staleBefore time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `
+		UPDATE sync_jobs
+		SET status = $1, started_at = NULL, heartbeat_at = NULL
+		WHERE status = $2
+		AND COALESCE(heartbeat_at, started_at, created_at) < $3`,
+		models.SyncJobPending, models.SyncJobRunning, staleBefore,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -6,15 +6,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type SyncLogRepository interface {
-	MarkClientForDate(ctx context.Context, clientID string, syncDate time.Time) error
+	MarkClientForDate(ctx context.Context, clientID, source string, syncDate time.Time) error
 	GetLastSyncDate(ctx context.Context, clientID string) (*time.Time, error)
-	MarkClientForDates(ctx context.Context, clientID string, syncDates []time.Time) error
-	GetSyncedDates(ctx context.Context, clientID string, startDate time.Time, endDate time.Time) ([]time.Time, error)
-	CleanupSyncLogs(ctx context.Context, clientID string, startDate time.Time, endDate time.Time) error
+	MarkClientForDates(ctx context.Context, clientID, source string, syncDates []time.Time, tx pgx.Tx) error
+	GetSyncedDates(ctx context.Context, clientID, source string, startDate time.Time, endDate time.Time) ([]time.Time, error)
+	CleanupSyncLogs(ctx context.Context, clientID, source string, startDate time.Time, endDate time.Time, tx pgx.Tx) error
+	GetClientIDs(ctx context.Context) ([]string, error)
 }
 
 type syncLogRepo struct {
@@ -25,15 +27,15 @@ func NewSyncLogRepository(db *pgxpool.Pool) SyncLogRepository {
 	return &syncLogRepo{DB: db}
 }
 
-func (r *syncLogRepo) MarkClientForDate(ctx context.Context, clientID string, syncDate time.Time) error {
+func (r *syncLogRepo) MarkClientForDate(ctx context.Context, clientID, source string, syncDate time.Time) error {
 	query := `
-		INSERT INTO sync_logs (client_id, sync_date)
-		VALUES ($1, $2)
-		ON CONFLICT (client_id, sync_date) DO NOTHING`
+		INSERT INTO sync_logs (client_id, source, sync_date)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (client_id, source, sync_date) DO NOTHING`
 
 	var err error
 
-	_, err = r.DB.Exec(ctx, query, clientID, syncDate)
+	_, err = r.DB.Exec(ctx, query, clientID, source, syncDate)
 	if err != nil {
 		return err
 	}
@@ -58,33 +60,39 @@ func (r *syncLogRepo) GetLastSyncDate(ctx context.Context, clientID string) (*ti
 	return &syncDate, nil
 }
 
-func (r *syncLogRepo) MarkClientForDates(ctx context.Context, clientID string, syncDates []time.Time) error {
+func (r *syncLogRepo) MarkClientForDates(ctx context.Context, clientID, source string, syncDates []time.Time, tx pgx.Tx) error {
 	if len(syncDates) == 0 {
 		return nil
 	}
 
-	// Start a transaction
-	tx, err := r.DB.Begin(ctx)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback(ctx)
-
 	// Build the query with multiple value pairs and conflict handling
 	query := `
-		INSERT INTO sync_logs (client_id, sync_date)
+		INSERT INTO sync_logs (client_id, source, sync_date)
 		VALUES `
 
-	args := make([]interface{}, 0, len(syncDates)*2)
+	args := make([]interface{}, 0, len(syncDates)*3)
 	valueStrings := make([]string, 0, len(syncDates))
 
 	for i, date := range syncDates {
-		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d)", i*2+1, i*2+2))
-		args = append(args, clientID, date)
+		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d)", i*3+1, i*3+2, i*3+3))
+		args = append(args, clientID, source, date)
 	}
 
 	query += strings.Join(valueStrings, ",")
-	query += " ON CONFLICT (client_id, sync_date) DO NOTHING"
+	query += " ON CONFLICT (client_id, source, sync_date) DO NOTHING"
+
+	if tx != nil {
+		// Use the provided transaction
+		_, err := tx.Exec(ctx, query, args...)
+		return err
+	}
+
+	// Start a transaction
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
 
 	// Execute the single insert
 	_, err = tx.Exec(ctx, query, args...)
@@ -96,28 +104,36 @@ func (r *syncLogRepo) MarkClientForDates(ctx context.Context, clientID string, s
 	return tx.Commit(ctx)
 }
 
-func (r *syncLogRepo) CleanupSyncLogs(ctx context.Context, clientID string, startDate time.Time, endDate time.Time) error {
-	_, err := r.DB.Exec(ctx, `
+func (r *syncLogRepo) CleanupSyncLogs(ctx context.Context, clientID, source string, startDate time.Time, endDate time.Time, tx pgx.Tx) error {
+	query := `
 		DELETE FROM sync_logs
 		WHERE client_id = $1
-		AND sync_date >= $2
-		AND sync_date <= $3
-	`, clientID, startDate, endDate)
+		AND source = $2
+		AND sync_date >= $3
+		AND sync_date <= $4
+	`
+	var err error
+	if tx != nil {
+		_, err = tx.Exec(ctx, query, clientID, source, startDate, endDate)
+	} else {
+		_, err = r.DB.Exec(ctx, query, clientID, source, startDate, endDate)
+	}
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *syncLogRepo) GetSyncedDates(ctx context.Context, clientID string, startDate time.Time, endDate time.Time) ([]time.Time, error) {
+func (r *syncLogRepo) GetSyncedDates(ctx context.Context, clientID, source string, startDate time.Time, endDate time.Time) ([]time.Time, error) {
 	rows, err := r.DB.Query(ctx, `
 		SELECT sync_date
 		FROM sync_logs
 		WHERE client_id = $1
-		AND sync_date >= $2
-		AND sync_date < $3
+		AND source = $2
+		AND sync_date >= $3
+		AND sync_date < $4
 		ORDER BY sync_date ASC
-	`, clientID, startDate, endDate)
+	`, clientID, source, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -138,3 +154,30 @@ func (r *syncLogRepo) GetSyncedDates(ctx context.Context, clientID string, start
 
 	return dates, nil
 }
+
+func (r *syncLogRepo) GetClientIDs(ctx context.Context) ([]string, error) {
+	rows, err := r.DB.Query(ctx, `
+		SELECT DISTINCT client_id
+		FROM sync_logs
+		ORDER BY client_id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clientIDs []string
+	for rows.Next() {
+		var clientID string
+		if err := rows.Scan(&clientID); err != nil {
+			return nil, err
+		}
+		clientIDs = append(clientIDs, clientID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return clientIDs, nil
+}
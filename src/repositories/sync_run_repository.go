@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"server/src/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SyncRunRepository interface {
+	Create(ctx context.Context, run *models.SyncRun) error
+	Finish(ctx context.Context, run *models.SyncRun) error
+	GetByClientID(ctx context.Context, clientID string, limit int) ([]*models.SyncRun, error)
+}
+
+type syncRunRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSyncRunRepository(db *pgxpool.Pool) SyncRunRepository {
+	return &syncRunRepo{db: db}
+}
+
+const syncRunColumns = `id, client_id, start_date, end_date, triggered_by, status, dates_synced, holdings_count, transactions_count, error, started_at, finished_at`
+
+func scanSyncRun(row pgx.Row) (*models.SyncRun, error) {
+	var run models.SyncRun
+	err := row.Scan(
+		&run.ID,
+		&run.ClientID,
+		&run.StartDate,
+		&run.EndDate,
+		&run.TriggeredBy,
+		&run.Status,
+		&run.DatesSynced,
+		&run.HoldingsCount,
+		&run.TransactionsCount,
+		&run.Error,
+		&run.StartedAt,
+		&run.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// Create stores a new run in RUNNING status, setting its ID and start time
+func (r *syncRunRepo) Create(ctx context.Context, run *models.SyncRun) error {
+	run.Status = models.SyncRunRunning
+	return r.db.QueryRow(ctx, `
+		INSERT INTO sync_runs (client_id, start_date, end_date, triggered_by, status)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, started_at`,
+		run.ClientID, run.StartDate, run.EndDate, run.TriggeredBy, run.Status,
+	).Scan(&run.ID, &run.StartedAt)
+}
+
+// Finish stores the final status, counts and error of the run
+func (r *syncRunRepo) Finish(ctx context.Context, run *models.SyncRun) error {
+	finishedAt := time.Now()
+	_, err := r.db.Exec(ctx, `
+		UPDATE sync_runs
+		SET status = $2, dates_synced = $3, holdings_count = $4, transactions_count = $5, error = $6, finished_at = $7
+		WHERE id = $1`,
+		run.ID, run.Status, run.DatesSynced, run.HoldingsCount, run.TransactionsCount, run.Error, finishedAt,
+	)
+	if err != nil {
+		return err
+	}
+	run.FinishedAt = &finishedAt
+	return nil
+}
+
+// GetByClientID returns the latest runs of the client, most recent first
+func (r *syncRunRepo) GetByClientID(ctx context.Context, clientID string, limit int) ([]*models.SyncRun, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+syncRunColumns+`
+		FROM sync_runs
+		WHERE client_id = $1
+		ORDER BY started_at DESC, id DESC
+		LIMIT $2`,
+		clientID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := make([]*models.SyncRun, 0)
+	for rows.Next() {
+		run, err := scanSyncRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
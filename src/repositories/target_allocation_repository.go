@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+
+	"server/src/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TargetAllocationRepository interface {
+	GetNames(ctx context.Context) ([]string, error)
+	GetByName(ctx context.Context, name string) ([]models.TargetAllocation, error)
+	Create(ctx context.Context, allocation *models.TargetAllocation, tx pgx.Tx) error
+	DeleteByName(ctx context.Context, name string, tx pgx.Tx) error
+}
+
+type targetAllocationRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewTargetAllocationRepository(db *pgxpool.Pool) TargetAllocationRepository {
+	return &targetAllocationRepo{db: db}
+}
+
+// GetNames returns the names of the stored allocations, sorted
+func (r *targetAllocationRepo) GetNames(ctx context.Context) ([]string, error) {
+	rows, err := r.db.Query(ctx, `SELECT DISTINCT name FROM target_allocations ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// GetByName returns the weights of the categories of the allocation, sorted by category
+func (r *targetAllocationRepo) GetByName(ctx context.Context, name string) ([]models.TargetAllocation, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, name, category, weight, benchmark, benchmark_kind, created_at
+		FROM target_allocations
+		WHERE name = $1
+		ORDER BY category`,
+		name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	allocations := make([]models.TargetAllocation, 0)
+	for rows.Next() {
+		var allocation models.TargetAllocation
+		if err := rows.Scan(&allocation.ID, &allocation.Name, &allocation.Category, &allocation.Weight, &allocation.Benchmark, &allocation.BenchmarkKind, &allocation.CreatedAt); err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, allocation)
+	}
+	return allocations, rows.Err()
+}
+
+func (r *targetAllocationRepo) Create(ctx context.Context, allocation *models.TargetAllocation, tx pgx.Tx) error {
+	query := `
+		INSERT INTO target_allocations (name, category, weight, benchmark, benchmark_kind)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	args := []interface{}{allocation.Name, allocation.Category, allocation.Weight, allocation.Benchmark, allocation.BenchmarkKind}
+	if tx != nil {
+		return tx.QueryRow(ctx, query, args...).Scan(&allocation.ID, &allocation.CreatedAt)
+	}
+	return r.db.QueryRow(ctx, query, args...).Scan(&allocation.ID, &allocation.CreatedAt)
+}
+
+func (r *targetAllocationRepo) DeleteByName(ctx context.Context, name string, tx pgx.Tx) error {
+	query := `DELETE FROM target_allocations WHERE name = $1`
+	var err error
+	if tx != nil {
+		_, err = tx.Exec(ctx, query, name)
+	} else {
+		_, err = r.db.Exec(ctx, query, name)
+	}
+	return err
+}
@@ -12,10 +12,14 @@ import (
 
 type TransactionRepository interface {
 	GetByClientID(ctx context.Context, clientID string, startDate, endDate time.Time) ([]models.Transaction, error)
-	GetByClientIDs(ctx context.Context, clientIDs []string, startDate, endDate time.Time) ([]models.Transaction, error)
-	GetGroupedByCategoryAndDate(ctx context.Context, clientIDs []string, startDate, endDate time.Time) (map[string]map[string]float64, error)
-	GetTotalByDate(ctx context.Context, clientIDs []string, startDate, endDate time.Time) (map[string]float64, error)
+	GetByClientIDs(ctx context.Context, clientIDs []string, startDate, endDate time.Time, transactionTypes []string) ([]models.Transaction, error)
+	GetGroupedByCategoryAndDate(ctx context.Context, clientIDs []string, startDate, endDate time.Time, transactionTypes []string) (map[string]map[string]float64, error)
+	GetGroupedByTypeAndDate(ctx context.Context, clientIDs []string, startDate, endDate time.Time, transactionTypes []string) (map[string]map[string]float64, error)
+	GetTotalByDate(ctx context.Context, clientIDs []string, startDate, endDate time.Time, transactionTypes []string) (map[string]float64, error)
+	GetByAssetID(ctx context.Context, clientID string, assetID int) ([]models.Transaction, error)
 	Create(ctx context.Context, t *models.Transaction, tx pgx.Tx) error
+	SoftDeleteByClientID(ctx context.Context, clientID, source string, startDate, endDate time.Time, tx pgx.Tx) error
+	SoftDeleteByAssetID(ctx context.Context, clientID string, assetID int, date *time.Time, tx pgx.Tx) error
 }
 
 type transactionRepo struct {
@@ -26,11 +30,19 @@ func NewTransactionRepository(db *pgxpool.Pool) TransactionRepository {
 	return &transactionRepo{db: db}
 }
 
+// transactionTypesFilter returns the types to filter by, nil (no filter) when none are given
+func transactionTypesFilter(transactionTypes []string) []string {
+	if len(transactionTypes) == 0 {
+		return nil
+	}
+	return transactionTypes
+}
+
 func (r *transactionRepo) GetByClientID(ctx context.Context, clientID string, startDate, endDate time.Time) ([]models.Transaction, error) {
 	rows, err := r.db.Query(ctx,
-		`SELECT id, client_id, asset_id, transaction_type, units, price_per_unit, total_value, date, created_at, deleted, deleted_at
+		`SELECT id, client_id, source, asset_id, transaction_type, units, price_per_unit, gross_value, exchange_fees, market_fees, total_value, date, created_at, deleted, deleted_at
 		FROM transactions
-		WHERE client_id = $1 AND date BETWEEN $2 AND $3
+		WHERE client_id = $1 AND date BETWEEN $2 AND $3 AND deleted = FALSE
 		ORDER BY date DESC`,
 		clientID, startDate, endDate,
 	)
@@ -44,7 +56,7 @@ func (r *transactionRepo) GetByClientID(ctx context.Context, clientID string, st
 		var t models.Transaction
 		var date, createdAt time.Time
 		var deletedAt *time.Time
-		if err := rows.Scan(&t.ID, &t.ClientID, &t.AssetID, &t.TransactionType, &t.Units, &t.PricePerUnit, &t.TotalValue, &date, &createdAt, &t.Deleted, &deletedAt); err != nil {
+		if err := rows.Scan(&t.ID, &t.ClientID, &t.Source, &t.AssetID, &t.TransactionType, &t.Units, &t.PricePerUnit, &t.GrossValue, &t.ExchangeFees, &t.MarketFees, &t.TotalValue, &date, &createdAt, &t.Deleted, &deletedAt); err != nil {
 			return nil, err
 		}
 		t.Date = date
@@ -55,17 +67,20 @@ func (r *transactionRepo) GetByClientID(ctx context.Context, clientID string, st
 	return transactions, rows.Err()
 }
 
-func (r *transactionRepo) GetByClientIDs(ctx context.Context, clientIDs []string, startDate, endDate time.Time) ([]models.Transaction, error) {
+// GetByClientIDs returns the transactions of the clients between startDate and endDate (inclusive),
+// only of the given types when any are given
+func (r *transactionRepo) GetByClientIDs(ctx context.Context, clientIDs []string, startDate, endDate time.Time, transactionTypes []string) ([]models.Transaction, error) {
 	if len(clientIDs) == 0 {
 		return []models.Transaction{}, nil
 	}
 
-	query := `SELECT t.id, t.client_id, t.asset_id, t.transaction_type, t.units, t.price_per_unit, t.total_value, t.date, t.created_at, t.deleted, t.deleted_at
+	query := `SELECT t.id, t.client_id, t.source, t.asset_id, t.transaction_type, t.units, t.price_per_unit, t.gross_value, t.exchange_fees, t.market_fees, t.total_value, t.date, t.created_at, t.deleted, t.deleted_at
 		FROM transactions t
-		WHERE t.client_id = ANY($1) AND t.date BETWEEN $2 AND $3
+		WHERE t.client_id = ANY($1) AND t.date BETWEEN $2 AND $3 AND t.deleted = FALSE
+			AND ($4::text[] IS NULL OR t.transaction_type = ANY($4))
 		ORDER BY t.date DESC`
 
-	rows, err := r.db.Query(ctx, query, clientIDs, startDate, endDate)
+	rows, err := r.db.Query(ctx, query, clientIDs, startDate, endDate, transactionTypesFilter(transactionTypes))
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +91,7 @@ func (r *transactionRepo) GetByClientIDs(ctx context.Context, clientIDs []string
 		var t models.Transaction
 		var date, createdAt time.Time
 		var deletedAt *time.Time
-		if err := rows.Scan(&t.ID, &t.ClientID, &t.AssetID, &t.TransactionType, &t.Units, &t.PricePerUnit, &t.TotalValue, &date, &createdAt, &t.Deleted, &deletedAt); err != nil {
+		if err := rows.Scan(&t.ID, &t.ClientID, &t.Source, &t.AssetID, &t.TransactionType, &t.Units, &t.PricePerUnit, &t.GrossValue, &t.ExchangeFees, &t.MarketFees, &t.TotalValue, &date, &createdAt, &t.Deleted, &deletedAt); err != nil {
 			return nil, err
 		}
 		t.Date = date
@@ -87,7 +102,7 @@ func (r *transactionRepo) GetByClientIDs(ctx context.Context, clientIDs []string
 	return transactions, rows.Err()
 }
 
-func (r *transactionRepo) GetGroupedByCategoryAndDate(ctx context.Context, clientIDs []string, startDate, endDate time.Time) (map[string]map[string]float64, error) {
+func (r *transactionRepo) GetGroupedByCategoryAndDate(ctx context.Context, clientIDs []string, startDate, endDate time.Time, transactionTypes []string) (map[string]map[string]float64, error) {
 	if len(clientIDs) == 0 {
 		return make(map[string]map[string]float64), nil
 	}
@@ -100,11 +115,12 @@ func (r *transactionRepo) GetGroupedByCategoryAndDate(ctx context.Context, clien
 		FROM transactions t
 		JOIN assets a ON t.asset_id = a.id
 		JOIN asset_categories ac ON a.category_id = ac.id
-		WHERE t.client_id = ANY($1) AND t.date BETWEEN $2 AND $3
+		WHERE t.client_id = ANY($1) AND t.date BETWEEN $2 AND $3 AND t.deleted = FALSE
+			AND ($4::text[] IS NULL OR t.transaction_type = ANY($4))
 		GROUP BY ac.name, DATE(t.date)
 		ORDER BY ac.name, DATE(t.date)`
 
-	rows, err := r.db.Query(ctx, query, clientIDs, startDate, endDate)
+	rows, err := r.db.Query(ctx, query, clientIDs, startDate, endDate, transactionTypesFilter(transactionTypes))
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +144,49 @@ func (r *transactionRepo) GetGroupedByCategoryAndDate(ctx context.Context, clien
 	return result, rows.Err()
 }
 
-func (r *transactionRepo) GetTotalByDate(ctx context.Context, clientIDs []string, startDate, endDate time.Time) (map[string]float64, error) {
+// GetGroupedByTypeAndDate returns the total value of the client transactions by transaction type and date.
+// Transactions without a type are grouped under an empty type.
+func (r *transactionRepo) GetGroupedByTypeAndDate(ctx context.Context, clientIDs []string, startDate, endDate time.Time, transactionTypes []string) (map[string]map[string]float64, error) {
+	if len(clientIDs) == 0 {
+		return make(map[string]map[string]float64), nil
+	}
+
+	query := `
+		SELECT
+			t.transaction_type,
+			DATE(t.date) as date,
+			SUM(t.total_value) as total_value
+		FROM transactions t
+		WHERE t.client_id = ANY($1) AND t.date BETWEEN $2 AND $3 AND t.deleted = FALSE
+			AND ($4::text[] IS NULL OR t.transaction_type = ANY($4))
+		GROUP BY t.transaction_type, DATE(t.date)
+		ORDER BY t.transaction_type, DATE(t.date)`
+
+	rows, err := r.db.Query(ctx, query, clientIDs, startDate, endDate, transactionTypesFilter(transactionTypes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]map[string]float64)
+	for rows.Next() {
+		var transactionType string
+		var date time.Time
+		var totalValue float64
+		if err := rows.Scan(&transactionType, &date, &totalValue); err != nil {
+			return nil, err
+		}
+
+		dateStr := date.Format("2006-01-02")
+		if result[transactionType] == nil {
+			result[transactionType] = make(map[string]float64)
+		}
+		result[transactionType][dateStr] = totalValue
+	}
+	return result, rows.Err()
+}
+
+func (r *transactionRepo) GetTotalByDate(ctx context.Context, clientIDs []string, startDate, endDate time.Time, transactionTypes []string) (map[string]float64, error) {
 	if len(clientIDs) == 0 {
 		return make(map[string]float64), nil
 	}
@@ -138,11 +196,12 @@ func (r *transactionRepo) GetTotalByDate(ctx context.Context, clientIDs []string
 			DATE(t.date) as date,
 			SUM(t.total_value) as total_value
 		FROM transactions t
-		WHERE t.client_id = ANY($1) AND t.date BETWEEN $2 AND $3
+		WHERE t.client_id = ANY($1) AND t.date BETWEEN $2 AND $3 AND t.deleted = FALSE
+			AND ($4::text[] IS NULL OR t.transaction_type = ANY($4))
 		GROUP BY DATE(t.date)
 		ORDER BY DATE(t.date)`
 
-	rows, err := r.db.Query(ctx, query, clientIDs, startDate, endDate)
+	rows, err := r.db.Query(ctx, query, clientIDs, startDate, endDate, transactionTypesFilter(transactionTypes))
 	if err != nil {
 		return nil, err
 	}
@@ -162,10 +221,44 @@ func (r *transactionRepo) GetTotalByDate(ctx context.Context, clientIDs []string
 	return result, rows.Err()
 }
 
+// GetByAssetID returns the client transactions of the asset, oldest first
+func (r *transactionRepo) GetByAssetID(ctx context.Context, clientID string, assetID int) ([]models.Transaction, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, client_id, source, asset_id, transaction_type, units, price_per_unit, gross_value, exchange_fees, market_fees, total_value, date, created_at, deleted, deleted_at
+		FROM transactions
+		WHERE client_id = $1 AND asset_id = $2 AND deleted = FALSE
+		ORDER BY date, transaction_type`,
+		clientID, assetID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []models.Transaction{}
+	for rows.Next() {
+		var t models.Transaction
+		if err := rows.Scan(&t.ID, &t.ClientID, &t.Source, &t.AssetID, &t.TransactionType, &t.Units, &t.PricePerUnit, &t.GrossValue, &t.ExchangeFees, &t.MarketFees, &t.TotalValue, &t.Date, &t.CreatedAt, &t.Deleted, &t.DeletedAt); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, rows.Err()
+}
+
 func (r *transactionRepo) Create(ctx context.Context, t *models.Transaction, tx pgx.Tx) error {
 	query := `
-		INSERT INTO transactions (client_id, asset_id, transaction_type, units, price_per_unit, total_value, date)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO transactions (client_id, source, asset_id, transaction_type, units, price_per_unit, gross_value, exchange_fees, market_fees, total_value, date)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (client_id, source, asset_id, date, transaction_type) DO UPDATE SET
+			units = EXCLUDED.units,
+			price_per_unit = EXCLUDED.price_per_unit,
+			gross_value = EXCLUDED.gross_value,
+			exchange_fees = EXCLUDED.exchange_fees,
+			market_fees = EXCLUDED.market_fees,
+			total_value = EXCLUDED.total_value,
+			deleted = FALSE,
+			deleted_at = NULL
 		RETURNING id`
 
 	var err error
@@ -182,7 +275,7 @@ func (r *transactionRepo) Create(ctx context.Context, t *models.Transaction, tx
 		}()
 
 		err = tx.QueryRow(ctx, query,
-			t.ClientID, t.AssetID, t.TransactionType, t.Units, t.PricePerUnit, t.TotalValue, t.Date,
+			t.ClientID, t.Source, t.AssetID, t.TransactionType, t.Units, t.PricePerUnit, t.GrossValue, t.ExchangeFees, t.MarketFees, t.TotalValue, t.Date,
 		).Scan(&t.ID)
 
 		if err != nil {
@@ -194,6 +287,38 @@ func (r *transactionRepo) Create(ctx context.Context, t *models.Transaction, tx
 
 	// Use the provided transaction
 	return tx.QueryRow(ctx, query,
-		t.ClientID, t.AssetID, t.TransactionType, t.Units, t.PricePerUnit, t.TotalValue, t.Date,
+		t.ClientID, t.Source, t.AssetID, t.TransactionType, t.Units, t.PricePerUnit, t.GrossValue, t.ExchangeFees, t.MarketFees, t.TotalValue, t.Date,
 	).Scan(&t.ID)
 }
+
+// SoftDeleteByClientID flags the client transactions synced from source between startDate and endDate (inclusive) as deleted
+func (r *transactionRepo) SoftDeleteByClientID(ctx context.Context, clientID, source string, startDate, endDate time.Time, tx pgx.Tx) error {
+	query := `
+		UPDATE transactions
+		SET deleted = TRUE, deleted_at = $5
+		WHERE client_id = $1 AND source = $2 AND date BETWEEN $3 AND $4 AND deleted = FALSE`
+
+	var err error
+	if tx != nil {
+		_, err = tx.Exec(ctx, query, clientID, source, startDate, endDate, time.Now())
+	} else {
+		_, err = r.db.Exec(ctx, query, clientID, source, startDate, endDate, time.Now())
+	}
+	return err
+}
+
+// SoftDeleteByAssetID flags the client transactions of the asset as deleted, only the ones of date when given
+func (r *transactionRepo) SoftDeleteByAssetID(ctx context.Context, clientID string, assetID int, date *time.Time, tx pgx.Tx) error {
+	query := `
+		UPDATE transactions
+		SET deleted = TRUE, deleted_at = $4
+		WHERE client_id = $1 AND asset_id = $2 AND ($3::date IS NULL OR date = $3) AND deleted = FALSE`
+
+	var err error
+	if tx != nil {
+		_, err = tx.Exec(ctx, query, clientID, assetID, date, time.Now())
+	} else {
+		_, err = r.db.Exec(ctx, query, clientID, assetID, date, time.Now())
+	}
+	return err
+}
@@ -54,19 +54,33 @@ type Holding struct {
 	Date          *time.Time
 }
 
+// Transaction is a movement of an asset. Type is one of the models.TransactionType* values,
+// empty when the movement could not be classified.
+// Value is the net amount of the movement, GrossValue the amount before ExchangeFees and MarketFees.
+// Execution details are only known for boletos and are zero for every other movement.
 type Transaction struct {
 	Currency     string
 	CurrencySign string
+	Type         string
 	Value        float64
 	Units        float64
+	PricePerUnit float64
+	GrossValue   float64
+	ExchangeFees float64
+	MarketFees   float64
 	Date         *time.Time
 }
 
+// Asset is an instrument of an account state. CategoryKey is the ESCO denomination its Category was
+// resolved from, empty for assets built from stored data. Manual is set for the assets the client
+// tracks by hand outside of the brokers.
 type Asset struct {
 	ID           string
 	Type         string
 	Denomination string
 	Category     string
+	CategoryKey  string `json:"-"`
+	Manual       bool
 	Holdings     []Holding
 	Transactions []Transaction
 }
@@ -80,18 +94,107 @@ type TotalHoldingsAndTransactionsByDate struct {
 	TotalTransactionsByDate *map[string]Transaction
 }
 
+// AccountStateByCategory is the account state grouped by asset category.
+// TransactionsByType holds the total transactions of every transaction type by date.
 type AccountStateByCategory struct {
 	AssetsByCategory        *map[string][]Asset
 	CategoryAssets          *map[string]Asset
 	TotalHoldingsByDate     *map[string]Holding
 	TotalTransactionsByDate *map[string]Transaction
+	TransactionsByType      *map[string][]Transaction
 }
 
 // SyncRequest represents a request to sync account data
+// EndDate is exclusive, except when it equals StartDate, which syncs that single day.
+// Force re-syncs dates already synced, replacing the stored data with a fresh ESCO copy.
 type SyncRequest struct {
 	AccountID string `json:"accountID"`
 	StartDate Date   `json:"startDate"`
 	EndDate   Date   `json:"endDate"`
+	Force     bool   `json:"force"`
+	Source    string `json:"source"`
+}
+
+// BulkSyncRequest represents a request to sync several accounts at once.
+// Accounts can be listed explicitly or resolved from an ESCO BuscarCuentas filter.
+// The date range is read as in SyncRequest.
+type BulkSyncRequest struct {
+	AccountIDs []string `json:"accountIDs"`
+	Filter     string   `json:"filter"`
+	StartDate  Date     `json:"startDate"`
+	EndDate    Date     `json:"endDate"`
+	Force      bool     `json:"force"`
+	Source     string   `json:"source"`
+}
+
+// BulkSyncResult represents the sync job queued for an account, or why it could not be queued
+type BulkSyncResult struct {
+	AccountID string `json:"accountID"`
+	JobID     int    `json:"jobID,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BulkSyncResponse represents the per-account outcome of a bulk sync.
+// The outcome of the sync of each queued account is polled through the status of its job.
+type BulkSyncResponse struct {
+	Total   int              `json:"total"`
+	Queued  int              `json:"queued"`
+	Failed  int              `json:"failed"`
+	Results []BulkSyncResult `json:"results"`
+}
+
+// SyncJobResponse represents the status of an asynchronous sync job
+type SyncJobResponse struct {
+	ID             int        `json:"id"`
+	AccountID      string     `json:"accountID"`
+	StartDate      Date       `json:"startDate"`
+	EndDate        Date       `json:"endDate"`
+	Status         string     `json:"status"`
+	Force          bool       `json:"force"`
+	Source         string     `json:"source,omitempty"`
+	TotalDates     int        `json:"totalDates"`
+	ProcessedDates int        `json:"processedDates"`
+	Error          string     `json:"error,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	StartedAt      *time.Time `json:"startedAt,omitempty"`
+	FinishedAt     *time.Time `json:"finishedAt,omitempty"`
+}
+
+// SyncRunResponse represents a sync run recorded in the sync run history
+type SyncRunResponse struct {
+	ID                int        `json:"id"`
+	AccountID         string     `json:"accountID"`
+	StartDate         Date       `json:"startDate"`
+	EndDate           Date       `json:"endDate"`
+	TriggeredBy       string     `json:"triggeredBy"`
+	Status            string     `json:"status"`
+	DatesSynced       int        `json:"datesSynced"`
+	HoldingsCount     int        `json:"holdingsCount"`
+	TransactionsCount int        `json:"transactionsCount"`
+	Error             string     `json:"error,omitempty"`
+	StartedAt         time.Time  `json:"startedAt"`
+	FinishedAt        *time.Time `json:"finishedAt,omitempty"`
+}
+
+// ESCOImportResponse represents the outcome of importing a raw ESCO export
+type ESCOImportResponse struct {
+	AccountID    string `json:"accountID"`
+	Type         string `json:"type"`
+	Assets       int    `json:"assets"`
+	Holdings     int    `json:"holdings"`
+	Transactions int    `json:"transactions"`
+	Dates        []Date `json:"dates"`
+}
+
+// ESCOReplayResponse represents the outcome of replaying archived ESCO payloads through the parsers
+type ESCOReplayResponse struct {
+	AccountID    string `json:"accountID"`
+	Payloads     int    `json:"payloads"`
+	Assets       int    `json:"assets"`
+	Holdings     int    `json:"holdings"`
+	Transactions int    `json:"transactions"`
+	Dates        []Date `json:"dates"`
+	Stored       bool   `json:"stored"`
 }
 
 func NewAccountState() *AccountState {
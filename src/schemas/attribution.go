@@ -0,0 +1,66 @@
+package schemas
+
+import "time"
+
+// TargetAllocationCategory is the target weight of a category, as a percentage. Benchmark is the BCRA
+// variable or stored series measuring the target return of the category, read as BenchmarkKind (see
+// BenchmarkRequest), and the return of the category in the portfolio is used when it is empty.
+type TargetAllocationCategory struct {
+	Category      string  `json:"category"`
+	Weight        float64 `json:"weight"`
+	Benchmark     string  `json:"benchmark,omitempty"`
+	BenchmarkKind string  `json:"benchmarkKind,omitempty"`
+}
+
+// TargetAllocationRequest represents the target weights of the categories, which must add up to 100
+type TargetAllocationRequest struct {
+	Categories []TargetAllocationCategory `json:"categories"`
+}
+
+type TargetAllocationResponse struct {
+	Name       string                     `json:"name"`
+	Categories []TargetAllocationCategory `json:"categories"`
+}
+
+// ContributionByDate is the part of the total return of an interval an asset or category accounts for.
+// Weight is its share of the portfolio at the start of the interval, as a fraction, and Contribution its
+// return weighted by it, in percentage points.
+type ContributionByDate struct {
+	StartDate        time.Time
+	EndDate          time.Time
+	Weight           float64
+	ReturnPercentage float64
+	Contribution     float64
+}
+
+// CategoryAttribution splits the active return of a category, the difference between the portfolio and
+// the target allocation, into the effects of allocation (weighting the category differently than the
+// target), selection (its return differing from the target one) and their interaction, in percentage
+// points. Weights are fractions and returns percentages.
+type CategoryAttribution struct {
+	Category        string
+	PortfolioWeight float64
+	TargetWeight    float64
+	PortfolioReturn float64
+	TargetReturn    float64
+	Allocation      float64
+	Selection       float64
+	Interaction     float64
+}
+
+// AttributionPeriod is the attribution of the total return of an interval of the report
+type AttributionPeriod struct {
+	StartDate       time.Time
+	EndDate         time.Time
+	PortfolioReturn float64
+	TargetReturn    float64
+	Categories      []CategoryAttribution
+}
+
+// Attribution is the Brinson attribution of the returns of a report against the target allocation
+// TargetAllocation. Categories add up the effects of every period, with the average weights.
+type Attribution struct {
+	TargetAllocation string
+	Periods          []AttributionPeriod
+	Categories       []CategoryAttribution
+}
@@ -0,0 +1,50 @@
+package schemas
+
+// BenchmarkRequest names a series a report is compared against. ID is either a BCRA variable ID or the
+// name of a stored custom series, and Kind tells how its values are read: level (a price or an index,
+// the default), monthly (monthly rates as percentages, like inflation) or annual (nominal annual rates
+// as percentages, like the rate of fixed-term deposits).
+type BenchmarkRequest struct {
+	ID   string
+	Kind string
+}
+
+// BenchmarkSeries holds the values of a benchmark
+type BenchmarkSeries struct {
+	ID         string
+	Name       string
+	Kind       string
+	Valuations []VariableValuation
+}
+
+// BenchmarkComparison compares the time-weighted returns of a report with a benchmark over the same
+// intervals. Returns are the ones of the benchmark in every interval and CumulativeReturns the ones
+// since the start of the report. ExcessReturns are the report returns minus the benchmark ones, in
+// percentage points, and CumulativeExcessReturns the same for the cumulative returns. TrackingError is
+// the standard deviation of the excess returns and HitRatio the percentage of intervals the report beat
+// the benchmark.
+type BenchmarkComparison struct {
+	ID                      string
+	Name                    string
+	Kind                    string
+	Returns                 []ReturnByDate
+	CumulativeReturns       []ReturnByDate
+	ExcessReturns           []ReturnByDate
+	CumulativeExcessReturns []ReturnByDate
+	CumulativeReturn        float64
+	CumulativeExcessReturn  float64
+	TrackingError           float64
+	HitRatio                float64
+}
+
+// BenchmarkValueRequest represents the value of a custom series on a date
+type BenchmarkValueRequest struct {
+	Date  Date    `json:"date"`
+	Value float64 `json:"value"`
+}
+
+type BenchmarkValueResponse struct {
+	Name  string  `json:"name"`
+	Date  Date    `json:"date"`
+	Value float64 `json:"value"`
+}
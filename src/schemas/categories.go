@@ -0,0 +1,50 @@
+package schemas
+
+import "time"
+
+// CategoryRequest represents the request to create or update an asset category
+type CategoryRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type CategoryResponse struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// CategoryRuleRequest represents the request to create or update a category rule.
+// MatchType is one of exact, prefix or regex.
+type CategoryRuleRequest struct {
+	CategoryID int    `json:"categoryID"`
+	MatchType  string `json:"matchType"`
+	Pattern    string `json:"pattern"`
+}
+
+type CategoryRuleResponse struct {
+	ID         int       `json:"id"`
+	CategoryID int       `json:"categoryID"`
+	Category   string    `json:"category"`
+	MatchType  string    `json:"matchType"`
+	Pattern    string    `json:"pattern"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// AssetCategoryRequest represents the request to assign the category of an asset by hand.
+// A null categoryID returns the asset to the category resolved by the rules.
+type AssetCategoryRequest struct {
+	CategoryID *int `json:"categoryID"`
+}
+
+// AssetCategoryResponse represents an asset with its category and the denomination it is resolved from
+type AssetCategoryResponse struct {
+	ID             int    `json:"id"`
+	ExternalID     string `json:"externalID"`
+	Name           string `json:"name"`
+	AssetType      string `json:"assetType"`
+	CategoryKey    string `json:"categoryKey"`
+	CategoryID     int    `json:"categoryID"`
+	Category       string `json:"category"`
+	CategoryLocked bool   `json:"categoryLocked"`
+}
@@ -40,3 +40,15 @@ type VariableValuation struct {
 	Date  string
 	Value float64
 }
+
+// ExchangeRateRequest represents the pesos a dollar is worth on a date
+type ExchangeRateRequest struct {
+	Date Date    `json:"date"`
+	Rate float64 `json:"rate"`
+}
+
+type ExchangeRateResponse struct {
+	Currency string  `json:"currency"`
+	Date     Date    `json:"date"`
+	Rate     float64 `json:"rate"`
+}
@@ -0,0 +1,56 @@
+package schemas
+
+// ManualAssetRequest represents the request to create or update an asset a client tracks by hand,
+// such as real estate, crypto or a foreign bank deposit. A null categoryID leaves it unclassified.
+// Manual assets are valued in pesos, so they have no currency of their own.
+type ManualAssetRequest struct {
+	Name       string `json:"name"`
+	AssetType  string `json:"assetType"`
+	CategoryID *int   `json:"categoryID"`
+}
+
+// ManualAssetResponse represents a manual asset with its valuations and cash flows, oldest first
+type ManualAssetResponse struct {
+	ID         int                       `json:"id"`
+	ClientID   string                    `json:"clientID"`
+	ExternalID string                    `json:"externalID"`
+	Name       string                    `json:"name"`
+	AssetType  string                    `json:"assetType"`
+	CategoryID int                       `json:"categoryID"`
+	Category   string                    `json:"category"`
+	Manual     bool                      `json:"manual"`
+	Valuations []ManualValuationResponse `json:"valuations"`
+	CashFlows  []ManualCashFlowResponse  `json:"cashFlows"`
+}
+
+// ManualValuationRequest represents the value of a manual asset on a date. Units are whatever the asset
+// is counted in, e.g. dollars deposited or coins held, and Value is expressed in pesos. The valuation
+// holds until the next one, so a zero valuation records the asset was sold.
+type ManualValuationRequest struct {
+	Date  Date    `json:"date"`
+	Units float64 `json:"units"`
+	Value float64 `json:"value"`
+}
+
+type ManualValuationResponse struct {
+	Date  Date    `json:"date"`
+	Units float64 `json:"units"`
+	Value float64 `json:"value"`
+}
+
+// ManualCashFlowRequest represents money put into (positive amount) or taken out of (negative amount)
+// a manual asset, in pesos. Type is one of the transaction types, transfer when empty.
+// A cash flow replaces the one of the same date and type.
+type ManualCashFlowRequest struct {
+	Date   Date    `json:"date"`
+	Type   string  `json:"type"`
+	Units  float64 `json:"units"`
+	Amount float64 `json:"amount"`
+}
+
+type ManualCashFlowResponse struct {
+	Date   Date    `json:"date"`
+	Type   string  `json:"type"`
+	Units  float64 `json:"units"`
+	Amount float64 `json:"amount"`
+}
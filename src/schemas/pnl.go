@@ -0,0 +1,61 @@
+package schemas
+
+// CostLot represents the units of a purchase still held, with what they cost per unit
+type CostLot struct {
+	Date     Date    `json:"date"`
+	Units    float64 `json:"units"`
+	UnitCost float64 `json:"unitCost"`
+	Cost     float64 `json:"cost"`
+}
+
+// RealizedSale represents a sale and the profit or loss it realized against the cost of the units sold.
+// UnmatchedUnits are the units sold beyond the ones bought, which have no cost and realize nothing.
+type RealizedSale struct {
+	Date           Date    `json:"date"`
+	Units          float64 `json:"units"`
+	Proceeds       float64 `json:"proceeds"`
+	Cost           float64 `json:"cost"`
+	RealizedPnL    float64 `json:"realizedPnL"`
+	UnmatchedUnits float64 `json:"unmatchedUnits,omitempty"`
+}
+
+// AssetPnL represents the cost basis of an asset held by an account, with the profit or loss realized by
+// its sales and the one of the units still held against their latest value.
+// MarketValue is only known when the asset has holdings, ValuationDate being the date of the last one.
+type AssetPnL struct {
+	AccountID               string         `json:"accountID"`
+	AssetID                 int            `json:"assetID"`
+	ExternalID              string         `json:"externalID"`
+	AssetName               string         `json:"assetName"`
+	Currency                string         `json:"currency"`
+	Units                   float64        `json:"units"`
+	AverageCost             float64        `json:"averageCost"`
+	CostBasis               float64        `json:"costBasis"`
+	MarketValue             float64        `json:"marketValue"`
+	ValuationDate           *Date          `json:"valuationDate,omitempty"`
+	UnrealizedPnL           float64        `json:"unrealizedPnL"`
+	UnrealizedPnLPercentage float64        `json:"unrealizedPnLPercentage"`
+	RealizedPnL             float64        `json:"realizedPnL"`
+	OpenLots                []CostLot      `json:"openLots"`
+	Sales                   []RealizedSale `json:"sales"`
+}
+
+// PnLTotal adds up the positions of the assets of a currency
+type PnLTotal struct {
+	Currency      string  `json:"currency"`
+	CostBasis     float64 `json:"costBasis"`
+	MarketValue   float64 `json:"marketValue"`
+	UnrealizedPnL float64 `json:"unrealizedPnL"`
+	RealizedPnL   float64 `json:"realizedPnL"`
+}
+
+// PnLReport represents the cost basis and profit or loss of the assets of the accounts up to Date,
+// measured with the FIFO or weighted-average Method. Amounts are in the currency of each asset, so they
+// are only added up by currency.
+type PnLReport struct {
+	AccountIDs []string   `json:"accountIDs"`
+	Date       Date       `json:"date"`
+	Method     string     `json:"method"`
+	Assets     []AssetPnL `json:"assets"`
+	Totals     []PnLTotal `json:"totals"`
+}
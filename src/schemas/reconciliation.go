@@ -0,0 +1,26 @@
+package schemas
+
+// AssetDiscrepancy represents an asset whose stored holdings do not match the previous
+// holdings plus the transactions stored between both dates
+type AssetDiscrepancy struct {
+	AssetID          int     `json:"assetID"`
+	ExternalID       string  `json:"externalID"`
+	AssetName        string  `json:"assetName"`
+	PreviousDate     Date    `json:"previousDate"`
+	Date             Date    `json:"date"`
+	PreviousUnits    float64 `json:"previousUnits"`
+	TransactionUnits float64 `json:"transactionUnits"`
+	ExpectedUnits    float64 `json:"expectedUnits"`
+	ActualUnits      float64 `json:"actualUnits"`
+	Difference       float64 `json:"difference"`
+}
+
+// ReconciliationReport represents the result of reconciling the holdings and transactions of a client
+type ReconciliationReport struct {
+	AccountID     string             `json:"accountID"`
+	StartDate     Date               `json:"startDate"`
+	EndDate       Date               `json:"endDate"`
+	Tolerance     float64            `json:"tolerance"`
+	DatesChecked  int                `json:"datesChecked"`
+	Discrepancies []AssetDiscrepancy `json:"discrepancies"`
+}
@@ -6,23 +6,76 @@ import (
 	"github.com/go-gota/gota/dataframe"
 )
 
+// AccountsReports is the report of a group of accounts. TotalReturns are the time-weighted returns of every
+// interval and TotalMoneyWeightedReturns the money-weighted ones, and the real ones are both deflated by
+// inflation. ReturnMethod is the method the report files chart the returns with, either twr or mwr, and
+// Currency the one every value is expressed in. Benchmarks compare the returns with the series requested.
+// Risk is measured on TotalDailyReturns, the daily returns of the whole period, with the rate of
+// RiskFreeRateID as the risk-free rate. Attribution splits the returns by category against a target allocation.
 type AccountsReports struct {
-	AssetsByCategory       *map[string][]Asset
-	AssetsReturnByCategory *map[string][]AssetReturn
-	CategoryAssets         *map[string]Asset
-	CategoryAssetsReturn   *map[string]AssetReturn
-	ReferenceVariables     *map[string]*VariableWithValuationResponse
-	TotalHoldingsByDate    []Holding
-	TotalReturns           []ReturnByDate
-	FinalIntervalReturn    float64
+	AssetsByCategory              *map[string][]Asset
+	AssetsReturnByCategory        *map[string][]AssetReturn
+	CategoryAssets                *map[string]Asset
+	CategoryAssetsReturn          *map[string]AssetReturn
+	ReferenceVariables            *map[string]*VariableWithValuationResponse
+	TotalHoldingsByDate           []Holding
+	TotalReturns                  []ReturnByDate
+	TotalMoneyWeightedReturns     []ReturnByDate
+	TotalRealReturns              []ReturnByDate
+	TotalRealMoneyWeightedReturns []ReturnByDate
+	TotalDailyReturns             []ReturnByDate `json:"-"`
+	FinalIntervalReturn           float64
+	ReturnMethod                  string
+	Currency                      string
+	TimeWeightedReturn            float64
+	MoneyWeightedReturn           float64
+	AnnualizedMoneyWeightedReturn float64
+	RealReturn                    float64
+	Benchmarks                    []BenchmarkComparison
+	Risk                          RiskMetrics
+	RiskFreeRateID                string
+	Attribution                   *Attribution
+	TransactionsByType            *map[string][]Transaction
 }
 
+// AssetReturn holds the returns of an asset or category, and its returns deflated by inflation.
+// TimeWeightedReturn, MoneyWeightedReturn and RealReturn are the returns of the whole period as
+// percentages, and AnnualizedMoneyWeightedReturn the XIRR of its cash flows. DailyReturns are the
+// returns of the days it was held, which Risk is measured on. ContributionsByDateRange are the parts of
+// the total return of every interval it accounts for, and Contribution the part of the time-weighted
+// return of the report.
 type AssetReturn struct {
-	ID                 string
-	Type               string
-	Denomination       string
-	Category           string
-	ReturnsByDateRange []ReturnByDate
+	ID                            string
+	Type                          string
+	Denomination                  string
+	Category                      string
+	Manual                        bool
+	ReturnsByDateRange            []ReturnByDate
+	RealReturnsByDateRange        []ReturnByDate
+	DailyReturns                  []ReturnByDate `json:"-"`
+	TimeWeightedReturn            float64
+	MoneyWeightedReturn           float64
+	AnnualizedMoneyWeightedReturn float64
+	RealReturn                    float64
+	Risk                          RiskMetrics
+	ContributionsByDateRange      []ContributionByDate
+	Contribution                  float64
+}
+
+// RiskMetrics measures the risk of a series of daily returns. Volatility is the annualized standard
+// deviation of the returns and MaxDrawdown the largest fall from a peak, as percentages, between the
+// dates of the peak and the trough. SharpeRatio and SortinoRatio are the annualized returns over the
+// risk-free rate divided by the volatility and the downside deviation. BestPeriod and WorstPeriod are
+// the intervals of the report with the highest and lowest return.
+type RiskMetrics struct {
+	Volatility        float64
+	MaxDrawdown       float64
+	MaxDrawdownPeak   *time.Time
+	MaxDrawdownTrough *time.Time
+	SharpeRatio       float64
+	SortinoRatio      float64
+	BestPeriod        *ReturnByDate
+	WorstPeriod       *ReturnByDate
 }
 
 type ReturnByDate struct {
@@ -69,4 +122,10 @@ type ReportDataframes struct {
 	ReferenceVariablesDF *dataframe.DataFrame
 	CategoryDF           *dataframe.DataFrame
 	CategoryPercentageDF *dataframe.DataFrame
+	ReturnSummaryDF      *dataframe.DataFrame
+	BenchmarkDF          *dataframe.DataFrame
+	BenchmarkSummaryDF   *dataframe.DataFrame
+	RiskDF               *dataframe.DataFrame
+	ContributionDF       *dataframe.DataFrame
+	AttributionDF        *dataframe.DataFrame
 }
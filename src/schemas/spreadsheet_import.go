@@ -0,0 +1,45 @@
+package schemas
+
+// SpreadsheetMapping describes how the columns of a CSV or XLSX file map to holdings or transactions.
+// Columns maps each field (date, assetID, assetName, assetType, category, units, value, price, type,
+// exchangeFees, marketFees) to the header of the column holding it.
+type SpreadsheetMapping struct {
+	// Kind is either holdings or transactions
+	Kind string `json:"kind"`
+	// Sheet is the XLSX sheet to read, the first one when empty
+	Sheet string `json:"sheet"`
+	// HeaderRow is the 1-based row holding the column headers, the first one when zero
+	HeaderRow int `json:"headerRow"`
+	// Delimiter separates the CSV fields, a comma when empty
+	Delimiter string `json:"delimiter"`
+	// DateFormat is the Go layout of the dates, YYYY-MM-DD when empty
+	DateFormat string `json:"dateFormat"`
+	// DecimalSeparator is the separator of the decimals of numbers, a dot when empty
+	DecimalSeparator string            `json:"decimalSeparator"`
+	Columns          map[string]string `json:"columns"`
+	// TransactionTypes maps the values of the type column to transaction types, e.g. "Compra" to buy
+	TransactionTypes map[string]string `json:"transactionTypes"`
+}
+
+// SpreadsheetRowError represents a row of the file that could not be imported
+type SpreadsheetRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// SpreadsheetImportResponse represents the outcome of importing a CSV or XLSX file.
+// Rows with errors are skipped and the valid ones are stored, unless it was a dry run.
+type SpreadsheetImportResponse struct {
+	AccountID    string                `json:"accountID"`
+	Kind         string                `json:"kind"`
+	Source       string                `json:"source"`
+	DryRun       bool                  `json:"dryRun"`
+	Rows         int                   `json:"rows"`
+	ImportedRows int                   `json:"importedRows"`
+	Assets       int                   `json:"assets"`
+	Holdings     int                   `json:"holdings"`
+	Transactions int                   `json:"transactions"`
+	Dates        []Date                `json:"dates"`
+	Errors       []SpreadsheetRowError `json:"errors"`
+}
@@ -2,17 +2,19 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"server/src/models"
 	"server/src/repositories"
 	"server/src/schemas"
+	"server/src/utils"
 	"sort"
 	"time"
 )
 
 type AccountServiceI interface {
 	GetAccountState(ctx context.Context, clientID string, date time.Time) (*schemas.AccountState, error)
-	GetMultiAccountStateWithTransactions(ctx context.Context, clientIDs []string, startDate, endDate time.Time, interval time.Duration) ([]*schemas.AccountState, error)
-	GetMultiAccountStateByCategory(ctx context.Context, clientIDs []string, startDate, endDate time.Time, interval time.Duration) (*schemas.AccountStateByCategory, error)
+	GetMultiAccountStateWithTransactions(ctx context.Context, clientIDs []string, startDate, endDate time.Time, interval time.Duration, transactionTypes []string) ([]*schemas.AccountState, error)
+	GetMultiAccountStateByCategory(ctx context.Context, clientIDs []string, startDate, endDate time.Time, interval time.Duration, transactionTypes []string, converter *CurrencyConverter) (*schemas.AccountStateByCategory, error)
 }
 
 type AccountService struct {
@@ -40,6 +42,11 @@ func (s *AccountService) GetAccountState(ctx context.Context, clientID string, d
 	if err != nil {
 		return nil, err
 	}
+	carriedHoldings, err := s.carryManualValuations(ctx, []string{clientID}, holdings, date, date)
+	if err != nil {
+		return nil, err
+	}
+	holdings = append(holdings, carriedHoldings...)
 
 	// Get transactions for the specific date
 	transactions, err := s.transactionRepo.GetByClientID(ctx, clientID, date, date)
@@ -50,18 +57,32 @@ func (s *AccountService) GetAccountState(ctx context.Context, clientID string, d
 	return s.buildAccountState(ctx, holdings, transactions)
 }
 
-// GetMultiAccountStateWithTransactions returns account states for multiple clients
-func (s *AccountService) GetMultiAccountStateWithTransactions(ctx context.Context, clientIDs []string, startDate, endDate time.Time, interval time.Duration) ([]*schemas.AccountState, error) {
+// GetMultiAccountStateWithTransactions returns account states for multiple clients.
+// When transactionTypes are given only transactions of those types are included.
+func (s *AccountService) GetMultiAccountStateWithTransactions(ctx context.Context, clientIDs []string, startDate, endDate time.Time, interval time.Duration, transactionTypes []string) ([]*schemas.AccountState, error) {
+	accountStates, _, err := s.getMultiAccountStates(ctx, clientIDs, startDate, endDate, transactionTypes)
+	return accountStates, err
+}
+
+// getMultiAccountStates returns the account states of the clients along with the holdings carried
+// from the last valuation of their manual assets, which are not stored
+func (s *AccountService) getMultiAccountStates(ctx context.Context, clientIDs []string, startDate, endDate time.Time, transactionTypes []string) ([]*schemas.AccountState, []models.Holding, error) {
 	// Get all holdings for the client IDs
 	holdings, err := s.holdingRepo.GetByClientIDs(ctx, clientIDs, startDate, endDate)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	carriedHoldings, err := s.carryManualValuations(ctx, clientIDs, holdings, startDate, endDate)
+	if err != nil {
+		return nil, nil, err
+	}
+	holdings = append(holdings, carriedHoldings...)
+
 	// Get all transactions for the client IDs
-	transactions, err := s.transactionRepo.GetByClientIDs(ctx, clientIDs, startDate, endDate)
+	transactions, err := s.transactionRepo.GetByClientIDs(ctx, clientIDs, startDate, endDate, transactionTypes)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Group by client ID
@@ -76,23 +97,85 @@ func (s *AccountService) GetMultiAccountStateWithTransactions(ctx context.Contex
 
 		accountState, err := s.buildAccountState(ctx, clientHoldings, clientTransactions)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		accountStates = append(accountStates, accountState)
 	}
 
-	return accountStates, nil
+	return accountStates, carriedHoldings, nil
 }
 
-// GetMultiAccountStateByCategory returns account states grouped by category for multiple clients
-func (s *AccountService) GetMultiAccountStateByCategory(ctx context.Context, clientIDs []string, startDate, endDate time.Time, interval time.Duration) (*schemas.AccountStateByCategory, error) {
+// carryManualValuations returns a holding for every day between startDate and endDate (up to today)
+// a manual asset has no valuation, with the value of its last one. Broker holdings are synced daily
+// while manual assets are only valued when their value changes, and a zero valuation ends them.
+func (s *AccountService) carryManualValuations(ctx context.Context, clientIDs []string, holdings []models.Holding, startDate, endDate time.Time) ([]models.Holding, error) {
+	previousValuations, err := s.holdingRepo.GetLatestBeforeDate(ctx, clientIDs, models.SourceManual, startDate)
+	if err != nil {
+		return nil, err
+	}
+
+	valuationsByAsset := make(map[string][]models.Holding)
+	for _, holding := range append(previousValuations, holdings...) {
+		if holding.Source != models.SourceManual {
+			continue
+		}
+		key := fmt.Sprintf("%s/%d", holding.ClientID, holding.AssetID)
+		valuationsByAsset[key] = append(valuationsByAsset[key], holding)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	lastDate := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, time.UTC)
+	if lastDate.After(today) {
+		lastDate = today
+	}
+	firstDate := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC)
+
+	carriedHoldings := make([]models.Holding, 0)
+	for _, valuations := range valuationsByAsset {
+		sort.Slice(valuations, func(i, j int) bool {
+			return valuations[i].Date.Before(valuations[j].Date)
+		})
+		var current *models.Holding
+		next := 0
+		for date := firstDate; !date.After(lastDate); date = date.AddDate(0, 0, 1) {
+			dateStr := date.Format("2006-01-02")
+			for next < len(valuations) && valuations[next].Date.Format("2006-01-02") <= dateStr {
+				current = &valuations[next]
+				next++
+			}
+			if current == nil || current.Date.Format("2006-01-02") == dateStr || (current.Value == 0 && current.Units == 0) {
+				continue
+			}
+			carriedHolding := *current
+			carriedHolding.ID = 0
+			carriedHolding.Date = date
+			carriedHoldings = append(carriedHoldings, carriedHolding)
+		}
+	}
+	return carriedHoldings, nil
+}
+
+// GetMultiAccountStateByCategory returns account states grouped by category for multiple clients.
+// When transactionTypes are given only transactions of those types are grouped in TransactionsByType,
+// every other total keeps all of them as returns are calculated from them. When a converter is
+// given every holding and transaction is converted to its currency before being added up.
+func (s *AccountService) GetMultiAccountStateByCategory(ctx context.Context, clientIDs []string, startDate, endDate time.Time, interval time.Duration, transactionTypes []string, converter *CurrencyConverter) (*schemas.AccountStateByCategory, error) {
+	if converter != nil {
+		return s.getConvertedMultiAccountStateByCategory(ctx, clientIDs, startDate, endDate, transactionTypes, converter)
+	}
+
 	// Get grouped data from database
 	categoryHoldings, err := s.holdingRepo.GetGroupedByCategoryAndDate(ctx, clientIDs, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
 
-	categoryTransactions, err := s.transactionRepo.GetGroupedByCategoryAndDate(ctx, clientIDs, startDate, endDate)
+	categoryTransactions, err := s.transactionRepo.GetGroupedByCategoryAndDate(ctx, clientIDs, startDate, endDate, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	typeTransactions, err := s.transactionRepo.GetGroupedByTypeAndDate(ctx, clientIDs, startDate, endDate, transactionTypes)
 	if err != nil {
 		return nil, err
 	}
@@ -102,7 +185,7 @@ func (s *AccountService) GetMultiAccountStateByCategory(ctx context.Context, cli
 		return nil, err
 	}
 
-	totalTransactions, err := s.transactionRepo.GetTotalByDate(ctx, clientIDs, startDate, endDate)
+	totalTransactions, err := s.transactionRepo.GetTotalByDate(ctx, clientIDs, startDate, endDate, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -114,18 +197,126 @@ func (s *AccountService) GetMultiAccountStateByCategory(ctx context.Context, cli
 	}
 
 	// Get individual account states for asset details
-	accountStates, err := s.GetMultiAccountStateWithTransactions(ctx, clientIDs, startDate, endDate, interval)
+	accountStates, carriedHoldings, err := s.getMultiAccountStates(ctx, clientIDs, startDate, endDate, nil)
 	if err != nil {
 		return nil, err
 	}
 
+	// The grouped holdings only add up the stored valuations of manual assets, not the carried ones
+	categoryNames := make(map[int]string, len(assetsWithCategories))
+	for _, asset := range assetsWithCategories {
+		categoryNames[asset.ID] = asset.CategoryName
+	}
+	for _, holding := range carriedHoldings {
+		dateStr := holding.Date.Format("2006-01-02")
+		totalHoldings[dateStr] += holding.Value
+		category := categoryNames[holding.AssetID]
+		if category == "" {
+			continue
+		}
+		if categoryHoldings[category] == nil {
+			categoryHoldings[category] = make(map[string]float64)
+		}
+		categoryHoldings[category][dateStr] += holding.Value
+	}
+
 	return s.buildAccountStateByCategory(
 		accountStates,
 		categoryHoldings,
 		categoryTransactions,
+		typeTransactions,
 		totalHoldings,
 		totalTransactions,
 		assetsWithCategories,
+		utils.AssetCurrencyPesos,
+		"$",
+	), nil
+}
+
+// getConvertedMultiAccountStateByCategory returns the account states grouped by category with every holding
+// and transaction converted on its own date. Values of different dates are worth different amounts once
+// converted, so they are added up here instead of by the database.
+func (s *AccountService) getConvertedMultiAccountStateByCategory(ctx context.Context, clientIDs []string, startDate, endDate time.Time, transactionTypes []string, converter *CurrencyConverter) (*schemas.AccountStateByCategory, error) {
+	accountStates, _, err := s.getMultiAccountStates(ctx, clientIDs, startDate, endDate, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	assetsWithCategories, err := s.assetRepo.GetWithCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	assetCategoryMap := make(map[string]string, len(assetsWithCategories))
+	for _, asset := range assetsWithCategories {
+		assetCategoryMap[asset.ExternalID] = asset.CategoryName
+	}
+
+	currencyName := converter.CurrencyName()
+	currencySign := getCurrencySign(currencyName)
+	categoryHoldings := make(map[string]map[string]float64)
+	categoryTransactions := make(map[string]map[string]float64)
+	typeTransactions := make(map[string]map[string]float64)
+	totalHoldings := make(map[string]float64)
+	totalTransactions := make(map[string]float64)
+	addToGroup := func(groups map[string]map[string]float64, key, dateStr string, value float64) {
+		if groups[key] == nil {
+			groups[key] = make(map[string]float64)
+		}
+		groups[key][dateStr] += value
+	}
+
+	for _, accountState := range accountStates {
+		if accountState.Assets == nil {
+			continue
+		}
+		for assetKey, asset := range *accountState.Assets {
+			category := assetCategoryMap[asset.ID]
+			if category == "" {
+				category = "S / C"
+			}
+
+			for i, holding := range asset.Holdings {
+				date := *holding.DateRequested
+				holding.Value = converter.Convert(holding.Value, holding.Currency, date)
+				holding.Currency, holding.CurrencySign = currencyName, currencySign
+				asset.Holdings[i] = holding
+
+				dateStr := date.Format("2006-01-02")
+				totalHoldings[dateStr] += holding.Value
+				addToGroup(categoryHoldings, category, dateStr, holding.Value)
+			}
+
+			for i, transaction := range asset.Transactions {
+				date := *transaction.Date
+				transaction.Value = converter.Convert(transaction.Value, transaction.Currency, date)
+				transaction.PricePerUnit = converter.Convert(transaction.PricePerUnit, transaction.Currency, date)
+				transaction.GrossValue = converter.Convert(transaction.GrossValue, transaction.Currency, date)
+				transaction.ExchangeFees = converter.Convert(transaction.ExchangeFees, transaction.Currency, date)
+				transaction.MarketFees = converter.Convert(transaction.MarketFees, transaction.Currency, date)
+				transaction.Currency, transaction.CurrencySign = currencyName, currencySign
+				asset.Transactions[i] = transaction
+
+				dateStr := date.Format("2006-01-02")
+				totalTransactions[dateStr] += transaction.Value
+				addToGroup(categoryTransactions, category, dateStr, transaction.Value)
+				if includesTransactionType(transactionTypes, transaction.Type) {
+					addToGroup(typeTransactions, transaction.Type, dateStr, transaction.Value)
+				}
+			}
+			(*accountState.Assets)[assetKey] = asset
+		}
+	}
+
+	return s.buildAccountStateByCategory(
+		accountStates,
+		categoryHoldings,
+		categoryTransactions,
+		typeTransactions,
+		totalHoldings,
+		totalTransactions,
+		assetsWithCategories,
+		currencyName,
+		currencySign,
 	), nil
 }
 
@@ -159,7 +350,9 @@ func (s *AccountService) buildAccountState(ctx context.Context, holdings []model
 		assetMap[assetsList[i].ID] = &assetsList[i]
 	}
 
-	// Process holdings
+	// Process holdings. An asset held at several broker sources is reported as a single
+	// holding per date, adding up the units and value held at each one.
+	holdingIndexes := make(map[string]int)
 	for _, holding := range holdings {
 		asset, exists := assetMap[holding.AssetID]
 		if !exists {
@@ -173,15 +366,24 @@ func (s *AccountService) buildAccountState(ctx context.Context, holdings []model
 				Type:         asset.AssetType,
 				Denomination: asset.Name,
 				Category:     "", // Will be populated if we have category info
+				Manual:       asset.IsManual(),
 				Holdings:     []schemas.Holding{},
 				Transactions: []schemas.Transaction{},
 			}
 		}
 
 		assetState := assets[assetKey]
+		holdingKey := assetKey + "/" + holding.Date.Format("2006-01-02")
+		if i, exists := holdingIndexes[holdingKey]; exists {
+			assetState.Holdings[i].Units += holding.Units
+			assetState.Holdings[i].Value += holding.Value
+			continue
+		}
+		holdingIndexes[holdingKey] = len(assetState.Holdings)
+		currency := valueCurrency(asset, holding.Source)
 		assetState.Holdings = append(assetState.Holdings, schemas.Holding{
-			Currency:      asset.Currency,
-			CurrencySign:  getCurrencySign(asset.Currency),
+			Currency:      currency,
+			CurrencySign:  getCurrencySign(currency),
 			Value:         holding.Value,
 			Units:         holding.Units,
 			DateRequested: &holding.Date,
@@ -204,17 +406,24 @@ func (s *AccountService) buildAccountState(ctx context.Context, holdings []model
 				Type:         asset.AssetType,
 				Denomination: asset.Name,
 				Category:     "", // Will be populated if we have category info
+				Manual:       asset.IsManual(),
 				Holdings:     []schemas.Holding{},
 				Transactions: []schemas.Transaction{},
 			}
 		}
 
 		assetState := assets[assetKey]
+		currency := valueCurrency(asset, transaction.Source)
 		assetState.Transactions = append(assetState.Transactions, schemas.Transaction{
-			Currency:     asset.Currency,
-			CurrencySign: getCurrencySign(asset.Currency),
+			Currency:     currency,
+			CurrencySign: getCurrencySign(currency),
+			Type:         transaction.TransactionType,
 			Value:        transaction.TotalValue,
 			Units:        transaction.Units,
+			PricePerUnit: transaction.PricePerUnit,
+			GrossValue:   transaction.GrossValue,
+			ExchangeFees: transaction.ExchangeFees,
+			MarketFees:   transaction.MarketFees,
 			Date:         &transaction.Date,
 		})
 		assets[assetKey] = assetState
@@ -257,9 +466,11 @@ func (s *AccountService) buildAccountStateByCategory(
 	accountStates []*schemas.AccountState,
 	categoryHoldings map[string]map[string]float64,
 	categoryTransactions map[string]map[string]float64,
+	typeTransactions map[string]map[string]float64,
 	totalHoldings map[string]float64,
 	totalTransactions map[string]float64,
 	assetsWithCategories []models.AssetWithCategory,
+	currency, currencySign string,
 ) *schemas.AccountStateByCategory {
 
 	// Build assets by category from individual account states
@@ -299,8 +510,8 @@ func (s *AccountService) buildAccountStateByCategory(
 	for dateStr, value := range totalHoldings {
 		date, _ := time.Parse("2006-01-02", dateStr)
 		totalHoldingsByDate[dateStr] = schemas.Holding{
-			Currency:      "Pesos",
-			CurrencySign:  "$",
+			Currency:      currency,
+			CurrencySign:  currencySign,
 			Value:         value,
 			DateRequested: &date,
 			Date:          &date,
@@ -311,8 +522,8 @@ func (s *AccountService) buildAccountStateByCategory(
 	for dateStr, value := range totalTransactions {
 		date, _ := time.Parse("2006-01-02", dateStr)
 		totalTransactionsByDate[dateStr] = schemas.Transaction{
-			Currency:     "Pesos",
-			CurrencySign: "$",
+			Currency:     currency,
+			CurrencySign: currencySign,
 			Value:        value,
 			Date:         &date,
 		}
@@ -335,8 +546,8 @@ func (s *AccountService) buildAccountStateByCategory(
 			date, _ := time.Parse("2006-01-02", dateStr)
 			asset := categoryAssets[category]
 			asset.Holdings = append(asset.Holdings, schemas.Holding{
-				Currency:      "Pesos",
-				CurrencySign:  "$",
+				Currency:      currency,
+				CurrencySign:  currencySign,
 				Value:         value,
 				DateRequested: &date,
 				Date:          &date,
@@ -363,8 +574,8 @@ func (s *AccountService) buildAccountStateByCategory(
 			date, _ := time.Parse("2006-01-02", dateStr)
 			asset := categoryAssets[category]
 			asset.Transactions = append(asset.Transactions, schemas.Transaction{
-				Currency:     "Pesos",
-				CurrencySign: "$",
+				Currency:     currency,
+				CurrencySign: currencySign,
 				Value:        value,
 				Date:         &date,
 			})
@@ -372,12 +583,39 @@ func (s *AccountService) buildAccountStateByCategory(
 		}
 	}
 
+	// Build transactions by type
+	typeTransactionsByDate := make(map[string]map[string]schemas.Transaction)
+	for transactionType, transactionsByDate := range typeTransactions {
+		typeTransactionsByDate[transactionType] = make(map[string]schemas.Transaction)
+		for dateStr, value := range transactionsByDate {
+			date, _ := time.Parse("2006-01-02", dateStr)
+			typeTransactionsByDate[transactionType][dateStr] = schemas.Transaction{
+				Currency:     currency,
+				CurrencySign: currencySign,
+				Type:         transactionType,
+				Value:        value,
+				Date:         &date,
+			}
+		}
+	}
+	transactionsByType := sortTransactionsByType(typeTransactionsByDate)
+
 	return &schemas.AccountStateByCategory{
 		AssetsByCategory:        &assetsByCategory,
 		CategoryAssets:          &categoryAssets,
 		TotalHoldingsByDate:     &totalHoldingsByDate,
 		TotalTransactionsByDate: &totalTransactionsByDate,
+		TransactionsByType:      &transactionsByType,
+	}
+}
+
+// valueCurrency returns the currency the values stored by source are in. Brokers report values in the
+// currency of the asset, while manual valuations and cash flows are always entered in pesos.
+func valueCurrency(asset *models.Asset, source string) string {
+	if source == models.SourceManual {
+		return utils.AssetCurrencyPesos
 	}
+	return asset.Currency
 }
 
 // getCurrencySign returns the currency sign for a given currency
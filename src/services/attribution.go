@@ -0,0 +1,326 @@
+package services
+
+import (
+	"fmt"
+	"server/src/schemas"
+	"sort"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+)
+
+// calculateContributions sets the part of the total return of every interval each asset and category
+// accounts for. Returns are weighted by the share of the portfolio at the start of the interval, like
+// CalculateWeightedTotalReturns does, so the contributions of an interval add up to its total return.
+// The contribution of the whole period links the ones of every interval with the growth of the portfolio
+// until its start, so the contributions add up to the time-weighted return of the report.
+func (rs *ReportService) calculateContributions(
+	assetReturnsByCategory map[string][]schemas.AssetReturn,
+	categoryReturns map[string]schemas.AssetReturn,
+	assetsByCategory map[string][]schemas.Asset,
+	totalHoldingsByDate []schemas.Holding,
+	totalReturns []schemas.ReturnByDate,
+) {
+	totalValueByDate := make(map[string]float64)
+	for _, holding := range totalHoldingsByDate {
+		if holding.DateRequested != nil {
+			totalValueByDate[holding.DateRequested.Format("2006-01-02")] = holding.Value
+		}
+	}
+	// The intervals of the total returns by end date, with the growth of the portfolio until their start
+	intervalByEndDate := make(map[string]int)
+	growth := make([]float64, len(totalReturns))
+	for i, totalReturn := range totalReturns {
+		intervalByEndDate[totalReturn.EndDate.Format("2006-01-02")] = i
+		growth[i] = 1
+		if i > 0 {
+			growth[i] = growth[i-1] * (1 + totalReturns[i-1].ReturnPercentage/100)
+		}
+	}
+
+	type assetPeriod struct {
+		category string
+		index    int
+		interval int
+		weight   float64
+		period   schemas.ReturnByDate
+	}
+	var assetPeriods []assetPeriod
+	intervalWeights := make([]float64, len(totalReturns))
+	for category, assetReturns := range assetReturnsByCategory {
+		assets := assetsByCategory[category]
+		for i := range assetReturns {
+			if i >= len(assets) {
+				break
+			}
+			for _, period := range assetReturns[i].ReturnsByDateRange {
+				interval, exists := intervalByEndDate[period.EndDate.Format("2006-01-02")]
+				totalValue := totalValueByDate[period.StartDate.Format("2006-01-02")]
+				if !exists || totalValue <= 0 {
+					continue
+				}
+				weight := rs.getAssetValueAtDate(assets[i], period.StartDate) / totalValue
+				intervalWeights[interval] += weight
+				assetPeriods = append(assetPeriods, assetPeriod{category: category, index: i, interval: interval, weight: weight, period: period})
+			}
+		}
+	}
+
+	categoryContributions := make(map[string]map[int]*schemas.ContributionByDate)
+	for _, assetPeriod := range assetPeriods {
+		weight := assetPeriod.weight
+		if intervalWeights[assetPeriod.interval] > 0 {
+			weight /= intervalWeights[assetPeriod.interval]
+		}
+		totalReturn := totalReturns[assetPeriod.interval]
+		contribution := schemas.ContributionByDate{
+			StartDate:        totalReturn.StartDate,
+			EndDate:          totalReturn.EndDate,
+			Weight:           weight,
+			ReturnPercentage: assetPeriod.period.ReturnPercentage,
+			Contribution:     weight * assetPeriod.period.ReturnPercentage,
+		}
+		assetReturn := &assetReturnsByCategory[assetPeriod.category][assetPeriod.index]
+		assetReturn.ContributionsByDateRange = append(assetReturn.ContributionsByDateRange, contribution)
+		assetReturn.Contribution += contribution.Contribution * growth[assetPeriod.interval]
+
+		if categoryContributions[assetPeriod.category] == nil {
+			categoryContributions[assetPeriod.category] = make(map[int]*schemas.ContributionByDate)
+		}
+		categoryContribution, exists := categoryContributions[assetPeriod.category][assetPeriod.interval]
+		if !exists {
+			categoryContribution = &schemas.ContributionByDate{StartDate: totalReturn.StartDate, EndDate: totalReturn.EndDate}
+			categoryContributions[assetPeriod.category][assetPeriod.interval] = categoryContribution
+		}
+		categoryContribution.Weight += contribution.Weight
+		categoryContribution.Contribution += contribution.Contribution
+	}
+
+	for _, assetReturns := range assetReturnsByCategory {
+		for i := range assetReturns {
+			contributions := assetReturns[i].ContributionsByDateRange
+			sort.Slice(contributions, func(a, b int) bool {
+				return contributions[a].StartDate.Before(contributions[b].StartDate)
+			})
+		}
+	}
+	for category, contributionsByInterval := range categoryContributions {
+		categoryReturn, exists := categoryReturns[category]
+		if !exists {
+			continue
+		}
+		categoryReturn.ContributionsByDateRange = nil
+		categoryReturn.Contribution = 0
+		for interval := range totalReturns {
+			contribution, exists := contributionsByInterval[interval]
+			if !exists {
+				continue
+			}
+			if contribution.Weight != 0 {
+				contribution.ReturnPercentage = contribution.Contribution / contribution.Weight
+			}
+			categoryReturn.ContributionsByDateRange = append(categoryReturn.ContributionsByDateRange, *contribution)
+			categoryReturn.Contribution += contribution.Contribution * growth[interval]
+		}
+		categoryReturns[category] = categoryReturn
+	}
+}
+
+// CalculateAttribution splits the total return of every interval of the report by category against the
+// target allocation with the Brinson-Fachler model. The target return of a category is the return of its
+// benchmark in categoryBenchmarks or, without one, its return in the portfolio. Categories the portfolio
+// does not hold take the target return, so they only weigh in through the allocation effect.
+func (rs *ReportService) CalculateAttribution(accountsReport *schemas.AccountsReports, target *schemas.TargetAllocationResponse, categoryBenchmarks map[string]schemas.BenchmarkSeries) {
+	totalReturns := rs.sortReturnsByDate(accountsReport.TotalReturns)
+	if target == nil || len(totalReturns) == 0 {
+		return
+	}
+	firstDate := totalReturns[0].StartDate
+	lastDate := totalReturns[len(totalReturns)-1].EndDate
+
+	var totalTargetWeight float64
+	targetWeights := make(map[string]float64)
+	for _, targetCategory := range target.Categories {
+		targetWeights[targetCategory.Category] = targetCategory.Weight
+		totalTargetWeight += targetCategory.Weight
+	}
+	if totalTargetWeight <= 0 {
+		return
+	}
+
+	portfolioContributions := make(map[string]map[string]schemas.ContributionByDate)
+	categorySet := make(map[string]bool)
+	if accountsReport.CategoryAssetsReturn != nil {
+		for category, categoryReturn := range *accountsReport.CategoryAssetsReturn {
+			portfolioContributions[category] = make(map[string]schemas.ContributionByDate)
+			for _, contribution := range categoryReturn.ContributionsByDateRange {
+				portfolioContributions[category][contribution.EndDate.Format("2006-01-02")] = contribution
+			}
+			categorySet[category] = true
+		}
+	}
+	for category := range targetWeights {
+		categorySet[category] = true
+	}
+	categories := make([]string, 0, len(categorySet))
+	for category := range categorySet {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	benchmarkIndexes := make(map[string]*dailyIndex)
+	for category, benchmark := range categoryBenchmarks {
+		if index := newBenchmarkIndex(benchmark, firstDate, lastDate); index != nil {
+			benchmarkIndexes[category] = index
+		}
+	}
+
+	attribution := &schemas.Attribution{TargetAllocation: target.Name}
+	totals := make(map[string]*schemas.CategoryAttribution)
+	portfolioReturns := make(map[string][]schemas.ReturnByDate)
+	targetReturns := make(map[string][]schemas.ReturnByDate)
+	for _, totalReturn := range totalReturns {
+		endDate := totalReturn.EndDate.Format("2006-01-02")
+		period := schemas.AttributionPeriod{
+			StartDate:       totalReturn.StartDate,
+			EndDate:         totalReturn.EndDate,
+			PortfolioReturn: totalReturn.ReturnPercentage,
+		}
+
+		for _, category := range categories {
+			categoryAttribution := schemas.CategoryAttribution{
+				Category:     category,
+				TargetWeight: targetWeights[category] / totalTargetWeight,
+			}
+			contribution, held := portfolioContributions[category][endDate]
+			if held {
+				categoryAttribution.PortfolioWeight = contribution.Weight
+				categoryAttribution.PortfolioReturn = contribution.ReturnPercentage
+				categoryAttribution.TargetReturn = contribution.ReturnPercentage
+			}
+			if index, exists := benchmarkIndexes[category]; exists {
+				categoryAttribution.TargetReturn = index.periodReturn(totalReturn.StartDate, totalReturn.EndDate)
+			}
+			if !held {
+				categoryAttribution.PortfolioReturn = categoryAttribution.TargetReturn
+			}
+			period.TargetReturn += categoryAttribution.TargetWeight * categoryAttribution.TargetReturn
+			period.Categories = append(period.Categories, categoryAttribution)
+		}
+
+		for i := range period.Categories {
+			categoryAttribution := &period.Categories[i]
+			activeWeight := categoryAttribution.PortfolioWeight - categoryAttribution.TargetWeight
+			categoryAttribution.Allocation = activeWeight * (categoryAttribution.TargetReturn - period.TargetReturn)
+			categoryAttribution.Selection = categoryAttribution.TargetWeight * (categoryAttribution.PortfolioReturn - categoryAttribution.TargetReturn)
+			categoryAttribution.Interaction = activeWeight * (categoryAttribution.PortfolioReturn - categoryAttribution.TargetReturn)
+
+			total, exists := totals[categoryAttribution.Category]
+			if !exists {
+				total = &schemas.CategoryAttribution{Category: categoryAttribution.Category}
+				totals[categoryAttribution.Category] = total
+			}
+			total.PortfolioWeight += categoryAttribution.PortfolioWeight / float64(len(totalReturns))
+			total.TargetWeight += categoryAttribution.TargetWeight / float64(len(totalReturns))
+			total.Allocation += categoryAttribution.Allocation
+			total.Selection += categoryAttribution.Selection
+			total.Interaction += categoryAttribution.Interaction
+			portfolioReturns[categoryAttribution.Category] = append(portfolioReturns[categoryAttribution.Category], schemas.ReturnByDate{ReturnPercentage: categoryAttribution.PortfolioReturn})
+			targetReturns[categoryAttribution.Category] = append(targetReturns[categoryAttribution.Category], schemas.ReturnByDate{ReturnPercentage: categoryAttribution.TargetReturn})
+		}
+		attribution.Periods = append(attribution.Periods, period)
+	}
+
+	for _, category := range categories {
+		total := totals[category]
+		total.PortfolioReturn = chainReturns(portfolioReturns[category])
+		total.TargetReturn = chainReturns(targetReturns[category])
+		attribution.Categories = append(attribution.Categories, *total)
+	}
+	accountsReport.Attribution = attribution
+}
+
+// parseContributionToDataFrame lists the time-weighted return of every asset, category and the total with
+// the part of the return of the report it accounts for, one per row
+func (rs *ReportService) parseContributionToDataFrame(accountsReport *schemas.AccountsReports) *dataframe.DataFrame {
+	var names, returns, contributions []string
+	addRow := func(name string, returnPercentage, contribution float64) {
+		names = append(names, name)
+		returns = append(returns, fmt.Sprintf("%.2f", returnPercentage))
+		contributions = append(contributions, fmt.Sprintf("%.2f", contribution))
+	}
+
+	var categories []string
+	if accountsReport.CategoryAssetsReturn != nil {
+		for category := range *accountsReport.CategoryAssetsReturn {
+			categories = append(categories, category)
+		}
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		if accountsReport.AssetsReturnByCategory != nil {
+			for _, asset := range (*accountsReport.AssetsReturnByCategory)[category] {
+				addRow(fmt.Sprintf("%s-%s", asset.Category, asset.ID), asset.TimeWeightedReturn, asset.Contribution)
+			}
+		}
+		categoryReturn := (*accountsReport.CategoryAssetsReturn)[category]
+		addRow(category, categoryReturn.TimeWeightedReturn, categoryReturn.Contribution)
+	}
+	addRow("TOTAL", accountsReport.TimeWeightedReturn, accountsReport.TimeWeightedReturn)
+
+	df := dataframe.New(
+		series.New(names, series.String, "Contribucion"),
+		series.New(returns, series.String, "TWR %"),
+		series.New(contributions, series.String, "Contribucion %"),
+	)
+	return &df
+}
+
+// parseAttributionToDataFrame lists the attribution of the whole period of every category and the total,
+// one per row, with the weights as percentages
+func (rs *ReportService) parseAttributionToDataFrame(accountsReport *schemas.AccountsReports) *dataframe.DataFrame {
+	if accountsReport.Attribution == nil {
+		return nil
+	}
+	var names, weights, targetWeights, returns, targetReturns, allocation, selection, interaction, effects []string
+	addRow := func(name string, categoryAttribution schemas.CategoryAttribution) {
+		names = append(names, name)
+		weights = append(weights, fmt.Sprintf("%.2f", categoryAttribution.PortfolioWeight*100))
+		targetWeights = append(targetWeights, fmt.Sprintf("%.2f", categoryAttribution.TargetWeight*100))
+		returns = append(returns, fmt.Sprintf("%.2f", categoryAttribution.PortfolioReturn))
+		targetReturns = append(targetReturns, fmt.Sprintf("%.2f", categoryAttribution.TargetReturn))
+		allocation = append(allocation, fmt.Sprintf("%.2f", categoryAttribution.Allocation))
+		selection = append(selection, fmt.Sprintf("%.2f", categoryAttribution.Selection))
+		interaction = append(interaction, fmt.Sprintf("%.2f", categoryAttribution.Interaction))
+		effects = append(effects, fmt.Sprintf("%.2f", categoryAttribution.Allocation+categoryAttribution.Selection+categoryAttribution.Interaction))
+	}
+
+	total := schemas.CategoryAttribution{PortfolioWeight: 1, TargetWeight: 1}
+	var portfolioReturns, targetPeriodReturns []schemas.ReturnByDate
+	for _, period := range accountsReport.Attribution.Periods {
+		portfolioReturns = append(portfolioReturns, schemas.ReturnByDate{ReturnPercentage: period.PortfolioReturn})
+		targetPeriodReturns = append(targetPeriodReturns, schemas.ReturnByDate{ReturnPercentage: period.TargetReturn})
+	}
+	total.PortfolioReturn = chainReturns(portfolioReturns)
+	total.TargetReturn = chainReturns(targetPeriodReturns)
+	for _, categoryAttribution := range accountsReport.Attribution.Categories {
+		addRow(categoryAttribution.Category, categoryAttribution)
+		total.Allocation += categoryAttribution.Allocation
+		total.Selection += categoryAttribution.Selection
+		total.Interaction += categoryAttribution.Interaction
+	}
+	addRow("TOTAL", total)
+
+	df := dataframe.New(
+		series.New(names, series.String, "Atribucion"),
+		series.New(weights, series.String, "Peso promedio %"),
+		series.New(targetWeights, series.String, "Peso objetivo %"),
+		series.New(returns, series.String, "Rendimiento %"),
+		series.New(targetReturns, series.String, "Rendimiento objetivo %"),
+		series.New(allocation, series.String, "Asignacion %"),
+		series.New(selection, series.String, "Seleccion %"),
+		series.New(interaction, series.String, "Interaccion %"),
+		series.New(effects, series.String, "Efecto total %"),
+	)
+	return &df
+}
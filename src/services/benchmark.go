@@ -0,0 +1,171 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"server/src/schemas"
+	"strings"
+	"time"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+)
+
+// CalculateBenchmarks compares the time-weighted returns of the report with every benchmark over the
+// intervals of TotalReturns. Benchmarks without values in the range of the report are left out.
+func (rs *ReportService) CalculateBenchmarks(accountsReport *schemas.AccountsReports, benchmarks []schemas.BenchmarkSeries) {
+	returns := rs.sortReturnsByDate(accountsReport.TotalReturns)
+	if len(returns) == 0 {
+		return
+	}
+	firstDate := returns[0].StartDate
+	lastDate := returns[len(returns)-1].EndDate
+
+	// The cumulative returns of the report, to measure the cumulative excess of every benchmark
+	cumulativeReturns := make([]float64, len(returns))
+	for i := range returns {
+		cumulativeReturns[i] = chainReturns(returns[:i+1])
+	}
+
+	comparisons := make([]schemas.BenchmarkComparison, 0, len(benchmarks))
+	for _, benchmark := range benchmarks {
+		index := newBenchmarkIndex(benchmark, firstDate, lastDate)
+		if index == nil {
+			continue
+		}
+
+		comparison := schemas.BenchmarkComparison{
+			ID:   benchmark.ID,
+			Name: benchmark.Name,
+			Kind: benchmark.Kind,
+		}
+		var excesses []float64
+		var hits int
+		for i, returnByDate := range returns {
+			benchmarkReturn := index.periodReturn(returnByDate.StartDate, returnByDate.EndDate)
+			cumulativeReturn := index.periodReturn(firstDate, returnByDate.EndDate)
+			excess := returnByDate.ReturnPercentage - benchmarkReturn
+			excesses = append(excesses, excess)
+			if excess > 0 {
+				hits++
+			}
+
+			comparison.Returns = append(comparison.Returns, schemas.ReturnByDate{
+				StartDate:        returnByDate.StartDate,
+				EndDate:          returnByDate.EndDate,
+				ReturnPercentage: benchmarkReturn,
+			})
+			comparison.CumulativeReturns = append(comparison.CumulativeReturns, schemas.ReturnByDate{
+				StartDate:        firstDate,
+				EndDate:          returnByDate.EndDate,
+				ReturnPercentage: cumulativeReturn,
+			})
+			comparison.ExcessReturns = append(comparison.ExcessReturns, schemas.ReturnByDate{
+				StartDate:        returnByDate.StartDate,
+				EndDate:          returnByDate.EndDate,
+				ReturnPercentage: excess,
+			})
+			comparison.CumulativeExcessReturns = append(comparison.CumulativeExcessReturns, schemas.ReturnByDate{
+				StartDate:        firstDate,
+				EndDate:          returnByDate.EndDate,
+				ReturnPercentage: cumulativeReturns[i] - cumulativeReturn,
+			})
+		}
+		comparison.CumulativeReturn = comparison.CumulativeReturns[len(returns)-1].ReturnPercentage
+		comparison.CumulativeExcessReturn = comparison.CumulativeExcessReturns[len(returns)-1].ReturnPercentage
+		comparison.TrackingError = standardDeviation(excesses)
+		comparison.HitRatio = float64(hits) / float64(len(returns)) * 100
+		comparisons = append(comparisons, comparison)
+	}
+	accountsReport.Benchmarks = comparisons
+}
+
+// newBenchmarkIndex builds the daily index of the benchmark between firstDate and lastDate as its kind
+// reads its values. It returns nil when the benchmark has no values.
+func newBenchmarkIndex(benchmark schemas.BenchmarkSeries, firstDate, lastDate time.Time) *dailyIndex {
+	switch benchmark.Kind {
+	case BenchmarkKindMonthly:
+		return newMonthlyRateIndex(benchmark.Valuations, firstDate, lastDate)
+	case BenchmarkKindAnnual:
+		return newAnnualRateIndex(benchmark.Valuations, firstDate, lastDate)
+	default:
+		return newLevelIndex(benchmark.Valuations, firstDate, lastDate)
+	}
+}
+
+// standardDeviation returns the sample standard deviation of the values, zero when there are less than two
+func standardDeviation(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	var mean float64
+	for _, value := range values {
+		mean += value
+	}
+	mean /= float64(len(values))
+	var squares float64
+	for _, value := range values {
+		squares += (value - mean) * (value - mean)
+	}
+	return math.Sqrt(squares / float64(len(values)-1))
+}
+
+// parseBenchmarksToDataFrame lists on every date the cumulative return of the report, as TOTAL, and the
+// cumulative return of every benchmark with its excess return in the interval and since the start
+func (rs *ReportService) parseBenchmarksToDataFrame(accountsReport *schemas.AccountsReports) *dataframe.DataFrame {
+	if len(accountsReport.Benchmarks) == 0 {
+		return nil
+	}
+	returns := rs.sortReturnsByDate(accountsReport.TotalReturns)
+	dates := make([]string, len(returns))
+	totals := make([]string, len(returns))
+	for i, returnByDate := range returns {
+		dates[i] = returnByDate.EndDate.Format("2006-01-02")
+		totals[i] = fmt.Sprintf("%.2f", chainReturns(returns[:i+1]))
+	}
+
+	columns := []series.Series{series.New(dates, series.String, "DateRequested")}
+	for _, benchmark := range accountsReport.Benchmarks {
+		// The files split the column names by dashes into the benchmark and the value shown
+		name := strings.ReplaceAll(benchmark.Name, "-", " ")
+		columns = append(columns,
+			series.New(returnsToColumnValues(benchmark.CumulativeReturns), series.String, name+"-Acumulado"),
+			series.New(returnsToColumnValues(benchmark.ExcessReturns), series.String, name+"-Exceso"),
+			series.New(returnsToColumnValues(benchmark.CumulativeExcessReturns), series.String, name+"-Exceso acumulado"),
+		)
+	}
+	columns = append(columns, series.New(totals, series.String, "TOTAL"))
+	df := dataframe.New(columns...)
+	return &df
+}
+
+// parseBenchmarkSummaryToDataFrame lists the comparison of the whole period with every benchmark, one per row
+func (rs *ReportService) parseBenchmarkSummaryToDataFrame(accountsReport *schemas.AccountsReports) *dataframe.DataFrame {
+	if len(accountsReport.Benchmarks) == 0 {
+		return nil
+	}
+	var names, cumulative, excess, trackingError, hitRatio []string
+	for _, benchmark := range accountsReport.Benchmarks {
+		names = append(names, benchmark.Name)
+		cumulative = append(cumulative, fmt.Sprintf("%.2f", benchmark.CumulativeReturn))
+		excess = append(excess, fmt.Sprintf("%.2f", benchmark.CumulativeExcessReturn))
+		trackingError = append(trackingError, fmt.Sprintf("%.2f", benchmark.TrackingError))
+		hitRatio = append(hitRatio, fmt.Sprintf("%.2f", benchmark.HitRatio))
+	}
+	df := dataframe.New(
+		series.New(names, series.String, "Benchmark"),
+		series.New(cumulative, series.String, "Rendimiento %"),
+		series.New(excess, series.String, "Exceso %"),
+		series.New(trackingError, series.String, "Tracking error %"),
+		series.New(hitRatio, series.String, "Hit ratio %"),
+	)
+	return &df
+}
+
+func returnsToColumnValues(returns []schemas.ReturnByDate) []string {
+	values := make([]string, len(returns))
+	for i, returnByDate := range returns {
+		values[i] = fmt.Sprintf("%.2f", returnByDate.ReturnPercentage)
+	}
+	return values
+}
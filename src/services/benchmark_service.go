@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"server/src/clients/bcra"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/schemas"
+	"server/src/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of benchmark series, telling how their values are read
+const (
+	// BenchmarkKindLevel series are prices or indexes, like the official dollar or CER
+	BenchmarkKindLevel = "level"
+	// BenchmarkKindMonthly series are monthly rates as percentages, like the monthly inflation
+	BenchmarkKindMonthly = "monthly"
+	// BenchmarkKindAnnual series are nominal annual rates as percentages, like the rate of fixed-term deposits
+	BenchmarkKindAnnual = "annual"
+)
+
+var BenchmarkKinds = []string{BenchmarkKindLevel, BenchmarkKindMonthly, BenchmarkKindAnnual}
+
+// IsValidBenchmarkKind reports whether kind is one of the benchmark kinds
+func IsValidBenchmarkKind(kind string) bool {
+	return kind == BenchmarkKindLevel || kind == BenchmarkKindMonthly || kind == BenchmarkKindAnnual
+}
+
+type BenchmarkServiceI interface {
+	GetSeriesNames(ctx context.Context) ([]string, error)
+	GetValues(ctx context.Context, name string, startDate, endDate time.Time) ([]*schemas.BenchmarkValueResponse, error)
+	SetValues(ctx context.Context, name string, req []schemas.BenchmarkValueRequest) ([]*schemas.BenchmarkValueResponse, error)
+	DeleteValue(ctx context.Context, name string, date time.Time) error
+	GetBenchmarkSeries(ctx context.Context, benchmarks []schemas.BenchmarkRequest, startDate, endDate time.Time) ([]schemas.BenchmarkSeries, error)
+}
+
+// BenchmarkService manages the custom series reports can be compared against, which are loaded by hand,
+// and loads the series of the benchmarks of a report, either from BCRA or the stored ones.
+type BenchmarkService struct {
+	bcraClient               bcra.BCRAServiceClientI
+	benchmarkValueRepository repositories.BenchmarkValueRepository
+}
+
+func NewBenchmarkService(bcraClient bcra.BCRAServiceClientI, benchmarkValueRepository repositories.BenchmarkValueRepository) *BenchmarkService {
+	return &BenchmarkService{
+		bcraClient:               bcraClient,
+		benchmarkValueRepository: benchmarkValueRepository,
+	}
+}
+
+func (s *BenchmarkService) GetSeriesNames(ctx context.Context) ([]string, error) {
+	return s.benchmarkValueRepository.GetNames(ctx)
+}
+
+func (s *BenchmarkService) GetValues(ctx context.Context, name string, startDate, endDate time.Time) ([]*schemas.BenchmarkValueResponse, error) {
+	name, err := parseCustomSeriesName(name)
+	if err != nil {
+		return nil, err
+	}
+	values, err := s.benchmarkValueRepository.GetByName(ctx, name, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	response := make([]*schemas.BenchmarkValueResponse, 0, len(values))
+	for i := range values {
+		response = append(response, benchmarkValueToResponse(&values[i]))
+	}
+	return response, nil
+}
+
+// SetValues stores the values of the series, replacing the ones of the same dates
+func (s *BenchmarkService) SetValues(ctx context.Context, name string, req []schemas.BenchmarkValueRequest) ([]*schemas.BenchmarkValueResponse, error) {
+	name, err := parseCustomSeriesName(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range req {
+		if value.Date.IsZero() {
+			return nil, utils.BadRequest("date is required")
+		}
+	}
+
+	response := make([]*schemas.BenchmarkValueResponse, 0, len(req))
+	for _, value := range req {
+		benchmarkValue := &models.BenchmarkValue{
+			Name:  name,
+			Date:  value.Date.Time,
+			Value: value.Value,
+		}
+		if err := s.benchmarkValueRepository.Create(ctx, benchmarkValue, nil); err != nil {
+			return nil, fmt.Errorf("error storing benchmark value: %w", err)
+		}
+		response = append(response, benchmarkValueToResponse(benchmarkValue))
+	}
+	utils.LoggerFromContext(ctx).Infof("Stored %d values of benchmark %s", len(response), name)
+	return response, nil
+}
+
+func (s *BenchmarkService) DeleteValue(ctx context.Context, name string, date time.Time) error {
+	name, err := parseCustomSeriesName(name)
+	if err != nil {
+		return err
+	}
+	return s.benchmarkValueRepository.Delete(ctx, name, date)
+}
+
+// GetBenchmarkSeries loads the values of the benchmarks between startDate and endDate. Numeric IDs are
+// BCRA variables and any other one a stored series, which includes its last value before startDate so
+// the first days of the report have one.
+func (s *BenchmarkService) GetBenchmarkSeries(ctx context.Context, benchmarks []schemas.BenchmarkRequest, startDate, endDate time.Time) ([]schemas.BenchmarkSeries, error) {
+	var descriptions map[string]string
+	series := make([]schemas.BenchmarkSeries, 0, len(benchmarks))
+	for _, benchmark := range benchmarks {
+		kind := benchmark.Kind
+		if kind == "" {
+			kind = BenchmarkKindLevel
+		}
+
+		if _, err := strconv.Atoi(benchmark.ID); err != nil {
+			valuations, err := s.getCustomSeriesValuations(ctx, benchmark.ID, startDate, endDate)
+			if err != nil {
+				return nil, err
+			}
+			series = append(series, schemas.BenchmarkSeries{ID: benchmark.ID, Name: benchmark.ID, Kind: kind, Valuations: valuations})
+			continue
+		}
+
+		if descriptions == nil {
+			var err error
+			if descriptions, err = s.getBCRAVariableDescriptions(ctx); err != nil {
+				return nil, err
+			}
+		}
+		valuations, err := s.getBCRAVariableValuations(ctx, benchmark.ID, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		name := descriptions[benchmark.ID]
+		if name == "" {
+			name = "BCRA " + benchmark.ID
+		}
+		series = append(series, schemas.BenchmarkSeries{ID: benchmark.ID, Name: name, Kind: kind, Valuations: valuations})
+	}
+	return series, nil
+}
+
+func (s *BenchmarkService) getCustomSeriesValuations(ctx context.Context, name string, startDate, endDate time.Time) ([]schemas.VariableValuation, error) {
+	values, err := s.benchmarkValueRepository.GetByName(ctx, name, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	previousValue, err := s.benchmarkValueRepository.GetLatestBeforeDate(ctx, name, startDate)
+	if err != nil {
+		return nil, err
+	}
+	if previousValue != nil {
+		values = append([]models.BenchmarkValue{*previousValue}, values...)
+	}
+	if len(values) == 0 {
+		return nil, utils.NotFound(fmt.Sprintf("benchmark %s has no values until %s", name, endDate.Format("2006-01-02")))
+	}
+
+	valuations := make([]schemas.VariableValuation, 0, len(values))
+	for _, value := range values {
+		valuations = append(valuations, schemas.VariableValuation{
+			Date:  value.Date.Format("2006-01-02"),
+			Value: value.Value,
+		})
+	}
+	return valuations, nil
+}
+
+func (s *BenchmarkService) getBCRAVariableValuations(ctx context.Context, id string, startDate, endDate time.Time) ([]schemas.VariableValuation, error) {
+	response, err := s.bcraClient.GetVariablesPorFecha(ctx, id, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	if response.Status != http.StatusOK {
+		return nil, utils.NewHTTPError(response.Status, fmt.Sprintf("Error requesting BCRA variables: %s", strings.Join(response.ErrorMessages, ", ")))
+	}
+	if len(response.Results) == 0 {
+		return nil, utils.NotFound(fmt.Sprintf("BCRA variable %s has no values between %s and %s", id, startDate.Format("2006-01-02"), endDate.Format("2006-01-02")))
+	}
+
+	valuations := make([]schemas.VariableValuation, 0, len(response.Results))
+	for _, variable := range response.Results {
+		valuations = append(valuations, schemas.VariableValuation{
+			Date:  variable.Fecha,
+			Value: variable.Valor,
+		})
+	}
+	return valuations, nil
+}
+
+func (s *BenchmarkService) getBCRAVariableDescriptions(ctx context.Context) (map[string]string, error) {
+	response, err := s.bcraClient.GetVariables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	descriptions := make(map[string]string, len(response.Results))
+	for _, variable := range response.Results {
+		descriptions[strconv.Itoa(variable.IDVariable)] = variable.Descripcion
+	}
+	return descriptions, nil
+}
+
+// parseCustomSeriesName validates the name of a stored series, which cannot be numeric so it is told
+// apart from the BCRA variables, nor hold the separators of the benchmarks of a report
+func parseCustomSeriesName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", utils.BadRequest("name is required")
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		return "", utils.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("invalid benchmark name %q, numeric names are reserved for BCRA variables", name))
+	}
+	if strings.ContainsAny(name, ",:") {
+		return "", utils.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("invalid benchmark name %q, it cannot contain commas or colons", name))
+	}
+	return name, nil
+}
+
+func benchmarkValueToResponse(value *models.BenchmarkValue) *schemas.BenchmarkValueResponse {
+	return &schemas.BenchmarkValueResponse{
+		Name:  value.Name,
+		Date:  schemas.Date{Time: value.Date},
+		Value: value.Value,
+	}
+}
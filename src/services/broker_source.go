@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"server/src/models"
+	"server/src/schemas"
+	"time"
+)
+
+// BrokerSource is a broker holdings and transactions are synced from. Sources produce the
+// broker-neutral schemas.AccountState, so everything downstream of the sync is the same for all of them.
+type BrokerSource interface {
+	// Name identifies the source on the stored holdings, transactions and sync logs
+	Name() string
+	// FetchAccountState returns the daily holdings and the transactions of the account between
+	// startDate and endDate, or nil when the broker has no data for it
+	FetchAccountState(ctx context.Context, token, accountID string, startDate, endDate time.Time) (*schemas.AccountState, error)
+}
+
+// ESCOBrokerSource syncs accounts from ESCO
+type ESCOBrokerSource struct {
+	escoService ESCOServiceI
+}
+
+func NewESCOBrokerSource(escoService ESCOServiceI) *ESCOBrokerSource {
+	return &ESCOBrokerSource{escoService: escoService}
+}
+
+func (s *ESCOBrokerSource) Name() string {
+	return models.SourceESCO
+}
+
+func (s *ESCOBrokerSource) FetchAccountState(ctx context.Context, token, accountID string, startDate, endDate time.Time) (*schemas.AccountState, error) {
+	return s.escoService.GetAccountStateWithTransactions(ctx, token, accountID, startDate, endDate, time.Hour*24)
+}
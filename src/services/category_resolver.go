@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/utils"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCategoryRulesTTL  = time.Minute
+	categoryRulesLoadTimeout = 5 * time.Second
+)
+
+// CategoryResolverI resolves the category of an asset from its ESCO denomination
+type CategoryResolverI interface {
+	Resolve(categoryKey string) string
+}
+
+// MapCategoryResolver resolves categories by exact denomination, as listed in denominaciones.csv
+type MapCategoryResolver map[string]string
+
+func (m MapCategoryResolver) Resolve(categoryKey string) string {
+	if category, exists := m[categoryKey]; exists && category != "" {
+		return category
+	}
+	return models.UnclassifiedCategory
+}
+
+// compiledCategoryRules are the stored rules ready to be matched
+type compiledCategoryRules struct {
+	exact    map[string]string
+	prefixes []*models.CategoryRule
+	regexes  []compiledRegexRule
+}
+
+type compiledRegexRule struct {
+	regex    *regexp.Regexp
+	category string
+}
+
+// CategoryRuleResolver resolves categories with the rules stored in the database. A denomination is
+// matched by an exact rule first, then by the longest matching prefix and then by the first regex created.
+// Rules are cached and reloaded once they are older than the ttl, so every process sees changes
+// made by the others. While no rule is stored the fallback resolver is used.
+type CategoryRuleResolver struct {
+	repository repositories.CategoryRuleRepository
+	fallback   CategoryResolverI
+	ttl        time.Duration
+
+	mu       sync.RWMutex
+	rules    *compiledCategoryRules
+	loadedAt time.Time
+}
+
+func NewCategoryRuleResolver(repository repositories.CategoryRuleRepository, fallback CategoryResolverI, ttl time.Duration) *CategoryRuleResolver {
+	if ttl <= 0 {
+		ttl = defaultCategoryRulesTTL
+	}
+	return &CategoryRuleResolver{
+		repository: repository,
+		fallback:   fallback,
+		ttl:        ttl,
+	}
+}
+
+// Resolve returns the category of the denomination, or the unclassified category when no rule matches
+func (r *CategoryRuleResolver) Resolve(categoryKey string) string {
+	r.mu.RLock()
+	// loadedAt is zero until the first load, so the rules start stale
+	rules, stale := r.rules, time.Since(r.loadedAt) > r.ttl
+	r.mu.RUnlock()
+
+	if stale {
+		ctx, cancel := context.WithTimeout(context.Background(), categoryRulesLoadTimeout)
+		defer cancel()
+		if err := r.Reload(ctx); err != nil {
+			// Keep resolving with the rules already loaded, if any, and retry once the ttl passes again
+			utils.LoggerFromContext(ctx).Warnf("Error loading category rules: %v", err)
+			r.mu.Lock()
+			r.loadedAt = time.Now()
+			r.mu.Unlock()
+		}
+		r.mu.RLock()
+		rules = r.rules
+		r.mu.RUnlock()
+	}
+
+	if rules == nil || (len(rules.exact) == 0 && len(rules.prefixes) == 0 && len(rules.regexes) == 0) {
+		if r.fallback != nil {
+			return r.fallback.Resolve(categoryKey)
+		}
+		return models.UnclassifiedCategory
+	}
+	return rules.match(categoryKey)
+}
+
+// Reload loads the stored rules, replacing the cached ones
+func (r *CategoryRuleResolver) Reload(ctx context.Context) error {
+	stored, err := r.repository.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	rules := compileCategoryRules(stored)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules = rules
+	r.loadedAt = time.Now()
+	return nil
+}
+
+func compileCategoryRules(stored []*models.CategoryRule) *compiledCategoryRules {
+	rules := &compiledCategoryRules{exact: make(map[string]string)}
+	for _, rule := range stored {
+		switch rule.MatchType {
+		case models.CategoryRuleMatchExact:
+			rules.exact[rule.Pattern] = rule.CategoryName
+		case models.CategoryRuleMatchPrefix:
+			rules.prefixes = append(rules.prefixes, rule)
+		case models.CategoryRuleMatchRegex:
+			// Patterns are validated when stored, an invalid one can only come from a manual edit
+			regex, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				continue
+			}
+			rules.regexes = append(rules.regexes, compiledRegexRule{regex: regex, category: rule.CategoryName})
+		}
+	}
+	sort.SliceStable(rules.prefixes, func(i, j int) bool {
+		return len(rules.prefixes[i].Pattern) > len(rules.prefixes[j].Pattern)
+	})
+	return rules
+}
+
+func (rules *compiledCategoryRules) match(categoryKey string) string {
+	if category, exists := rules.exact[categoryKey]; exists {
+		return category
+	}
+	for _, rule := range rules.prefixes {
+		if strings.HasPrefix(categoryKey, rule.Pattern) {
+			return rule.CategoryName
+		}
+	}
+	for _, rule := range rules.regexes {
+		if rule.regex.MatchString(categoryKey) {
+			return rule.category
+		}
+	}
+	return models.UnclassifiedCategory
+}
@@ -0,0 +1,404 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/schemas"
+	"server/src/utils"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type CategoryServiceI interface {
+	GetCategories(ctx context.Context) ([]*schemas.CategoryResponse, error)
+	CreateCategory(ctx context.Context, req *schemas.CategoryRequest) (*schemas.CategoryResponse, error)
+	UpdateCategory(ctx context.Context, id int, req *schemas.CategoryRequest) (*schemas.CategoryResponse, error)
+	DeleteCategory(ctx context.Context, id int) error
+	GetRules(ctx context.Context) ([]*schemas.CategoryRuleResponse, error)
+	CreateRule(ctx context.Context, req *schemas.CategoryRuleRequest) (*schemas.CategoryRuleResponse, error)
+	UpdateRule(ctx context.Context, id int, req *schemas.CategoryRuleRequest) (*schemas.CategoryRuleResponse, error)
+	DeleteRule(ctx context.Context, id int) error
+	GetUnclassifiedAssets(ctx context.Context) ([]*schemas.AssetCategoryResponse, error)
+	SetAssetCategory(ctx context.Context, assetID int, req *schemas.AssetCategoryRequest) (*schemas.AssetCategoryResponse, error)
+	SeedRules(ctx context.Context, categoryMap map[string]string) (int, error)
+}
+
+// CategoryService manages the asset categories and the rules assigning them. Stored assets are
+// reclassified whenever the rules change, so reports reflect them without a re-sync.
+type CategoryService struct {
+	categoryRepository repositories.AssetCategoryRepository
+	ruleRepository     repositories.CategoryRuleRepository
+	assetRepository    repositories.AssetRepository
+	resolver           *CategoryRuleResolver
+}
+
+func NewCategoryService(
+	categoryRepository repositories.AssetCategoryRepository,
+	ruleRepository repositories.CategoryRuleRepository,
+	assetRepository repositories.AssetRepository,
+	resolver *CategoryRuleResolver,
+) *CategoryService {
+	return &CategoryService{
+		categoryRepository: categoryRepository,
+		ruleRepository:     ruleRepository,
+		assetRepository:    assetRepository,
+		resolver:           resolver,
+	}
+}
+
+func (s *CategoryService) GetCategories(ctx context.Context) ([]*schemas.CategoryResponse, error) {
+	categories, err := s.categoryRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	response := make([]*schemas.CategoryResponse, 0, len(categories))
+	for i := range categories {
+		response = append(response, categoryToResponse(&categories[i]))
+	}
+	return response, nil
+}
+
+func (s *CategoryService) CreateCategory(ctx context.Context, req *schemas.CategoryRequest) (*schemas.CategoryResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, utils.BadRequest("name is required")
+	}
+	existing, err := s.categoryRepository.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, utils.NewHTTPError(http.StatusConflict, fmt.Sprintf("category %s already exists", name))
+	}
+	category := &models.AssetCategory{Name: name, Description: req.Description}
+	if err := s.categoryRepository.Create(ctx, category, nil); err != nil {
+		return nil, err
+	}
+	return categoryToResponse(category), nil
+}
+
+func (s *CategoryService) UpdateCategory(ctx context.Context, id int, req *schemas.CategoryRequest) (*schemas.CategoryResponse, error) {
+	category, err := s.getCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, utils.BadRequest("name is required")
+	}
+	if category.Name == models.UnclassifiedCategory && name != category.Name {
+		return nil, utils.BadRequest("the unclassified category cannot be renamed")
+	}
+	if existing, err := s.categoryRepository.GetByName(ctx, name); err != nil {
+		return nil, err
+	} else if existing != nil && existing.ID != id {
+		return nil, utils.NewHTTPError(http.StatusConflict, fmt.Sprintf("category %s already exists", name))
+	}
+
+	category.Name = name
+	category.Description = req.Description
+	if err := s.categoryRepository.Update(ctx, category); err != nil {
+		return nil, err
+	}
+	// Rules carry the category name
+	if err := s.resolver.Reload(ctx); err != nil {
+		return nil, err
+	}
+	return categoryToResponse(category), nil
+}
+
+// DeleteCategory deletes a category without assets, along with its rules
+func (s *CategoryService) DeleteCategory(ctx context.Context, id int) error {
+	category, err := s.getCategory(ctx, id)
+	if err != nil {
+		return err
+	}
+	if category.Name == models.UnclassifiedCategory {
+		return utils.BadRequest("the unclassified category cannot be deleted")
+	}
+	assets, err := s.assetRepository.CountByCategoryID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if assets > 0 {
+		return utils.NewHTTPError(http.StatusConflict, fmt.Sprintf("category %s still has %d assets", category.Name, assets))
+	}
+	if err := s.categoryRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.reclassifyAssets(ctx)
+}
+
+func (s *CategoryService) GetRules(ctx context.Context) ([]*schemas.CategoryRuleResponse, error) {
+	rules, err := s.ruleRepository.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	response := make([]*schemas.CategoryRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		response = append(response, categoryRuleToResponse(rule))
+	}
+	return response, nil
+}
+
+func (s *CategoryService) CreateRule(ctx context.Context, req *schemas.CategoryRuleRequest) (*schemas.CategoryRuleResponse, error) {
+	rule := &models.CategoryRule{}
+	if err := s.applyRuleRequest(ctx, rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.ruleRepository.Create(ctx, rule, nil); err != nil {
+		return nil, err
+	}
+	if err := s.reclassifyAssets(ctx); err != nil {
+		return nil, err
+	}
+	return categoryRuleToResponse(rule), nil
+}
+
+func (s *CategoryService) UpdateRule(ctx context.Context, id int, req *schemas.CategoryRuleRequest) (*schemas.CategoryRuleResponse, error) {
+	rule, err := s.ruleRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, utils.NotFound(fmt.Sprintf("category rule %d not found", id))
+	}
+	if err := s.applyRuleRequest(ctx, rule, req); err != nil {
+		return nil, err
+	}
+	if err := s.ruleRepository.Update(ctx, rule); err != nil {
+		return nil, err
+	}
+	if err := s.reclassifyAssets(ctx); err != nil {
+		return nil, err
+	}
+	return categoryRuleToResponse(rule), nil
+}
+
+func (s *CategoryService) DeleteRule(ctx context.Context, id int) error {
+	rule, err := s.ruleRepository.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if rule == nil {
+		return utils.NotFound(fmt.Sprintf("category rule %d not found", id))
+	}
+	if err := s.ruleRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.reclassifyAssets(ctx)
+}
+
+// GetUnclassifiedAssets returns every stored asset in the unclassified category, with the
+// denomination a rule has to match to classify it
+func (s *CategoryService) GetUnclassifiedAssets(ctx context.Context) ([]*schemas.AssetCategoryResponse, error) {
+	assets, err := s.assetRepository.GetByCategoryName(ctx, models.UnclassifiedCategory)
+	if err != nil {
+		return nil, err
+	}
+	response := make([]*schemas.AssetCategoryResponse, 0, len(assets))
+	for i := range assets {
+		response = append(response, assetCategoryToResponse(&assets[i]))
+	}
+	return response, nil
+}
+
+// SetAssetCategory assigns the category of an asset by hand, locking it so syncs keep it.
+// Without a category the asset is unlocked and classified by the rules again.
+func (s *CategoryService) SetAssetCategory(ctx context.Context, assetID int, req *schemas.AssetCategoryRequest) (*schemas.AssetCategoryResponse, error) {
+	asset, err := s.assetRepository.GetByID(ctx, assetID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, utils.NotFound(fmt.Sprintf("asset %d not found", assetID))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var category *models.AssetCategory
+	locked := req.CategoryID != nil
+	if locked {
+		category, err = s.categoryRepository.GetByID(ctx, *req.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		if category == nil {
+			return nil, utils.BadRequest(fmt.Sprintf("category %d does not exist", *req.CategoryID))
+		}
+	} else {
+		category, err = s.getOrCreateCategory(ctx, s.resolver.Resolve(asset.CategoryKey))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := s.assetRepository.SetCategory(ctx, asset.ID, category.ID, locked); err != nil {
+		return nil, err
+	}
+
+	return &schemas.AssetCategoryResponse{
+		ID:             asset.ID,
+		ExternalID:     asset.ExternalID,
+		Name:           asset.Name,
+		AssetType:      asset.AssetType,
+		CategoryKey:    asset.CategoryKey,
+		CategoryID:     category.ID,
+		Category:       category.Name,
+		CategoryLocked: locked,
+	}, nil
+}
+
+// SeedRules stores an exact rule for every denomination of the map, creating the categories
+// missing. It is used to move the static denominaciones.csv into the database.
+func (s *CategoryService) SeedRules(ctx context.Context, categoryMap map[string]string) (int, error) {
+	seeded := 0
+	for denomination, categoryName := range categoryMap {
+		if denomination == "" || categoryName == "" {
+			continue
+		}
+		category, err := s.getOrCreateCategory(ctx, categoryName)
+		if err != nil {
+			return seeded, err
+		}
+		rule := &models.CategoryRule{
+			CategoryID: category.ID,
+			MatchType:  models.CategoryRuleMatchExact,
+			Pattern:    denomination,
+		}
+		if err := s.ruleRepository.Create(ctx, rule, nil); err != nil {
+			return seeded, fmt.Errorf("error creating rule for %s: %w", denomination, err)
+		}
+		seeded++
+	}
+	return seeded, s.reclassifyAssets(ctx)
+}
+
+// reclassifyAssets reloads the rules and applies them to the stored assets whose category
+// was not assigned by hand
+func (s *CategoryService) reclassifyAssets(ctx context.Context) error {
+	logger := utils.LoggerFromContext(ctx)
+	if err := s.resolver.Reload(ctx); err != nil {
+		return fmt.Errorf("error loading category rules: %w", err)
+	}
+	assets, err := s.assetRepository.GetWithCategories(ctx)
+	if err != nil {
+		return err
+	}
+
+	reclassified := 0
+	for _, asset := range assets {
+		if asset.CategoryLocked || asset.CategoryKey == "" {
+			continue
+		}
+		categoryName := s.resolver.Resolve(asset.CategoryKey)
+		if categoryName == asset.CategoryName {
+			continue
+		}
+		category, err := s.getOrCreateCategory(ctx, categoryName)
+		if err != nil {
+			return err
+		}
+		if err := s.assetRepository.SetCategory(ctx, asset.ID, category.ID, false); err != nil {
+			return fmt.Errorf("error reclassifying asset %s: %w", asset.ExternalID, err)
+		}
+		reclassified++
+	}
+	logger.Infof("Reclassified %d assets", reclassified)
+	return nil
+}
+
+// applyRuleRequest validates the request and sets it on the rule
+func (s *CategoryService) applyRuleRequest(ctx context.Context, rule *models.CategoryRule, req *schemas.CategoryRuleRequest) error {
+	if req.Pattern == "" {
+		return utils.BadRequest("pattern is required")
+	}
+	switch req.MatchType {
+	case models.CategoryRuleMatchExact, models.CategoryRuleMatchPrefix:
+	case models.CategoryRuleMatchRegex:
+		if _, err := regexp.Compile(req.Pattern); err != nil {
+			return utils.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("invalid regex pattern: %v", err))
+		}
+	default:
+		return utils.BadRequest(fmt.Sprintf("matchType must be one of %s, %s or %s",
+			models.CategoryRuleMatchExact, models.CategoryRuleMatchPrefix, models.CategoryRuleMatchRegex))
+	}
+	category, err := s.categoryRepository.GetByID(ctx, req.CategoryID)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return utils.BadRequest(fmt.Sprintf("category %d does not exist", req.CategoryID))
+	}
+
+	rule.CategoryID = category.ID
+	rule.CategoryName = category.Name
+	rule.MatchType = req.MatchType
+	rule.Pattern = req.Pattern
+	return nil
+}
+
+func (s *CategoryService) getCategory(ctx context.Context, id int) (*models.AssetCategory, error) {
+	category, err := s.categoryRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, utils.NotFound(fmt.Sprintf("category %d not found", id))
+	}
+	return category, nil
+}
+
+func (s *CategoryService) getOrCreateCategory(ctx context.Context, name string) (*models.AssetCategory, error) {
+	category, err := s.categoryRepository.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if category != nil {
+		return category, nil
+	}
+	category = &models.AssetCategory{Name: name}
+	if err := s.categoryRepository.Create(ctx, category, nil); err != nil {
+		return nil, fmt.Errorf("error creating asset category: %w", err)
+	}
+	return category, nil
+}
+
+func categoryToResponse(category *models.AssetCategory) *schemas.CategoryResponse {
+	return &schemas.CategoryResponse{
+		ID:          category.ID,
+		Name:        category.Name,
+		Description: category.Description,
+	}
+}
+
+func categoryRuleToResponse(rule *models.CategoryRule) *schemas.CategoryRuleResponse {
+	return &schemas.CategoryRuleResponse{
+		ID:         rule.ID,
+		CategoryID: rule.CategoryID,
+		Category:   rule.CategoryName,
+		MatchType:  rule.MatchType,
+		Pattern:    rule.Pattern,
+		CreatedAt:  rule.CreatedAt,
+	}
+}
+
+func assetCategoryToResponse(asset *models.AssetWithCategory) *schemas.AssetCategoryResponse {
+	category := asset.CategoryName
+	if category == "" {
+		category = models.UnclassifiedCategory
+	}
+	return &schemas.AssetCategoryResponse{
+		ID:             asset.ID,
+		ExternalID:     asset.ExternalID,
+		Name:           asset.Name,
+		AssetType:      asset.AssetType,
+		CategoryKey:    asset.CategoryKey,
+		CategoryID:     asset.CategoryID,
+		Category:       category,
+		CategoryLocked: asset.CategoryLocked,
+	}
+}
@@ -0,0 +1,406 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/schemas"
+	"server/src/utils"
+	"sort"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Methods the cost of the units sold is measured with
+const (
+	// CostBasisMethodFIFO sells the units of the oldest purchases first, at what each of them cost
+	CostBasisMethodFIFO = "fifo"
+	// CostBasisMethodAverage sells the units at the average cost of all the units held
+	CostBasisMethodAverage = "average"
+)
+
+const (
+	pnlSheetName   = "Resultados"
+	salesSheetName = "Ventas"
+	// closedLotUnits is the remainder under which a lot is considered sold, to absorb rounding
+	closedLotUnits = 1e-9
+)
+
+// IsValidCostBasisMethod reports whether method is one of the cost basis methods
+func IsValidCostBasisMethod(method string) bool {
+	return method == CostBasisMethodFIFO || method == CostBasisMethodAverage
+}
+
+// costBasisTransactionTypes are the transactions that buy or sell units. Subscriptions and redemptions
+// of funds buy and sell their shares.
+var costBasisTransactionTypes = []string{
+	models.TransactionTypeBuy,
+	models.TransactionTypeSell,
+	models.TransactionTypeSubscription,
+	models.TransactionTypeRedemption,
+}
+
+type CostBasisServiceI interface {
+	GetPnL(ctx context.Context, clientIDs []string, date time.Time, method string) (*schemas.PnLReport, error)
+	GenerateXLSXPnL(ctx context.Context, report *schemas.PnLReport) (*excelize.File, error)
+}
+
+// CostBasisService measures what the positions of the clients cost from their stored purchases and sales,
+// and the profit or loss of the units sold and still held
+type CostBasisService struct {
+	holdingRepo     repositories.HoldingRepository
+	transactionRepo repositories.TransactionRepository
+	assetRepo       repositories.AssetRepository
+}
+
+func NewCostBasisService(
+	holdingRepo repositories.HoldingRepository,
+	transactionRepo repositories.TransactionRepository,
+	assetRepo repositories.AssetRepository,
+) *CostBasisService {
+	return &CostBasisService{
+		holdingRepo:     holdingRepo,
+		transactionRepo: transactionRepo,
+		assetRepo:       assetRepo,
+	}
+}
+
+// costBasisPosition tracks the units of an asset an account bought and not sold yet
+type costBasisPosition struct {
+	pnl  *schemas.AssetPnL
+	cost float64
+}
+
+// GetPnL replays the purchases and sales of every asset of the clients up to date. Purchases open lots
+// at their cost, fees included, and sales close the units of the lots and realize the difference between
+// what they were sold for and their cost under the method. The units still held are valued with the
+// last holding of the asset. Assets never bought are left out, as their cost is unknown.
+func (s *CostBasisService) GetPnL(ctx context.Context, clientIDs []string, date time.Time, method string) (*schemas.PnLReport, error) {
+	logger := utils.LoggerFromContext(ctx)
+	logger.Infof("Calculating %s cost basis for accounts %v up to %s", method, clientIDs, date)
+
+	if !IsValidCostBasisMethod(method) {
+		return nil, utils.BadRequest(fmt.Sprintf("invalid method %q, expected one of %s, %s", method, CostBasisMethodFIFO, CostBasisMethodAverage))
+	}
+
+	transactions, err := s.transactionRepo.GetByClientIDs(ctx, clientIDs, time.Time{}, date, costBasisTransactionTypes)
+	if err != nil {
+		return nil, fmt.Errorf("error getting transactions: %w", err)
+	}
+	holdings, err := s.holdingRepo.GetLatestByClientIDs(ctx, clientIDs, date)
+	if err != nil {
+		return nil, fmt.Errorf("error getting holdings: %w", err)
+	}
+
+	// Purchases go before the sales of the same date, so units bought and sold within a day have a cost
+	sort.SliceStable(transactions, func(i, j int) bool {
+		a, b := transactions[i], transactions[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		if isPurchase(a) != isPurchase(b) {
+			return isPurchase(a)
+		}
+		return a.ID < b.ID
+	})
+
+	positions := make(map[string]*costBasisPosition)
+	var keys []string
+	for _, transaction := range transactions {
+		if transaction.Units == 0 {
+			continue
+		}
+		key := fmt.Sprintf("%s/%d", transaction.ClientID, transaction.AssetID)
+		position, exists := positions[key]
+		if !exists {
+			position = &costBasisPosition{pnl: &schemas.AssetPnL{
+				AccountID: transaction.ClientID,
+				AssetID:   transaction.AssetID,
+				OpenLots:  make([]schemas.CostLot, 0),
+				Sales:     make([]schemas.RealizedSale, 0),
+			}}
+			positions[key] = position
+			keys = append(keys, key)
+		}
+		units, amount := math.Abs(transaction.Units), transactionAmount(transaction)
+		transactionDate := schemas.Date{Time: truncateToDate(transaction.Date)}
+		if isPurchase(transaction) {
+			position.buy(transactionDate, units, amount)
+		} else {
+			position.sell(transactionDate, units, amount, method)
+		}
+	}
+
+	holdingsByKey := make(map[string]models.Holding, len(holdings))
+	for _, holding := range holdings {
+		holdingsByKey[fmt.Sprintf("%s/%d", holding.ClientID, holding.AssetID)] = holding
+	}
+
+	report := &schemas.PnLReport{
+		AccountIDs: clientIDs,
+		Date:       schemas.Date{Time: truncateToDate(date)},
+		Method:     method,
+		Assets:     make([]schemas.AssetPnL, 0, len(keys)),
+	}
+	for _, key := range keys {
+		position := positions[key]
+		pnl := position.pnl
+		pnl.CostBasis = position.cost
+		if pnl.Units > closedLotUnits {
+			pnl.AverageCost = position.cost / pnl.Units
+		} else {
+			pnl.Units, pnl.CostBasis = 0, 0
+		}
+		if holding, exists := holdingsByKey[key]; exists && pnl.Units > 0 {
+			// Manual assets are only valued as a whole
+			pnl.MarketValue = holding.Value
+			if holding.Units != 0 {
+				pnl.MarketValue = pnl.Units * holding.Value / holding.Units
+			}
+			pnl.ValuationDate = &schemas.Date{Time: truncateToDate(holding.Date)}
+			pnl.UnrealizedPnL = pnl.MarketValue - pnl.CostBasis
+			if pnl.CostBasis > 0 {
+				pnl.UnrealizedPnLPercentage = pnl.UnrealizedPnL / pnl.CostBasis * 100
+			}
+		}
+		report.Assets = append(report.Assets, *pnl)
+	}
+
+	if err = s.setAssetDetails(ctx, report.Assets); err != nil {
+		return nil, err
+	}
+	sort.Slice(report.Assets, func(i, j int) bool {
+		a, b := report.Assets[i], report.Assets[j]
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		return a.ExternalID < b.ExternalID
+	})
+	report.Totals = totalPnLByCurrency(report.Assets)
+	return report, nil
+}
+
+func (p *costBasisPosition) buy(date schemas.Date, units, amount float64) {
+	p.pnl.OpenLots = append(p.pnl.OpenLots, schemas.CostLot{
+		Date:     date,
+		Units:    units,
+		UnitCost: amount / units,
+		Cost:     amount,
+	})
+	p.pnl.Units += units
+	p.cost += amount
+}
+
+// sell closes the units sold from the oldest lots. Under the average method the cost of the units sold
+// is their share of the cost of the position, so the lots only tell which purchases are still held.
+func (p *costBasisPosition) sell(date schemas.Date, units, proceeds float64, method string) {
+	matched := math.Min(units, p.pnl.Units)
+	averageCost := 0.0
+	if p.pnl.Units > 0 {
+		averageCost = p.cost / p.pnl.Units
+	}
+
+	var fifoCost float64
+	remaining := matched
+	lots := p.pnl.OpenLots[:0]
+	for _, lot := range p.pnl.OpenLots {
+		if remaining > 0 {
+			sold := math.Min(remaining, lot.Units)
+			fifoCost += sold * lot.UnitCost
+			remaining -= sold
+			lot.Units -= sold
+			lot.Cost = lot.Units * lot.UnitCost
+		}
+		if lot.Units > closedLotUnits {
+			lots = append(lots, lot)
+		}
+	}
+	p.pnl.OpenLots = lots
+
+	cost := fifoCost
+	if method == CostBasisMethodAverage {
+		cost = averageCost * matched
+	}
+	sale := schemas.RealizedSale{
+		Date:           date,
+		Units:          units,
+		Proceeds:       proceeds,
+		Cost:           cost,
+		UnmatchedUnits: units - matched,
+	}
+	if matched > 0 {
+		sale.RealizedPnL = proceeds*matched/units - cost
+	}
+	p.pnl.Sales = append(p.pnl.Sales, sale)
+	p.pnl.RealizedPnL += sale.RealizedPnL
+	p.pnl.Units -= matched
+	p.cost -= cost
+}
+
+func isPurchase(transaction models.Transaction) bool {
+	return transaction.TransactionType == models.TransactionTypeBuy || transaction.TransactionType == models.TransactionTypeSubscription
+}
+
+// transactionAmount is the money paid or received for the units, net of fees, valued at the price per
+// unit when the transaction has no value
+func transactionAmount(transaction models.Transaction) float64 {
+	if transaction.TotalValue != 0 {
+		return math.Abs(transaction.TotalValue)
+	}
+	if transaction.GrossValue != 0 {
+		return math.Abs(transaction.GrossValue)
+	}
+	return math.Abs(transaction.Units * transaction.PricePerUnit)
+}
+
+func totalPnLByCurrency(assets []schemas.AssetPnL) []schemas.PnLTotal {
+	totals := make([]schemas.PnLTotal, 0)
+	indexes := make(map[string]int)
+	for _, asset := range assets {
+		index, exists := indexes[asset.Currency]
+		if !exists {
+			index = len(totals)
+			indexes[asset.Currency] = index
+			totals = append(totals, schemas.PnLTotal{Currency: asset.Currency})
+		}
+		totals[index].CostBasis += asset.CostBasis
+		totals[index].MarketValue += asset.MarketValue
+		totals[index].UnrealizedPnL += asset.UnrealizedPnL
+		totals[index].RealizedPnL += asset.RealizedPnL
+	}
+	sort.Slice(totals, func(i, j int) bool { return totals[i].Currency < totals[j].Currency })
+	return totals
+}
+
+func (s *CostBasisService) setAssetDetails(ctx context.Context, assetPnLs []schemas.AssetPnL) error {
+	if len(assetPnLs) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(assetPnLs))
+	for _, assetPnL := range assetPnLs {
+		ids = append(ids, assetPnL.AssetID)
+	}
+	assets, err := s.assetRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("error getting assets: %w", err)
+	}
+	assetsByID := make(map[int]models.Asset, len(assets))
+	for _, asset := range assets {
+		assetsByID[asset.ID] = asset
+	}
+	for i := range assetPnLs {
+		asset := assetsByID[assetPnLs[i].AssetID]
+		assetPnLs[i].ExternalID = asset.ExternalID
+		assetPnLs[i].AssetName = asset.Name
+		assetPnLs[i].Currency = asset.Currency
+		if asset.IsManual() {
+			// Manual valuations and cash flows are entered in pesos whatever the asset currency
+			assetPnLs[i].Currency = utils.AssetCurrencyPesos
+		}
+	}
+	return nil
+}
+
+// GenerateXLSXPnL writes the positions with their totals by currency into a sheet, and the sales
+// with the profit or loss they realized into another one
+func (s *CostBasisService) GenerateXLSXPnL(_ context.Context, report *schemas.PnLReport) (*excelize.File, error) {
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", pnlSheetName); err != nil {
+		return nil, err
+	}
+	if _, err := f.NewSheet(salesSheetName); err != nil {
+		return nil, err
+	}
+
+	headers := []interface{}{
+		"Cuenta", "Activo", "Denominacion", "Moneda", "Cantidad", "Costo Promedio", "Costo Total",
+		"Valor de Mercado", "Fecha Valuacion", "Resultado No Realizado", "Resultado No Realizado %", "Resultado Realizado",
+	}
+	if err := f.SetSheetRow(pnlSheetName, "A1", &headers); err != nil {
+		return nil, err
+	}
+	row := 2
+	for _, asset := range report.Assets {
+		valuationDate := ""
+		if asset.ValuationDate != nil {
+			valuationDate = asset.ValuationDate.Format(utils.ShortDashDateLayout)
+		}
+		values := []interface{}{
+			asset.AccountID,
+			asset.ExternalID,
+			asset.AssetName,
+			asset.Currency,
+			asset.Units,
+			asset.AverageCost,
+			asset.CostBasis,
+			asset.MarketValue,
+			valuationDate,
+			asset.UnrealizedPnL,
+			asset.UnrealizedPnLPercentage,
+			asset.RealizedPnL,
+		}
+		if err := f.SetSheetRow(pnlSheetName, fmt.Sprintf("A%d", row), &values); err != nil {
+			return nil, err
+		}
+		row++
+	}
+	// Totals by currency, after a blank row
+	row++
+	for _, total := range report.Totals {
+		values := []interface{}{
+			"TOTAL", "", "", total.Currency, "", "", total.CostBasis, total.MarketValue, "", total.UnrealizedPnL, "", total.RealizedPnL,
+		}
+		if err := f.SetSheetRow(pnlSheetName, fmt.Sprintf("A%d", row), &values); err != nil {
+			return nil, err
+		}
+		row++
+	}
+
+	salesHeaders := []interface{}{
+		"Cuenta", "Activo", "Denominacion", "Fecha", "Cantidad", "Monto", "Costo", "Resultado Realizado", "Cantidad Sin Costo",
+	}
+	if err := f.SetSheetRow(salesSheetName, "A1", &salesHeaders); err != nil {
+		return nil, err
+	}
+	row = 2
+	for _, asset := range report.Assets {
+		for _, sale := range asset.Sales {
+			values := []interface{}{
+				asset.AccountID,
+				asset.ExternalID,
+				asset.AssetName,
+				sale.Date.Format(utils.ShortDashDateLayout),
+				sale.Units,
+				sale.Proceeds,
+				sale.Cost,
+				sale.RealizedPnL,
+				sale.UnmatchedUnits,
+			}
+			if err := f.SetSheetRow(salesSheetName, fmt.Sprintf("A%d", row), &values); err != nil {
+				return nil, err
+			}
+			row++
+		}
+	}
+
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+	if err = f.SetCellStyle(pnlSheetName, "A1", "L1", headerStyle); err != nil {
+		return nil, err
+	}
+	if err = f.SetColWidth(pnlSheetName, "A", "L", 18); err != nil {
+		return nil, err
+	}
+	if err = f.SetCellStyle(salesSheetName, "A1", "I1", headerStyle); err != nil {
+		return nil, err
+	}
+	if err = f.SetColWidth(salesSheetName, "A", "I", 18); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
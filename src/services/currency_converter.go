@@ -0,0 +1,119 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"server/src/schemas"
+	"server/src/utils"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Currencies a report can be expressed in
+const (
+	ReportCurrencyARS = "ARS"
+	// ReportCurrencyUSD converts with the official wholesale dollar (BCRA Comunicación A3500)
+	ReportCurrencyUSD = "USD"
+	ReportCurrencyMEP = "MEP"
+	ReportCurrencyCCL = "CCL"
+)
+
+// ReportCurrencies lists every currency a report can be expressed in
+var ReportCurrencies = []string{ReportCurrencyARS, ReportCurrencyUSD, ReportCurrencyMEP, ReportCurrencyCCL}
+
+// IsValidReportCurrency reports whether currency is one of the ReportCurrencies
+func IsValidReportCurrency(currency string) bool {
+	for _, reportCurrency := range ReportCurrencies {
+		if currency == reportCurrency {
+			return true
+		}
+	}
+	return false
+}
+
+// CurrencyConverter converts values between pesos and dollars with the rate of their date. Rates are the
+// pesos a dollar is worth: of the target dollar when converting to dollars, and of the official one when
+// converting the dollar assets of a report in pesos.
+type CurrencyConverter struct {
+	currency string
+	dates    []time.Time
+	rates    []float64
+}
+
+// NewCurrencyConverter returns a converter to currency with the given rates. Reports in dollars
+// cannot be converted without rates.
+func NewCurrencyConverter(currency string, rates []schemas.VariableValuation) (*CurrencyConverter, error) {
+	if !IsValidReportCurrency(currency) {
+		return nil, utils.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("invalid currency %q, expected one of %s", currency, strings.Join(ReportCurrencies, ", ")))
+	}
+	sortedRates := make([]schemas.VariableValuation, 0, len(rates))
+	for _, rate := range rates {
+		if rate.Value > 0 {
+			sortedRates = append(sortedRates, rate)
+		}
+	}
+	sort.Slice(sortedRates, func(i, j int) bool {
+		return sortedRates[i].Date < sortedRates[j].Date
+	})
+
+	converter := &CurrencyConverter{currency: currency}
+	for _, rate := range sortedRates {
+		date, err := time.Parse("2006-01-02", rate.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q of %s exchange rate: %w", rate.Date, currency, err)
+		}
+		converter.dates = append(converter.dates, date)
+		converter.rates = append(converter.rates, rate.Value)
+	}
+	if currency != ReportCurrencyARS && len(converter.rates) == 0 {
+		return nil, utils.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("no %s exchange rates to convert the report with", currency))
+	}
+	return converter, nil
+}
+
+// Currency returns the currency the converter converts to
+func (c *CurrencyConverter) Currency() string {
+	return c.currency
+}
+
+// CurrencyName returns the name values are labeled with once converted, as assets name their currency
+func (c *CurrencyConverter) CurrencyName() string {
+	if c.currency == ReportCurrencyARS {
+		return utils.AssetCurrencyPesos
+	}
+	return utils.AssetCurrencyDolares
+}
+
+// Convert converts a value of an asset in fromCurrency to the converter currency with the rate of date
+func (c *CurrencyConverter) Convert(value float64, fromCurrency string, date time.Time) float64 {
+	fromDollars := fromCurrency == utils.AssetCurrencyDolares
+	toDollars := c.currency != ReportCurrencyARS
+	if value == 0 || fromDollars == toDollars {
+		return value
+	}
+	rate, found := c.rateAt(date)
+	if !found {
+		return value
+	}
+	if toDollars {
+		return value / rate
+	}
+	return value * rate
+}
+
+// rateAt returns the rate of the date, the last one before it when the date has none
+// (weekends and holidays), or the first one when the date is before every rate
+func (c *CurrencyConverter) rateAt(date time.Time) (float64, bool) {
+	if len(c.rates) == 0 {
+		return 0, false
+	}
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	i := sort.Search(len(c.dates), func(i int) bool {
+		return c.dates[i].After(day)
+	})
+	if i == 0 {
+		return c.rates[0], true
+	}
+	return c.rates[i-1], true
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"math"
+	"server/src/schemas"
+	"sort"
+	"time"
+)
+
+// dailyIndex holds the level of a series on every day between two dates, 1 on the first one, so the
+// performance of the series can be measured over any range of days
+type dailyIndex struct {
+	firstDate time.Time
+	levels    []float64
+}
+
+// newMonthlyRateIndex compounds a series of monthly rates, as percentages (like the monthly inflation),
+// spreading the rate of each month evenly over its days. The rate of a month is the last value dated
+// within it, and months not yet published take the rate of the closest month that is.
+// It returns nil when there are no rates.
+func newMonthlyRateIndex(monthlyRates []schemas.VariableValuation, firstDate, lastDate time.Time) *dailyIndex {
+	ratesByMonth := make(map[string]float64)
+	var months []string
+	for _, valuation := range sortValuations(monthlyRates) {
+		date, err := time.Parse("2006-01-02", valuation.Date)
+		if err != nil {
+			continue
+		}
+		month := date.Format("2006-01")
+		if _, exists := ratesByMonth[month]; !exists {
+			months = append(months, month)
+		}
+		ratesByMonth[month] = valuation.Value
+	}
+	if len(months) == 0 {
+		return nil
+	}
+	monthRate := func(month string) float64 {
+		if rate, exists := ratesByMonth[month]; exists {
+			return rate
+		}
+		i := sort.SearchStrings(months, month)
+		if i == 0 {
+			return ratesByMonth[months[0]]
+		}
+		return ratesByMonth[months[i-1]]
+	}
+
+	return buildDailyIndex(firstDate, lastDate, func(date time.Time) float64 {
+		daysInMonth := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		return math.Pow(1+monthRate(date.Format("2006-01"))/100, 1/float64(daysInMonth))
+	})
+}
+
+// newAnnualRateIndex accrues a series of nominal annual rates, as percentages (like the rate of fixed-term
+// deposits), daily over a 365 days year. Days without rate accrue the last one.
+// It returns nil when there are no rates.
+func newAnnualRateIndex(annualRates []schemas.VariableValuation, firstDate, lastDate time.Time) *dailyIndex {
+	rateAt := valuationAtDate(annualRates)
+	if rateAt == nil {
+		return nil
+	}
+	return buildDailyIndex(firstDate, lastDate, func(date time.Time) float64 {
+		return 1 + rateAt(date)/100/365
+	})
+}
+
+// newLevelIndex follows a series of levels (like a price or an index), carrying the last level to the
+// days without one. It returns nil when there are no levels.
+func newLevelIndex(levels []schemas.VariableValuation, firstDate, lastDate time.Time) *dailyIndex {
+	levelAt := valuationAtDate(levels)
+	if levelAt == nil {
+		return nil
+	}
+	first := truncateToDate(firstDate)
+	firstLevel := levelAt(first)
+	return buildDailyIndex(firstDate, lastDate, func(date time.Time) float64 {
+		return levelAt(date) / levelAt(date.AddDate(0, 0, -1))
+	}).rebase(firstLevel)
+}
+
+// buildDailyIndex builds the index between firstDate and lastDate multiplying the level of each day
+// by the growth of the day
+func buildDailyIndex(firstDate, lastDate time.Time, dailyGrowth func(date time.Time) float64) *dailyIndex {
+	first := truncateToDate(firstDate)
+	last := truncateToDate(lastDate)
+	index := &dailyIndex{firstDate: first, levels: []float64{1}}
+	for date := first.AddDate(0, 0, 1); !date.After(last); date = date.AddDate(0, 0, 1) {
+		index.levels = append(index.levels, index.levels[len(index.levels)-1]*dailyGrowth(date))
+	}
+	return index
+}
+
+// rebase multiplies every level so the index starts at base
+func (d *dailyIndex) rebase(base float64) *dailyIndex {
+	for i := range d.levels {
+		d.levels[i] *= base
+	}
+	return d
+}
+
+// level returns the level of the date, clamped to the dates of the index
+func (d *dailyIndex) level(date time.Time) float64 {
+	day := int(truncateToDate(date).Sub(d.firstDate).Hours() / 24)
+	if day < 0 {
+		day = 0
+	}
+	if day >= len(d.levels) {
+		day = len(d.levels) - 1
+	}
+	return d.levels[day]
+}
+
+// periodReturn returns the performance of the index between two dates, as a percentage
+func (d *dailyIndex) periodReturn(startDate, endDate time.Time) float64 {
+	return (d.level(endDate)/d.level(startDate) - 1) * 100
+}
+
+// valuationAtDate returns a function giving the value of the date, the last one before it when the date
+// has none, or the first one when the date is before every value. It returns nil when there are no values.
+func valuationAtDate(valuations []schemas.VariableValuation) func(date time.Time) float64 {
+	var dates []time.Time
+	var values []float64
+	for _, valuation := range sortValuations(valuations) {
+		date, err := time.Parse("2006-01-02", valuation.Date)
+		if err != nil || valuation.Value == 0 {
+			continue
+		}
+		dates = append(dates, date)
+		values = append(values, valuation.Value)
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return func(date time.Time) float64 {
+		day := truncateToDate(date)
+		i := sort.Search(len(dates), func(i int) bool {
+			return dates[i].After(day)
+		})
+		if i == 0 {
+			return values[0]
+		}
+		return values[i-1]
+	}
+}
+
+// sortValuations returns a copy of the valuations sorted by date
+func sortValuations(valuations []schemas.VariableValuation) []schemas.VariableValuation {
+	sorted := make([]schemas.VariableValuation, len(valuations))
+	copy(sorted, valuations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Date < sorted[j].Date
+	})
+	return sorted
+}
@@ -2,8 +2,11 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"math"
 	"server/src/clients/esco"
+	"server/src/models"
 	"server/src/schemas"
 	"server/src/utils"
 	"sort"
@@ -23,18 +26,35 @@ type ESCOServiceI interface {
 	GetMultiAccountStateWithTransactions(ctx context.Context, token string, ids []string, startDate, endDate time.Time, interval time.Duration) ([]*schemas.AccountState, error)
 	GetMultiAccountStateByCategory(ctx context.Context, token string, ids []string, startDate, endDate time.Time, interval time.Duration) (*schemas.AccountStateByCategory, error)
 	GetCtaCteConsolidadoDateRange(ctx context.Context, token, id string, startDate, endDate time.Time) (*schemas.AccountState, error)
+	ParseExport(exportType ESCOExportType, data []byte, date *time.Time) (*schemas.AccountState, error)
 }
 
+// ESCOExportType identifies the ESCO endpoint a raw JSON export was taken from
+type ESCOExportType string
+
+const (
+	ESCOExportEstadoCuenta  ESCOExportType = "estado"
+	ESCOExportBoletos       ESCOExportType = "boletos"
+	ESCOExportLiquidaciones ESCOExportType = "liquidaciones"
+	ESCOExportCtaCte        ESCOExportType = "ctacte"
+)
+
 type ESCOService struct {
-	client esco.ESCOServiceClientI
+	client           esco.ESCOServiceClientI
+	categoryResolver CategoryResolverI
 }
 
-func NewESCOService(client esco.ESCOServiceClientI) *ESCOService {
-	return &ESCOService{client: client}
+// NewESCOService creates the service. Without a category resolver, categories are resolved
+// with the static category map of the client.
+func NewESCOService(client esco.ESCOServiceClientI, categoryResolver CategoryResolverI) *ESCOService {
+	if categoryResolver == nil {
+		categoryResolver = MapCategoryResolver(client.GetCategoryMap())
+	}
+	return &ESCOService{client: client, categoryResolver: categoryResolver}
 }
 
 func (s *ESCOService) GetAccountByID(ctx context.Context, token, id string) (*esco.CuentaSchema, error) {
-	acc, err := s.client.BuscarCuentas(token, id)
+	acc, err := s.client.BuscarCuentas(ctx, token, id)
 	if err != nil {
 		return nil, err
 	}
@@ -52,7 +72,7 @@ func (s *ESCOService) GetAccountState(ctx context.Context, token, id string, dat
 	if err != nil {
 		return nil, err
 	}
-	accStateData, err := s.client.GetEstadoCuenta(token, account.ID, account.FI, strconv.Itoa(account.N), "0", date, false)
+	accStateData, err := s.client.GetEstadoCuenta(ctx, token, account.ID, account.FI, strconv.Itoa(account.N), "0", date, utils.RefreshCacheFromContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -63,17 +83,18 @@ func (s *ESCOService) GetAccountStateWithTransactions(ctx context.Context, token
 	var err error
 	logger := utils.LoggerFromContext(ctx)
 	var wg sync.WaitGroup
-	wg.Add(2)
+	wg.Add(3)
 
 	var accountState *schemas.AccountState
 	var liquidaciones *schemas.AccountState
 	var boletos *schemas.AccountState
 	var instrumentos *schemas.AccountState
 
+	var stateErr error
 	go func() {
-		accountState, err = s.GetAccountStateDateRange(ctx, token, id, startDate, endDate, interval)
-		if err != nil {
-			logger.Errorf("error while on GetAccountStateDateRange: %v", err)
+		accountState, stateErr = s.GetAccountStateDateRange(ctx, token, id, startDate, endDate, interval)
+		if stateErr != nil {
+			logger.Errorf("error while on GetAccountStateDateRange: %v", stateErr)
 		}
 		wg.Done()
 	}()
@@ -96,23 +117,28 @@ func (s *ESCOService) GetAccountStateWithTransactions(ctx context.Context, token
 	// 	wg.Done()
 	// }()
 
-	// go func() {
-	// 	retries := 3
-	// 	for {
-	// 		boletos, err = s.GetBoletosDateRange(ctx, token, id, startDate, endDate)
-	// 		if err != nil {
-	// 			logger.Errorf("error while on GetBoletosDateRange: %v. Retrying...", err)
-	// 			retries--
-	// 		} else {
-	// 			break
-	// 		}
-	// 		if retries == 0 {
-	// 			logger.Errorf("exhausted retries on GetBoletosDateRange: %v", err)
-	// 			break
-	// 		}
-	// 	}
-	// 	wg.Done()
-	// }()
+	var boletosErr error
+	go func() {
+		retries := 3
+		for {
+			boletos, boletosErr = s.GetBoletosDateRange(ctx, token, id, startDate, endDate)
+			if boletosErr != nil {
+				logger.Errorf("error while on GetBoletosDateRange: %v. Retrying...", boletosErr)
+				retries--
+			} else {
+				break
+			}
+			if ctx.Err() != nil {
+				boletosErr = ctx.Err()
+				break
+			}
+			if retries == 0 {
+				logger.Errorf("exhausted retries on GetBoletosDateRange: %v", boletosErr)
+				break
+			}
+		}
+		wg.Done()
+	}()
 
 	go func() {
 		retries := 3
@@ -124,6 +150,10 @@ func (s *ESCOService) GetAccountStateWithTransactions(ctx context.Context, token
 			} else {
 				break
 			}
+			if ctx.Err() != nil {
+				err = ctx.Err()
+				break
+			}
 			if retries == 0 {
 				logger.Errorf("exhausted retries on GetCtaCteConsolidadoDateRange: %v", err)
 				break
@@ -133,6 +163,12 @@ func (s *ESCOService) GetAccountStateWithTransactions(ctx context.Context, token
 	}()
 
 	wg.Wait()
+	if stateErr != nil {
+		return nil, stateErr
+	}
+	if boletosErr != nil {
+		return nil, boletosErr
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -157,7 +193,13 @@ func (s *ESCOService) GetAccountStateWithTransactions(ctx context.Context, token
 		}
 		if instrumentos != nil {
 			if instrumento, ok := (*instrumentos.Assets)[id]; ok {
-				asset.Transactions = append(asset.Transactions, instrumento.Transactions...)
+				movements := instrumento.Transactions
+				if boletos != nil {
+					if boleto, ok := (*boletos.Assets)[id]; ok {
+						movements = withoutBoletoMovements(movements, boleto.Transactions)
+					}
+				}
+				asset.Transactions = append(asset.Transactions, movements...)
 				(*accountState.Assets)[id] = asset
 			}
 		}
@@ -165,6 +207,27 @@ func (s *ESCOService) GetAccountStateWithTransactions(ctx context.Context, token
 	return accountState, nil
 }
 
+// withoutBoletoMovements returns the cuenta corriente movements of an asset without the buys and sells
+// settled on the date of one of its boletos. Both list the same trade, the boleto along with its price
+// and fees, so keeping both would count its units twice.
+func withoutBoletoMovements(movements, boletos []schemas.Transaction) []schemas.Transaction {
+	boletoDates := make(map[string]bool, len(boletos))
+	for _, boleto := range boletos {
+		if boleto.Date != nil {
+			boletoDates[boleto.Date.Format("2006-01-02")] = true
+		}
+	}
+	filtered := make([]schemas.Transaction, 0, len(movements))
+	for _, movement := range movements {
+		isTrade := movement.Type == models.TransactionTypeBuy || movement.Type == models.TransactionTypeSell
+		if isTrade && movement.Date != nil && boletoDates[movement.Date.Format("2006-01-02")] {
+			continue
+		}
+		filtered = append(filtered, movement)
+	}
+	return filtered
+}
+
 func (s *ESCOService) GetAccountStateDateRange(ctx context.Context, token, id string, startDate, endDate time.Time, interval time.Duration) (*schemas.AccountState, error) {
 	logger := utils.LoggerFromContext(ctx)
 	account, err := s.GetAccountByID(ctx, token, id)
@@ -186,16 +249,23 @@ func (s *ESCOService) GetAccountStateDateRange(ctx context.Context, token, id st
 			defer wg.Done()
 			var retries = 3
 			var accStateData []esco.EstadoCuentaSchema
+			var err error
 			date := startDate.AddDate(0, 0, i*int(intervalHours/24))
 			for {
-				accStateData, err = s.client.GetEstadoCuenta(token, account.ID, account.FI, strconv.Itoa(account.N), "0", date, false)
+				accStateData, err = s.client.GetEstadoCuenta(ctx, token, account.ID, account.FI, strconv.Itoa(account.N), "0", date, utils.RefreshCacheFromContext(ctx))
 				if err != nil || accStateData == nil {
 					retries -= 1
 					logger.Warnf("error while on GetEstadoCuenta: %v. Retrying..", err)
-					time.Sleep(100 * time.Millisecond)
 				} else {
 					break
 				}
+				// Stop retrying once the caller is gone, there is no one left to read the result
+				select {
+				case <-ctx.Done():
+					errChan <- ctx.Err()
+					return
+				case <-time.After(100 * time.Millisecond):
+				}
 				if retries == 0 {
 					errChan <- err
 					logger.Errorf("retries exceeded for GetEstadoCuenta: %v", err)
@@ -238,7 +308,7 @@ func (s *ESCOService) GetLiquidacionesDateRange(ctx context.Context, token, id s
 	if err != nil {
 		return nil, err
 	}
-	liquidaciones, err := s.client.GetLiquidaciones(token, account.ID, account.FI, strconv.Itoa(account.N), "0", startDate, endDate, false)
+	liquidaciones, err := s.client.GetLiquidaciones(ctx, token, account.ID, account.FI, strconv.Itoa(account.N), "0", startDate, endDate, utils.RefreshCacheFromContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -250,7 +320,7 @@ func (s *ESCOService) GetBoletosDateRange(ctx context.Context, token, id string,
 	if err != nil {
 		return nil, err
 	}
-	boletos, err := s.client.GetBoletos(token, account.ID, account.FI, strconv.Itoa(account.N), "0", startDate, endDate, false)
+	boletos, err := s.client.GetBoletos(ctx, token, account.ID, account.FI, strconv.Itoa(account.N), "0", startDate, endDate, utils.RefreshCacheFromContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -263,6 +333,9 @@ func (s *ESCOService) GetMultiAccountStateWithTransactions(ctx context.Context,
 	var err error
 
 	for _, id := range ids {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
 		var accountState *schemas.AccountState
 		accountState, err = s.GetAccountStateWithTransactions(ctx, token, id, startDate, endDate, interval)
 		if err != nil {
@@ -291,9 +364,44 @@ func (s *ESCOService) CollapseAndGroupAccountsStates(accountsStates []*schemas.A
 	return s.groupTotalHoldingsAndTransactionsByDate(&collapsedAccountState)
 }
 
+// ParseExport parses a raw ESCO JSON export into an account state, using the same parsers as the live API.
+// The date is the requested date of the holdings and is only required for estado de cuenta exports.
+func (s *ESCOService) ParseExport(exportType ESCOExportType, data []byte, date *time.Time) (*schemas.AccountState, error) {
+	switch exportType {
+	case ESCOExportEstadoCuenta:
+		if date == nil {
+			return nil, fmt.Errorf("date is required to parse estado de cuenta exports")
+		}
+		var accStateData []esco.EstadoCuentaSchema
+		if err := json.Unmarshal(data, &accStateData); err != nil {
+			return nil, fmt.Errorf("error decoding estado de cuenta export: %w", err)
+		}
+		return s.parseEstadoToAccountState(&accStateData, date)
+	case ESCOExportBoletos:
+		var boletos []esco.Boleto
+		if err := json.Unmarshal(data, &boletos); err != nil {
+			return nil, fmt.Errorf("error decoding boletos export: %w", err)
+		}
+		return s.parseBoletosToAccountState(&boletos)
+	case ESCOExportLiquidaciones:
+		var liquidaciones []esco.Liquidacion
+		if err := json.Unmarshal(data, &liquidaciones); err != nil {
+			return nil, fmt.Errorf("error decoding liquidaciones export: %w", err)
+		}
+		return s.parseLiquidacionesToAccountState(&liquidaciones)
+	case ESCOExportCtaCte:
+		var instrumentos []esco.Instrumentos
+		if err := json.Unmarshal(data, &instrumentos); err != nil {
+			return nil, fmt.Errorf("error decoding cuenta corriente export: %w", err)
+		}
+		return s.parseInstrumentosRecoveriesToAccountState(&instrumentos)
+	default:
+		return nil, fmt.Errorf("unknown esco export type %q", exportType)
+	}
+}
+
 func (s *ESCOService) parseEstadoToAccountState(accStateData *[]esco.EstadoCuentaSchema, date *time.Time) (*schemas.AccountState, error) {
 	var categoryKey string
-	categoryMap := s.client.GetCategoryMap()
 	accStateRes := schemas.NewAccountState()
 	for _, accData := range *accStateData {
 		var asset schemas.Asset
@@ -301,16 +409,13 @@ func (s *ESCOService) parseEstadoToAccountState(accStateData *[]esco.EstadoCuent
 		var parsedDate *time.Time
 		if asset, exists = (*accStateRes.Assets)[accData.A]; !exists {
 			categoryKey = fmt.Sprintf("%s - %s", accData.A, accData.D)
-			var category string
-			var exists bool
-			if category, exists = categoryMap[categoryKey]; !exists {
-				category = "S / C"
-			}
+			category := s.categoryResolver.Resolve(categoryKey)
 			(*accStateRes.Assets)[accData.A] = schemas.Asset{
 				ID:           accData.A,
 				Type:         accData.TI,
 				Denomination: accData.D,
 				Category:     category,
+				CategoryKey:  categoryKey,
 				Holdings:     make([]schemas.Holding, 0, len(*accStateData)),
 			}
 			asset = (*accStateRes.Assets)[accData.A]
@@ -340,7 +445,6 @@ func (s *ESCOService) parseEstadoToAccountState(accStateData *[]esco.EstadoCuent
 
 func (s *ESCOService) parseBoletosToAccountState(boletos *[]esco.Boleto) (*schemas.AccountState, error) {
 	var categoryKey string
-	categoryMap := s.client.GetCategoryMap()
 	accStateRes := schemas.NewAccountState()
 	for _, boleto := range *boletos {
 		var asset schemas.Asset
@@ -349,16 +453,13 @@ func (s *ESCOService) parseBoletosToAccountState(boletos *[]esco.Boleto) (*schem
 		id := strings.Split(boleto.I, " - ")[0]
 		categoryKey = boleto.I
 		if asset, exists = (*accStateRes.Assets)[id]; !exists {
-			var category string
-			var exists bool
-			if category, exists = categoryMap[categoryKey]; !exists {
-				category = "S / C"
-			}
+			category := s.categoryResolver.Resolve(categoryKey)
 			(*accStateRes.Assets)[id] = schemas.Asset{
 				ID:           id,
 				Type:         boleto.T,
 				Denomination: boleto.FL,
 				Category:     category,
+				CategoryKey:  categoryKey,
 				Transactions: make([]schemas.Transaction, 0, len(*boletos)),
 			}
 			asset = (*accStateRes.Assets)[id]
@@ -377,8 +478,13 @@ func (s *ESCOService) parseBoletosToAccountState(boletos *[]esco.Boleto) (*schem
 		asset.Transactions = append(asset.Transactions, schemas.Transaction{
 			Currency:     "Pesos",
 			CurrencySign: boleto.NS,
+			Type:         classifyBoletoOperation(boleto.O, boleto.TO),
 			Value:        -boleto.N,
 			Units:        boleto.C,
+			PricePerUnit: boleto.PR,
+			GrossValue:   -boleto.B,
+			ExchangeFees: math.Abs(boleto.DB),
+			MarketFees:   math.Abs(boleto.DM),
 			Date:         parsedDate,
 		})
 		(*accStateRes.Assets)[id] = asset
@@ -389,7 +495,6 @@ func (s *ESCOService) parseBoletosToAccountState(boletos *[]esco.Boleto) (*schem
 
 func (s *ESCOService) parseLiquidacionesToAccountState(liquidaciones *[]esco.Liquidacion) (*schemas.AccountState, error) {
 	var categoryKey string
-	categoryMap := s.client.GetCategoryMap()
 	accStateRes := schemas.NewAccountState()
 	for _, liquidacion := range *liquidaciones {
 		var asset schemas.Asset
@@ -398,16 +503,13 @@ func (s *ESCOService) parseLiquidacionesToAccountState(liquidaciones *[]esco.Liq
 		id := strings.Split(liquidacion.F, " - ")[0]
 		categoryKey = fmt.Sprintf("%s / %s", liquidacion.F, id)
 		if asset, exists = (*accStateRes.Assets)[id]; !exists {
-			var category string
-			var exists bool
-			if category, exists = categoryMap[categoryKey]; !exists {
-				category = "S / C"
-			}
+			category := s.categoryResolver.Resolve(categoryKey)
 			(*accStateRes.Assets)[id] = schemas.Asset{
 				ID:           id,
 				Type:         "",
 				Denomination: categoryKey,
 				Category:     category,
+				CategoryKey:  categoryKey,
 				Transactions: make([]schemas.Transaction, 0, len(*liquidaciones)),
 			}
 			asset = (*accStateRes.Assets)[id]
@@ -426,6 +528,7 @@ func (s *ESCOService) parseLiquidacionesToAccountState(liquidaciones *[]esco.Liq
 		asset.Transactions = append(asset.Transactions, schemas.Transaction{
 			Currency:     "Pesos",
 			CurrencySign: liquidacion.MS,
+			Type:         classifyLiquidacionOperation(liquidacion.TO),
 			Value:        -liquidacion.I,
 			Units:        liquidacion.Q,
 			Date:         parsedDate,
@@ -441,7 +544,7 @@ func (s *ESCOService) GetCtaCteConsolidadoDateRange(ctx context.Context, token,
 	if err != nil {
 		return nil, err
 	}
-	instrumentos, err := s.client.GetCtaCteConsolidado(token, account.ID, account.FI, strconv.Itoa(account.N), "0", startDate, endDate, false)
+	instrumentos, err := s.client.GetCtaCteConsolidado(ctx, token, account.ID, account.FI, strconv.Itoa(account.N), "0", startDate, endDate, utils.RefreshCacheFromContext(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -454,7 +557,6 @@ func (s *ESCOService) parseInstrumentosRecoveriesToAccountState(instrumentos *[]
 	}
 	var id, currencySign, categoryKey string
 	var units, value float64
-	categoryMap := s.client.GetCategoryMap()
 	accStateRes := schemas.NewAccountState()
 	for _, ins := range *instrumentos {
 		if ins.C < float64(0) && strings.Contains(ins.D, "Retiro de Títulos") {
@@ -509,6 +611,28 @@ func (s *ESCOService) parseInstrumentosRecoveriesToAccountState(instrumentos *[]
 			units = -ins.N
 			value = 0
 			categoryKey = "MEP"
+		} else if strings.Contains(ins.D, "Partial redemption") {
+			// Amortizations of dollar bonds are paid into the dollar account like their interest payments,
+			// so they add to the MEP dollars held without being a cash flow of the account
+			id = strings.Split(ins.I, " - ")[1]
+			if id != "USD" {
+				continue
+			}
+			currencySign = "USD"
+			units = -ins.N
+			value = 0
+			categoryKey = "MEP"
+		} else if movementType := classifyCtaCteMovement(ins.D, 0); ins.TI == "Monedas" && (movementType == models.TransactionTypeDividend || movementType == models.TransactionTypeFee) {
+			// Dividends and fees only move cash, so they are kept on the currency they are paid in
+			currency := strings.Split(ins.I, " - ")
+			if len(currency) < 2 {
+				continue
+			}
+			id = currency[1]
+			currencySign = ins.N_S
+			units = -ins.N
+			value = 0
+			categoryKey = fmt.Sprintf("%s - %s", id, currency[0])
 		} else {
 			continue
 		}
@@ -517,16 +641,13 @@ func (s *ESCOService) parseInstrumentosRecoveriesToAccountState(instrumentos *[]
 		var parsedDate *time.Time
 
 		if asset, exists = (*accStateRes.Assets)[id]; !exists {
-			var category string
-			var exists bool
-			if category, exists = categoryMap[categoryKey]; !exists {
-				category = "S / C"
-			}
+			category := s.categoryResolver.Resolve(categoryKey)
 			(*accStateRes.Assets)[id] = schemas.Asset{
 				ID:           id,
 				Type:         "",
 				Denomination: categoryKey,
 				Category:     category,
+				CategoryKey:  categoryKey,
 				Transactions: make([]schemas.Transaction, 0, len(*instrumentos)),
 			}
 			asset = (*accStateRes.Assets)[id]
@@ -545,6 +666,7 @@ func (s *ESCOService) parseInstrumentosRecoveriesToAccountState(instrumentos *[]
 		asset.Transactions = append(asset.Transactions, schemas.Transaction{
 			Currency:     "Pesos",
 			CurrencySign: currencySign,
+			Type:         classifyCtaCteMovement(ins.D, -units),
 			Value:        value,
 			Units:        -units,
 			Date:         parsedDate,
@@ -591,13 +713,11 @@ func (s *ESCOService) collapseAccountStates(states []*schemas.AccountState) sche
 			holdingMapByAssetID[assetID] = holdingMap
 
 			for _, transaction := range asset.Transactions {
-				key := transaction.Date.Format("2006-01-02")
+				key := transaction.Date.Format("2006-01-02") + "/" + transaction.Type
 				if existing, found := transactionMap[key]; !found {
 					transactionMap[key] = transaction
 				} else {
-					existing.Value += transaction.Value
-					existing.Units += transaction.Units
-					transactionMap[key] = existing
+					transactionMap[key] = mergeTransactions(existing, transaction)
 				}
 			}
 			transactionMapByAssetID[assetID] = transactionMap
@@ -612,6 +732,7 @@ func (s *ESCOService) collapseAccountStates(states []*schemas.AccountState) sche
 				ID:           assetID,
 				Type:         asset.Type,
 				Category:     asset.Category,
+				CategoryKey:  asset.CategoryKey,
 				Denomination: asset.Denomination,
 				Holdings:     []schemas.Holding{},
 				Transactions: []schemas.Transaction{},
@@ -647,6 +768,7 @@ func (s *ESCOService) groupTotalHoldingsAndTransactionsByDate(state *schemas.Acc
 	assetsByCategory := make(map[string][]schemas.Asset)
 	categoryHoldingsByDate := make(map[string]map[string]schemas.Holding)
 	categoryTransactionsByDate := make(map[string]map[string]schemas.Transaction)
+	typeTransactionsByDate := make(map[string]map[string]schemas.Transaction)
 
 	for _, asset := range *state.Assets {
 		category := asset.Category
@@ -736,6 +858,21 @@ func (s *ESCOService) groupTotalHoldingsAndTransactionsByDate(state *schemas.Acc
 			categoryTransaction := categoryTransactionsByDate[category][dateStr]
 			categoryTransaction.Value += transactionValue
 			categoryTransactionsByDate[category][dateStr] = categoryTransaction
+
+			if _, exists := typeTransactionsByDate[transaction.Type]; !exists {
+				typeTransactionsByDate[transaction.Type] = make(map[string]schemas.Transaction)
+			}
+			if _, exists := typeTransactionsByDate[transaction.Type][dateStr]; !exists {
+				typeTransactionsByDate[transaction.Type][dateStr] = schemas.Transaction{
+					Currency:     "Pesos",
+					CurrencySign: "$",
+					Type:         transaction.Type,
+					Date:         &date,
+				}
+			}
+			typeTransaction := typeTransactionsByDate[transaction.Type][dateStr]
+			typeTransaction.Value += transactionValue
+			typeTransactionsByDate[transaction.Type][dateStr] = typeTransaction
 		}
 	}
 
@@ -746,12 +883,14 @@ func (s *ESCOService) groupTotalHoldingsAndTransactionsByDate(state *schemas.Acc
 	}
 
 	categoryAssets := s.generateCategoryAssets(categoryHoldingsByDate, categoryTransactionsByDate)
+	transactionsByType := sortTransactionsByType(typeTransactionsByDate)
 
 	return &schemas.AccountStateByCategory{
 		AssetsByCategory:        &assetsByCategory,
 		CategoryAssets:          &categoryAssets,
 		TotalHoldingsByDate:     &totalHoldingsByDate,
 		TotalTransactionsByDate: &totalTransactionsByDate,
+		TransactionsByType:      &transactionsByType,
 	}
 }
 
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"server/src/clients/esco"
+	"server/src/schemas"
+	"server/src/utils"
+	redis_utils "server/src/utils/redis"
+	"sync"
+	"time"
+)
+
+const defaultTokenRefreshBefore = 5 * time.Minute
+
+// ErrServiceAccountNotConfigured is returned when no ESCO service account credentials are set
+var ErrServiceAccountNotConfigured = errors.New("esco service account credentials are not configured")
+
+type ESCOTokenManagerI interface {
+	GetToken(ctx context.Context) (string, error)
+	Configured() bool
+}
+
+// ESCOTokenManager hands out an ESCO token for the configured service account, so background
+// work does not depend on an end user's bearer token. The token is cached in Redis, shared by
+// every instance, and renewed refreshBefore its expiry.
+type ESCOTokenManager struct {
+	client        esco.ESCOServiceClientI
+	cacheHandler  utils.CacheHandlerI
+	username      string
+	password      string
+	refreshBefore time.Duration
+
+	mu sync.Mutex
+}
+
+// cachedESCOToken is the token as stored in the cache, with its absolute expiry
+type cachedESCOToken struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+func NewESCOTokenManager(
+	client esco.ESCOServiceClientI,
+	cacheHandler utils.CacheHandlerI,
+	username, password string,
+	refreshBefore time.Duration,
+) *ESCOTokenManager {
+	if refreshBefore <= 0 {
+		refreshBefore = defaultTokenRefreshBefore
+	}
+	return &ESCOTokenManager{
+		client:        client,
+		cacheHandler:  cacheHandler,
+		username:      username,
+		password:      password,
+		refreshBefore: refreshBefore,
+	}
+}
+
+// Configured reports whether the service account credentials are set, without logging in
+func (m *ESCOTokenManager) Configured() bool {
+	return m.username != "" && m.password != ""
+}
+
+// GetToken returns the cached access token, renewing it first when it is about to expire.
+// If the renewal fails while the cached token is still valid, the cached token is returned.
+func (m *ESCOTokenManager) GetToken(ctx context.Context) (string, error) {
+	logger := utils.LoggerFromContext(ctx)
+	if !m.Configured() {
+		return "", ErrServiceAccountNotConfigured
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, err := m.cacheKey()
+	if err != nil {
+		return "", err
+	}
+	var cached cachedESCOToken
+	if err := m.cacheHandler.Get(key, &cached); err != nil || cached.AccessToken == "" {
+		return m.login(ctx, key)
+	}
+
+	now := time.Now()
+	if now.Before(cached.ExpiresAt.Add(-m.refreshBefore)) {
+		return cached.AccessToken, nil
+	}
+
+	token, err := m.renew(ctx, key, cached.RefreshToken)
+	if err != nil {
+		if now.Before(cached.ExpiresAt) {
+			logger.Warnf("Error renewing esco service account token, using the cached one: %v", err)
+			return cached.AccessToken, nil
+		}
+		return "", err
+	}
+	return token, nil
+}
+
+// renew uses the refresh token when there is one and falls back to a new login
+func (m *ESCOTokenManager) renew(ctx context.Context, key, refreshToken string) (string, error) {
+	if refreshToken != "" {
+		tokenResponse, err := m.client.RefreshToken(ctx, refreshToken)
+		if err == nil {
+			return m.store(key, tokenResponse)
+		}
+		utils.LoggerFromContext(ctx).Warnf("Error refreshing esco service account token, logging in again: %v", err)
+	}
+	return m.login(ctx, key)
+}
+
+func (m *ESCOTokenManager) login(ctx context.Context, key string) (string, error) {
+	tokenResponse, err := m.client.PostToken(ctx, m.username, m.password)
+	if err != nil {
+		return "", fmt.Errorf("error logging in with esco service account: %w", err)
+	}
+	return m.store(key, tokenResponse)
+}
+
+func (m *ESCOTokenManager) store(key string, tokenResponse *schemas.TokenResponse) (string, error) {
+	if tokenResponse == nil || tokenResponse.AccessToken == "" {
+		return "", fmt.Errorf("empty token received for esco service account")
+	}
+	expiresIn := time.Duration(tokenResponse.ExpiresIn) * time.Second
+	cached := cachedESCOToken{
+		AccessToken:  tokenResponse.AccessToken,
+		RefreshToken: tokenResponse.RefreshToken,
+		ExpiresAt:    time.Now().Add(expiresIn),
+	}
+	// Tokens without an expiry are not cached, a new one is requested on every call
+	if expiresIn > 0 {
+		if err := m.cacheHandler.Set(key, cached, expiresIn); err != nil {
+			return "", fmt.Errorf("error caching esco service account token: %w", err)
+		}
+	}
+	return cached.AccessToken, nil
+}
+
+func (m *ESCOTokenManager) cacheKey() (string, error) {
+	return redis_utils.GenerateUUID("esco-service-account-token", m.username)
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/schemas"
+	"server/src/utils"
+	"strings"
+	"time"
+)
+
+type ExchangeRateServiceI interface {
+	GetRates(ctx context.Context, currency string, startDate, endDate time.Time) ([]*schemas.ExchangeRateResponse, error)
+	SetRates(ctx context.Context, currency string, req []schemas.ExchangeRateRequest) ([]*schemas.ExchangeRateResponse, error)
+	DeleteRate(ctx context.Context, currency string, date time.Time) error
+	GetConverter(ctx context.Context, currency string, officialRates []schemas.VariableValuation, startDate, endDate time.Time) (*CurrencyConverter, error)
+}
+
+// ExchangeRateService manages the rates of the dollars BCRA does not publish, MEP and CCL, which are
+// loaded by hand, and builds the converters reports are expressed in other currencies with.
+type ExchangeRateService struct {
+	exchangeRateRepository repositories.ExchangeRateRepository
+}
+
+func NewExchangeRateService(exchangeRateRepository repositories.ExchangeRateRepository) *ExchangeRateService {
+	return &ExchangeRateService{
+		exchangeRateRepository: exchangeRateRepository,
+	}
+}
+
+func (s *ExchangeRateService) GetRates(ctx context.Context, currency string, startDate, endDate time.Time) ([]*schemas.ExchangeRateResponse, error) {
+	currency, err := parseStoredRateCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := s.exchangeRateRepository.GetByCurrency(ctx, currency, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	response := make([]*schemas.ExchangeRateResponse, 0, len(rates))
+	for i := range rates {
+		response = append(response, exchangeRateToResponse(&rates[i]))
+	}
+	return response, nil
+}
+
+// SetRates stores the rates of the currency, replacing the ones of the same dates
+func (s *ExchangeRateService) SetRates(ctx context.Context, currency string, req []schemas.ExchangeRateRequest) ([]*schemas.ExchangeRateResponse, error) {
+	currency, err := parseStoredRateCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	for _, rate := range req {
+		if rate.Date.IsZero() {
+			return nil, utils.BadRequest("date is required")
+		}
+		if rate.Rate <= 0 {
+			return nil, utils.BadRequest(fmt.Sprintf("the rate of %s must be positive", rate.Date.Format("2006-01-02")))
+		}
+	}
+
+	response := make([]*schemas.ExchangeRateResponse, 0, len(req))
+	for _, rate := range req {
+		exchangeRate := &models.ExchangeRate{
+			Currency: currency,
+			Date:     rate.Date.Time,
+			Rate:     rate.Rate,
+		}
+		if err := s.exchangeRateRepository.Create(ctx, exchangeRate, nil); err != nil {
+			return nil, fmt.Errorf("error storing exchange rate: %w", err)
+		}
+		response = append(response, exchangeRateToResponse(exchangeRate))
+	}
+	utils.LoggerFromContext(ctx).Infof("Stored %d %s exchange rates", len(response), currency)
+	return response, nil
+}
+
+func (s *ExchangeRateService) DeleteRate(ctx context.Context, currency string, date time.Time) error {
+	currency, err := parseStoredRateCurrency(currency)
+	if err != nil {
+		return err
+	}
+	return s.exchangeRateRepository.Delete(ctx, currency, date)
+}
+
+// GetConverter returns the converter of a report in currency between startDate and endDate. Pesos and the
+// official dollar use the official rates (A3500), while MEP and CCL use the stored rates, including the
+// last one before startDate so the first days of the report have a rate.
+func (s *ExchangeRateService) GetConverter(ctx context.Context, currency string, officialRates []schemas.VariableValuation, startDate, endDate time.Time) (*CurrencyConverter, error) {
+	if currency != ReportCurrencyMEP && currency != ReportCurrencyCCL {
+		return NewCurrencyConverter(currency, officialRates)
+	}
+
+	rates, err := s.exchangeRateRepository.GetByCurrency(ctx, currency, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	previousRate, err := s.exchangeRateRepository.GetLatestBeforeDate(ctx, currency, startDate)
+	if err != nil {
+		return nil, err
+	}
+	if previousRate != nil {
+		rates = append(rates, *previousRate)
+	}
+
+	valuations := make([]schemas.VariableValuation, 0, len(rates))
+	for _, rate := range rates {
+		valuations = append(valuations, schemas.VariableValuation{
+			Date:  rate.Date.Format("2006-01-02"),
+			Value: rate.Rate,
+		})
+	}
+	return NewCurrencyConverter(currency, valuations)
+}
+
+// parseStoredRateCurrency validates the currency of the rates stored by hand
+func parseStoredRateCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(currency)
+	if currency != ReportCurrencyMEP && currency != ReportCurrencyCCL {
+		return "", utils.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("invalid currency %q, expected one of %s, %s", currency, ReportCurrencyMEP, ReportCurrencyCCL))
+	}
+	return currency, nil
+}
+
+func exchangeRateToResponse(rate *models.ExchangeRate) *schemas.ExchangeRateResponse {
+	return &schemas.ExchangeRateResponse{
+		Currency: rate.Currency,
+		Date:     schemas.Date{Time: rate.Date},
+		Rate:     rate.Rate,
+	}
+}
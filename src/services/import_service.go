@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"server/src/models"
+	"server/src/schemas"
+	"server/src/utils"
+	"sort"
+	"time"
+)
+
+type ImportServiceI interface {
+	ImportESCOExport(ctx context.Context, accountID string, exportType ESCOExportType, data []byte, date *time.Time) (*schemas.ESCOImportResponse, error)
+}
+
+// ImportService loads data that was not fetched from a live ESCO session
+type ImportService struct {
+	escoService ESCOServiceI
+	syncService SyncServiceI
+}
+
+func NewImportService(escoService ESCOServiceI, syncService SyncServiceI) *ImportService {
+	return &ImportService{
+		escoService: escoService,
+		syncService: syncService,
+	}
+}
+
+// ImportESCOExport parses a raw ESCO JSON export and stores it as the account state for every date it contains.
+// The dates of an estado de cuenta export are marked as synced, so a forced sync is needed to replace them with
+// live ESCO data. Exports of movements carry no holdings, so their dates are left to be synced.
+func (s *ImportService) ImportESCOExport(ctx context.Context, accountID string, exportType ESCOExportType, data []byte, date *time.Time) (*schemas.ESCOImportResponse, error) {
+	logger := utils.LoggerFromContext(ctx)
+	logger.Infof("Importing esco %s export for account %s", exportType, accountID)
+
+	accountState, err := s.escoService.ParseExport(exportType, data, date)
+	if err != nil {
+		return nil, utils.BadRequest(err.Error())
+	}
+
+	response := &schemas.ESCOImportResponse{
+		AccountID: accountID,
+		Type:      string(exportType),
+		Assets:    len(*accountState.Assets),
+		Dates:     make([]schemas.Date, 0),
+	}
+	dates := make(map[string]time.Time)
+	addDate := func(date *time.Time) {
+		if date == nil {
+			return
+		}
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		dates[day.Format(utils.ShortDashDateLayout)] = day
+	}
+	for _, asset := range *accountState.Assets {
+		for _, holding := range asset.Holdings {
+			addDate(holding.DateRequested)
+			response.Holdings++
+		}
+		for _, transaction := range asset.Transactions {
+			addDate(transaction.Date)
+			response.Transactions++
+		}
+	}
+
+	datesToSync := make([]time.Time, 0, len(dates))
+	for _, day := range dates {
+		datesToSync = append(datesToSync, day)
+	}
+	sort.Slice(datesToSync, func(i, j int) bool {
+		return datesToSync[i].Before(datesToSync[j])
+	})
+	if len(datesToSync) == 0 {
+		logger.Infof("Nothing to import for account %s", accountID)
+		return response, nil
+	}
+
+	// Exports are ESCO payloads, whatever the default source is
+	ctx = utils.WithSyncSource(ctx, models.SourceESCO)
+	if exportType == ESCOExportEstadoCuenta {
+		err = s.syncService.StoreAccountState(ctx, accountID, accountState, datesToSync)
+	} else {
+		err = s.syncService.StoreTransactions(ctx, accountID, accountState, datesToSync)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error storing imported account state: %w", err)
+	}
+	for _, day := range datesToSync {
+		response.Dates = append(response.Dates, schemas.Date{Time: day})
+	}
+	return response, nil
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"server/src/repositories"
+	"server/src/utils"
+	"time"
+)
+
+const defaultIncrementalSyncBackfillDays = 30
+
+type IncrementalSyncServiceI interface {
+	RunIncrementalSync(ctx context.Context) error
+	SyncAccountIncremental(ctx context.Context, token, accountID string, until time.Time) error
+}
+
+// IncrementalSyncService keeps stored data current by syncing every account from its
+// last synced date up to yesterday, filling any gaps left inside the backfill window
+type IncrementalSyncService struct {
+	syncService       SyncServiceI
+	syncLogRepository repositories.SyncLogRepository
+	tokenManager      ESCOTokenManagerI
+
+	accounts     []string
+	backfillDays int
+}
+
+func NewIncrementalSyncService(
+	syncService SyncServiceI,
+	syncLogRepository repositories.SyncLogRepository,
+	tokenManager ESCOTokenManagerI,
+	accounts []string,
+	backfillDays int,
+) *IncrementalSyncService {
+	if backfillDays <= 0 {
+		backfillDays = defaultIncrementalSyncBackfillDays
+	}
+	return &IncrementalSyncService{
+		syncService:       syncService,
+		syncLogRepository: syncLogRepository,
+		tokenManager:      tokenManager,
+		accounts:          accounts,
+		backfillDays:      backfillDays,
+	}
+}
+
+// RunIncrementalSync syncs every configured account, or every account found in sync_logs
+// when none are configured. Failures are logged per account and do not stop the run.
+func (s *IncrementalSyncService) RunIncrementalSync(ctx context.Context) error {
+	logger := utils.LoggerFromContext(ctx)
+	ctx = utils.WithSyncTrigger(ctx, utils.SyncTriggerIncrementalSync)
+
+	token, err := s.tokenManager.GetToken(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting esco token for incremental sync: %w", err)
+	}
+
+	accountIDs := s.accounts
+	if len(accountIDs) == 0 {
+		accountIDs, err = s.syncLogRepository.GetClientIDs(ctx)
+		if err != nil {
+			return fmt.Errorf("error getting accounts to sync: %w", err)
+		}
+	}
+
+	until := today()
+	failed := 0
+	for _, accountID := range accountIDs {
+		if err := s.SyncAccountIncremental(ctx, token, accountID, until); err != nil {
+			logger.Errorf("Incremental sync failed for account %s: %v", accountID, err)
+			failed++
+		}
+	}
+	logger.Infof("Incremental sync finished: %d accounts, %d failed", len(accountIDs), failed)
+	return nil
+}
+
+// SyncAccountIncremental syncs every missing date of the account before until (exclusive).
+// The window starts backfillDays before until, or earlier if the last sync is older than that.
+func (s *IncrementalSyncService) SyncAccountIncremental(ctx context.Context, token, accountID string, until time.Time) error {
+	logger := utils.LoggerFromContext(ctx)
+
+	startDate := until.AddDate(0, 0, -s.backfillDays)
+	lastSyncDate, err := s.syncLogRepository.GetLastSyncDate(ctx, accountID)
+	if err != nil {
+		return err
+	}
+	if lastSyncDate != nil && lastSyncDate.AddDate(0, 0, 1).Before(startDate) {
+		startDate = lastSyncDate.AddDate(0, 0, 1)
+	}
+
+	datesToSync, err := s.syncService.GetDatesToSync(ctx, token, accountID, startDate, until)
+	if err != nil {
+		return err
+	}
+	if len(datesToSync) == 0 {
+		logger.Infof("Account %s is up to date", accountID)
+		return nil
+	}
+
+	for _, dateRange := range groupContiguousDates(datesToSync) {
+		logger.Infof("Syncing account %s from %s to %s", accountID, dateRange[0], dateRange[1])
+		err = s.syncService.SyncDataFromAccount(ctx, token, accountID, dateRange[0], dateRange[1])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// groupContiguousDates splits sorted daily dates into [start, end) ranges of consecutive days
+func groupContiguousDates(dates []time.Time) [][2]time.Time {
+	ranges := make([][2]time.Time, 0)
+	if len(dates) == 0 {
+		return ranges
+	}
+	start, previous := dates[0], dates[0]
+	for _, date := range dates[1:] {
+		if !date.Equal(previous.AddDate(0, 0, 1)) {
+			ranges = append(ranges, [2]time.Time{start, previous.AddDate(0, 0, 1)})
+			start = date
+		}
+		previous = date
+	}
+	return append(ranges, [2]time.Time{start, previous.AddDate(0, 0, 1)})
+}
+
+// today returns the current Argentina date at UTC midnight, matching how sync dates are stored
+func today() time.Time {
+	location, _ := time.LoadLocation("America/Argentina/Buenos_Aires")
+	now := time.Now().In(location)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
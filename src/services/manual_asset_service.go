@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/schemas"
+	"server/src/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// manualAssetIDPrefix starts the external id of the manual assets, so they are told apart in the reports
+const manualAssetIDPrefix = "MANUAL-"
+
+type ManualAssetServiceI interface {
+	GetManualAssets(ctx context.Context, clientID string) ([]*schemas.ManualAssetResponse, error)
+	CreateManualAsset(ctx context.Context, clientID string, req *schemas.ManualAssetRequest) (*schemas.ManualAssetResponse, error)
+	UpdateManualAsset(ctx context.Context, clientID string, assetID int, req *schemas.ManualAssetRequest) (*schemas.ManualAssetResponse, error)
+	DeleteManualAsset(ctx context.Context, clientID string, assetID int) error
+	SetValuation(ctx context.Context, clientID string, assetID int, req *schemas.ManualValuationRequest) (*schemas.ManualAssetResponse, error)
+	DeleteValuation(ctx context.Context, clientID string, assetID int, date time.Time) error
+	AddCashFlow(ctx context.Context, clientID string, assetID int, req *schemas.ManualCashFlowRequest) (*schemas.ManualAssetResponse, error)
+	DeleteCashFlows(ctx context.Context, clientID string, assetID int, date time.Time) error
+}
+
+// ManualAssetService manages the assets clients hold outside of the brokers. They are stored as
+// assets owned by the client, with their valuations as holdings and their cash flows as transactions
+// tagged with the manual source, so reports include them like any synced asset.
+type ManualAssetService struct {
+	db *pgxpool.Pool
+
+	assetRepository         repositories.AssetRepository
+	assetCategoryRepository repositories.AssetCategoryRepository
+	holdingRepository       repositories.HoldingRepository
+	transactionRepository   repositories.TransactionRepository
+}
+
+func NewManualAssetService(
+	db *pgxpool.Pool,
+	assetRepository repositories.AssetRepository,
+	assetCategoryRepository repositories.AssetCategoryRepository,
+	holdingRepository repositories.HoldingRepository,
+	transactionRepository repositories.TransactionRepository,
+) *ManualAssetService {
+	return &ManualAssetService{
+		db:                      db,
+		assetRepository:         assetRepository,
+		assetCategoryRepository: assetCategoryRepository,
+		holdingRepository:       holdingRepository,
+		transactionRepository:   transactionRepository,
+	}
+}
+
+func (s *ManualAssetService) GetManualAssets(ctx context.Context, clientID string) ([]*schemas.ManualAssetResponse, error) {
+	assets, err := s.assetRepository.GetByClientID(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	response := make([]*schemas.ManualAssetResponse, 0, len(assets))
+	for i := range assets {
+		assetResponse, err := s.manualAssetToResponse(ctx, &assets[i])
+		if err != nil {
+			return nil, err
+		}
+		response = append(response, assetResponse)
+	}
+	return response, nil
+}
+
+func (s *ManualAssetService) CreateManualAsset(ctx context.Context, clientID string, req *schemas.ManualAssetRequest) (*schemas.ManualAssetResponse, error) {
+	asset := &models.Asset{
+		ExternalID: manualAssetIDPrefix + strings.ToUpper(uuid.NewString()),
+		ClientID:   clientID,
+	}
+	if err := s.applyManualAssetRequest(ctx, asset, req); err != nil {
+		return nil, err
+	}
+	if err := s.assetRepository.Create(ctx, asset, nil); err != nil {
+		return nil, fmt.Errorf("error creating manual asset: %w", err)
+	}
+	utils.LoggerFromContext(ctx).Infof("Created manual asset %s for account %s", asset.ExternalID, clientID)
+	return s.manualAssetToResponse(ctx, asset)
+}
+
+func (s *ManualAssetService) UpdateManualAsset(ctx context.Context, clientID string, assetID int, req *schemas.ManualAssetRequest) (*schemas.ManualAssetResponse, error) {
+	asset, err := s.getManualAsset(ctx, clientID, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyManualAssetRequest(ctx, asset, req); err != nil {
+		return nil, err
+	}
+	if err := s.assetRepository.Update(ctx, asset); err != nil {
+		return nil, fmt.Errorf("error updating manual asset: %w", err)
+	}
+	return s.manualAssetToResponse(ctx, asset)
+}
+
+// DeleteManualAsset deletes the asset with all its valuations and cash flows
+func (s *ManualAssetService) DeleteManualAsset(ctx context.Context, clientID string, assetID int) error {
+	if _, err := s.getManualAsset(ctx, clientID, assetID); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	// Rollback is a no-op once the transaction has been committed
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := s.holdingRepository.SoftDeleteByAssetID(ctx, clientID, assetID, nil, tx); err != nil {
+		return fmt.Errorf("error deleting valuations: %w", err)
+	}
+	if err := s.transactionRepository.SoftDeleteByAssetID(ctx, clientID, assetID, nil, tx); err != nil {
+		return fmt.Errorf("error deleting cash flows: %w", err)
+	}
+	if err := s.assetRepository.Delete(ctx, assetID, tx); err != nil {
+		return fmt.Errorf("error deleting manual asset: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// SetValuation records the value of the asset on a date, replacing the one of that date
+func (s *ManualAssetService) SetValuation(ctx context.Context, clientID string, assetID int, req *schemas.ManualValuationRequest) (*schemas.ManualAssetResponse, error) {
+	asset, err := s.getManualAsset(ctx, clientID, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Date.IsZero() {
+		return nil, utils.BadRequest("date is required")
+	}
+	if req.Value < 0 || req.Units < 0 {
+		return nil, utils.BadRequest("units and value cannot be negative")
+	}
+	err = s.holdingRepository.Create(ctx, &models.Holding{
+		ClientID: clientID,
+		Source:   models.SourceManual,
+		AssetID:  asset.ID,
+		Units:    req.Units,
+		Value:    req.Value,
+		Date:     req.Date.Time,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error storing valuation: %w", err)
+	}
+	return s.manualAssetToResponse(ctx, asset)
+}
+
+func (s *ManualAssetService) DeleteValuation(ctx context.Context, clientID string, assetID int, date time.Time) error {
+	if _, err := s.getManualAsset(ctx, clientID, assetID); err != nil {
+		return err
+	}
+	return s.holdingRepository.SoftDeleteByAssetID(ctx, clientID, assetID, &date, nil)
+}
+
+// AddCashFlow records money put into or taken out of the asset, replacing the cash flow of the same date and type
+func (s *ManualAssetService) AddCashFlow(ctx context.Context, clientID string, assetID int, req *schemas.ManualCashFlowRequest) (*schemas.ManualAssetResponse, error) {
+	asset, err := s.getManualAsset(ctx, clientID, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Date.IsZero() {
+		return nil, utils.BadRequest("date is required")
+	}
+	if req.Amount == 0 {
+		return nil, utils.BadRequest("amount is required")
+	}
+	transactionType := req.Type
+	if transactionType == "" {
+		transactionType = models.TransactionTypeTransfer
+	}
+	if !models.IsValidTransactionType(transactionType) {
+		return nil, utils.BadRequest(fmt.Sprintf("invalid transaction type %s", req.Type))
+	}
+	err = s.transactionRepository.Create(ctx, &models.Transaction{
+		ClientID:        clientID,
+		Source:          models.SourceManual,
+		AssetID:         asset.ID,
+		TransactionType: transactionType,
+		Units:           req.Units,
+		GrossValue:      req.Amount,
+		TotalValue:      req.Amount,
+		Date:            req.Date.Time,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error storing cash flow: %w", err)
+	}
+	return s.manualAssetToResponse(ctx, asset)
+}
+
+func (s *ManualAssetService) DeleteCashFlows(ctx context.Context, clientID string, assetID int, date time.Time) error {
+	if _, err := s.getManualAsset(ctx, clientID, assetID); err != nil {
+		return err
+	}
+	return s.transactionRepository.SoftDeleteByAssetID(ctx, clientID, assetID, &date, nil)
+}
+
+// getManualAsset returns the asset when the client tracks it by hand, so broker instruments
+// and the assets of other clients cannot be changed
+func (s *ManualAssetService) getManualAsset(ctx context.Context, clientID string, assetID int) (*models.Asset, error) {
+	asset, err := s.assetRepository.GetByID(ctx, assetID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && (asset.ClientID != clientID || asset.Deleted)) {
+		return nil, utils.NotFound(fmt.Sprintf("manual asset %d not found for account %s", assetID, clientID))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// applyManualAssetRequest validates the request and sets it on the asset
+func (s *ManualAssetService) applyManualAssetRequest(ctx context.Context, asset *models.Asset, req *schemas.ManualAssetRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return utils.BadRequest("name is required")
+	}
+
+	var category *models.AssetCategory
+	var err error
+	if req.CategoryID != nil {
+		category, err = s.assetCategoryRepository.GetByID(ctx, *req.CategoryID)
+		if err != nil {
+			return err
+		}
+		if category == nil {
+			return utils.NotFound(fmt.Sprintf("category %d not found", *req.CategoryID))
+		}
+	} else {
+		category, err = s.assetCategoryRepository.GetByName(ctx, models.UnclassifiedCategory)
+		if err != nil {
+			return err
+		}
+		if category == nil {
+			category = &models.AssetCategory{Name: models.UnclassifiedCategory}
+			if err := s.assetCategoryRepository.Create(ctx, category, nil); err != nil {
+				return fmt.Errorf("error creating asset category: %w", err)
+			}
+		}
+	}
+
+	asset.Name = name
+	asset.AssetType = strings.TrimSpace(req.AssetType)
+	asset.CategoryID = category.ID
+	// Manual valuations and cash flows are entered in pesos, which reports take as the asset currency
+	asset.Currency = utils.AssetCurrencyPesos
+	return nil
+}
+
+func (s *ManualAssetService) manualAssetToResponse(ctx context.Context, asset *models.Asset) (*schemas.ManualAssetResponse, error) {
+	category, err := s.assetCategoryRepository.GetByID(ctx, asset.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	holdings, err := s.holdingRepository.GetByAssetID(ctx, asset.ClientID, asset.ID)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := s.transactionRepository.GetByAssetID(ctx, asset.ClientID, asset.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &schemas.ManualAssetResponse{
+		ID:         asset.ID,
+		ClientID:   asset.ClientID,
+		ExternalID: asset.ExternalID,
+		Name:       asset.Name,
+		AssetType:  asset.AssetType,
+		CategoryID: asset.CategoryID,
+		Manual:     true,
+		Valuations: make([]schemas.ManualValuationResponse, 0, len(holdings)),
+		CashFlows:  make([]schemas.ManualCashFlowResponse, 0, len(transactions)),
+	}
+	if category != nil {
+		response.Category = category.Name
+	}
+	for _, holding := range holdings {
+		response.Valuations = append(response.Valuations, schemas.ManualValuationResponse{
+			Date:  schemas.Date{Time: holding.Date},
+			Units: holding.Units,
+			Value: holding.Value,
+		})
+	}
+	for _, transaction := range transactions {
+		response.CashFlows = append(response.CashFlows, schemas.ManualCashFlowResponse{
+			Date:   schemas.Date{Time: transaction.Date},
+			Type:   transaction.TransactionType,
+			Units:  transaction.Units,
+			Amount: transaction.TotalValue,
+		})
+	}
+	return response, nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"server/src/schemas"
+	"time"
+)
+
+// Methods a report can present its returns with
+const (
+	// ReturnMethodTWR chains the daily returns, so the timing of deposits and withdrawals does not weigh in
+	ReturnMethodTWR = "twr"
+	// ReturnMethodMWR solves the internal rate of return (XIRR) of the money invested, weighting it by timing
+	ReturnMethodMWR = "mwr"
+)
+
+// IsValidReturnMethod reports whether method is one of the return methods
+func IsValidReturnMethod(method string) bool {
+	return method == ReturnMethodTWR || method == ReturnMethodMWR
+}
+
+// cashFlow is an amount received (positive) or paid (negative) by the investor on a date
+type cashFlow struct {
+	date   time.Time
+	amount float64
+}
+
+// CalculateMoneyWeightedReturn calculates the money-weighted return of the assets between startDate and endDate.
+// The value held on startDate is treated as invested on that date and the value held on endDate as withdrawn,
+// and every transaction in between as money put into the assets (positive value) or taken out of them.
+// It returns the return of the period and its annualized rate (XIRR), both as percentages.
+func (rs *ReportService) CalculateMoneyWeightedReturn(assets []schemas.Asset, startDate, endDate time.Time) (float64, float64, error) {
+	start, end := truncateToDate(startDate), truncateToDate(endDate)
+	if !end.After(start) {
+		return 0, 0, fmt.Errorf("the end date must be after the start date to calculate a money-weighted return")
+	}
+	startStr, endStr := start.Format("2006-01-02"), end.Format("2006-01-02")
+
+	var flows []cashFlow
+	for _, asset := range assets {
+		holdingsByDate := make(map[string]schemas.Holding)
+		for _, holding := range asset.Holdings {
+			if holding.DateRequested != nil {
+				holdingsByDate[holding.DateRequested.Format("2006-01-02")] = holding
+			}
+		}
+		flows = append(flows,
+			cashFlow{date: start, amount: -holdingsByDate[startStr].Value},
+			cashFlow{date: end, amount: holdingsByDate[endStr].Value},
+		)
+
+		for _, transaction := range asset.Transactions {
+			if transaction.Date == nil {
+				continue
+			}
+			date := truncateToDate(*transaction.Date)
+			if !date.After(start) || date.After(end) {
+				continue
+			}
+			// Like the time-weighted return, movements without value are valued at the price of their date
+			value := transaction.Value
+			if value == 0 && transaction.Units != 0 {
+				if holding, exists := holdingsByDate[date.Format("2006-01-02")]; exists && holding.Units != 0 {
+					value = transaction.Units * holding.Value / holding.Units
+				}
+			}
+			flows = append(flows, cashFlow{date: date, amount: -value})
+		}
+	}
+
+	rate, err := moneyWeightedRate(flows, start, end)
+	if err != nil {
+		return 0, 0, err
+	}
+	years := end.Sub(start).Hours() / 24 / 365
+	return rate * 100, (math.Pow(1+rate, 1/years) - 1) * 100, nil
+}
+
+// moneyWeightedRate solves the rate of the period between start and end that makes the present value of
+// the cash flows zero, discounting each one by the fraction of the period elapsed at its date.
+// Newton's method is tried first, falling back to bisection when it does not converge.
+func moneyWeightedRate(flows []cashFlow, start, end time.Time) (float64, error) {
+	period := end.Sub(start).Hours()
+	var paid, received, scale float64
+	for _, flow := range flows {
+		if flow.amount < 0 {
+			paid += flow.amount
+		} else {
+			received += flow.amount
+		}
+		scale += math.Abs(flow.amount)
+	}
+	if paid == 0 || received == 0 {
+		return 0, fmt.Errorf("the cash flows must include money paid and received to calculate a money-weighted return")
+	}
+
+	presentValue := func(rate float64) (float64, float64) {
+		var value, derivative float64
+		for _, flow := range flows {
+			elapsed := flow.date.Sub(start).Hours() / period
+			discount := math.Pow(1+rate, -elapsed)
+			value += flow.amount * discount
+			derivative -= elapsed * flow.amount * discount / (1 + rate)
+		}
+		return value, derivative
+	}
+	tolerance := scale * 1e-10
+
+	rate := 0.0
+	for i := 0; i < 100; i++ {
+		value, derivative := presentValue(rate)
+		if math.Abs(value) < tolerance {
+			return rate, nil
+		}
+		if derivative == 0 {
+			break
+		}
+		next := rate - value/derivative
+		if next <= -1 || math.IsNaN(next) || math.IsInf(next, 0) {
+			break
+		}
+		rate = next
+	}
+
+	low, high := -0.999999, 1.0
+	lowValue, _ := presentValue(low)
+	highValue, _ := presentValue(high)
+	for lowValue*highValue > 0 && high < 1e6 {
+		high *= 2
+		highValue, _ = presentValue(high)
+	}
+	if lowValue*highValue > 0 {
+		return 0, fmt.Errorf("no money-weighted return solves the cash flows")
+	}
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+		midValue, _ := presentValue(mid)
+		if math.Abs(midValue) < tolerance {
+			return mid, nil
+		}
+		if midValue*lowValue > 0 {
+			low, lowValue = mid, midValue
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2, nil
+}
+
+// chainReturns compounds the returns of consecutive ranges into the return of the whole period, as a percentage
+func chainReturns(returns []schemas.ReturnByDate) float64 {
+	chained := 1.0
+	for _, returnByDate := range returns {
+		chained *= 1 + returnByDate.ReturnPercentage/100
+	}
+	return (chained - 1) * 100
+}
+
+func truncateToDate(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"server/src/schemas"
+	"time"
+)
+
+// CalculateRealReturns deflates the returns of the report by the monthly inflation (CPI), setting the real
+// returns of every asset, category and the total. The inflation of each month is spread evenly over its
+// days, so intervals of any length can be deflated. Reports without inflation are left unchanged.
+func (rs *ReportService) CalculateRealReturns(accountsReport *schemas.AccountsReports, monthlyInflation *schemas.VariableWithValuationResponse) {
+	if monthlyInflation == nil || len(monthlyInflation.Valuations) == 0 || len(accountsReport.TotalReturns) == 0 {
+		return
+	}
+
+	returnSeries := [][]schemas.ReturnByDate{accountsReport.TotalReturns, accountsReport.TotalMoneyWeightedReturns}
+	if accountsReport.AssetsReturnByCategory != nil {
+		for _, assetReturns := range *accountsReport.AssetsReturnByCategory {
+			for _, assetReturn := range assetReturns {
+				returnSeries = append(returnSeries, assetReturn.ReturnsByDateRange)
+			}
+		}
+	}
+	if accountsReport.CategoryAssetsReturn != nil {
+		for _, categoryReturn := range *accountsReport.CategoryAssetsReturn {
+			returnSeries = append(returnSeries, categoryReturn.ReturnsByDateRange)
+		}
+	}
+	var firstDate, lastDate time.Time
+	for _, returns := range returnSeries {
+		for _, returnByDate := range returns {
+			if firstDate.IsZero() || returnByDate.StartDate.Before(firstDate) {
+				firstDate = returnByDate.StartDate
+			}
+			if returnByDate.EndDate.After(lastDate) {
+				lastDate = returnByDate.EndDate
+			}
+		}
+	}
+	priceLevel := newMonthlyRateIndex(monthlyInflation.Valuations, firstDate, lastDate)
+	if priceLevel == nil {
+		return
+	}
+
+	if accountsReport.AssetsReturnByCategory != nil {
+		for _, assetReturns := range *accountsReport.AssetsReturnByCategory {
+			for i := range assetReturns {
+				assetReturns[i].RealReturnsByDateRange = deflateReturns(priceLevel, assetReturns[i].ReturnsByDateRange)
+				assetReturns[i].RealReturn = chainReturns(assetReturns[i].RealReturnsByDateRange)
+			}
+		}
+	}
+	if accountsReport.CategoryAssetsReturn != nil {
+		for category, categoryReturn := range *accountsReport.CategoryAssetsReturn {
+			categoryReturn.RealReturnsByDateRange = deflateReturns(priceLevel, categoryReturn.ReturnsByDateRange)
+			categoryReturn.RealReturn = chainReturns(categoryReturn.RealReturnsByDateRange)
+			(*accountsReport.CategoryAssetsReturn)[category] = categoryReturn
+		}
+	}
+	accountsReport.TotalRealReturns = deflateReturns(priceLevel, accountsReport.TotalReturns)
+	accountsReport.TotalRealMoneyWeightedReturns = deflateReturns(priceLevel, accountsReport.TotalMoneyWeightedReturns)
+	accountsReport.RealReturn = chainReturns(accountsReport.TotalRealReturns)
+}
+
+// deflateReturns returns the real returns of the nominal ones, removing the inflation of each range
+func deflateReturns(priceLevel *dailyIndex, returns []schemas.ReturnByDate) []schemas.ReturnByDate {
+	realReturns := make([]schemas.ReturnByDate, 0, len(returns))
+	for _, returnByDate := range returns {
+		inflation := priceLevel.level(returnByDate.EndDate) / priceLevel.level(returnByDate.StartDate)
+		realReturns = append(realReturns, schemas.ReturnByDate{
+			StartDate:        returnByDate.StartDate,
+			EndDate:          returnByDate.EndDate,
+			ReturnPercentage: ((1+returnByDate.ReturnPercentage/100)/inflation - 1) * 100,
+		})
+	}
+	return realReturns
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/schemas"
+	"server/src/utils"
+	"sort"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	DefaultReconciliationTolerance = 0.01
+	reconciliationSheetName        = "Conciliacion"
+)
+
+type ReconciliationServiceI interface {
+	Reconcile(ctx context.Context, clientID string, startDate, endDate time.Time, tolerance float64) (*schemas.ReconciliationReport, error)
+	GenerateXLSXReconciliation(ctx context.Context, report *schemas.ReconciliationReport) (*excelize.File, error)
+}
+
+// ReconciliationService checks that the stored holdings of a client add up with its stored transactions
+type ReconciliationService struct {
+	holdingRepo     repositories.HoldingRepository
+	transactionRepo repositories.TransactionRepository
+	assetRepo       repositories.AssetRepository
+}
+
+func NewReconciliationService(
+	holdingRepo repositories.HoldingRepository,
+	transactionRepo repositories.TransactionRepository,
+	assetRepo repositories.AssetRepository,
+) *ReconciliationService {
+	return &ReconciliationService{
+		holdingRepo:     holdingRepo,
+		transactionRepo: transactionRepo,
+		assetRepo:       assetRepo,
+	}
+}
+
+// Reconcile compares, for every pair of consecutive dates with stored holdings, the units held on the
+// later date with the units held on the previous one plus the transaction units dated after the previous
+// date and up to the later one. Assets whose difference exceeds the tolerance are reported.
+func (s *ReconciliationService) Reconcile(ctx context.Context, clientID string, startDate, endDate time.Time, tolerance float64) (*schemas.ReconciliationReport, error) {
+	logger := utils.LoggerFromContext(ctx)
+	logger.Infof("Reconciling holdings and transactions for account %s from %s to %s", clientID, startDate, endDate)
+
+	holdings, err := s.holdingRepo.GetByClientID(ctx, clientID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("error getting holdings: %w", err)
+	}
+	transactions, err := s.transactionRepo.GetByClientID(ctx, clientID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("error getting transactions: %w", err)
+	}
+
+	// Manual assets are only valued when their value changes, so there is nothing to reconcile
+	unitsByDate := make(map[string]map[int]float64)
+	for _, holding := range holdings {
+		if holding.Source == models.SourceManual {
+			continue
+		}
+		date := holding.Date.Format(utils.ShortDashDateLayout)
+		if unitsByDate[date] == nil {
+			unitsByDate[date] = make(map[int]float64)
+		}
+		unitsByDate[date][holding.AssetID] += holding.Units
+	}
+	dates := make([]string, 0, len(unitsByDate))
+	for date := range unitsByDate {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	report := &schemas.ReconciliationReport{
+		AccountID:     clientID,
+		StartDate:     schemas.Date{Time: startDate},
+		EndDate:       schemas.Date{Time: endDate},
+		Tolerance:     tolerance,
+		Discrepancies: make([]schemas.AssetDiscrepancy, 0),
+	}
+	if len(dates) < 2 {
+		return report, nil
+	}
+
+	for i := 1; i < len(dates); i++ {
+		previousDate, date := dates[i-1], dates[i]
+		transactionUnits := s.sumTransactionUnits(transactions, previousDate, date)
+
+		assetIDs := make(map[int]bool)
+		for assetID := range unitsByDate[previousDate] {
+			assetIDs[assetID] = true
+		}
+		for assetID := range unitsByDate[date] {
+			assetIDs[assetID] = true
+		}
+		for assetID := range transactionUnits {
+			assetIDs[assetID] = true
+		}
+
+		for assetID := range assetIDs {
+			expected := unitsByDate[previousDate][assetID] + transactionUnits[assetID]
+			actual := unitsByDate[date][assetID]
+			if math.Abs(actual-expected) <= tolerance {
+				continue
+			}
+			previous, _ := time.Parse(utils.ShortDashDateLayout, previousDate)
+			current, _ := time.Parse(utils.ShortDashDateLayout, date)
+			report.Discrepancies = append(report.Discrepancies, schemas.AssetDiscrepancy{
+				AssetID:          assetID,
+				PreviousDate:     schemas.Date{Time: previous},
+				Date:             schemas.Date{Time: current},
+				PreviousUnits:    unitsByDate[previousDate][assetID],
+				TransactionUnits: transactionUnits[assetID],
+				ExpectedUnits:    expected,
+				ActualUnits:      actual,
+				Difference:       actual - expected,
+			})
+		}
+	}
+	report.DatesChecked = len(dates) - 1
+
+	if err = s.setAssetNames(ctx, report.Discrepancies); err != nil {
+		return nil, err
+	}
+	sort.Slice(report.Discrepancies, func(i, j int) bool {
+		a, b := report.Discrepancies[i], report.Discrepancies[j]
+		if !a.Date.Equal(b.Date.Time) {
+			return a.Date.Before(b.Date.Time)
+		}
+		return a.ExternalID < b.ExternalID
+	})
+	return report, nil
+}
+
+// sumTransactionUnits sums the transaction units by asset for dates after previousDate and up to date
+func (s *ReconciliationService) sumTransactionUnits(transactions []models.Transaction, previousDate, date string) map[int]float64 {
+	units := make(map[int]float64)
+	for _, transaction := range transactions {
+		if transaction.Source == models.SourceManual {
+			continue
+		}
+		transactionDate := transaction.Date.Format(utils.ShortDashDateLayout)
+		if transactionDate > previousDate && transactionDate <= date {
+			units[transaction.AssetID] += transaction.Units
+		}
+	}
+	return units
+}
+
+func (s *ReconciliationService) setAssetNames(ctx context.Context, discrepancies []schemas.AssetDiscrepancy) error {
+	if len(discrepancies) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(discrepancies))
+	for _, discrepancy := range discrepancies {
+		ids = append(ids, discrepancy.AssetID)
+	}
+	assets, err := s.assetRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("error getting assets: %w", err)
+	}
+	assetsByID := make(map[int]models.Asset, len(assets))
+	for _, asset := range assets {
+		assetsByID[asset.ID] = asset
+	}
+	for i := range discrepancies {
+		asset := assetsByID[discrepancies[i].AssetID]
+		discrepancies[i].ExternalID = asset.ExternalID
+		discrepancies[i].AssetName = asset.Name
+	}
+	return nil
+}
+
+// GenerateXLSXReconciliation writes the discrepancies of the report into a single sheet
+func (s *ReconciliationService) GenerateXLSXReconciliation(_ context.Context, report *schemas.ReconciliationReport) (*excelize.File, error) {
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", reconciliationSheetName); err != nil {
+		return nil, err
+	}
+
+	headers := []interface{}{
+		"Fecha Anterior", "Fecha", "Activo", "Denominacion",
+		"Cantidad Anterior", "Movimientos", "Cantidad Esperada", "Cantidad Real", "Diferencia",
+	}
+	if err := f.SetSheetRow(reconciliationSheetName, "A1", &headers); err != nil {
+		return nil, err
+	}
+	for i, discrepancy := range report.Discrepancies {
+		row := []interface{}{
+			discrepancy.PreviousDate.Format(utils.ShortDashDateLayout),
+			discrepancy.Date.Format(utils.ShortDashDateLayout),
+			discrepancy.ExternalID,
+			discrepancy.AssetName,
+			discrepancy.PreviousUnits,
+			discrepancy.TransactionUnits,
+			discrepancy.ExpectedUnits,
+			discrepancy.ActualUnits,
+			discrepancy.Difference,
+		}
+		if err := f.SetSheetRow(reconciliationSheetName, fmt.Sprintf("A%d", i+2), &row); err != nil {
+			return nil, err
+		}
+	}
+
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, err
+	}
+	if err = f.SetCellStyle(reconciliationSheetName, "A1", "I1", headerStyle); err != nil {
+		return nil, err
+	}
+	if err = f.SetColWidth(reconciliationSheetName, "A", "I", 18); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
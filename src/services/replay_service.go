@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"server/src/clients/esco"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/schemas"
+	"server/src/utils"
+	"sort"
+	"time"
+)
+
+type ReplayServiceI interface {
+	ReplayESCOPayloads(ctx context.Context, accountID string, startDate, endDate time.Time, dryRun bool) (*schemas.ESCOReplayResponse, error)
+}
+
+// replayEndpoints are the archived endpoints a sync is built from, with the parser used for each one
+var replayEndpoints = map[string]ESCOExportType{
+	esco.EndpointGetEstadoCuenta:      ESCOExportEstadoCuenta,
+	esco.EndpointGetBoletos:           ESCOExportBoletos,
+	esco.EndpointGetCtaCteConsolidado: ESCOExportCtaCte,
+}
+
+// ReplayService rebuilds stored account data from archived ESCO payloads, so parser fixes can be
+// applied to history without refetching it from ESCO
+type ReplayService struct {
+	payloadRepository repositories.ESCOPayloadRepository
+	escoService       ESCOServiceI
+	syncService       SyncServiceI
+}
+
+func NewReplayService(payloadRepository repositories.ESCOPayloadRepository, escoService ESCOServiceI, syncService SyncServiceI) *ReplayService {
+	return &ReplayService{
+		payloadRepository: payloadRepository,
+		escoService:       escoService,
+		syncService:       syncService,
+	}
+}
+
+// ReplayESCOPayloads parses the archived payloads of the account between startDate and endDate (exclusive)
+// and replaces the stored data of every date with archived holdings. When several payloads cover the same
+// date the most recently fetched one wins. As on sync, the cuenta corriente trades settled on the date of a
+// boleto are dropped in favour of the boleto. With dryRun the result is only parsed and counted.
+func (s *ReplayService) ReplayESCOPayloads(ctx context.Context, accountID string, startDate, endDate time.Time, dryRun bool) (*schemas.ESCOReplayResponse, error) {
+	logger := utils.LoggerFromContext(ctx)
+	ctx = utils.WithSyncTrigger(ctx, utils.SyncTriggerReplay)
+	ctx = utils.WithSyncSource(ctx, models.SourceESCO)
+
+	endpoints := make([]string, 0, len(replayEndpoints))
+	for endpoint := range replayEndpoints {
+		endpoints = append(endpoints, endpoint)
+	}
+	payloads, err := s.payloadRepository.GetLatestByAccount(ctx, accountID, endpoints, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("error getting archived payloads: %w", err)
+	}
+	logger.Infof("Replaying %d archived payloads for account %s from %s to %s", len(payloads), accountID, startDate, endDate)
+
+	accountState := schemas.NewAccountState()
+	boletos := schemas.NewAccountState()
+	movements := schemas.NewAccountState()
+	holdingDates := make(map[string]time.Time)
+	// Transaction dates are claimed per endpoint, boletos and cuenta corriente payloads cover them independently
+	claimedTransactionDates := map[ESCOExportType]map[string]bool{
+		ESCOExportBoletos: make(map[string]bool),
+		ESCOExportCtaCte:  make(map[string]bool),
+	}
+	for _, payload := range payloads {
+		if payload.StartDate == nil || payload.EndDate == nil {
+			continue
+		}
+		parsed, err := s.escoService.ParseExport(replayEndpoints[payload.Endpoint], payload.Payload, payload.StartDate)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing archived payload %d: %w", payload.ID, err)
+		}
+
+		switch exportType := replayEndpoints[payload.Endpoint]; exportType {
+		case ESCOExportEstadoCuenta:
+			day := *payload.StartDate
+			if _, ok := holdingDates[day.Format(utils.ShortDashDateLayout)]; ok {
+				continue
+			}
+			holdingDates[day.Format(utils.ShortDashDateLayout)] = day
+			mergeReplayedAssets(accountState, parsed, func(asset *schemas.Asset) {
+				asset.Transactions = nil
+			})
+		case ESCOExportBoletos, ESCOExportCtaCte:
+			target, claimed := movements, claimedTransactionDates[exportType]
+			if exportType == ESCOExportBoletos {
+				target = boletos
+			}
+			// Only keep the transactions of the requested range not covered by a newer payload
+			covered := make(map[string]bool)
+			for day := *payload.StartDate; day.Before(*payload.EndDate); day = day.AddDate(0, 0, 1) {
+				if !claimed[day.Format(utils.ShortDashDateLayout)] {
+					covered[day.Format(utils.ShortDashDateLayout)] = true
+				}
+			}
+			mergeReplayedAssets(target, parsed, func(asset *schemas.Asset) {
+				asset.Holdings = nil
+				transactions := make([]schemas.Transaction, 0, len(asset.Transactions))
+				for _, transaction := range asset.Transactions {
+					if transaction.Date != nil && covered[transaction.Date.Format(utils.ShortDashDateLayout)] {
+						transactions = append(transactions, transaction)
+					}
+				}
+				asset.Transactions = transactions
+			})
+			for day := range covered {
+				claimed[day] = true
+			}
+		}
+	}
+
+	for id, asset := range *movements.Assets {
+		if boleto, ok := (*boletos.Assets)[id]; ok {
+			asset.Transactions = withoutBoletoMovements(asset.Transactions, boleto.Transactions)
+			(*movements.Assets)[id] = asset
+		}
+	}
+	mergeReplayedAssets(accountState, boletos, func(*schemas.Asset) {})
+	mergeReplayedAssets(accountState, movements, func(*schemas.Asset) {})
+
+	dates := make([]time.Time, 0, len(holdingDates))
+	for _, day := range holdingDates {
+		dates = append(dates, day)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+
+	response := &schemas.ESCOReplayResponse{
+		AccountID: accountID,
+		Payloads:  len(payloads),
+		Assets:    len(*accountState.Assets),
+		Dates:     make([]schemas.Date, 0, len(dates)),
+	}
+	for id, asset := range *accountState.Assets {
+		sortHoldingsByDateRequested(&asset)
+		(*accountState.Assets)[id] = asset
+		response.Holdings += len(asset.Holdings)
+		for _, transaction := range asset.Transactions {
+			if transaction.Date != nil {
+				if _, ok := holdingDates[transaction.Date.Format(utils.ShortDashDateLayout)]; ok {
+					response.Transactions++
+				}
+			}
+		}
+	}
+	for _, day := range dates {
+		response.Dates = append(response.Dates, schemas.Date{Time: day})
+	}
+	if dryRun || len(dates) == 0 {
+		return response, nil
+	}
+
+	// Each contiguous range is replaced on its own, so dates without archived holdings keep their data
+	for _, dateRange := range groupContiguousDates(dates) {
+		rangeDates := make([]time.Time, 0)
+		for day := dateRange[0]; day.Before(dateRange[1]); day = day.AddDate(0, 0, 1) {
+			rangeDates = append(rangeDates, day)
+		}
+		if err = s.syncService.ReplaceAccountState(ctx, accountID, accountState, rangeDates); err != nil {
+			return nil, fmt.Errorf("error storing replayed account state: %w", err)
+		}
+	}
+	response.Stored = true
+	return response, nil
+}
+
+// mergeReplayedAssets appends the holdings and transactions of parsed to state, after letting
+// keep drop the ones that should not be replayed
+func mergeReplayedAssets(state, parsed *schemas.AccountState, keep func(asset *schemas.Asset)) {
+	for id, asset := range *parsed.Assets {
+		keep(&asset)
+		existing, ok := (*state.Assets)[id]
+		if !ok {
+			(*state.Assets)[id] = asset
+			continue
+		}
+		existing.Holdings = append(existing.Holdings, asset.Holdings...)
+		existing.Transactions = append(existing.Transactions, asset.Transactions...)
+		(*state.Assets)[id] = existing
+	}
+}
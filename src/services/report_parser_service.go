@@ -69,15 +69,21 @@ func (rc *ReportParserService) ParseAccountsReportToPDF(ctx context.Context, dat
 	returnsDF := *dataframesAndCharts.ReturnDF
 	referenceVariablesDF := *dataframesAndCharts.ReferenceVariablesDF
 	returnWithReferencesDF := utils.UnionDataFramesByIndex(returnsDF, referenceVariablesDF, "DateRequested")
-	orderedReturnWithReferencesDF := utils.SortDataFrameColumns(&returnWithReferencesDF, []string{"DateRequested"}, []string{"TOTAL"})
+	orderedReturnWithReferencesDF := utils.SortDataFrameColumns(&returnWithReferencesDF, []string{"DateRequested"}, []string{"TOTAL REAL", "TOTAL"})
 	// Generate bar graphs for each dataframe
 	for _, report := range []*ReportConfig{
-		{name: "RETORNO", df: orderedReturnWithReferencesDF, columnsToInclude: []string{"Inflacion Mensual", "USD A3500 Variacion", "TOTAL"}, graphType: "line", isPercentage: true, includeTable: true},
+		{name: "RETORNO", df: orderedReturnWithReferencesDF, columnsToInclude: []string{"Inflacion Mensual", "USD A3500 Variacion", "TOTAL", "TOTAL REAL"}, graphType: "line", isPercentage: true, includeTable: true},
 		{name: "TENENCIA POR CATEGORIAS", df: dataframesAndCharts.CategoryDF, columnsToExclude: []string{"TOTAL"}, graphType: "line", includeTable: true},
 		// {name: "TENENCIA POR CATEGORIAS PORCENTAJE", df: dataframesAndCharts.CategoryPercentageDF, columnsToExclude: []string{"TOTAL"}, graphType: "bar", isPercentage: true},
 		{name: "TENENCIA POR CATEGORIAS PORCENTAJE", df: dataframesAndCharts.ReportPercentageDf, graphType: "pie", columnsToExclude: []string{"TOTAL"}, isPercentage: true},
 		{name: "TENENCIA POR CATEGORIAS PORCENTAJE", df: dataframesAndCharts.ReportPercentageDf, columnsToExclude: []string{"TOTAL"}, graphType: "bar", isPercentage: true},
 		{name: "TENENCIA TOTAL", df: dataframesAndCharts.ReportDF, columnsToInclude: []string{"TOTAL"}, graphType: "line", includeTable: true},
+		{name: "RENDIMIENTOS DEL PERIODO", df: dataframesAndCharts.ReturnSummaryDF, includeTable: true},
+		{name: "BENCHMARKS", df: dataframesAndCharts.BenchmarkDF, columnsToInclude: benchmarkGraphColumns(dataframesAndCharts.BenchmarkDF), graphType: "line", isPercentage: true, includeTable: true},
+		{name: "COMPARACION CON BENCHMARKS", df: dataframesAndCharts.BenchmarkSummaryDF, includeTable: true},
+		{name: "RIESGO", df: dataframesAndCharts.RiskDF, includeTable: true},
+		{name: "CONTRIBUCION AL RENDIMIENTO", df: dataframesAndCharts.ContributionDF, includeTable: true},
+		{name: "ATRIBUCION POR CATEGORIA", df: dataframesAndCharts.AttributionDF, includeTable: true},
 	} {
 		if report.df == nil {
 			continue
@@ -93,6 +99,10 @@ func (rc *ReportParserService) ParseAccountsReportToPDF(ctx context.Context, dat
 			htmlContents = append(htmlContents, htmlContent)
 		}
 
+		if report.graphType == "" {
+			continue
+		}
+
 		// Generate bar graph and embed in HTML
 		switch report.graphType {
 		case "bar":
@@ -117,6 +127,20 @@ func (rc *ReportParserService) ParseAccountsReportToPDF(ctx context.Context, dat
 	return pdfBuffer.Bytes(), nil
 }
 
+// benchmarkGraphColumns returns the cumulative returns of the benchmarks dataframe, charted against the total
+func benchmarkGraphColumns(benchmarkDF *dataframe.DataFrame) []string {
+	if benchmarkDF == nil {
+		return nil
+	}
+	var columns []string
+	for _, column := range benchmarkDF.Names() {
+		if column == "TOTAL" || strings.HasSuffix(column, "-Acumulado") {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
 func (rc *ReportParserService) generateLineGraphHTML(report *ReportConfig) (string, error) {
 	df := report.df
 	// Create a bar chart
@@ -20,6 +20,10 @@ type ReportServiceI interface {
 	GenerateReport(ctx context.Context, accountStateByCategory *schemas.AccountStateByCategory, startDate, endDate time.Time, interval time.Duration) (*schemas.AccountsReports, error)
 	GenerateReportDataframes(ctx context.Context, accountsReport *schemas.AccountsReports, startDate, endDate time.Time, interval time.Duration) (*schemas.ReportDataframes, error)
 	GenerateXLSXReport(ctx context.Context, dataframesAndCharts *schemas.ReportDataframes) (*excelize.File, error)
+	CalculateRealReturns(accountsReport *schemas.AccountsReports, monthlyInflation *schemas.VariableWithValuationResponse)
+	CalculateBenchmarks(accountsReport *schemas.AccountsReports, benchmarks []schemas.BenchmarkSeries)
+	CalculateRiskMetrics(accountsReport *schemas.AccountsReports, riskFreeRates []schemas.VariableValuation)
+	CalculateAttribution(accountsReport *schemas.AccountsReports, target *schemas.TargetAllocationResponse, categoryBenchmarks map[string]schemas.BenchmarkSeries)
 }
 
 type ReportService struct{}
@@ -64,17 +68,94 @@ func (rs *ReportService) GenerateReport(
 	// Calculate total returns using weighted asset returns instead of total holdings/transactions
 	totalReturns := rs.CalculateWeightedTotalReturns(assetReturnsByCategory, *accountStateByCategory.AssetsByCategory, totalHoldingsByDate, interval)
 	finalIntervalReturn := rs.CalculateFinalIntervalReturn(totalReturns)
+
+	// Money-weighted returns span from the first to the last date with holdings, over the same assets
+	// the time-weighted returns are calculated for
+	var reportAssets []schemas.Asset
+	for category, assets := range *accountStateByCategory.AssetsByCategory {
+		if category != "ARS" {
+			reportAssets = append(reportAssets, assets...)
+		}
+	}
+	sortedTotalHoldings := rs.sortHoldingsByDate(totalHoldingsByDate)
+	var moneyWeightedReturn, annualizedMoneyWeightedReturn float64
+	if len(sortedTotalHoldings) > 1 {
+		firstDate := *sortedTotalHoldings[0].DateRequested
+		lastDate := *sortedTotalHoldings[len(sortedTotalHoldings)-1].DateRequested
+		for category, assetReturns := range assetReturnsByCategory {
+			for i := range assetReturns {
+				asset := (*accountStateByCategory.AssetsByCategory)[category][i]
+				assetReturns[i].TimeWeightedReturn = chainReturns(assetReturns[i].ReturnsByDateRange)
+				assetReturns[i].MoneyWeightedReturn, assetReturns[i].AnnualizedMoneyWeightedReturn, _ = rs.CalculateMoneyWeightedReturn([]schemas.Asset{asset}, firstDate, lastDate)
+			}
+		}
+		for category, categoryReturn := range categoryAssetReturns {
+			categoryReturn.TimeWeightedReturn = chainReturns(categoryReturn.ReturnsByDateRange)
+			categoryReturn.MoneyWeightedReturn, categoryReturn.AnnualizedMoneyWeightedReturn, _ = rs.CalculateMoneyWeightedReturn((*accountStateByCategory.AssetsByCategory)[category], firstDate, lastDate)
+			categoryAssetReturns[category] = categoryReturn
+		}
+		moneyWeightedReturn, annualizedMoneyWeightedReturn, _ = rs.CalculateMoneyWeightedReturn(reportAssets, firstDate, lastDate)
+	}
+	totalMoneyWeightedReturns := make([]schemas.ReturnByDate, 0, len(totalReturns))
+	for _, totalReturn := range totalReturns {
+		intervalReturn, _, _ := rs.CalculateMoneyWeightedReturn(reportAssets, totalReturn.StartDate, totalReturn.EndDate)
+		totalMoneyWeightedReturns = append(totalMoneyWeightedReturns, schemas.ReturnByDate{
+			StartDate:        totalReturn.StartDate,
+			EndDate:          totalReturn.EndDate,
+			ReturnPercentage: intervalReturn,
+		})
+	}
+	rs.calculateContributions(assetReturnsByCategory, categoryAssetReturns, *accountStateByCategory.AssetsByCategory, totalHoldingsByDate, totalReturns)
+
+	// Risk is measured on daily returns whatever the interval of the report, counting only the days
+	// each asset, category and the total were held
+	dailyInterval := 24 * time.Hour
+	dailyReturnsByCategory := assetReturnsByCategory
+	totalDailyReturns := totalReturns
+	if interval != dailyInterval {
+		dailyReturnsByCategory = make(map[string][]schemas.AssetReturn)
+		for category, assets := range *accountStateByCategory.AssetsByCategory {
+			if category == "ARS" {
+				continue
+			}
+			for _, asset := range assets {
+				assetReturn, _ := rs.CalculateAssetReturn(asset, dailyInterval)
+				dailyReturnsByCategory[category] = append(dailyReturnsByCategory[category], assetReturn)
+			}
+		}
+		totalDailyReturns = rs.CalculateWeightedTotalReturns(dailyReturnsByCategory, *accountStateByCategory.AssetsByCategory, totalHoldingsByDate, dailyInterval)
+	}
+	for category, assetReturns := range assetReturnsByCategory {
+		for i := range assetReturns {
+			asset := (*accountStateByCategory.AssetsByCategory)[category][i]
+			assetReturns[i].DailyReturns = returnsWhileHeld(dailyReturnsByCategory[category][i].ReturnsByDateRange, asset.Holdings)
+		}
+	}
+	for category, categoryReturn := range categoryAssetReturns {
+		dailyCategoryReturn, _ := rs.CalculateCategoryReturn(category, accountStateByCategory.AssetsByCategory, &dailyReturnsByCategory, accountStateByCategory.CategoryAssets, dailyInterval)
+		categoryReturn.DailyReturns = returnsWhileHeld(dailyCategoryReturn.ReturnsByDateRange, (*accountStateByCategory.CategoryAssets)[category].Holdings)
+		categoryAssetReturns[category] = categoryReturn
+	}
+	totalDailyReturns = returnsWhileHeld(totalDailyReturns, totalHoldingsByDate)
+
 	filteredAssets := rs.FilterAssetsByCategoryHoldingsByInterval(accountStateByCategory.AssetsByCategory, startDate, endDate, interval)
 	filteredCategoryAssets := rs.FilterAssetsHoldingsByInterval(accountStateByCategory.CategoryAssets, startDate, endDate, interval)
 	filteredTotalHoldings := rs.FilterHoldingsByInterval(totalHoldingsByDate, startDate, endDate, interval)
 	return &schemas.AccountsReports{
-		AssetsByCategory:       &filteredAssets,
-		AssetsReturnByCategory: &assetReturnsByCategory,
-		CategoryAssets:         &filteredCategoryAssets,
-		CategoryAssetsReturn:   &categoryAssetReturns,
-		TotalHoldingsByDate:    filteredTotalHoldings,
-		TotalReturns:           totalReturns,
-		FinalIntervalReturn:    finalIntervalReturn,
+		AssetsByCategory:              &filteredAssets,
+		AssetsReturnByCategory:        &assetReturnsByCategory,
+		CategoryAssets:                &filteredCategoryAssets,
+		CategoryAssetsReturn:          &categoryAssetReturns,
+		TotalHoldingsByDate:           filteredTotalHoldings,
+		TotalReturns:                  totalReturns,
+		TotalMoneyWeightedReturns:     totalMoneyWeightedReturns,
+		TotalDailyReturns:             totalDailyReturns,
+		FinalIntervalReturn:           finalIntervalReturn,
+		ReturnMethod:                  ReturnMethodTWR,
+		TimeWeightedReturn:            (finalIntervalReturn - 1) * 100,
+		MoneyWeightedReturn:           moneyWeightedReturn,
+		AnnualizedMoneyWeightedReturn: annualizedMoneyWeightedReturn,
+		TransactionsByType:            accountStateByCategory.TransactionsByType,
 	}, nil
 }
 
@@ -151,6 +232,7 @@ func (rs *ReportService) CalculateAssetReturn(asset schemas.Asset, interval time
 		Type:               asset.Type,
 		Denomination:       asset.Denomination,
 		Category:           asset.Category,
+		Manual:             asset.Manual,
 		ReturnsByDateRange: returnsByInterval,
 	}, nil
 }
@@ -510,6 +592,12 @@ func (rs *ReportService) GenerateReportDataframes(ctx context.Context, accountsR
 	var referenceVariablesDf *dataframe.DataFrame
 	var categoryDf *dataframe.DataFrame
 	var categoryPercentageDf *dataframe.DataFrame
+	returnSummaryDf := rs.parseReturnSummaryToDataFrame(accountsReport)
+	benchmarkDf := rs.parseBenchmarksToDataFrame(accountsReport)
+	benchmarkSummaryDf := rs.parseBenchmarkSummaryToDataFrame(accountsReport)
+	riskDf := rs.parseRiskToDataFrame(accountsReport)
+	contributionDf := rs.parseContributionToDataFrame(accountsReport)
+	attributionDf := rs.parseAttributionToDataFrame(accountsReport)
 
 	var wg sync.WaitGroup
 	wg.Add(4)
@@ -572,6 +660,7 @@ func (rs *ReportService) GenerateReportDataframes(ctx context.Context, accountsR
 		"DateRequested",
 	}
 	finalColumns := []string{
+		"TOTAL REAL",
 		"TOTAL",
 	}
 	reportDf = utils.SortDataFrameColumns(reportDf, firstColumns, finalColumns)
@@ -580,6 +669,7 @@ func (rs *ReportService) GenerateReportDataframes(ctx context.Context, accountsR
 	referenceVariablesDf = utils.SortDataFrameColumns(referenceVariablesDf, firstColumns, finalColumns)
 	categoryDf = utils.SortDataFrameColumns(categoryDf, firstColumns, finalColumns)
 	categoryPercentageDf = utils.SortDataFrameColumns(categoryPercentageDf, firstColumns, finalColumns)
+	benchmarkDf = utils.SortDataFrameColumns(benchmarkDf, firstColumns, finalColumns)
 
 	return &schemas.ReportDataframes{
 		ReportDF:             reportDf,
@@ -588,6 +678,12 @@ func (rs *ReportService) GenerateReportDataframes(ctx context.Context, accountsR
 		ReferenceVariablesDF: referenceVariablesDf,
 		CategoryDF:           categoryDf,
 		CategoryPercentageDF: categoryPercentageDf,
+		ReturnSummaryDF:      returnSummaryDf,
+		BenchmarkDF:          benchmarkDf,
+		BenchmarkSummaryDF:   benchmarkSummaryDf,
+		RiskDF:               riskDf,
+		ContributionDF:       contributionDf,
+		AttributionDF:        attributionDf,
 	}, nil
 }
 
@@ -618,6 +714,48 @@ func (rs *ReportService) GenerateXLSXReport(ctx context.Context, dataframesAndCh
 		}
 	}
 
+	if dataframesAndCharts.ReturnSummaryDF != nil {
+		file, err = rs.convertReturnSummaryToExcel(file, dataframesAndCharts.ReturnSummaryDF, "Rendimientos", "Rendimientos del periodo")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if dataframesAndCharts.RiskDF != nil {
+		file, err = rs.convertReturnSummaryToExcel(file, dataframesAndCharts.RiskDF, "Riesgo", "Riesgo del periodo")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if dataframesAndCharts.ContributionDF != nil {
+		file, err = rs.convertReturnSummaryToExcel(file, dataframesAndCharts.ContributionDF, "Contribucion", "Contribucion al rendimiento")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if dataframesAndCharts.AttributionDF != nil {
+		file, err = rs.convertReturnSummaryToExcel(file, dataframesAndCharts.AttributionDF, "Atribucion", "Atribucion por categoria")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if dataframesAndCharts.BenchmarkDF != nil {
+		file, err = rs.convertReportDataframeToExcel(file, dataframesAndCharts.BenchmarkDF, "Benchmarks", false, true, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if dataframesAndCharts.BenchmarkSummaryDF != nil {
+		file, err = rs.convertReturnSummaryToExcel(file, dataframesAndCharts.BenchmarkSummaryDF, "Benchmarks_Resumen", "Comparacion con benchmarks")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	err = rs.applyStylesToAllSheets(file)
 	if err != nil {
 		return nil, err
@@ -761,8 +899,15 @@ func (rs *ReportService) parseAccountsReturnToDataFrame(ctx context.Context, acc
 			df = *updatedDf
 		}
 	}
+	// The total follows the return method chosen for the report
+	totalReturns := accountsReport.TotalReturns
+	totalRealReturns := accountsReport.TotalRealReturns
+	if accountsReport.ReturnMethod == ReturnMethodMWR {
+		totalReturns = accountsReport.TotalMoneyWeightedReturns
+		totalRealReturns = accountsReport.TotalRealMoneyWeightedReturns
+	}
 	totalReturnValues := make([]string, len(dates))
-	for _, totalReturn := range accountsReport.TotalReturns {
+	for _, totalReturn := range totalReturns {
 		for i, date := range dates {
 			if rs.isSameDate(date, totalReturn.EndDate) {
 				totalReturnValues[i] = fmt.Sprintf("%.2f", totalReturn.ReturnPercentage)
@@ -776,9 +921,74 @@ func (rs *ReportService) parseAccountsReturnToDataFrame(ctx context.Context, acc
 		df = *updatedDf
 	}
 
+	// Real returns are only calculated for the reports inflation applies to
+	if len(totalRealReturns) > 0 {
+		totalRealReturnValues := make([]string, len(dates))
+		for _, totalRealReturn := range totalRealReturns {
+			for i, date := range dates {
+				if rs.isSameDate(date, totalRealReturn.EndDate) {
+					totalRealReturnValues[i] = fmt.Sprintf("%.2f", totalRealReturn.ReturnPercentage)
+					break
+				}
+			}
+		}
+		updatedDf, err := rs.updateDataFrame(df, "TOTAL REAL", totalRealReturnValues)
+		if err != nil {
+			return nil, err
+		}
+		df = *updatedDf
+	}
+
 	return &df, nil
 }
 
+// parseReturnSummaryToDataFrame lists the time-weighted and money-weighted returns of the whole period
+// of every asset, category and the total, one per row
+func (rs *ReportService) parseReturnSummaryToDataFrame(accountsReport *schemas.AccountsReports) *dataframe.DataFrame {
+	var names, timeWeighted, moneyWeighted, annualized, realReturns []string
+	addRow := func(name string, returns schemas.AssetReturn) {
+		names = append(names, name)
+		timeWeighted = append(timeWeighted, fmt.Sprintf("%.2f", returns.TimeWeightedReturn))
+		moneyWeighted = append(moneyWeighted, fmt.Sprintf("%.2f", returns.MoneyWeightedReturn))
+		annualized = append(annualized, fmt.Sprintf("%.2f", returns.AnnualizedMoneyWeightedReturn))
+		realReturns = append(realReturns, fmt.Sprintf("%.2f", returns.RealReturn))
+	}
+
+	var categories []string
+	if accountsReport.CategoryAssetsReturn != nil {
+		for category := range *accountsReport.CategoryAssetsReturn {
+			categories = append(categories, category)
+		}
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		if accountsReport.AssetsReturnByCategory != nil {
+			for _, asset := range (*accountsReport.AssetsReturnByCategory)[category] {
+				addRow(fmt.Sprintf("%s-%s", asset.Category, asset.ID), asset)
+			}
+		}
+		addRow(category, (*accountsReport.CategoryAssetsReturn)[category])
+	}
+	addRow("TOTAL", schemas.AssetReturn{
+		TimeWeightedReturn:            accountsReport.TimeWeightedReturn,
+		MoneyWeightedReturn:           accountsReport.MoneyWeightedReturn,
+		AnnualizedMoneyWeightedReturn: accountsReport.AnnualizedMoneyWeightedReturn,
+		RealReturn:                    accountsReport.RealReturn,
+	})
+
+	columns := []series.Series{
+		series.New(names, series.String, "Rendimiento"),
+		series.New(timeWeighted, series.String, "TWR %"),
+		series.New(moneyWeighted, series.String, "MWR %"),
+		series.New(annualized, series.String, "XIRR anual %"),
+	}
+	if len(accountsReport.TotalRealReturns) > 0 {
+		columns = append(columns, series.New(realReturns, series.String, "Real %"))
+	}
+	df := dataframe.New(columns...)
+	return &df
+}
+
 func (rs *ReportService) parseReferenceVariablesToDataFrame(ctx context.Context, accountsReport *schemas.AccountsReports, startDate, endDate time.Time, interval time.Duration) (*dataframe.DataFrame, error) {
 	if accountsReport.ReferenceVariables == nil {
 		// Return empty dataframe if no reference variables
@@ -1108,6 +1318,67 @@ func (rs *ReportService) convertReportDataframeToExcel(
 	return f, nil
 }
 
+// convertReturnSummaryToExcel adds a sheet with a summary, titled in the first row with the headers in
+// the second one so it is styled like the other sheets. Columns whose header ends with % hold percentages.
+func (rs *ReportService) convertReturnSummaryToExcel(f *excelize.File, summaryDf *dataframe.DataFrame, sheetName, title string) (*excelize.File, error) {
+	if summaryDf == nil || summaryDf.Nrow() == 0 {
+		return f, nil
+	}
+
+	if f == nil {
+		f = excelize.NewFile()
+		if err := f.SetSheetName("Sheet1", sheetName); err != nil {
+			return nil, err
+		}
+	} else if _, err := f.NewSheet(sheetName); err != nil {
+		return nil, err
+	}
+
+	cols := summaryDf.Names()
+	lastCell := fmt.Sprintf("%s1", rs.toAlphaString(len(cols)))
+	if err := f.MergeCell(sheetName, "A1", lastCell); err != nil {
+		return nil, err
+	}
+	if err := f.SetCellValue(sheetName, "A1", title); err != nil {
+		return nil, err
+	}
+
+	percentageStyle, err := f.NewStyle(&excelize.Style{
+		NumFmt: 10,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for rowIndex, row := range summaryDf.Records() {
+		for colIndex, cellValue := range row {
+			cell := fmt.Sprintf("%s%d", rs.toAlphaString(colIndex+1), rowIndex+2)
+			numCellValue, err := strconv.ParseFloat(cellValue, 64)
+			if rowIndex == 0 || err != nil {
+				if err := f.SetCellValue(sheetName, cell, cellValue); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if !strings.HasSuffix(cols[colIndex], "%") {
+				if err := f.SetCellValue(sheetName, cell, numCellValue); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			// The returns are stored as percentages and the cell format expects fractions
+			if err := f.SetCellValue(sheetName, cell, numCellValue/100); err != nil {
+				return nil, err
+			}
+			if err := f.SetCellStyle(sheetName, cell, cell, percentageStyle); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return f, nil
+}
+
 func (rs *ReportService) toAlphaString(column int) string {
 	result := ""
 	for column > 0 {
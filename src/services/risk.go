@@ -0,0 +1,181 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"server/src/schemas"
+	"sort"
+	"time"
+
+	"github.com/go-gota/gota/dataframe"
+	"github.com/go-gota/gota/series"
+)
+
+// daysPerYear annualizes the daily returns, which include every calendar day the assets were held
+const daysPerYear = 365
+
+// CalculateRiskMetrics measures the risk of the total, every category and every asset of the report from
+// their daily returns. riskFreeRates are nominal annual rates, as percentages, accrued daily to measure the
+// Sharpe and Sortino ratios, which use no risk-free rate when there are none.
+func (rs *ReportService) CalculateRiskMetrics(accountsReport *schemas.AccountsReports, riskFreeRates []schemas.VariableValuation) {
+	dailyReturns := rs.sortReturnsByDate(accountsReport.TotalDailyReturns)
+	var riskFree *dailyIndex
+	if len(dailyReturns) > 0 {
+		riskFree = newAnnualRateIndex(riskFreeRates, dailyReturns[0].StartDate, dailyReturns[len(dailyReturns)-1].EndDate)
+	}
+
+	accountsReport.Risk = rs.calculateRisk(dailyReturns, accountsReport.TotalReturns, riskFree)
+	if accountsReport.CategoryAssetsReturn != nil {
+		for category, categoryReturn := range *accountsReport.CategoryAssetsReturn {
+			categoryReturn.Risk = rs.calculateRisk(categoryReturn.DailyReturns, categoryReturn.ReturnsByDateRange, riskFree)
+			(*accountsReport.CategoryAssetsReturn)[category] = categoryReturn
+		}
+	}
+	if accountsReport.AssetsReturnByCategory != nil {
+		for _, assetReturns := range *accountsReport.AssetsReturnByCategory {
+			for i := range assetReturns {
+				assetReturns[i].Risk = rs.calculateRisk(assetReturns[i].DailyReturns, assetReturns[i].ReturnsByDateRange, riskFree)
+			}
+		}
+	}
+}
+
+// calculateRisk measures the risk of the daily returns, and picks the best and worst of the returns of
+// the intervals of the report
+func (rs *ReportService) calculateRisk(dailyReturns, periodReturns []schemas.ReturnByDate, riskFree *dailyIndex) schemas.RiskMetrics {
+	var risk schemas.RiskMetrics
+	for i := range periodReturns {
+		if risk.BestPeriod == nil || periodReturns[i].ReturnPercentage > risk.BestPeriod.ReturnPercentage {
+			risk.BestPeriod = &periodReturns[i]
+		}
+		if risk.WorstPeriod == nil || periodReturns[i].ReturnPercentage < risk.WorstPeriod.ReturnPercentage {
+			risk.WorstPeriod = &periodReturns[i]
+		}
+	}
+	if len(dailyReturns) == 0 {
+		return risk
+	}
+
+	returns := make([]float64, 0, len(dailyReturns))
+	excessReturns := make([]float64, 0, len(dailyReturns))
+	var meanExcess, downsideSquares float64
+	wealth, peak := 1.0, 1.0
+	peakDate := dailyReturns[0].StartDate
+	for _, dailyReturn := range rs.sortReturnsByDate(dailyReturns) {
+		var riskFreeReturn float64
+		if riskFree != nil {
+			riskFreeReturn = riskFree.periodReturn(dailyReturn.StartDate, dailyReturn.EndDate)
+		}
+		excess := dailyReturn.ReturnPercentage - riskFreeReturn
+		returns = append(returns, dailyReturn.ReturnPercentage)
+		excessReturns = append(excessReturns, excess)
+		meanExcess += excess
+		if excess < 0 {
+			downsideSquares += excess * excess
+		}
+
+		wealth *= 1 + dailyReturn.ReturnPercentage/100
+		if wealth > peak {
+			peak, peakDate = wealth, dailyReturn.EndDate
+		}
+		if drawdown := (wealth/peak - 1) * 100; drawdown < risk.MaxDrawdown {
+			peakDate, troughDate := peakDate, dailyReturn.EndDate
+			risk.MaxDrawdown = drawdown
+			risk.MaxDrawdownPeak = &peakDate
+			risk.MaxDrawdownTrough = &troughDate
+		}
+	}
+	meanExcess /= float64(len(excessReturns))
+
+	annualization := math.Sqrt(daysPerYear)
+	risk.Volatility = standardDeviation(returns) * annualization
+	if deviation := standardDeviation(excessReturns); deviation > 0 {
+		risk.SharpeRatio = meanExcess / deviation * annualization
+	}
+	if downsideDeviation := math.Sqrt(downsideSquares / float64(len(excessReturns))); downsideDeviation > 0 {
+		risk.SortinoRatio = meanExcess / downsideDeviation * annualization
+	}
+	return risk
+}
+
+// returnsWhileHeld returns the returns, sorted by date, of the days starting with a value held
+func returnsWhileHeld(returns []schemas.ReturnByDate, holdings []schemas.Holding) []schemas.ReturnByDate {
+	heldDates := make(map[string]bool)
+	for _, holding := range holdings {
+		if holding.DateRequested != nil && holding.Value != 0 {
+			heldDates[holding.DateRequested.Format("2006-01-02")] = true
+		}
+	}
+	held := make([]schemas.ReturnByDate, 0, len(returns))
+	for _, returnByDate := range returns {
+		if heldDates[returnByDate.StartDate.Format("2006-01-02")] {
+			held = append(held, returnByDate)
+		}
+	}
+	sort.Slice(held, func(i, j int) bool {
+		return held[i].StartDate.Before(held[j].StartDate)
+	})
+	return held
+}
+
+// parseRiskToDataFrame lists the risk of every asset, category and the total, one per row
+func (rs *ReportService) parseRiskToDataFrame(accountsReport *schemas.AccountsReports) *dataframe.DataFrame {
+	var names, volatility, drawdown, peaks, troughs, sharpe, sortino, best, bestReturn, worst, worstReturn []string
+	formatDate := func(date *time.Time) string {
+		if date == nil {
+			return "-"
+		}
+		return date.Format("2006-01-02")
+	}
+	formatPeriod := func(period *schemas.ReturnByDate) (string, string) {
+		if period == nil {
+			return "-", "-"
+		}
+		return fmt.Sprintf("%s - %s", period.StartDate.Format("2006-01-02"), period.EndDate.Format("2006-01-02")), fmt.Sprintf("%.2f", period.ReturnPercentage)
+	}
+	addRow := func(name string, risk schemas.RiskMetrics) {
+		names = append(names, name)
+		volatility = append(volatility, fmt.Sprintf("%.2f", risk.Volatility))
+		drawdown = append(drawdown, fmt.Sprintf("%.2f", risk.MaxDrawdown))
+		peaks = append(peaks, formatDate(risk.MaxDrawdownPeak))
+		troughs = append(troughs, formatDate(risk.MaxDrawdownTrough))
+		sharpe = append(sharpe, fmt.Sprintf("%.2f", risk.SharpeRatio))
+		sortino = append(sortino, fmt.Sprintf("%.2f", risk.SortinoRatio))
+		bestPeriod, bestPeriodReturn := formatPeriod(risk.BestPeriod)
+		best, bestReturn = append(best, bestPeriod), append(bestReturn, bestPeriodReturn)
+		worstPeriod, worstPeriodReturn := formatPeriod(risk.WorstPeriod)
+		worst, worstReturn = append(worst, worstPeriod), append(worstReturn, worstPeriodReturn)
+	}
+
+	var categories []string
+	if accountsReport.CategoryAssetsReturn != nil {
+		for category := range *accountsReport.CategoryAssetsReturn {
+			categories = append(categories, category)
+		}
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		if accountsReport.AssetsReturnByCategory != nil {
+			for _, asset := range (*accountsReport.AssetsReturnByCategory)[category] {
+				addRow(fmt.Sprintf("%s-%s", asset.Category, asset.ID), asset.Risk)
+			}
+		}
+		addRow(category, (*accountsReport.CategoryAssetsReturn)[category].Risk)
+	}
+	addRow("TOTAL", accountsReport.Risk)
+
+	df := dataframe.New(
+		series.New(names, series.String, "Riesgo"),
+		series.New(volatility, series.String, "Volatilidad anual %"),
+		series.New(drawdown, series.String, "Max drawdown %"),
+		series.New(peaks, series.String, "Pico"),
+		series.New(troughs, series.String, "Valle"),
+		series.New(sharpe, series.String, "Sharpe"),
+		series.New(sortino, series.String, "Sortino"),
+		series.New(best, series.String, "Mejor periodo"),
+		series.New(bestReturn, series.String, "Mejor periodo %"),
+		series.New(worst, series.String, "Peor periodo"),
+		series.New(worstReturn, series.String, "Peor periodo %"),
+	)
+	return &df
+}
@@ -0,0 +1,578 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/schemas"
+	"server/src/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/xuri/excelize/v2"
+)
+
+// SpreadsheetFormat is the file format of an imported spreadsheet
+type SpreadsheetFormat string
+
+const (
+	SpreadsheetCSV  SpreadsheetFormat = "csv"
+	SpreadsheetXLSX SpreadsheetFormat = "xlsx"
+)
+
+// Kinds of rows a spreadsheet holds
+const (
+	SpreadsheetHoldings     = "holdings"
+	SpreadsheetTransactions = "transactions"
+)
+
+// Fields a spreadsheet column can be mapped to
+const (
+	spreadsheetFieldDate         = "date"
+	spreadsheetFieldAssetID      = "assetID"
+	spreadsheetFieldAssetName    = "assetName"
+	spreadsheetFieldAssetType    = "assetType"
+	spreadsheetFieldCategory     = "category"
+	spreadsheetFieldUnits        = "units"
+	spreadsheetFieldValue        = "value"
+	spreadsheetFieldPrice        = "price"
+	spreadsheetFieldType         = "type"
+	spreadsheetFieldExchangeFees = "exchangeFees"
+	spreadsheetFieldMarketFees   = "marketFees"
+)
+
+var spreadsheetFields = map[string]bool{
+	spreadsheetFieldDate:         true,
+	spreadsheetFieldAssetID:      true,
+	spreadsheetFieldAssetName:    true,
+	spreadsheetFieldAssetType:    true,
+	spreadsheetFieldCategory:     true,
+	spreadsheetFieldUnits:        true,
+	spreadsheetFieldValue:        true,
+	spreadsheetFieldPrice:        true,
+	spreadsheetFieldType:         true,
+	spreadsheetFieldExchangeFees: true,
+	spreadsheetFieldMarketFees:   true,
+}
+
+// spreadsheetRequiredFields are the fields every row of each kind must have
+var spreadsheetRequiredFields = []string{spreadsheetFieldDate, spreadsheetFieldAssetID, spreadsheetFieldUnits, spreadsheetFieldValue}
+
+type SpreadsheetImportServiceI interface {
+	ImportSpreadsheet(ctx context.Context, accountID, source string, format SpreadsheetFormat, data []byte, mapping *schemas.SpreadsheetMapping, dryRun bool) (*schemas.SpreadsheetImportResponse, error)
+}
+
+// SpreadsheetImportService loads holdings and transactions of brokers without an API from the
+// CSV or XLSX files they send, writing them like synced data tagged with the broker source
+type SpreadsheetImportService struct {
+	db *pgxpool.Pool
+
+	assetRepository         repositories.AssetRepository
+	assetCategoryRepository repositories.AssetCategoryRepository
+	holdingRepository       repositories.HoldingRepository
+	transactionRepository   repositories.TransactionRepository
+
+	syncService SyncServiceI
+}
+
+func NewSpreadsheetImportService(
+	db *pgxpool.Pool,
+	assetRepository repositories.AssetRepository,
+	assetCategoryRepository repositories.AssetCategoryRepository,
+	holdingRepository repositories.HoldingRepository,
+	transactionRepository repositories.TransactionRepository,
+	syncService SyncServiceI,
+) *SpreadsheetImportService {
+	return &SpreadsheetImportService{
+		db:                      db,
+		assetRepository:         assetRepository,
+		assetCategoryRepository: assetCategoryRepository,
+		holdingRepository:       holdingRepository,
+		transactionRepository:   transactionRepository,
+		syncService:             syncService,
+	}
+}
+
+// spreadsheetAsset is an asset of the file with its rows, already validated
+type spreadsheetAsset struct {
+	externalID   string
+	name         string
+	assetType    string
+	category     string
+	holdings     []schemas.Holding
+	transactions []schemas.Transaction
+}
+
+// ImportSpreadsheet validates every row of the file and stores the valid ones in a single database
+// transaction. Rows of the same asset, date and type are added up, since that is the key they are
+// stored with. With dryRun the rows are only validated. The sources of the synced brokers and of the
+// manual assets are rejected, as a sync or a valuation would replace or mix with the imported rows.
+func (s *SpreadsheetImportService) ImportSpreadsheet(ctx context.Context, accountID, source string, format SpreadsheetFormat, data []byte, mapping *schemas.SpreadsheetMapping, dryRun bool) (*schemas.SpreadsheetImportResponse, error) {
+	logger := utils.LoggerFromContext(ctx)
+	source = strings.TrimSpace(source)
+	if source == "" {
+		source = models.SourceSpreadsheet
+	}
+	if source == models.SourceESCO || source == models.SourceManual || s.syncService.HasSource(source) {
+		return nil, utils.BadRequest(fmt.Sprintf("source %s is reserved, name the broker the file comes from", source))
+	}
+	logger.Infof("Importing %s %s spreadsheet for account %s from %s", format, mapping.Kind, accountID, source)
+
+	if err := validateSpreadsheetMapping(mapping); err != nil {
+		return nil, err
+	}
+	rows, err := readSpreadsheetRows(format, data, mapping)
+	if err != nil {
+		return nil, utils.BadRequest(err.Error())
+	}
+	assets, response, err := parseSpreadsheetRows(rows, format, mapping)
+	if err != nil {
+		return nil, err
+	}
+	response.AccountID = accountID
+	response.Source = source
+	response.DryRun = dryRun
+
+	dates := make(map[string]time.Time)
+	for _, asset := range assets {
+		asset.holdings = aggregateHoldingsByDate(asset.holdings)
+		asset.transactions = aggregateTransactions(asset.transactions)
+		response.Holdings += len(asset.holdings)
+		response.Transactions += len(asset.transactions)
+		for _, holding := range asset.holdings {
+			dates[holding.Date.Format(utils.ShortDashDateLayout)] = *holding.Date
+		}
+		for _, transaction := range asset.transactions {
+			dates[transaction.Date.Format(utils.ShortDashDateLayout)] = *transaction.Date
+		}
+	}
+	response.Assets = len(assets)
+	response.Dates = make([]schemas.Date, 0, len(dates))
+	for _, date := range dates {
+		response.Dates = append(response.Dates, schemas.Date{Time: date})
+	}
+	sort.Slice(response.Dates, func(i, j int) bool {
+		return response.Dates[i].Before(response.Dates[j].Time)
+	})
+
+	if dryRun || len(assets) == 0 {
+		return response, nil
+	}
+	if err := s.storeSpreadsheetAssets(ctx, accountID, source, assets); err != nil {
+		return nil, err
+	}
+	logger.Infof("Imported %d holdings and %d transactions of %d assets for account %s, skipping %d rows with errors",
+		response.Holdings, response.Transactions, response.Assets, accountID, len(response.Errors))
+	return response, nil
+}
+
+func (s *SpreadsheetImportService) storeSpreadsheetAssets(ctx context.Context, accountID, source string, assets []*spreadsheetAsset) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	// Rollback is a no-op once the transaction has been committed
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	for _, asset := range assets {
+		assetID, err := s.getOrCreateAsset(ctx, asset, tx)
+		if err != nil {
+			return fmt.Errorf("error storing asset %s: %w", asset.externalID, err)
+		}
+		for _, holding := range asset.holdings {
+			err = s.holdingRepository.Create(ctx, &models.Holding{
+				ClientID: accountID,
+				Source:   source,
+				AssetID:  assetID,
+				Units:    holding.Units,
+				Value:    holding.Value,
+				Date:     *holding.Date,
+			}, tx)
+			if err != nil {
+				return fmt.Errorf("error creating holding: %w", err)
+			}
+		}
+		for _, transaction := range asset.transactions {
+			err = s.transactionRepository.Create(ctx, &models.Transaction{
+				ClientID:        accountID,
+				Source:          source,
+				AssetID:         assetID,
+				TransactionType: transaction.Type,
+				Units:           transaction.Units,
+				PricePerUnit:    transaction.PricePerUnit,
+				GrossValue:      transaction.GrossValue,
+				ExchangeFees:    transaction.ExchangeFees,
+				MarketFees:      transaction.MarketFees,
+				TotalValue:      transaction.Value,
+				Date:            *transaction.Date,
+			}, tx)
+			if err != nil {
+				return fmt.Errorf("error creating transaction: %w", err)
+			}
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing spreadsheet import: %w", err)
+	}
+	return nil
+}
+
+// getOrCreateAsset returns the id of the stored asset, creating it when the file is the first to hold it.
+// Assets already stored keep their name and category.
+func (s *SpreadsheetImportService) getOrCreateAsset(ctx context.Context, asset *spreadsheetAsset, tx pgx.Tx) (int, error) {
+	dbAsset, err := s.assetRepository.GetByExternalID(ctx, asset.externalID)
+	if err != nil {
+		return 0, err
+	}
+	if dbAsset != nil {
+		return dbAsset.ID, nil
+	}
+
+	categoryName := asset.category
+	if categoryName == "" {
+		categoryName = models.UnclassifiedCategory
+	}
+	category, err := s.assetCategoryRepository.GetByName(ctx, categoryName)
+	if err != nil {
+		return 0, fmt.Errorf("error getting asset category: %w", err)
+	}
+	if category == nil {
+		category = &models.AssetCategory{Name: categoryName}
+		if err = s.assetCategoryRepository.Create(ctx, category, tx); err != nil {
+			return 0, fmt.Errorf("error creating asset category: %w", err)
+		}
+	}
+
+	name := asset.name
+	if name == "" {
+		name = asset.externalID
+	}
+	dbAsset = &models.Asset{
+		ExternalID: asset.externalID,
+		Name:       name,
+		AssetType:  asset.assetType,
+		CategoryID: category.ID,
+		Currency:   utils.AssetCurrencyPesos,
+	}
+	if err = s.assetRepository.Create(ctx, dbAsset, tx); err != nil {
+		return 0, fmt.Errorf("error creating asset: %w", err)
+	}
+	return dbAsset.ID, nil
+}
+
+func validateSpreadsheetMapping(mapping *schemas.SpreadsheetMapping) error {
+	if mapping.Kind != SpreadsheetHoldings && mapping.Kind != SpreadsheetTransactions {
+		return utils.BadRequest(fmt.Sprintf("kind must be %s or %s", SpreadsheetHoldings, SpreadsheetTransactions))
+	}
+	for field := range mapping.Columns {
+		if !spreadsheetFields[field] {
+			return utils.BadRequest(fmt.Sprintf("unknown field %s in columns", field))
+		}
+	}
+	for _, field := range spreadsheetRequiredFields {
+		if mapping.Columns[field] == "" {
+			return utils.BadRequest(fmt.Sprintf("a column for %s is required", field))
+		}
+	}
+	for value, transactionType := range mapping.TransactionTypes {
+		if !models.IsValidTransactionType(transactionType) {
+			return utils.BadRequest(fmt.Sprintf("invalid transaction type %s for %s", transactionType, value))
+		}
+	}
+	if utf8.RuneCountInString(mapping.Delimiter) > 1 {
+		return utils.BadRequest("delimiter must be a single character")
+	}
+	if mapping.DecimalSeparator != "" && mapping.DecimalSeparator != "." && mapping.DecimalSeparator != "," {
+		return utils.BadRequest("decimalSeparator must be . or ,")
+	}
+	if mapping.HeaderRow < 0 {
+		return utils.BadRequest("headerRow must be positive")
+	}
+	return nil
+}
+
+// readSpreadsheetRows returns every row of the file. XLSX cells are read raw, so numbers keep
+// a dot as decimal separator and dates come as Excel serial numbers.
+func readSpreadsheetRows(format SpreadsheetFormat, data []byte, mapping *schemas.SpreadsheetMapping) ([][]string, error) {
+	switch format {
+	case SpreadsheetCSV:
+		reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+		reader.FieldsPerRecord = -1
+		if mapping.Delimiter != "" {
+			reader.Comma, _ = utf8.DecodeRuneInString(mapping.Delimiter)
+		}
+		// Blank lines are skipped by the reader, so rows are placed at their line to keep the row numbers of the file
+		rows := make([][]string, 0)
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				return rows, nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error reading csv: %w", err)
+			}
+			line, _ := reader.FieldPos(0)
+			for len(rows) < line-1 {
+				rows = append(rows, nil)
+			}
+			rows = append(rows, record)
+		}
+	case SpreadsheetXLSX:
+		file, err := excelize.OpenReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("error reading xlsx: %w", err)
+		}
+		defer file.Close()
+		sheet := mapping.Sheet
+		if sheet == "" {
+			sheet = file.GetSheetName(0)
+		}
+		rows, err := file.GetRows(sheet, excelize.Options{RawCellValue: true})
+		if err != nil {
+			return nil, fmt.Errorf("error reading sheet %s: %w", sheet, err)
+		}
+		return rows, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+// parseSpreadsheetRows validates the rows after the header and groups the valid ones by asset,
+// in the order the assets first appear. Invalid rows are reported in the response errors.
+func parseSpreadsheetRows(rows [][]string, format SpreadsheetFormat, mapping *schemas.SpreadsheetMapping) ([]*spreadsheetAsset, *schemas.SpreadsheetImportResponse, error) {
+	headerIndex := mapping.HeaderRow - 1
+	if headerIndex < 0 {
+		headerIndex = 0
+	}
+	if headerIndex >= len(rows) {
+		return nil, nil, utils.BadRequest(fmt.Sprintf("the file has no header row %d", headerIndex+1))
+	}
+
+	headers := make(map[string]int)
+	for i, header := range rows[headerIndex] {
+		headers[strings.ToLower(strings.TrimSpace(header))] = i
+	}
+	columns := make(map[string]int, len(mapping.Columns))
+	for field, header := range mapping.Columns {
+		index, exists := headers[strings.ToLower(strings.TrimSpace(header))]
+		if !exists {
+			return nil, nil, utils.BadRequest(fmt.Sprintf("column %s mapped to %s not found", header, field))
+		}
+		columns[field] = index
+	}
+
+	parser := spreadsheetRowParser{format: format, mapping: mapping, columns: columns}
+	response := &schemas.SpreadsheetImportResponse{Kind: mapping.Kind, Errors: []schemas.SpreadsheetRowError{}}
+	assets := make([]*spreadsheetAsset, 0)
+	assetIndexes := make(map[string]int)
+	for i := headerIndex + 1; i < len(rows); i++ {
+		if isEmptySpreadsheetRow(rows[i]) {
+			continue
+		}
+		response.Rows++
+		parser.row, parser.line, parser.errors = rows[i], i+1, nil
+
+		externalID := parser.text(spreadsheetFieldAssetID)
+		if externalID == "" {
+			parser.addError(spreadsheetFieldAssetID, "asset id is required")
+		}
+		date := parser.date(spreadsheetFieldDate)
+		units := parser.number(spreadsheetFieldUnits, true)
+		value := parser.number(spreadsheetFieldValue, true)
+		var transaction schemas.Transaction
+		if mapping.Kind == SpreadsheetTransactions {
+			transaction = schemas.Transaction{
+				Type:         parser.transactionType(spreadsheetFieldType),
+				PricePerUnit: parser.number(spreadsheetFieldPrice, false),
+				ExchangeFees: parser.number(spreadsheetFieldExchangeFees, false),
+				MarketFees:   parser.number(spreadsheetFieldMarketFees, false),
+			}
+		}
+		if len(parser.errors) > 0 {
+			response.Errors = append(response.Errors, parser.errors...)
+			continue
+		}
+		response.ImportedRows++
+
+		index, exists := assetIndexes[externalID]
+		if !exists {
+			index = len(assets)
+			assetIndexes[externalID] = index
+			assets = append(assets, &spreadsheetAsset{
+				externalID: externalID,
+				name:       parser.text(spreadsheetFieldAssetName),
+				assetType:  parser.text(spreadsheetFieldAssetType),
+				category:   parser.text(spreadsheetFieldCategory),
+			})
+		}
+		asset := assets[index]
+		if mapping.Kind == SpreadsheetHoldings {
+			asset.holdings = append(asset.holdings, schemas.Holding{
+				Currency:      utils.AssetCurrencyPesos,
+				Units:         units,
+				Value:         value,
+				DateRequested: &date,
+				Date:          &date,
+			})
+			continue
+		}
+		transaction.Units = units
+		transaction.Value = value
+		transaction.GrossValue = value
+		transaction.Date = &date
+		asset.transactions = append(asset.transactions, transaction)
+	}
+	return assets, response, nil
+}
+
+func isEmptySpreadsheetRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// spreadsheetRowParser reads the mapped fields of a row, collecting an error for every invalid one
+type spreadsheetRowParser struct {
+	format  SpreadsheetFormat
+	mapping *schemas.SpreadsheetMapping
+	columns map[string]int
+
+	row    []string
+	line   int
+	errors []schemas.SpreadsheetRowError
+}
+
+func (p *spreadsheetRowParser) addError(field, message string) {
+	p.errors = append(p.errors, schemas.SpreadsheetRowError{Row: p.line, Column: p.mapping.Columns[field], Message: message})
+}
+
+// text returns the trimmed value of the field, empty when it is not mapped or the row is shorter
+func (p *spreadsheetRowParser) text(field string) string {
+	index, exists := p.columns[field]
+	if !exists || index >= len(p.row) {
+		return ""
+	}
+	return strings.TrimSpace(p.row[index])
+}
+
+func (p *spreadsheetRowParser) date(field string) time.Time {
+	raw := p.text(field)
+	if raw == "" {
+		p.addError(field, "date is required")
+		return time.Time{}
+	}
+	layout := p.mapping.DateFormat
+	if layout == "" {
+		layout = utils.ShortDashDateLayout
+	}
+	if date, err := time.Parse(layout, raw); err == nil {
+		return date
+	}
+	// Date cells of XLSX files are stored as serial numbers
+	if p.format == SpreadsheetXLSX {
+		if serial, err := strconv.ParseFloat(raw, 64); err == nil {
+			if date, err := excelize.ExcelDateToTime(serial, false); err == nil {
+				return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+			}
+		}
+	}
+	p.addError(field, fmt.Sprintf("invalid date %s, expected the format %s", raw, layout))
+	return time.Time{}
+}
+
+func (p *spreadsheetRowParser) number(field string, required bool) float64 {
+	raw := p.text(field)
+	if raw == "" {
+		if required {
+			p.addError(field, fmt.Sprintf("%s is required", field))
+		}
+		return 0
+	}
+	// Numeric XLSX cells are read raw, with a dot as decimal separator whatever the file locale
+	if p.format == SpreadsheetXLSX {
+		if number, err := strconv.ParseFloat(raw, 64); err == nil {
+			return number
+		}
+	}
+	number, err := parseLocalizedNumber(raw, p.mapping.DecimalSeparator)
+	if err != nil {
+		p.addError(field, fmt.Sprintf("invalid number %s", raw))
+		return 0
+	}
+	return number
+}
+
+func (p *spreadsheetRowParser) transactionType(field string) string {
+	raw := p.text(field)
+	if raw == "" {
+		return ""
+	}
+	if transactionType, exists := p.mapping.TransactionTypes[raw]; exists {
+		return transactionType
+	}
+	if transactionType := strings.ToLower(raw); models.IsValidTransactionType(transactionType) {
+		return transactionType
+	}
+	p.addError(field, fmt.Sprintf("unknown transaction type %s", raw))
+	return ""
+}
+
+// parseLocalizedNumber parses a number written with the given decimal separator, dropping the
+// thousands separators, spaces and currency signs, e.g. "$ 1.234,50" with a comma is 1234.5
+func parseLocalizedNumber(raw, decimalSeparator string) (float64, error) {
+	thousandsSeparator := ","
+	if decimalSeparator == "," {
+		thousandsSeparator = "."
+	}
+	cleaned := strings.NewReplacer(thousandsSeparator, "", " ", "", "$", "").Replace(raw)
+	if decimalSeparator == "," {
+		cleaned = strings.Replace(cleaned, ",", ".", 1)
+	}
+	return strconv.ParseFloat(cleaned, 64)
+}
+
+// aggregateHoldingsByDate adds up the holdings of the same date
+func aggregateHoldingsByDate(holdings []schemas.Holding) []schemas.Holding {
+	aggregated := make([]schemas.Holding, 0, len(holdings))
+	indexByDate := make(map[string]int)
+	for _, holding := range holdings {
+		key := holding.Date.Format(utils.ShortDashDateLayout)
+		if i, exists := indexByDate[key]; exists {
+			aggregated[i].Units += holding.Units
+			aggregated[i].Value += holding.Value
+			continue
+		}
+		indexByDate[key] = len(aggregated)
+		aggregated = append(aggregated, holding)
+	}
+	return aggregated
+}
+
+// aggregateTransactions adds up the transactions of the same date and type
+func aggregateTransactions(transactions []schemas.Transaction) []schemas.Transaction {
+	aggregated := make([]schemas.Transaction, 0, len(transactions))
+	indexByKey := make(map[string]int)
+	for _, transaction := range transactions {
+		key := transaction.Date.Format(utils.ShortDashDateLayout) + "/" + transaction.Type
+		if i, exists := indexByKey[key]; exists {
+			aggregated[i] = mergeTransactions(aggregated[i], transaction)
+			continue
+		}
+		indexByKey[key] = len(aggregated)
+		aggregated = append(aggregated, transaction)
+	}
+	return aggregated
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/utils"
+	"time"
+)
+
+const defaultSyncJobChunkDays = 7
+
+// defaultSyncJobLeaseTimeout is how long a running job may go without a heartbeat before it is requeued
+const defaultSyncJobLeaseTimeout = 30 * time.Minute
+
+type SyncJobServiceI interface {
+	SubmitSyncJob(ctx context.Context, accountID string, startDate, endDate time.Time, force bool) (*models.SyncJob, error)
+	GetSyncJob(ctx context.Context, id int) (*models.SyncJob, error)
+	RunNextSyncJob(ctx context.Context) (bool, error)
+	RequeueInterruptedSyncJobs(ctx context.Context) error
+}
+
+type SyncJobService struct {
+	syncJobRepository repositories.SyncJobRepository
+	syncService       SyncServiceI
+	tokenManager      ESCOTokenManagerI
+	chunkDays         int
+	leaseTimeout      time.Duration
+}
+
+func NewSyncJobService(
+	syncJobRepository repositories.SyncJobRepository,
+	syncService SyncServiceI,
+	tokenManager ESCOTokenManagerI,
+	chunkDays int,
+	leaseTimeout time.Duration,
+) *SyncJobService {
+	if chunkDays <= 0 {
+		chunkDays = defaultSyncJobChunkDays
+	}
+	if leaseTimeout <= 0 {
+		leaseTimeout = defaultSyncJobLeaseTimeout
+	}
+	return &SyncJobService{
+		syncJobRepository: syncJobRepository,
+		syncService:       syncService,
+		tokenManager:      tokenManager,
+		chunkDays:         chunkDays,
+		leaseTimeout:      leaseTimeout,
+	}
+}
+
+// SubmitSyncJob persists a pending sync job to be picked up by the worker.
+// The job syncs from the broker source selected in the context. Jobs are rejected when no service
+// account is configured, since the worker could not get a token to run them.
+// The end date is exclusive, a job whose end date equals its start date syncs that single day.
+func (s *SyncJobService) SubmitSyncJob(ctx context.Context, accountID string, startDate, endDate time.Time, force bool) (*models.SyncJob, error) {
+	logger := utils.LoggerFromContext(ctx)
+	if s.tokenManager == nil || !s.tokenManager.Configured() {
+		return nil, utils.ServiceUnavailable(ErrServiceAccountNotConfigured.Error())
+	}
+	source := utils.SyncSourceFromContext(ctx)
+	if source != "" && !s.syncService.HasSource(source) {
+		return nil, utils.BadRequest(fmt.Sprintf("unknown source %s", source))
+	}
+	if endDate.Equal(startDate) {
+		endDate = startDate.AddDate(0, 0, 1)
+	}
+	job := &models.SyncJob{
+		ClientID:  accountID,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Status:    models.SyncJobPending,
+		Force:     force,
+		Source:    source,
+	}
+	if err := s.syncJobRepository.Create(ctx, job); err != nil {
+		return nil, fmt.Errorf("error creating sync job: %w", err)
+	}
+	logger.Infof("Submitted sync job %d for account %s from %s to %s", job.ID, accountID, startDate, endDate)
+	return job, nil
+}
+
+func (s *SyncJobService) GetSyncJob(ctx context.Context, id int) (*models.SyncJob, error) {
+	job, err := s.syncJobRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, utils.NotFound(fmt.Sprintf("sync job %d not found", id))
+	}
+	return job, nil
+}
+
+// RunNextSyncJob claims the oldest pending job and runs it to completion.
+// It returns false when there was no pending job to run.
+func (s *SyncJobService) RunNextSyncJob(ctx context.Context) (bool, error) {
+	job, err := s.syncJobRepository.ClaimNextPending(ctx)
+	if err != nil {
+		return false, fmt.Errorf("error claiming sync job: %w", err)
+	}
+	if job == nil {
+		return false, nil
+	}
+	return true, s.runSyncJob(ctx, job)
+}
+
+// RequeueInterruptedSyncJobs moves the running jobs without a heartbeat for longer than the lease
+// timeout, left by a worker that stopped, back to pending so they run again from the start.
+// Dates already synced are skipped unless the job is forced.
+func (s *SyncJobService) RequeueInterruptedSyncJobs(ctx context.Context) error {
+	logger := utils.LoggerFromContext(ctx)
+	requeued, err := s.syncJobRepository.RequeueRunning(ctx, time.Now().Add(-s.leaseTimeout))
+	if err != nil {
+		return fmt.Errorf("error requeuing interrupted sync jobs: %w", err)
+	}
+	if requeued > 0 {
+		logger.Warnf("Requeued %d sync jobs interrupted while running", requeued)
+	}
+	return nil
+}
+
+// runSyncJob syncs the job range in chunks of chunkDays, updating the progress after each one
+func (s *SyncJobService) runSyncJob(ctx context.Context, job *models.SyncJob) error {
+	logger := utils.LoggerFromContext(ctx)
+	logger.Infof("Running sync job %d for account %s", job.ID, job.ClientID)
+	ctx = utils.WithSyncTrigger(ctx, fmt.Sprintf("%s:%d", utils.SyncTriggerSyncJob, job.ID))
+	ctx = utils.WithSyncSource(ctx, job.Source)
+
+	token, err := s.jobToken(ctx)
+	if err != nil {
+		return s.failSyncJob(ctx, job, err)
+	}
+	datesToSync, err := s.getJobDatesToSync(ctx, token, job)
+	if err != nil {
+		return s.failSyncJob(ctx, job, err)
+	}
+	syncFunc := s.syncService.SyncDataFromAccount
+	if job.Force {
+		syncFunc = s.syncService.ForceSyncDataFromAccount
+	}
+	if err = s.syncJobRepository.UpdateProgress(ctx, job.ID, len(datesToSync), 0); err != nil {
+		return s.failSyncJob(ctx, job, err)
+	}
+
+	processed := 0
+	for chunkStart := job.StartDate; chunkStart.Before(job.EndDate); {
+		chunkEnd := chunkStart.AddDate(0, 0, s.chunkDays)
+		if chunkEnd.After(job.EndDate) {
+			chunkEnd = job.EndDate
+		}
+
+		// Asked again on every chunk so long jobs pick up the renewed service account token
+		if token, err = s.jobToken(ctx); err != nil {
+			return s.failSyncJob(ctx, job, err)
+		}
+		err = syncFunc(ctx, token, job.ClientID, chunkStart, chunkEnd)
+		if err != nil {
+			return s.failSyncJob(ctx, job, err)
+		}
+
+		for _, date := range datesToSync {
+			if !date.Before(chunkStart) && date.Before(chunkEnd) {
+				processed++
+			}
+		}
+		if err = s.syncJobRepository.UpdateProgress(ctx, job.ID, len(datesToSync), processed); err != nil {
+			return s.failSyncJob(ctx, job, err)
+		}
+		chunkStart = chunkEnd
+	}
+
+	if err = s.syncJobRepository.MarkCompleted(ctx, job.ID); err != nil {
+		return fmt.Errorf("error completing sync job %d: %w", job.ID, err)
+	}
+	logger.Infof("Sync job %d completed: %d dates processed", job.ID, processed)
+	return nil
+}
+
+// getJobDatesToSync returns the dates the job will sync, which are all of them on forced jobs
+func (s *SyncJobService) getJobDatesToSync(ctx context.Context, token string, job *models.SyncJob) ([]time.Time, error) {
+	if !job.Force {
+		return s.syncService.GetDatesToSync(ctx, token, job.ClientID, job.StartDate, job.EndDate)
+	}
+	dates := make([]time.Time, 0)
+	for date := job.StartDate; date.Before(job.EndDate); date = date.AddDate(0, 0, 1) {
+		dates = append(dates, date)
+	}
+	return dates, nil
+}
+
+// jobToken returns the ESCO service account token the job syncs with. The token of the user that
+// submitted the job is not stored, so jobs cannot run without a configured service account.
+func (s *SyncJobService) jobToken(ctx context.Context) (string, error) {
+	if s.tokenManager == nil {
+		return "", ErrServiceAccountNotConfigured
+	}
+	token, err := s.tokenManager.GetToken(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error getting esco service account token: %w", err)
+	}
+	return token, nil
+}
+
+func (s *SyncJobService) failSyncJob(ctx context.Context, job *models.SyncJob, jobErr error) error {
+	logger := utils.LoggerFromContext(ctx)
+	logger.Errorf("Sync job %d failed: %v", job.ID, jobErr)
+	if err := s.syncJobRepository.MarkFailed(ctx, job.ID, jobErr.Error()); err != nil {
+		return fmt.Errorf("error marking sync job %d as failed: %w", job.ID, err)
+	}
+	return jobErr
+}
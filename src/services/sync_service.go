@@ -3,84 +3,249 @@ package services
 import (
 	"context"
 	"fmt"
+	"math"
 	"server/src/models"
 	"server/src/repositories"
 	"server/src/schemas"
 	"server/src/utils"
 	"strconv"
 	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// syncRunFinishTimeout bounds storing the outcome of a sync run, which outlives the sync context
+const syncRunFinishTimeout = 5 * time.Second
+
 type SyncServiceI interface {
 	GetDatesToSync(ctx context.Context, token, accountID string, startDate, endDate time.Time) ([]time.Time, error)
 	SyncDataFromAccount(ctx context.Context, token, accountID string, startDate, endDate time.Time) error
+	ForceSyncDataFromAccount(ctx context.Context, token, accountID string, startDate, endDate time.Time) error
+	GetSyncRuns(ctx context.Context, accountID string, limit int) ([]*models.SyncRun, error)
+	StoreAccountState(ctx context.Context, accountID string, accountState *schemas.AccountState, datesToSync []time.Time) error
+	StoreTransactions(ctx context.Context, accountID string, accountState *schemas.AccountState, datesToSync []time.Time) error
+	ReplaceAccountState(ctx context.Context, accountID string, accountState *schemas.AccountState, datesToSync []time.Time) error
+	HasSource(source string) bool
 }
 
 type SyncService struct {
+	db *pgxpool.Pool
+
 	holdingRepository       repositories.HoldingRepository
 	transactionRepository   repositories.TransactionRepository
 	assetRepository         repositories.AssetRepository
 	assetCategoryRepository repositories.AssetCategoryRepository
 	syncLogRepository       repositories.SyncLogRepository
+	syncRunRepository       repositories.SyncRunRepository
 
-	escoService ESCOServiceI
+	sources       map[string]BrokerSource
+	defaultSource string
 }
 
+// NewSyncService returns a sync service storing the data of the given broker sources. The source
+// of a sync is selected with utils.WithSyncSource, the first one is used when none is selected.
 func NewSyncService(
+	db *pgxpool.Pool,
 	holdingRepository repositories.HoldingRepository,
 	transactionRepository repositories.TransactionRepository,
 	assetRepository repositories.AssetRepository,
 	assetCategoryRepository repositories.AssetCategoryRepository,
 	syncLogRepository repositories.SyncLogRepository,
-	escoService ESCOServiceI,
+	syncRunRepository repositories.SyncRunRepository,
+	sources ...BrokerSource,
 ) *SyncService {
-	return &SyncService{
+	service := &SyncService{
+		db:                      db,
 		holdingRepository:       holdingRepository,
 		transactionRepository:   transactionRepository,
 		assetRepository:         assetRepository,
 		assetCategoryRepository: assetCategoryRepository,
 		syncLogRepository:       syncLogRepository,
-		escoService:             escoService,
+		syncRunRepository:       syncRunRepository,
+		sources:                 make(map[string]BrokerSource, len(sources)),
+		defaultSource:           models.SourceESCO,
+	}
+	for i, source := range sources {
+		if i == 0 {
+			service.defaultSource = source.Name()
+		}
+		service.sources[source.Name()] = source
+	}
+	return service
+}
+
+// HasSource reports whether the service can sync from the named broker source
+func (s *SyncService) HasSource(source string) bool {
+	_, exists := s.sources[source]
+	return exists
+}
+
+// sourceName returns the name of the broker source selected in the context, or the default one
+func (s *SyncService) sourceName(ctx context.Context) string {
+	if source := utils.SyncSourceFromContext(ctx); source != "" {
+		return source
 	}
+	return s.defaultSource
 }
 
+// brokerSource returns the broker source selected in the context, or the default one
+func (s *SyncService) brokerSource(ctx context.Context) (BrokerSource, error) {
+	name := s.sourceName(ctx)
+	source, exists := s.sources[name]
+	if !exists {
+		return nil, utils.BadRequest(fmt.Sprintf("unknown source %s", name))
+	}
+	return source, nil
+}
+
+// syncCounts holds the amount of rows written by a sync
+type syncCounts struct {
+	dates        int
+	holdings     int
+	transactions int
+}
+
+// SyncDataFromAccount syncs the account for the date range and records the run in the sync run history
 func (s *SyncService) SyncDataFromAccount(ctx context.Context, token, accountID string, startDate, endDate time.Time) error {
 	logger := utils.LoggerFromContext(ctx)
 	logger.Infof("Starting sync for account %s from %s to %s", accountID, startDate, endDate)
 
+	return s.recordSyncRun(ctx, accountID, startDate, endDate, func() (syncCounts, error) {
+		return s.syncDataFromAccount(ctx, token, accountID, startDate, endDate)
+	})
+}
+
+// ForceSyncDataFromAccount re-syncs every date of the range, even the ones already synced.
+// The data is refetched skipping the broker cache and replaces the stored holdings, transactions
+// and sync logs of the range, which are soft-deleted in the same database transaction.
+func (s *SyncService) ForceSyncDataFromAccount(ctx context.Context, token, accountID string, startDate, endDate time.Time) error {
+	logger := utils.LoggerFromContext(ctx)
+	logger.Infof("Starting forced sync for account %s from %s to %s", accountID, startDate, endDate)
+
+	return s.recordSyncRun(ctx, accountID, startDate, endDate, func() (syncCounts, error) {
+		return s.forceSyncDataFromAccount(ctx, token, accountID, startDate, endDate)
+	})
+}
+
+func (s *SyncService) forceSyncDataFromAccount(ctx context.Context, token, accountID string, startDate, endDate time.Time) (syncCounts, error) {
+	logger := utils.LoggerFromContext(ctx)
+
+	datesToSync := make([]time.Time, 0)
+	for date := startDate; date.Before(endDate); date = date.AddDate(0, 0, 1) {
+		datesToSync = append(datesToSync, date)
+	}
+	if len(datesToSync) == 0 {
+		return syncCounts{}, nil
+	}
+
+	source, err := s.brokerSource(ctx)
+	if err != nil {
+		return syncCounts{}, err
+	}
+	accountState, err := source.FetchAccountState(utils.WithRefreshCache(ctx, true), token, accountID, startDate, endDate)
+	if err != nil {
+		logger.Error(err)
+		return syncCounts{}, err
+	}
+
+	// Keep the stored data when the broker returns nothing, instead of wiping the range
+	if accountState == nil {
+		logger.Infof("No account state returned for account %s", accountID)
+		return syncCounts{}, nil
+	}
+
+	counts, err := s.storeAccountState(ctx, accountID, accountState, datesToSync, true, true)
+	if err != nil {
+		logger.Error(err)
+		return syncCounts{}, err
+	}
+
+	return counts, nil
+}
+
+func (s *SyncService) syncDataFromAccount(ctx context.Context, token, accountID string, startDate, endDate time.Time) (syncCounts, error) {
+	logger := utils.LoggerFromContext(ctx)
+
 	datesToSync, err := s.GetDatesToSync(ctx, token, accountID, startDate, endDate)
 	if err != nil {
-		return err
+		return syncCounts{}, err
 	}
 	if len(datesToSync) == 0 {
 		logger.Infof("Data is already synced for account %s from %s to %s", accountID, startDate, endDate)
-		return nil
+		return syncCounts{}, nil
 	}
 
-	accountState, err := s.escoService.GetAccountStateWithTransactions(ctx, token, accountID, startDate, endDate, time.Hour*24)
+	source, err := s.brokerSource(ctx)
+	if err != nil {
+		return syncCounts{}, err
+	}
+	accountState, err := source.FetchAccountState(ctx, token, accountID, startDate, endDate)
 	if err != nil {
 		logger.Error(err)
-		return err
+		return syncCounts{}, err
 	}
 
 	if accountState == nil {
 		logger.Infof("No account state returned for account %s", accountID)
-		return nil
+		return syncCounts{}, nil
 	}
 
-	err = s.StoreAccountState(ctx, accountID, accountState, datesToSync)
+	counts, err := s.storeAccountState(ctx, accountID, accountState, datesToSync, false, true)
 	if err != nil {
 		logger.Error(err)
-		return err
+		return syncCounts{}, err
 	}
 
-	return nil
+	return counts, nil
+}
+
+// recordSyncRun runs syncFunc recording it in the sync run history
+func (s *SyncService) recordSyncRun(ctx context.Context, accountID string, startDate, endDate time.Time, syncFunc func() (syncCounts, error)) error {
+	run := &models.SyncRun{
+		ClientID:    accountID,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		TriggeredBy: utils.SyncTriggerFromContext(ctx),
+	}
+	if err := s.syncRunRepository.Create(ctx, run); err != nil {
+		return fmt.Errorf("error creating sync run: %w", err)
+	}
+
+	counts, err := syncFunc()
+	s.finishSyncRun(ctx, run, counts, err)
+	return err
+}
+
+// finishSyncRun stores the outcome of the run. Failing to store it is only logged,
+// since the run history must not change the result of the sync itself. It is stored even when
+// the sync was cancelled, so the run is not left running.
+func (s *SyncService) finishSyncRun(ctx context.Context, run *models.SyncRun, counts syncCounts, syncErr error) {
+	run.Status = models.SyncRunCompleted
+	run.DatesSynced = counts.dates
+	run.HoldingsCount = counts.holdings
+	run.TransactionsCount = counts.transactions
+	if syncErr != nil {
+		errMessage := syncErr.Error()
+		run.Status = models.SyncRunFailed
+		run.Error = &errMessage
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), syncRunFinishTimeout)
+	defer cancel()
+	if err := s.syncRunRepository.Finish(ctx, run); err != nil {
+		utils.LoggerFromContext(ctx).Errorf("Error finishing sync run %d: %v", run.ID, err)
+	}
+}
+
+// GetSyncRuns returns the latest sync runs of the account, most recent first
+func (s *SyncService) GetSyncRuns(ctx context.Context, accountID string, limit int) ([]*models.SyncRun, error) {
+	return s.syncRunRepository.GetByClientID(ctx, accountID, limit)
 }
 
 func (s *SyncService) GetDatesToSync(ctx context.Context, token, accountID string, startDate, endDate time.Time) ([]time.Time, error) {
 	logger := utils.LoggerFromContext(ctx)
 	logger.Infof("Checking if data is synced for account %s from %s to %s", accountID, startDate, endDate)
-	syncedDates, err := s.syncLogRepository.GetSyncedDates(ctx, accountID, startDate, endDate)
+	syncedDates, err := s.syncLogRepository.GetSyncedDates(ctx, accountID, s.sourceName(ctx), startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -101,11 +266,52 @@ func (s *SyncService) GetDatesToSync(ctx context.Context, token, accountID strin
 	return datesToSync, nil
 }
 
+// StoreAccountState stores the account state for the dates to sync in a single database transaction,
+// so either every holding, transaction and sync log of the range is stored or none of them is
 func (s *SyncService) StoreAccountState(ctx context.Context, accountID string, accountState *schemas.AccountState, datesToSync []time.Time) error {
+	_, err := s.storeAccountState(ctx, accountID, accountState, datesToSync, false, true)
+	return err
+}
+
+// StoreTransactions stores the assets and transactions of the account state for the dates to sync without
+// marking the dates as synced. It is meant for data without holdings, which cannot stand for a synced date.
+func (s *SyncService) StoreTransactions(ctx context.Context, accountID string, accountState *schemas.AccountState, datesToSync []time.Time) error {
+	_, err := s.storeAccountState(ctx, accountID, accountState, datesToSync, false, false)
+	return err
+}
+
+// ReplaceAccountState stores the account state replacing the data already stored between the first
+// and last date to sync, recording it in the sync run history like a forced sync
+func (s *SyncService) ReplaceAccountState(ctx context.Context, accountID string, accountState *schemas.AccountState, datesToSync []time.Time) error {
+	if len(datesToSync) == 0 {
+		return nil
+	}
+	startDate, endDate := datesToSync[0], datesToSync[len(datesToSync)-1].AddDate(0, 0, 1)
+	return s.recordSyncRun(ctx, accountID, startDate, endDate, func() (syncCounts, error) {
+		return s.storeAccountState(ctx, accountID, accountState, datesToSync, true, true)
+	})
+}
+
+// storeAccountState stores the account state for the dates to sync. When replace is set, the data
+// already stored between the first and last date to sync is invalidated before storing the new one.
+// When markSynced is set, the dates with data are recorded in the sync logs. An empty account state
+// means the account held nothing, so it clears the range on replace and every date to sync is marked.
+func (s *SyncService) storeAccountState(ctx context.Context, accountID string, accountState *schemas.AccountState, datesToSync []time.Time, replace, markSynced bool) (syncCounts, error) {
+	var counts syncCounts
 	logger := utils.LoggerFromContext(ctx)
-	logger.Infof("Storing account state for account %s", accountID)
-	var err error
+	source := s.sourceName(ctx)
+	logger.Infof("Storing account state for account %s from %s", accountID, source)
 	dates := make(map[time.Time]bool)
+	assets := make(map[string]schemas.Asset)
+	if accountState.Assets != nil {
+		assets = *accountState.Assets
+	}
+	if len(assets) == 0 {
+		logger.Infof("Empty account state for account %s, marking the dates to sync without data", accountID)
+		for _, date := range datesToSync {
+			dates[date] = true
+		}
+	}
 
 	// Create a map for quick lookup of dates to sync
 	datesToSyncMap := make(map[string]bool)
@@ -113,30 +319,46 @@ func (s *SyncService) StoreAccountState(ctx context.Context, accountID string, a
 		datesToSyncMap[date.Format("2006-01-02")] = true
 	}
 
-	for _, asset := range *accountState.Assets {
-		err = s.storeAsset(ctx, &asset)
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return syncCounts{}, fmt.Errorf("error starting transaction: %w", err)
+	}
+	// Rollback is a no-op once the transaction has been committed
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if replace && len(datesToSync) > 0 {
+		err = s.invalidateStoredData(ctx, accountID, source, datesToSync[0], datesToSync[len(datesToSync)-1], tx)
 		if err != nil {
-			return fmt.Errorf("error storing asset %s: %w", asset.ID, err)
+			return syncCounts{}, fmt.Errorf("error invalidating stored data: %w", err)
+		}
+	}
+
+	for _, asset := range assets {
+		err = s.storeAsset(ctx, &asset, tx)
+		if err != nil {
+			return syncCounts{}, fmt.Errorf("error storing asset %s: %w", asset.ID, err)
 		}
 
 		// Filter holdings to only include dates in datesToSync
 		filteredHoldings := s.filterHoldingsByDates(asset.Holdings, datesToSyncMap)
 		logger.Infof("Filtered holdings for asset %s: %d out of %d", asset.ID, len(filteredHoldings), len(asset.Holdings))
 		if len(filteredHoldings) > 0 {
-			err = s.storeHoldings(ctx, accountID, asset.ID, filteredHoldings)
+			err = s.storeHoldings(ctx, accountID, source, asset.ID, filteredHoldings, tx)
 			if err != nil {
-				return fmt.Errorf("error storing holdings for asset %s: %w", asset.ID, err)
+				return syncCounts{}, fmt.Errorf("error storing holdings for asset %s: %w", asset.ID, err)
 			}
+			counts.holdings += len(filteredHoldings)
 		}
 
 		// Filter transactions to only include dates in datesToSync
 		filteredTransactions := s.filterTransactionsByDates(asset.Transactions, datesToSyncMap)
 		logger.Infof("Filtered transactions for asset %s: %d out of %d", asset.ID, len(filteredTransactions), len(asset.Transactions))
 		if len(filteredTransactions) > 0 {
-			err = s.storeTransactions(ctx, accountID, asset.ID, filteredTransactions)
+			stored, err := s.storeTransactions(ctx, accountID, source, asset.ID, filteredTransactions, tx)
 			if err != nil {
-				return fmt.Errorf("error storing transactions for asset %s: %w", asset.ID, err)
+				return syncCounts{}, fmt.Errorf("error storing transactions for asset %s: %w", asset.ID, err)
 			}
+			counts.transactions += stored
 		}
 
 		// Only collect dates that are in datesToSync
@@ -153,23 +375,44 @@ func (s *SyncService) StoreAccountState(ctx context.Context, accountID string, a
 		datesList = append(datesList, date)
 	}
 
-	if len(datesList) > 0 {
-		err = s.markDatesAsSynced(ctx, accountID, datesList)
+	if markSynced && len(datesList) > 0 {
+		err = s.markDatesAsSynced(ctx, accountID, source, datesList, tx)
 		if err != nil {
-			return fmt.Errorf("error marking dates as synced: %w", err)
+			return syncCounts{}, fmt.Errorf("error marking dates as synced: %w", err)
 		}
 	}
 
+	if err = tx.Commit(ctx); err != nil {
+		return syncCounts{}, fmt.Errorf("error committing account state: %w", err)
+	}
+	counts.dates = len(datesList)
+	return counts, nil
+}
+
+// invalidateStoredData removes the sync logs and soft-deletes the holdings and transactions
+// synced from source for the account between startDate and endDate (inclusive)
+func (s *SyncService) invalidateStoredData(ctx context.Context, accountID, source string, startDate, endDate time.Time, tx pgx.Tx) error {
+	logger := utils.LoggerFromContext(ctx)
+	logger.Infof("Invalidating stored %s data for account %s from %s to %s", source, accountID, startDate, endDate)
+	if err := s.syncLogRepository.CleanupSyncLogs(ctx, accountID, source, startDate, endDate, tx); err != nil {
+		return fmt.Errorf("error cleaning up sync logs: %w", err)
+	}
+	if err := s.holdingRepository.SoftDeleteByClientID(ctx, accountID, source, startDate, endDate, tx); err != nil {
+		return fmt.Errorf("error deleting holdings: %w", err)
+	}
+	if err := s.transactionRepository.SoftDeleteByClientID(ctx, accountID, source, startDate, endDate, tx); err != nil {
+		return fmt.Errorf("error deleting transactions: %w", err)
+	}
 	return nil
 }
 
-func (s *SyncService) markDatesAsSynced(ctx context.Context, accountID string, dates []time.Time) error {
+func (s *SyncService) markDatesAsSynced(ctx context.Context, accountID, source string, dates []time.Time, tx pgx.Tx) error {
 	logger := utils.LoggerFromContext(ctx)
 	logger.Infof("Marking dates as synced for account %s", accountID)
-	return s.syncLogRepository.MarkClientForDates(ctx, accountID, dates)
+	return s.syncLogRepository.MarkClientForDates(ctx, accountID, source, dates, tx)
 }
 
-func (s *SyncService) storeAsset(ctx context.Context, asset *schemas.Asset) error {
+func (s *SyncService) storeAsset(ctx context.Context, asset *schemas.Asset, tx pgx.Tx) error {
 	logger := utils.LoggerFromContext(ctx)
 	logger.Infof("Storing asset %s", asset.ID)
 	dbAssetCategory, err := s.assetCategoryRepository.GetByName(ctx, asset.Category)
@@ -180,19 +423,20 @@ func (s *SyncService) storeAsset(ctx context.Context, asset *schemas.Asset) erro
 		dbAssetCategory = &models.AssetCategory{
 			Name: asset.Category,
 		}
-		err = s.assetCategoryRepository.Create(ctx, dbAssetCategory, nil)
+		err = s.assetCategoryRepository.Create(ctx, dbAssetCategory, tx)
 		if err != nil {
 			return fmt.Errorf("error creating asset category: %w", err)
 		}
 	}
 	dbAsset := models.Asset{
-		ExternalID: asset.ID,
-		Name:       asset.Denomination,
-		AssetType:  asset.Type,
-		CategoryID: dbAssetCategory.ID,
-		Currency:   utils.AssetCurrencyPesos,
+		ExternalID:  asset.ID,
+		Name:        asset.Denomination,
+		AssetType:   asset.Type,
+		CategoryID:  dbAssetCategory.ID,
+		Currency:    utils.AssetCurrencyPesos,
+		CategoryKey: asset.CategoryKey,
 	}
-	err = s.assetRepository.Create(ctx, &dbAsset, nil)
+	err = s.assetRepository.Create(ctx, &dbAsset, tx)
 	if err != nil {
 		return fmt.Errorf("error creating asset: %w", err)
 	}
@@ -200,7 +444,7 @@ func (s *SyncService) storeAsset(ctx context.Context, asset *schemas.Asset) erro
 	return nil
 }
 
-func (s *SyncService) storeHoldings(ctx context.Context, accountID, assetID string, holdings []schemas.Holding) error {
+func (s *SyncService) storeHoldings(ctx context.Context, accountID, source, assetID string, holdings []schemas.Holding, tx pgx.Tx) error {
 	logger := utils.LoggerFromContext(ctx)
 	logger.Infof("Storing holdings for account %s", accountID)
 	assetIDInt, err := strconv.Atoi(assetID)
@@ -210,12 +454,13 @@ func (s *SyncService) storeHoldings(ctx context.Context, accountID, assetID stri
 	for _, holding := range holdings {
 		err := s.holdingRepository.Create(ctx, &models.Holding{
 			ClientID:  accountID,
+			Source:    source,
 			AssetID:   assetIDInt,
 			Value:     holding.Value,
 			Units:     holding.Units,
 			Date:      *holding.DateRequested,
 			CreatedAt: time.Now(),
-		}, nil)
+		}, tx)
 		if err != nil {
 			return fmt.Errorf("error creating holding: %w", err)
 		}
@@ -223,26 +468,66 @@ func (s *SyncService) storeHoldings(ctx context.Context, accountID, assetID stri
 	return nil
 }
 
-func (s *SyncService) storeTransactions(ctx context.Context, accountID, assetID string, transactions []schemas.Transaction) error {
+// storeTransactions upserts the asset transactions aggregated by date and type, since they are the
+// natural key of a stored transaction and re-running a sync must not duplicate rows
+func (s *SyncService) storeTransactions(ctx context.Context, accountID, source, assetID string, transactions []schemas.Transaction, tx pgx.Tx) (int, error) {
 	logger := utils.LoggerFromContext(ctx)
 	logger.Infof("Storing transactions for account %s", accountID)
 	assetIDInt, err := strconv.Atoi(assetID)
 	if err != nil {
-		return fmt.Errorf("error creating transaction: %w", err)
+		return 0, fmt.Errorf("error creating transaction: %w", err)
 	}
-	for _, transaction := range transactions {
+	aggregatedTransactions := s.aggregateTransactionsByDate(transactions)
+	for _, transaction := range aggregatedTransactions {
 		err = s.transactionRepository.Create(ctx, &models.Transaction{
-			ClientID:  accountID,
-			AssetID:   assetIDInt,
-			Units:     transaction.Units,
-			Date:      *transaction.Date,
-			CreatedAt: time.Now(),
-		}, nil)
+			ClientID:        accountID,
+			Source:          source,
+			AssetID:         assetIDInt,
+			TransactionType: transaction.Type,
+			Units:           transaction.Units,
+			PricePerUnit:    transaction.PricePerUnit,
+			GrossValue:      transaction.GrossValue,
+			ExchangeFees:    transaction.ExchangeFees,
+			MarketFees:      transaction.MarketFees,
+			TotalValue:      transaction.Value,
+			Date:            *transaction.Date,
+			CreatedAt:       time.Now(),
+		}, tx)
 		if err != nil {
-			return fmt.Errorf("error creating transaction: %w", err)
+			return 0, fmt.Errorf("error creating transaction: %w", err)
 		}
 	}
-	return nil
+	return len(aggregatedTransactions), nil
+}
+
+// aggregateTransactionsByDate sums the units and value of transactions of the same type that fall on the same day
+func (s *SyncService) aggregateTransactionsByDate(transactions []schemas.Transaction) []schemas.Transaction {
+	aggregated := make([]schemas.Transaction, 0, len(transactions))
+	indexByKey := make(map[string]int)
+	for _, transaction := range transactions {
+		key := transaction.Date.Format("2006-01-02") + "/" + transaction.Type
+		if i, exists := indexByKey[key]; exists {
+			aggregated[i] = mergeTransactions(aggregated[i], transaction)
+			continue
+		}
+		indexByKey[key] = len(aggregated)
+		aggregated = append(aggregated, transaction)
+	}
+	return aggregated
+}
+
+// mergeTransactions adds up two transactions of the same day and type. The price per unit of the
+// result is the average execution price of both, weighted by the units of each one.
+func mergeTransactions(existing, transaction schemas.Transaction) schemas.Transaction {
+	if units := math.Abs(existing.Units) + math.Abs(transaction.Units); units != 0 {
+		existing.PricePerUnit = (existing.PricePerUnit*math.Abs(existing.Units) + transaction.PricePerUnit*math.Abs(transaction.Units)) / units
+	}
+	existing.Units += transaction.Units
+	existing.Value += transaction.Value
+	existing.GrossValue += transaction.GrossValue
+	existing.ExchangeFees += transaction.ExchangeFees
+	existing.MarketFees += transaction.MarketFees
+	return existing
 }
 
 // filterHoldingsByDates filters holdings to only include those with dates in the datesToSync map
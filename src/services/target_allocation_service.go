@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/schemas"
+	"server/src/utils"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// targetWeightTolerance is how far from 100 the weights of an allocation may add up, to allow for rounding
+const targetWeightTolerance = 0.01
+
+type TargetAllocationServiceI interface {
+	GetTargetAllocationNames(ctx context.Context) ([]string, error)
+	GetTargetAllocation(ctx context.Context, name string) (*schemas.TargetAllocationResponse, error)
+	SetTargetAllocation(ctx context.Context, name string, req *schemas.TargetAllocationRequest) (*schemas.TargetAllocationResponse, error)
+	DeleteTargetAllocation(ctx context.Context, name string) error
+}
+
+// TargetAllocationService manages the target weights of the categories the returns of the reports
+// are attributed against
+type TargetAllocationService struct {
+	db *pgxpool.Pool
+
+	targetAllocationRepository repositories.TargetAllocationRepository
+}
+
+func NewTargetAllocationService(db *pgxpool.Pool, targetAllocationRepository repositories.TargetAllocationRepository) *TargetAllocationService {
+	return &TargetAllocationService{
+		db:                         db,
+		targetAllocationRepository: targetAllocationRepository,
+	}
+}
+
+func (s *TargetAllocationService) GetTargetAllocationNames(ctx context.Context) ([]string, error) {
+	return s.targetAllocationRepository.GetNames(ctx)
+}
+
+func (s *TargetAllocationService) GetTargetAllocation(ctx context.Context, name string) (*schemas.TargetAllocationResponse, error) {
+	name = strings.TrimSpace(name)
+	allocations, err := s.targetAllocationRepository.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if len(allocations) == 0 {
+		return nil, utils.NotFound(fmt.Sprintf("target allocation %s not found", name))
+	}
+	return targetAllocationsToResponse(name, allocations), nil
+}
+
+// SetTargetAllocation stores the weights of the categories, replacing the whole allocation
+func (s *TargetAllocationService) SetTargetAllocation(ctx context.Context, name string, req *schemas.TargetAllocationRequest) (*schemas.TargetAllocationResponse, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, utils.BadRequest("name is required")
+	}
+	if len(req.Categories) == 0 {
+		return nil, utils.BadRequest("categories are required")
+	}
+
+	allocations := make([]models.TargetAllocation, 0, len(req.Categories))
+	seen := make(map[string]bool)
+	var totalWeight float64
+	for _, category := range req.Categories {
+		categoryName := strings.TrimSpace(category.Category)
+		if categoryName == "" {
+			return nil, utils.BadRequest("category is required")
+		}
+		if seen[categoryName] {
+			return nil, utils.BadRequest(fmt.Sprintf("category %s is repeated", categoryName))
+		}
+		seen[categoryName] = true
+		if category.Weight < 0 {
+			return nil, utils.BadRequest(fmt.Sprintf("the weight of category %s cannot be negative", categoryName))
+		}
+		totalWeight += category.Weight
+
+		benchmark := strings.TrimSpace(category.Benchmark)
+		kind := strings.ToLower(strings.TrimSpace(category.BenchmarkKind))
+		if benchmark == "" {
+			kind = ""
+		} else if kind == "" {
+			kind = BenchmarkKindLevel
+		} else if !IsValidBenchmarkKind(kind) {
+			return nil, utils.BadRequest(fmt.Sprintf("invalid benchmark kind %q, expected one of %s", category.BenchmarkKind, strings.Join(BenchmarkKinds, ", ")))
+		}
+
+		allocations = append(allocations, models.TargetAllocation{
+			Name:          name,
+			Category:      categoryName,
+			Weight:        category.Weight,
+			Benchmark:     benchmark,
+			BenchmarkKind: kind,
+		})
+	}
+	if math.Abs(totalWeight-100) > targetWeightTolerance {
+		return nil, utils.BadRequest(fmt.Sprintf("the weights must add up to 100, got %g", totalWeight))
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	// Rollback is a no-op once the transaction has been committed
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := s.targetAllocationRepository.DeleteByName(ctx, name, tx); err != nil {
+		return nil, fmt.Errorf("error deleting target allocation: %w", err)
+	}
+	for i := range allocations {
+		if err := s.targetAllocationRepository.Create(ctx, &allocations[i], tx); err != nil {
+			return nil, fmt.Errorf("error storing target allocation: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return targetAllocationsToResponse(name, allocations), nil
+}
+
+func (s *TargetAllocationService) DeleteTargetAllocation(ctx context.Context, name string) error {
+	name = strings.TrimSpace(name)
+	if _, err := s.GetTargetAllocation(ctx, name); err != nil {
+		return err
+	}
+	return s.targetAllocationRepository.DeleteByName(ctx, name, nil)
+}
+
+func targetAllocationsToResponse(name string, allocations []models.TargetAllocation) *schemas.TargetAllocationResponse {
+	response := &schemas.TargetAllocationResponse{
+		Name:       name,
+		Categories: make([]schemas.TargetAllocationCategory, 0, len(allocations)),
+	}
+	for _, allocation := range allocations {
+		response.Categories = append(response.Categories, schemas.TargetAllocationCategory{
+			Category:      allocation.Category,
+			Weight:        allocation.Weight,
+			Benchmark:     allocation.Benchmark,
+			BenchmarkKind: allocation.BenchmarkKind,
+		})
+	}
+	return response
+}
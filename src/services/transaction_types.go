@@ -0,0 +1,91 @@
+package services
+
+import (
+	"server/src/models"
+	"server/src/schemas"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// classifyBoletoOperation maps the operation of a boleto (O, or TO when O is empty)
+// to a transaction type, e.g. "CompraSENEBING" is a buy
+func classifyBoletoOperation(operation, operationType string) string {
+	if operation == "" {
+		operation = operationType
+	}
+	switch op := strings.ToLower(operation); {
+	case strings.HasPrefix(op, "compra"), strings.HasPrefix(op, "licitaci"):
+		return models.TransactionTypeBuy
+	case strings.HasPrefix(op, "venta"):
+		return models.TransactionTypeSell
+	case strings.HasPrefix(op, "suscrip"):
+		return models.TransactionTypeSubscription
+	case strings.HasPrefix(op, "rescate"):
+		return models.TransactionTypeRedemption
+	default:
+		return ""
+	}
+}
+
+// classifyLiquidacionOperation maps the operation type (TO) of a fund liquidacion to a transaction type
+func classifyLiquidacionOperation(operationType string) string {
+	switch op := strings.ToLower(operationType); {
+	case strings.HasPrefix(op, "suscrip"):
+		return models.TransactionTypeSubscription
+	case strings.HasPrefix(op, "rescate"):
+		return models.TransactionTypeRedemption
+	default:
+		return ""
+	}
+}
+
+// classifyCtaCteMovement maps the description (D) of a cuenta corriente movement to a transaction type.
+// Boletos only name the operation number, so they are told apart by the sign of the units received.
+func classifyCtaCteMovement(description string, units float64) string {
+	switch {
+	case strings.Contains(description, "Retiro de Títulos"), strings.Contains(description, "Transferencia"):
+		return models.TransactionTypeTransfer
+	case strings.Contains(description, "Liquidación de Suscripción"):
+		return models.TransactionTypeSubscription
+	case strings.Contains(description, "Liquidación de Rescate"):
+		return models.TransactionTypeRedemption
+	case strings.Contains(description, "Partial redemption"), strings.Contains(description, "Amortización"):
+		return models.TransactionTypeAmortization
+	case strings.Contains(description, "Dividend"):
+		return models.TransactionTypeDividend
+	case strings.Contains(description, "Renta"), strings.Contains(description, "Interest payment"):
+		return models.TransactionTypeCoupon
+	case strings.Contains(description, "Gasto"), strings.Contains(description, "Comisión"), strings.Contains(description, "Arancel"):
+		return models.TransactionTypeFee
+	case strings.Contains(description, "Boleto"):
+		if units < 0 {
+			return models.TransactionTypeSell
+		}
+		return models.TransactionTypeBuy
+	default:
+		return ""
+	}
+}
+
+// includesTransactionType reports whether transactionType is one of transactionTypes, every type being
+// included when none are given
+func includesTransactionType(transactionTypes []string, transactionType string) bool {
+	return len(transactionTypes) == 0 || slices.Contains(transactionTypes, transactionType)
+}
+
+// sortTransactionsByType flattens the transactions of every type by date into date sorted lists
+func sortTransactionsByType(transactionsByTypeAndDate map[string]map[string]schemas.Transaction) map[string][]schemas.Transaction {
+	transactionsByType := make(map[string][]schemas.Transaction, len(transactionsByTypeAndDate))
+	for transactionType, transactionsByDate := range transactionsByTypeAndDate {
+		transactions := make([]schemas.Transaction, 0, len(transactionsByDate))
+		for _, transaction := range transactionsByDate {
+			transactions = append(transactions, transaction)
+		}
+		sort.Slice(transactions, func(i, j int) bool {
+			return transactions[i].Date.Before(*transactions[j].Date)
+		})
+		transactionsByType[transactionType] = transactions
+	}
+	return transactionsByType
+}
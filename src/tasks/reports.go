@@ -1,7 +1,11 @@
 package tasks
 
-import "server/src/models"
+import (
+	"context"
+	"server/src/models"
+)
 
-func SendReportByEmail(reportSchedule *models.ReportSchedule) error {
+// SendReportByEmail runs with the ESCO service account token, since there is no user behind a scheduled report
+func SendReportByEmail(ctx context.Context, token string, reportSchedule *models.ReportSchedule) error {
 	return nil
 }
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +10,18 @@ import (
 	"time"
 )
 
+const refreshCacheKey = contextKey("refreshCache")
+
+// WithRefreshCache makes the client calls done with the returned context skip cached responses
+func WithRefreshCache(ctx context.Context, refresh bool) context.Context {
+	return context.WithValue(ctx, refreshCacheKey, refresh)
+}
+
+func RefreshCacheFromContext(ctx context.Context) bool {
+	refresh, _ := ctx.Value(refreshCacheKey).(bool)
+	return refresh
+}
+
 type CacheHandlerI interface {
 	Set(key string, value interface{}, expiration time.Duration) error
 	Get(key string, result interface{}) error
@@ -96,20 +96,16 @@ func SortDataFrameColumns(df *dataframe.DataFrame, firstColumns []string, finalC
 	// Get column names
 	colNames := df.Names()
 
-	// Sort column names (keeping first columns first and final columns last)
+	// Sort column names (keeping first columns first and final columns last, in the given order)
 	// Middle columns are sorted alphabetically
 	sort.Slice(colNames, func(i, j int) bool {
-		if slices.Contains(firstColumns, colNames[i]) {
-			return true
-		}
-		if slices.Contains(firstColumns, colNames[j]) {
-			return false
+		firstI, firstJ := slices.Index(firstColumns, colNames[i]), slices.Index(firstColumns, colNames[j])
+		if firstI >= 0 || firstJ >= 0 {
+			return firstI >= 0 && (firstJ < 0 || firstI < firstJ)
 		}
-		if slices.Contains(finalColumns, colNames[i]) {
-			return false
-		}
-		if slices.Contains(finalColumns, colNames[j]) {
-			return true
+		finalI, finalJ := slices.Index(finalColumns, colNames[i]), slices.Index(finalColumns, colNames[j])
+		if finalI >= 0 || finalJ >= 0 {
+			return finalJ >= 0 && (finalI < 0 || finalI < finalJ)
 		}
 		return colNames[i] < colNames[j]
 	})
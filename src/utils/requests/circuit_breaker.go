@@ -0,0 +1,78 @@
+package requests
+
+import (
+	"sync"
+	"time"
+)
+
+// circuitBreaker tracks consecutive failures per host. Once a host reaches the threshold
+// its calls are rejected until the cooldown passes, then a single trial call is let through:
+// a success closes the circuit again and a failure keeps it open for another cooldown.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	hosts     map[string]*hostState
+	now       func() time.Time
+}
+
+type hostState struct {
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		hosts:     make(map[string]*hostState),
+		now:       time.Now,
+	}
+}
+
+// allow reports whether a call to host may be made
+func (b *circuitBreaker) allow(host string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.hosts[host]
+	if !ok || state.failures < b.threshold {
+		return true
+	}
+	if b.now().Before(state.openUntil) || state.trial {
+		return false
+	}
+	state.trial = true
+	return true
+}
+
+func (b *circuitBreaker) success(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.hosts, host)
+}
+
+// release lets another trial call through when the one allowed ended without an outcome,
+// like when its caller cancelled it
+func (b *circuitBreaker) release(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if state, ok := b.hosts[host]; ok {
+		state.trial = false
+	}
+}
+
+func (b *circuitBreaker) failure(host string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.hosts[host]
+	if !ok {
+		state = &hostState{}
+		b.hosts[host] = state
+	}
+	state.failures++
+	state.trial = false
+	if state.failures >= b.threshold {
+		state.openUntil = b.now().Add(b.cooldown)
+	}
+}
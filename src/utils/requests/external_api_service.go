@@ -2,25 +2,189 @@ package requests
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
+	"server/src/config"
 	"server/src/utils"
+	"strconv"
+	"time"
 )
 
-// ExternalAPIService is a struct representing a configurable external service
+const (
+	defaultTimeout             = 30 * time.Second
+	defaultMaxIdleConnsPerHost = 10
+	defaultMaxRetries          = 3
+	defaultBaseBackoff         = 500 * time.Millisecond
+	defaultMaxBackoff          = 10 * time.Second
+	defaultBreakerThreshold    = 5
+	defaultBreakerCooldown     = 30 * time.Second
+)
+
+// ExternalAPIService is a struct representing a configurable external service.
+// All calls share one pooled client, idempotent calls are retried with exponential backoff
+// and calls to a host that keeps failing are short-circuited until it recovers.
 type ExternalAPIService struct {
 	TLSClientConfig *tls.Config
+	Client          *http.Client
+
+	maxRetries  int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	breaker     *circuitBreaker
 }
 
 // NewExternalAPIService creates a new instance of ExternalAPIService
-func NewExternalAPIService(tlsConfig *tls.Config) *ExternalAPIService {
-	return &ExternalAPIService{TLSClientConfig: tlsConfig}
+func NewExternalAPIService(tlsConfig *tls.Config, cfg config.HTTPClientConfig) *ExternalAPIService {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.MaxIdleConnsPerHost <= 0 {
+		cfg.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	} else if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultMaxRetries
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = defaultBaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	if cfg.BreakerThreshold <= 0 {
+		cfg.BreakerThreshold = defaultBreakerThreshold
+	}
+	if cfg.BreakerCooldown <= 0 {
+		cfg.BreakerCooldown = defaultBreakerCooldown
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	if tlsConfig != nil {
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &ExternalAPIService{
+		TLSClientConfig: tlsConfig,
+		Client:          &http.Client{Transport: transport, Timeout: cfg.Timeout},
+		maxRetries:      cfg.MaxRetries,
+		baseBackoff:     cfg.BaseBackoff,
+		maxBackoff:      cfg.MaxBackoff,
+		breaker:         newCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+	}
+}
+
+// Do sends the request through the shared client. Idempotent requests are retried on network
+// errors, 429 and 5xx responses, waiting for Retry-After when the upstream sends it.
+// When retries run out the last response is returned so callers can inspect its status.
+func (s *ExternalAPIService) Do(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	retries := 0
+	if isIdempotent(req.Method) {
+		retries = s.maxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		if !s.breaker.allow(host) {
+			return nil, utils.ServiceUnavailable(fmt.Sprintf("circuit open for %s, try again later", host))
+		}
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				s.breaker.release(host)
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := s.Client.Do(req)
+		// A cancelled call says nothing about the host
+		if err != nil && req.Context().Err() != nil {
+			s.breaker.release(host)
+			return nil, err
+		}
+		if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+			s.breaker.failure(host)
+		} else {
+			s.breaker.success(host)
+		}
+		if !shouldRetry(resp, err) || attempt >= retries {
+			return resp, err
+		}
+
+		delay, ok := s.retryDelay(attempt, resp)
+		if !ok {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// retryDelay returns how long to wait before the next attempt. A Retry-After longer than
+// maxBackoff is not waited for and the current response is returned instead.
+func (s *ExternalAPIService) retryDelay(attempt int, resp *http.Response) (time.Duration, bool) {
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			return retryAfter, retryAfter <= s.maxBackoff
+		}
+	}
+	backoff := s.baseBackoff << attempt
+	if backoff <= 0 || backoff > s.maxBackoff {
+		backoff = s.maxBackoff
+	}
+	// Equal jitter: wait at least half the backoff so retries still spread out over time
+	half := backoff / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1)), true
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+	return 0, false
 }
 
 // makeRequest is a helper function to make HTTP requests, supporting optional query parameters
-func (s *ExternalAPIService) makeRequest(method, endpoint, token string, params url.Values, body interface{}) (*http.Response, error) {
+func (s *ExternalAPIService) makeRequest(ctx context.Context, method, endpoint, token string, params url.Values, body interface{}) (*http.Response, error) {
 	// Convert params to query string
 	if params != nil {
 		endpoint = endpoint + "?" + params.Encode()
@@ -37,7 +201,7 @@ func (s *ExternalAPIService) makeRequest(method, endpoint, token string, params
 	}
 
 	// Create the request
-	req, err := http.NewRequest(method, endpoint, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, err
 	}
@@ -46,39 +210,31 @@ func (s *ExternalAPIService) makeRequest(method, endpoint, token string, params
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
-	// Execute the request
-	if s.TLSClientConfig != nil {
-		tr := &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-		client = &http.Client{Transport: tr}
-	}
-	return client.Do(req)
+	return s.Do(req)
 }
 
 // Get makes a GET request to the external service, accepting optional query parameters
-func (s *ExternalAPIService) Get(endpoint, token string, params url.Values) (*http.Response, error) {
-	return s.makeRequest("GET", endpoint, token, params, nil)
+func (s *ExternalAPIService) Get(ctx context.Context, endpoint, token string, params url.Values) (*http.Response, error) {
+	return s.makeRequest(ctx, "GET", endpoint, token, params, nil)
 }
 
 // Post makes a POST request to the external service, accepting optional query parameters
-func (s *ExternalAPIService) Post(endpoint, token string, params url.Values, body interface{}) (*http.Response, error) {
-	return s.makeRequest("POST", endpoint, token, params, body)
+func (s *ExternalAPIService) Post(ctx context.Context, endpoint, token string, params url.Values, body interface{}) (*http.Response, error) {
+	return s.makeRequest(ctx, "POST", endpoint, token, params, body)
 }
 
 // Put makes a PUT request to the external service, accepting optional query parameters
-func (s *ExternalAPIService) Put(endpoint, token string, params url.Values, body interface{}) (*http.Response, error) {
-	return s.makeRequest("PUT", endpoint, token, params, body)
+func (s *ExternalAPIService) Put(ctx context.Context, endpoint, token string, params url.Values, body interface{}) (*http.Response, error) {
+	return s.makeRequest(ctx, "PUT", endpoint, token, params, body)
 }
 
 // Delete makes a DELETE request to the external service, accepting optional query parameters
-func (s *ExternalAPIService) Delete(endpoint, token string, params url.Values) (*http.Response, error) {
-	return s.makeRequest("DELETE", endpoint, token, params, nil)
+func (s *ExternalAPIService) Delete(ctx context.Context, endpoint, token string, params url.Values) (*http.Response, error) {
+	return s.makeRequest(ctx, "DELETE", endpoint, token, params, nil)
 }
 
 // PostWithHeaders makes a POST request with custom headers
-func (s *ExternalAPIService) PostWithHeaders(endpoint, token string, body interface{}, headers map[string]string) (*http.Response, error) {
+func (s *ExternalAPIService) PostWithHeaders(ctx context.Context, endpoint, token string, body interface{}, headers map[string]string) (*http.Response, error) {
 	var err error
 	var jsonBody []byte
 	if body != nil {
@@ -88,7 +244,7 @@ func (s *ExternalAPIService) PostWithHeaders(endpoint, token string, body interf
 		}
 	}
 
-	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return nil, err
 	}
@@ -99,13 +255,13 @@ func (s *ExternalAPIService) PostWithHeaders(endpoint, token string, body interf
 		req.Header.Set(key, value)
 	}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := s.Do(req)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode > http.StatusCreated {
+		resp.Body.Close()
 		return nil, utils.NewHTTPError(resp.StatusCode, resp.Status)
 	}
 	return resp, nil
@@ -0,0 +1,39 @@
+package utils
+
+import "context"
+
+const syncTriggerKey = contextKey("syncTrigger")
+
+// Sync triggers recorded in the sync run history
+const (
+	SyncTriggerManual          = "manual"
+	SyncTriggerIncrementalSync = "incremental_sync"
+	SyncTriggerSyncJob         = "sync_job"
+	SyncTriggerReplay          = "replay"
+)
+
+// WithSyncTrigger stores who or what started the syncs run with the returned context
+func WithSyncTrigger(ctx context.Context, trigger string) context.Context {
+	return context.WithValue(ctx, syncTriggerKey, trigger)
+}
+
+func SyncTriggerFromContext(ctx context.Context) string {
+	trigger, ok := ctx.Value(syncTriggerKey).(string)
+	if !ok || trigger == "" {
+		return SyncTriggerManual
+	}
+	return trigger
+}
+
+const syncSourceKey = contextKey("syncSource")
+
+// WithSyncSource selects the broker source the syncs run with the returned context fetch from and store as
+func WithSyncSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, syncSourceKey, source)
+}
+
+// SyncSourceFromContext returns the selected broker source, empty when the default one is used
+func SyncSourceFromContext(ctx context.Context) string {
+	source, _ := ctx.Value(syncSourceKey).(string)
+	return source
+}
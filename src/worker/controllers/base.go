@@ -1,22 +1,50 @@
 package controllers
 
 import (
+	"context"
 	"server/src/scheduler"
+	"server/src/services"
 	"sync"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
 )
 
 type Controller struct {
+	Logger         *logrus.Logger
 	DB             *pgxpool.Pool
 	SchedulerMutex sync.Mutex
 	Schedulers     map[uint]*scheduler.ScheduledTask
+	SyncJobService services.SyncJobServiceI
+	SyncJobRunner  *scheduler.ScheduledTask
+
+	IncrementalSyncService   services.IncrementalSyncServiceI
+	IncrementalSyncScheduler *scheduler.ScheduledTask
+
+	TokenManager services.ESCOTokenManagerI
 }
 
-func NewController(db *pgxpool.Pool) *Controller {
-	return &Controller{DB: db, SchedulerMutex: sync.Mutex{}, Schedulers: map[uint]*scheduler.ScheduledTask{}}
+func NewController(logger *logrus.Logger, db *pgxpool.Pool, syncJobService services.SyncJobServiceI, incrementalSyncService services.IncrementalSyncServiceI, tokenManager services.ESCOTokenManagerI) *Controller {
+	return &Controller{
+		Logger:                 logger,
+		DB:                     db,
+		SchedulerMutex:         sync.Mutex{},
+		Schedulers:             map[uint]*scheduler.ScheduledTask{},
+		SyncJobService:         syncJobService,
+		IncrementalSyncService: incrementalSyncService,
+		TokenManager:           tokenManager,
+	}
 }
 
 func (c *Controller) GetSchedulers() map[uint]*scheduler.ScheduledTask {
 	return c.Schedulers
 }
+
+// serviceAccountToken returns the ESCO service account token for scheduled tasks,
+// or an empty token when no token manager is set
+func (c *Controller) serviceAccountToken(ctx context.Context) (string, error) {
+	if c.TokenManager == nil {
+		return "", nil
+	}
+	return c.TokenManager.GetToken(ctx)
+}
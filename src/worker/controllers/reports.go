@@ -2,10 +2,10 @@ package controllers
 
 import (
 	"context"
-	"fmt"
 	"server/src/models"
 	"server/src/scheduler"
 	"server/src/tasks"
+	"server/src/utils"
 )
 
 // LoadAllReportSchedule loads all report schedules and schedules them
@@ -38,7 +38,7 @@ func (c *Controller) LoadReportScheduleByID(ctx context.Context, ID uint) error
 }
 
 // scheduleReport handles the scheduling and re-scheduling of report tasks
-func (c *Controller) ScheduleReport(_ context.Context, reportSchedule *models.ReportSchedule, taskFunc func(*models.ReportSchedule) error) error {
+func (c *Controller) ScheduleReport(_ context.Context, reportSchedule *models.ReportSchedule, taskFunc func(context.Context, string, *models.ReportSchedule) error) error {
 	// Delete the existing scheduled goroutine
 	c.SchedulerMutex.Lock()
 	if existingTask, exists := c.Schedulers[reportSchedule.ID]; exists {
@@ -49,9 +49,14 @@ func (c *Controller) ScheduleReport(_ context.Context, reportSchedule *models.Re
 
 	// Create a new scheduled goroutine
 	newTask, err := scheduler.NewScheduledTask(reportSchedule.CronTime, func() {
-		err := taskFunc(reportSchedule)
+		ctx := utils.WithLogger(context.Background(), c.Logger)
+		token, err := c.serviceAccountToken(ctx)
 		if err != nil {
-			fmt.Println(err.Error())
+			c.Logger.Errorf("Error getting esco service account token for report schedule %d: %v", reportSchedule.ID, err)
+			return
+		}
+		if err = taskFunc(ctx, token, reportSchedule); err != nil {
+			c.Logger.Errorf("Error running report schedule %d: %v", reportSchedule.ID, err)
 		}
 	})
 	if err != nil {
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"server/src/scheduler"
+	"server/src/utils"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const defaultSyncJobPollInterval = 5 * time.Second
+
+// StartSyncJobRunner polls for pending sync jobs on every pollInterval tick,
+// keeping at most maxConcurrentJobs jobs running at the same time. The jobs a stopped
+// worker left running are requeued at start and on every tick once their lease expires.
+func (c *Controller) StartSyncJobRunner(logger *logrus.Logger, pollInterval time.Duration, maxConcurrentJobs int) error {
+	if pollInterval <= 0 {
+		pollInterval = defaultSyncJobPollInterval
+	}
+	if maxConcurrentJobs <= 0 {
+		maxConcurrentJobs = 1
+	}
+	if err := c.SyncJobService.RequeueInterruptedSyncJobs(utils.WithLogger(context.Background(), logger)); err != nil {
+		return err
+	}
+	slots := make(chan struct{}, maxConcurrentJobs)
+
+	task, err := scheduler.NewScheduledTask(fmt.Sprintf("@every %s", pollInterval), func() {
+		if err := c.SyncJobService.RequeueInterruptedSyncJobs(utils.WithLogger(context.Background(), logger)); err != nil {
+			logger.Error(err)
+		}
+		for i := 0; i < maxConcurrentJobs; i++ {
+			select {
+			case slots <- struct{}{}:
+				go func() {
+					defer func() { <-slots }()
+					ctx := utils.WithLogger(context.Background(), logger)
+					if _, err := c.SyncJobService.RunNextSyncJob(ctx); err != nil {
+						logger.Error(err)
+					}
+				}()
+			default:
+				return
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	c.SchedulerMutex.Lock()
+	if c.SyncJobRunner != nil {
+		c.SyncJobRunner.Cancel()
+	}
+	c.SyncJobRunner = task
+	c.SchedulerMutex.Unlock()
+	return nil
+}
+
+// StartIncrementalSyncScheduler runs the incremental sync of all accounts on the given cron spec.
+// An empty spec leaves the scheduler disabled.
+func (c *Controller) StartIncrementalSyncScheduler(logger *logrus.Logger, cronSpec string) error {
+	if cronSpec == "" {
+		logger.Info("Incremental sync scheduler is disabled")
+		return nil
+	}
+
+	task, err := scheduler.NewScheduledTask(cronSpec, func() {
+		ctx := utils.WithLogger(context.Background(), logger)
+		if err := c.IncrementalSyncService.RunIncrementalSync(ctx); err != nil {
+			logger.Error(err)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	c.SchedulerMutex.Lock()
+	if c.IncrementalSyncScheduler != nil {
+		c.IncrementalSyncScheduler.Cancel()
+	}
+	c.IncrementalSyncScheduler = task
+	c.SchedulerMutex.Unlock()
+	return nil
+}
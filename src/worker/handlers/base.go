@@ -5,23 +5,67 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"server/src/clients/esco"
 	"server/src/config"
 	"server/src/database"
+	"server/src/repositories"
+	"server/src/services"
 	"server/src/utils"
+	redis_utils "server/src/utils/redis"
 	"server/src/worker/controllers"
+
+	"github.com/sirupsen/logrus"
 )
 
 type Handler struct {
+	Logger     *logrus.Logger
 	Controller *controllers.Controller
 }
 
-func NewHandler(cfg *config.Config) (*Handler, error) {
+func NewHandler(cfg *config.Config, logger *logrus.Logger) (*Handler, error) {
+	redis, err := redis_utils.NewRedisHandler(cfg)
+	if err != nil {
+		return nil, err
+	}
 	db, err := database.SetupDB(cfg)
 	if err != nil {
 		return nil, err
 	}
-	controller := controllers.NewController(db)
-	return &Handler{Controller: controller}, nil
+	escoClient, err := esco.NewClient(cfg, redis, repositories.NewESCOPayloadRepository(db))
+	if err != nil {
+		return nil, err
+	}
+
+	syncLogRepository := repositories.NewSyncLogRepository(db)
+	categoryResolver := services.NewCategoryRuleResolver(repositories.NewCategoryRuleRepository(db), services.MapCategoryResolver(escoClient.GetCategoryMap()), 0)
+	syncService := services.NewSyncService(
+		db,
+		repositories.NewHoldingRepository(db),
+		repositories.NewTransactionRepository(db),
+		repositories.NewAssetRepository(db),
+		repositories.NewAssetCategoryRepository(db),
+		syncLogRepository,
+		repositories.NewSyncRunRepository(db),
+		services.NewESCOBrokerSource(services.NewESCOService(escoClient, categoryResolver)),
+	)
+	tokenManager := services.NewESCOTokenManager(
+		escoClient,
+		redis,
+		cfg.ExternalClients.ESCO.ServiceAccount.Username,
+		cfg.ExternalClients.ESCO.ServiceAccount.Password,
+		cfg.ExternalClients.ESCO.ServiceAccount.RefreshBefore,
+	)
+	syncJobService := services.NewSyncJobService(repositories.NewSyncJobRepository(db), syncService, tokenManager, cfg.Worker.SyncJobs.ChunkDays, cfg.Worker.SyncJobs.LeaseTimeout)
+	incrementalSyncService := services.NewIncrementalSyncService(
+		syncService,
+		syncLogRepository,
+		tokenManager,
+		cfg.Worker.IncrementalSync.Accounts,
+		cfg.Worker.IncrementalSync.BackfillDays,
+	)
+
+	controller := controllers.NewController(logger, db, syncJobService, incrementalSyncService, tokenManager)
+	return &Handler{Logger: logger, Controller: controller}, nil
 }
 
 func (h *Handler) respond(w http.ResponseWriter, _ *http.Request, data interface{}, status int) {
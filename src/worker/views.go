@@ -15,8 +15,16 @@ type Server struct {
 	Handler *handlers.Handler
 }
 
-func NewServer(cfg *config.Config) (*Server, error) {
-	handler, err := handlers.NewHandler(cfg)
+func NewServer(cfg *config.Config, logger *logrus.Logger) (*Server, error) {
+	handler, err := handlers.NewHandler(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	err = handler.Controller.StartSyncJobRunner(logger, cfg.Worker.SyncJobs.PollInterval, cfg.Worker.SyncJobs.MaxConcurrentJobs)
+	if err != nil {
+		return nil, err
+	}
+	err = handler.Controller.StartIncrementalSyncScheduler(logger, cfg.Worker.IncrementalSync.Cron)
 	if err != nil {
 		return nil, err
 	}
@@ -41,7 +49,7 @@ func (s *Server) InitRoutes() {
 }
 
 func NewHTTPServer(cfg *config.Config, logger *logrus.Logger) (*http.Server, error) {
-	server, err := NewServer(cfg)
+	server, err := NewServer(cfg, logger)
 	if err != nil {
 		return nil, err
 	}
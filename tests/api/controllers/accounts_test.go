@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"server/src/api/controllers"
+	"server/src/models"
 	"server/src/schemas"
+	"server/src/services"
 	"server/src/utils"
 	"testing"
 	"time"
@@ -144,3 +147,52 @@ func TestCollapseAndGroupAccountsStates(t *testing.T) {
 		}
 	}
 }
+
+// fakeSyncJobService queues jobs in memory, failing for the accounts in failAccounts
+type fakeSyncJobService struct {
+	services.SyncJobServiceI
+	failAccounts map[string]bool
+	queued       []string
+}
+
+func (s *fakeSyncJobService) SubmitSyncJob(_ context.Context, accountID string, startDate, endDate time.Time, force bool) (*models.SyncJob, error) {
+	if s.failAccounts[accountID] {
+		return nil, fmt.Errorf("error creating sync job for account %s", accountID)
+	}
+	s.queued = append(s.queued, accountID)
+	return &models.SyncJob{ID: len(s.queued), ClientID: accountID, StartDate: startDate, EndDate: endDate, Force: force, Status: models.SyncJobPending}, nil
+}
+
+func TestBulkSyncAccounts(t *testing.T) {
+	syncJobService := &fakeSyncJobService{failAccounts: map[string]bool{"2": true}}
+	controller := controllers.NewAccountsController(nil, nil, nil, nil, syncJobService)
+
+	req := &schemas.BulkSyncRequest{
+		AccountIDs: []string{"1", "2", "3"},
+		StartDate:  schemas.Date{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		EndDate:    schemas.Date{Time: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)},
+	}
+	response, err := controller.BulkSyncAccounts(context.Background(), "", req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(syncJobService.queued) != 2 || syncJobService.queued[0] != "1" || syncJobService.queued[1] != "3" {
+		t.Errorf("Expected accounts 1 and 3 to be queued, got %v", syncJobService.queued)
+	}
+	if response.Total != 3 || response.Queued != 2 || response.Failed != 1 {
+		t.Errorf("Expected 3 accounts with 2 queued and 1 failed, got %+v", response)
+	}
+	if len(response.Results) != 3 {
+		t.Fatalf("Expected a result per account, got %d", len(response.Results))
+	}
+	for _, result := range response.Results {
+		if result.AccountID == "2" {
+			if result.Error == "" || result.JobID != 0 {
+				t.Errorf("Expected account 2 to report its error without a job, got %+v", result)
+			}
+		} else if result.Error != "" || result.JobID == 0 {
+			t.Errorf("Expected account %s to report its job, got %+v", result.AccountID, result)
+		}
+	}
+}
@@ -64,19 +64,24 @@ func TestMain(m *testing.M) {
 	assetRepository := repositories.NewAssetRepository(testDB)
 	assetCategoryRepository := repositories.NewAssetCategoryRepository(testDB)
 	syncLogRepository := repositories.NewSyncLogRepository(testDB)
+	syncRunRepository := repositories.NewSyncRunRepository(testDB)
+	syncJobRepository := repositories.NewSyncJobRepository(testDB)
 
-	escoService := services.NewESCOService(escoClient)
+	escoService := services.NewESCOService(escoClient, nil)
 	syncService := services.NewSyncService(
+		testDB,
 		holdingRepository,
 		transactionRepository,
 		assetRepository,
 		assetCategoryRepository,
 		syncLogRepository,
-		escoService,
+		syncRunRepository,
+		services.NewESCOBrokerSource(escoService),
 	)
 	accountService := services.NewAccountService(holdingRepository, transactionRepository, assetRepository)
+	syncJobService := services.NewSyncJobService(syncJobRepository, syncService, nil, 0, 0)
 	ctrl = controllers.NewController(escoClient, bcraClient)
-	accountsController = controllers.NewAccountsController(escoClient, escoService, syncService, accountService)
+	accountsController = controllers.NewAccountsController(escoClient, escoService, syncService, accountService, syncJobService)
 
 	// Create report service
 	reportService := services.NewReportService()
@@ -84,7 +89,11 @@ func TestMain(m *testing.M) {
 	// Create report parser service
 	reportParserService := services.NewReportParserService()
 
-	reportsController = controllers.NewReportsController(escoClient, bcraClient, reportService, reportParserService, accountService)
+	exchangeRateService := services.NewExchangeRateService(repositories.NewExchangeRateRepository(testDB))
+	benchmarkService := services.NewBenchmarkService(bcraClient, repositories.NewBenchmarkValueRepository(testDB))
+	targetAllocationService := services.NewTargetAllocationService(testDB, repositories.NewTargetAllocationRepository(testDB))
+
+	reportsController = controllers.NewReportsController(escoClient, bcraClient, reportService, reportParserService, accountService, exchangeRateService, benchmarkService, targetAllocationService, cfg.Reports.RiskFreeRateID)
 	reportsScheduleController = controllers.NewReportScheduleController(testDB)
 
 	os.Exit(m.Run())
@@ -54,15 +54,19 @@ func TestMain(m *testing.M) {
 	assetRepository := repositories.NewAssetRepository(db)
 	assetCategoryRepository := repositories.NewAssetCategoryRepository(db)
 	syncLogRepository := repositories.NewSyncLogRepository(db)
+	syncRunRepository := repositories.NewSyncRunRepository(db)
+	syncJobRepository := repositories.NewSyncJobRepository(db)
 
-	escoService := services.NewESCOService(escoClient)
+	escoService := services.NewESCOService(escoClient, nil)
 	syncService := services.NewSyncService(
+		db,
 		holdingRepository,
 		transactionRepository,
 		assetRepository,
 		assetCategoryRepository,
 		syncLogRepository,
-		escoService,
+		syncRunRepository,
+		services.NewESCOBrokerSource(escoService),
 	)
 	if err != nil {
 		log.Println(err, "Error while starting handler")
@@ -73,8 +77,20 @@ func TestMain(m *testing.M) {
 
 	// Create account service
 	accountService := services.NewAccountService(holdingRepository, transactionRepository, assetRepository)
-
-	h, err := handlers.NewHandler(logger, db, escoClient, bcraClient, escoService, syncService, accountService)
+	syncJobService := services.NewSyncJobService(syncJobRepository, syncService, nil, 0, 0)
+	reconciliationService := services.NewReconciliationService(holdingRepository, transactionRepository, assetRepository)
+	importService := services.NewImportService(escoService, syncService)
+	categoryRuleRepository := repositories.NewCategoryRuleRepository(db)
+	categoryResolver := services.NewCategoryRuleResolver(categoryRuleRepository, nil, 0)
+	categoryService := services.NewCategoryService(assetCategoryRepository, categoryRuleRepository, assetRepository, categoryResolver)
+	spreadsheetImportService := services.NewSpreadsheetImportService(db, assetRepository, assetCategoryRepository, holdingRepository, transactionRepository, syncService)
+	manualAssetService := services.NewManualAssetService(db, assetRepository, assetCategoryRepository, holdingRepository, transactionRepository)
+	exchangeRateService := services.NewExchangeRateService(repositories.NewExchangeRateRepository(db))
+	benchmarkService := services.NewBenchmarkService(bcraClient, repositories.NewBenchmarkValueRepository(db))
+	targetAllocationService := services.NewTargetAllocationService(db, repositories.NewTargetAllocationRepository(db))
+	costBasisService := services.NewCostBasisService(holdingRepository, transactionRepository, assetRepository)
+
+	h, err := handlers.NewHandler(cfg, logger, db, escoClient, bcraClient, escoService, syncService, accountService, syncJobService, reconciliationService, importService, categoryService, spreadsheetImportService, manualAssetService, exchangeRateService, benchmarkService, targetAllocationService, costBasisService)
 	if err != nil {
 		log.Println(err, "Error while starting handler")
 		os.Exit(1)
@@ -48,8 +48,13 @@ func (c *ESCOServiceClientMock) PostToken(_ context.Context, _, _ string) (*sche
 	return &tokenResponse, nil
 }
 
+// RefreshToken reads the same saved token response used by PostToken.
+func (c *ESCOServiceClientMock) RefreshToken(ctx context.Context, _ string) (*schemas.TokenResponse, error) {
+	return c.PostToken(ctx, "", "")
+}
+
 // BuscarCuentas reads saved account data from a mock file.
-func (c *ESCOServiceClientMock) BuscarCuentas(_, filter string) ([]esco.CuentaSchema, error) {
+func (c *ESCOServiceClientMock) BuscarCuentas(_ context.Context, _, filter string) ([]esco.CuentaSchema, error) {
 	var cuentas []esco.CuentaSchema
 	err := c.ReadMockResponse("cuentas_response.json", &cuentas)
 	if err != nil {
@@ -62,7 +67,7 @@ func (c *ESCOServiceClientMock) BuscarCuentas(_, filter string) ([]esco.CuentaSc
 }
 
 // GetCuentaDetalle reads detailed account information from a mock file.
-func (c *ESCOServiceClientMock) GetCuentaDetalle(_, cid string) (*esco.CuentaDetalleSchema, error) {
+func (c *ESCOServiceClientMock) GetCuentaDetalle(_ context.Context, _, cid string) (*esco.CuentaDetalleSchema, error) {
 	var cuentaDetalle esco.CuentaDetalleSchema
 	err := c.ReadMockResponse(fmt.Sprintf("cuenta_detalle_%s_response.json", cid), &cuentaDetalle)
 	if err != nil {
@@ -72,7 +77,7 @@ func (c *ESCOServiceClientMock) GetCuentaDetalle(_, cid string) (*esco.CuentaDet
 }
 
 // GetEstadoCuenta reads account status information from a mock file.
-func (c *ESCOServiceClientMock) GetEstadoCuenta(_, cid, _, _, _ string, _ time.Time, _ bool) ([]esco.EstadoCuentaSchema, error) {
+func (c *ESCOServiceClientMock) GetEstadoCuenta(_ context.Context, _, cid, _, _, _ string, _ time.Time, _ bool) ([]esco.EstadoCuentaSchema, error) {
 	var estadoCuenta []esco.EstadoCuentaSchema
 	err := c.ReadMockResponse(fmt.Sprintf("estado_cuenta_%s_date_response.json", cid), &estadoCuenta)
 	if err != nil {
@@ -82,7 +87,7 @@ func (c *ESCOServiceClientMock) GetEstadoCuenta(_, cid, _, _, _ string, _ time.T
 }
 
 // GetLiquidaciones reads account liquidaciones information from a mock file.
-func (c *ESCOServiceClientMock) GetLiquidaciones(token, cid, fid, nncc, tf string, startDate, endDate time.Time, _ bool) ([]esco.Liquidacion, error) {
+func (c *ESCOServiceClientMock) GetLiquidaciones(_ context.Context, token, cid, fid, nncc, tf string, startDate, endDate time.Time, _ bool) ([]esco.Liquidacion, error) {
 	var liquidaciones []esco.Liquidacion
 	err := c.ReadMockResponse("liquidaciones_response.json", &liquidaciones)
 	if err != nil {
@@ -92,7 +97,7 @@ func (c *ESCOServiceClientMock) GetLiquidaciones(token, cid, fid, nncc, tf strin
 }
 
 // GetBoletos reads account boletos information from a mock file.
-func (c *ESCOServiceClientMock) GetBoletos(token, cid, fid, nncc, tf string, startDate, endDate time.Time, _ bool) ([]esco.Boleto, error) {
+func (c *ESCOServiceClientMock) GetBoletos(_ context.Context, token, cid, fid, nncc, tf string, startDate, endDate time.Time, _ bool) ([]esco.Boleto, error) {
 	var boletos []esco.Boleto
 	err := c.ReadMockResponse("boletos_response.json", &boletos)
 	if err != nil {
@@ -102,7 +107,7 @@ func (c *ESCOServiceClientMock) GetBoletos(token, cid, fid, nncc, tf string, sta
 }
 
 // GetCtaCteConsolidado reads account cte corriente information from a mock file.
-func (c *ESCOServiceClientMock) GetCtaCteConsolidado(token, cid, fid, nncc, tf string, startDate, endDate time.Time, _ bool) ([]esco.Instrumentos, error) {
+func (c *ESCOServiceClientMock) GetCtaCteConsolidado(_ context.Context, token, cid, fid, nncc, tf string, startDate, endDate time.Time, _ bool) ([]esco.Instrumentos, error) {
 	var instrumentos []esco.Instrumentos
 	err := c.ReadMockResponse("cte_corriente_response.json", &instrumentos)
 	if err != nil {
@@ -118,7 +118,11 @@ func TruncateTables(t *testing.T, pool *pgxpool.Pool) {
 	}
 
 	tables := []string{
+		"sync_jobs",
+		"sync_runs",
+		"esco_payloads",
 		"sync_logs",
+		"category_rules",
 		"asset_categories",
 		"transactions",
 		"assets",
@@ -144,6 +148,8 @@ func CleanupTestData(t *testing.T, pool *pgxpool.Pool, clientID string) {
 
 	// Delete in reverse order of dependencies to avoid foreign key constraints
 	queries := []string{
+		fmt.Sprintf("DELETE FROM sync_runs WHERE client_id = $1"),
+		fmt.Sprintf("DELETE FROM esco_payloads WHERE account_id = $1"),
 		fmt.Sprintf("DELETE FROM transactions WHERE client_id = $1"),
 		fmt.Sprintf("DELETE FROM holdings WHERE client_id = $1"),
 		fmt.Sprintf("DELETE FROM assets WHERE external_id LIKE $1"),
@@ -177,10 +183,14 @@ func CleanupAllTestData(t *testing.T, pool *pgxpool.Pool) {
 
 	// Delete in reverse order of dependencies
 	tables := []string{
+		"sync_jobs",
+		"sync_runs",
+		"esco_payloads",
 		"sync_logs",
 		"transactions",
 		"holdings",
 		"assets",
+		"category_rules",
 		"asset_categories",
 	}
 
@@ -0,0 +1,64 @@
+package repositories_test
+
+import (
+	"context"
+	"server/src/models"
+	"server/src/repositories"
+	"testing"
+
+	"server/tests/init_test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategoryRuleRepository(t *testing.T) {
+	db := init_test.SetupTestDB(t)
+	init_test.TruncateTables(t, db)
+
+	repo := repositories.NewCategoryRuleRepository(db)
+	categoryRepo := repositories.NewAssetCategoryRepository(db)
+	ctx := context.Background()
+
+	category := &models.AssetCategory{Name: "Test Category"}
+	require.NoError(t, categoryRepo.Create(ctx, category, nil))
+
+	t.Run("Create, Update and Delete", func(t *testing.T) {
+		rule := &models.CategoryRule{CategoryID: category.ID, MatchType: models.CategoryRuleMatchPrefix, Pattern: "BONO"}
+		require.NoError(t, repo.Create(ctx, rule, nil))
+		assert.NotZero(t, rule.ID)
+
+		retrieved, err := repo.GetByID(ctx, rule.ID)
+		require.NoError(t, err)
+		require.NotNil(t, retrieved)
+		assert.Equal(t, "Test Category", retrieved.CategoryName)
+		assert.Equal(t, models.CategoryRuleMatchPrefix, retrieved.MatchType)
+
+		rule.MatchType = models.CategoryRuleMatchRegex
+		rule.Pattern = "^BONO"
+		require.NoError(t, repo.Update(ctx, rule))
+		rules, err := repo.GetAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, rules, 1)
+		assert.Equal(t, "^BONO", rules[0].Pattern)
+
+		require.NoError(t, repo.Delete(ctx, rule.ID))
+		retrieved, err = repo.GetByID(ctx, rule.ID)
+		require.NoError(t, err)
+		assert.Nil(t, retrieved)
+	})
+
+	t.Run("Rules are deleted with their category", func(t *testing.T) {
+		other := &models.AssetCategory{Name: "Category 1"}
+		require.NoError(t, categoryRepo.Create(ctx, other, nil))
+		rule := &models.CategoryRule{CategoryID: other.ID, MatchType: models.CategoryRuleMatchExact, Pattern: "GD30"}
+		require.NoError(t, repo.Create(ctx, rule, nil))
+
+		require.NoError(t, categoryRepo.Delete(ctx, other.ID))
+		retrieved, err := repo.GetByID(ctx, rule.ID)
+		require.NoError(t, err)
+		assert.Nil(t, retrieved)
+	})
+
+	init_test.CleanupAllTestData(t, db)
+}
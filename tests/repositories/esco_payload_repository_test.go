@@ -0,0 +1,52 @@
+package repositories_test
+
+import (
+	"context"
+	"server/src/models"
+	"server/src/repositories"
+	"testing"
+	"time"
+
+	"server/tests/init_test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestESCOPayloadRepository(t *testing.T) {
+	db := init_test.SetupTestDB(t)
+	repo := repositories.NewESCOPayloadRepository(db)
+
+	ctx := context.Background()
+	accountID := "test-client-esco-payload"
+
+	t.Cleanup(func() {
+		_, _ = db.Exec(ctx, "DELETE FROM esco_payloads WHERE account_id = $1", accountID)
+	})
+
+	day := func(d int) *time.Time {
+		date := time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+
+	t.Run("GetLatestByAccount returns the last fetch of every range", func(t *testing.T) {
+		payloads := []*models.ESCOPayload{
+			{Endpoint: "GetEstadoCuenta", AccountID: accountID, StartDate: day(1), EndDate: day(1), Payload: []byte(`[1]`)},
+			{Endpoint: "GetEstadoCuenta", AccountID: accountID, StartDate: day(1), EndDate: day(1), Payload: []byte(`[2]`)},
+			{Endpoint: "GetEstadoCuenta", AccountID: accountID, StartDate: day(2), EndDate: day(2), Payload: []byte(`[3]`)},
+			{Endpoint: "GetBoletos", AccountID: accountID, StartDate: day(1), EndDate: day(5), Payload: []byte(`[4]`)},
+			{Endpoint: "GetEstadoCuenta", AccountID: accountID, StartDate: day(9), EndDate: day(9), Payload: []byte(`[5]`)},
+		}
+		for _, payload := range payloads {
+			require.NoError(t, repo.Create(ctx, payload))
+			assert.NotZero(t, payload.ID)
+			assert.False(t, payload.FetchedAt.IsZero())
+		}
+
+		latest, err := repo.GetLatestByAccount(ctx, accountID, []string{"GetEstadoCuenta"}, *day(1), *day(5))
+		require.NoError(t, err)
+		require.Len(t, latest, 2)
+		assert.Equal(t, []byte(`[3]`), latest[0].Payload)
+		assert.Equal(t, []byte(`[2]`), latest[1].Payload)
+	})
+}
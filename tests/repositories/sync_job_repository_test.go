@@ -0,0 +1,143 @@
+package repositories_test
+
+import (
+	"context"
+	"server/src/models"
+	"server/src/repositories"
+	"testing"
+	"time"
+
+	"server/tests/init_test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncJobRepository(t *testing.T) {
+	db := init_test.SetupTestDB(t)
+	repo := repositories.NewSyncJobRepository(db)
+
+	ctx := context.Background()
+	clientID := "test-client-sync-job"
+
+	t.Cleanup(func() {
+		_, _ = db.Exec(ctx, "DELETE FROM sync_jobs WHERE client_id = $1", clientID)
+	})
+
+	t.Run("Create and GetByID", func(t *testing.T) {
+		job := &models.SyncJob{
+			ClientID:  clientID,
+			StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		}
+		err := repo.Create(ctx, job)
+		require.NoError(t, err)
+		assert.NotZero(t, job.ID)
+
+		stored, err := repo.GetByID(ctx, job.ID)
+		require.NoError(t, err)
+		require.NotNil(t, stored)
+		assert.Equal(t, clientID, stored.ClientID)
+		assert.Equal(t, models.SyncJobPending, stored.Status)
+	})
+
+	t.Run("GetByID for non-existent job", func(t *testing.T) {
+		stored, err := repo.GetByID(ctx, -1)
+		require.NoError(t, err)
+		assert.Nil(t, stored)
+	})
+
+	t.Run("ClaimNextPending, UpdateProgress and MarkCompleted", func(t *testing.T) {
+		job := &models.SyncJob{
+			ClientID:  clientID,
+			StartDate: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC),
+		}
+		require.NoError(t, repo.Create(ctx, job))
+
+		// Drain older pending jobs until ours is claimed
+		var claimed *models.SyncJob
+		for {
+			next, err := repo.ClaimNextPending(ctx)
+			require.NoError(t, err)
+			require.NotNil(t, next)
+			if next.ID == job.ID {
+				claimed = next
+				break
+			}
+		}
+		assert.Equal(t, models.SyncJobRunning, claimed.Status)
+		assert.NotNil(t, claimed.StartedAt)
+
+		require.NoError(t, repo.UpdateProgress(ctx, job.ID, 4, 2))
+		require.NoError(t, repo.MarkCompleted(ctx, job.ID))
+
+		stored, err := repo.GetByID(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.SyncJobCompleted, stored.Status)
+		assert.Equal(t, 4, stored.TotalDates)
+		assert.Equal(t, 2, stored.ProcessedDates)
+		assert.NotNil(t, stored.FinishedAt)
+	})
+
+	t.Run("MarkFailed stores the error", func(t *testing.T) {
+		job := &models.SyncJob{
+			ClientID:  clientID,
+			StartDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+		}
+		require.NoError(t, repo.Create(ctx, job))
+		require.NoError(t, repo.MarkFailed(ctx, job.ID, "esco unavailable"))
+
+		stored, err := repo.GetByID(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.SyncJobFailed, stored.Status)
+		require.NotNil(t, stored.Error)
+		assert.Equal(t, "esco unavailable", *stored.Error)
+	})
+
+	t.Run("RequeueRunning moves stale running jobs back to pending", func(t *testing.T) {
+		job := &models.SyncJob{
+			ClientID:  clientID,
+			StartDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC),
+			Status:    models.SyncJobRunning,
+		}
+		require.NoError(t, repo.Create(ctx, job))
+
+		requeued, err := repo.RequeueRunning(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, requeued, int64(1))
+
+		stored, err := repo.GetByID(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.SyncJobPending, stored.Status)
+		assert.Nil(t, stored.StartedAt)
+	})
+
+	t.Run("RequeueRunning leaves jobs with a recent heartbeat running", func(t *testing.T) {
+		job := &models.SyncJob{
+			ClientID:  clientID,
+			StartDate: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+		}
+		require.NoError(t, repo.Create(ctx, job))
+		for {
+			next, err := repo.ClaimNextPending(ctx)
+			require.NoError(t, err)
+			require.NotNil(t, next)
+			if next.ID == job.ID {
+				break
+			}
+		}
+		require.NoError(t, repo.UpdateProgress(ctx, job.ID, 1, 0))
+
+		_, err := repo.RequeueRunning(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+
+		stored, err := repo.GetByID(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.SyncJobRunning, stored.Status)
+		assert.NotNil(t, stored.StartedAt)
+	})
+}
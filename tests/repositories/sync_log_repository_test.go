@@ -2,6 +2,7 @@ package repositories_test
 
 import (
 	"context"
+	"server/src/models"
 	"server/src/repositories"
 	"testing"
 	"time"
@@ -36,7 +37,7 @@ func TestMarkClientForDate(t *testing.T) {
 	syncDate := time.Now()
 
 	// Test Insert
-	err := repo.MarkClientForDate(ctx, clientID, syncDate)
+	err := repo.MarkClientForDate(ctx, clientID, models.SourceESCO, syncDate)
 	require.NoError(t, err)
 
 	// Test GetLastSyncDate
@@ -65,7 +66,7 @@ func TestGetLastSyncDate(t *testing.T) {
 			time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
 		}
 
-		err := repo.MarkClientForDates(ctx, clientID, dates)
+		err := repo.MarkClientForDates(ctx, clientID, models.SourceESCO, dates, nil)
 		require.NoError(t, err)
 
 		lastSyncDate, err := repo.GetLastSyncDate(ctx, clientID)
@@ -88,7 +89,7 @@ func TestMarkClientForDates(t *testing.T) {
 			time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC),
 		}
 
-		err := repo.MarkClientForDates(ctx, clientID, dates)
+		err := repo.MarkClientForDates(ctx, clientID, models.SourceESCO, dates, nil)
 		require.NoError(t, err)
 
 		// Verify all dates were inserted
@@ -108,7 +109,7 @@ func TestMarkClientForDates(t *testing.T) {
 		clientID := "test-client-4"
 		dates := []time.Time{}
 
-		err := repo.MarkClientForDates(ctx, clientID, dates)
+		err := repo.MarkClientForDates(ctx, clientID, models.SourceESCO, dates, nil)
 		require.NoError(t, err)
 
 		// Verify no records were inserted
@@ -127,7 +128,7 @@ func TestMarkClientForDates(t *testing.T) {
 		date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
 		dates := []time.Time{date, date, date}
 
-		err := repo.MarkClientForDates(ctx, clientID, dates)
+		err := repo.MarkClientForDates(ctx, clientID, models.SourceESCO, dates, nil)
 		require.NoError(t, err)
 
 		// Verify only one record was inserted
@@ -150,11 +151,11 @@ func TestMarkClientForDates(t *testing.T) {
 		}
 
 		// Insert for first client
-		err := repo.MarkClientForDates(ctx, clientID1, dates)
+		err := repo.MarkClientForDates(ctx, clientID1, models.SourceESCO, dates, nil)
 		require.NoError(t, err)
 
 		// Insert for second client
-		err = repo.MarkClientForDates(ctx, clientID2, dates)
+		err = repo.MarkClientForDates(ctx, clientID2, models.SourceESCO, dates, nil)
 		require.NoError(t, err)
 
 		// Verify both clients have their records
@@ -186,23 +187,23 @@ func TestGetSyncedDates(t *testing.T) {
 		time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
 	}
 
-	err := repo.MarkClientForDates(ctx, clientID, dates)
+	err := repo.MarkClientForDates(ctx, clientID, models.SourceESCO, dates, nil)
 	require.NoError(t, err)
 
 	t.Run("returns all dates in range", func(t *testing.T) {
-		syncedDates, err := repo.GetSyncedDates(ctx, clientID, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC))
+		syncedDates, err := repo.GetSyncedDates(ctx, clientID, models.SourceESCO, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC))
 		require.NoError(t, err)
 		assert.Equal(t, dates, syncedDates)
 	})
 
 	t.Run("returns partial range", func(t *testing.T) {
-		syncedDates, err := repo.GetSyncedDates(ctx, clientID, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC))
+		syncedDates, err := repo.GetSyncedDates(ctx, clientID, models.SourceESCO, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC))
 		require.NoError(t, err)
 		assert.Equal(t, dates[1:3], syncedDates)
 	})
 
 	t.Run("returns empty slice for no matches", func(t *testing.T) {
-		syncedDates, err := repo.GetSyncedDates(ctx, clientID, time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC))
+		syncedDates, err := repo.GetSyncedDates(ctx, clientID, models.SourceESCO, time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 7, 0, 0, 0, 0, time.UTC))
 		require.NoError(t, err)
 		assert.Empty(t, syncedDates)
 	})
@@ -215,10 +216,10 @@ func TestGetSyncedDates(t *testing.T) {
 			time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
 		}
 
-		err := repo.MarkClientForDates(ctx, clientID, duplicateDates)
+		err := repo.MarkClientForDates(ctx, clientID, models.SourceESCO, duplicateDates, nil)
 		require.NoError(t, err)
 
-		syncedDates, err := repo.GetSyncedDates(ctx, clientID, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC))
+		syncedDates, err := repo.GetSyncedDates(ctx, clientID, models.SourceESCO, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC))
 		require.NoError(t, err)
 		assert.Equal(t, []time.Time{
 			time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
@@ -227,7 +228,7 @@ func TestGetSyncedDates(t *testing.T) {
 	})
 
 	t.Run("handles non-existent client", func(t *testing.T) {
-		syncedDates, err := repo.GetSyncedDates(ctx, "non-existent-client", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC))
+		syncedDates, err := repo.GetSyncedDates(ctx, "non-existent-client", models.SourceESCO, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC))
 		require.NoError(t, err)
 		assert.Empty(t, syncedDates)
 	})
@@ -251,12 +252,12 @@ func TestCleanupSyncLogs(t *testing.T) {
 		}
 
 		// Insert test data
-		err := repo.MarkClientForDates(ctx, clientID, dates)
+		err := repo.MarkClientForDates(ctx, clientID, models.SourceESCO, dates, nil)
 		require.NoError(t, err)
 
 		// Clean up logs before March 3rd
 		cleanupDate := time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)
-		err = repo.CleanupSyncLogs(ctx, clientID, cleanupDate, cleanupDate)
+		err = repo.CleanupSyncLogs(ctx, clientID, models.SourceESCO, cleanupDate, cleanupDate, nil)
 		require.NoError(t, err)
 
 		// Verify only logs from March 3rd and later remain
@@ -270,7 +271,7 @@ func TestCleanupSyncLogs(t *testing.T) {
 		assert.Equal(t, 3, count, "Expected 3 records to remain")
 
 		// Verify specific dates remain
-		remainingDates, err := repo.GetSyncedDates(ctx, clientID, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC))
+		remainingDates, err := repo.GetSyncedDates(ctx, clientID, models.SourceESCO, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC))
 		require.NoError(t, err)
 		assert.Equal(t, []time.Time{
 			time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
@@ -281,7 +282,7 @@ func TestCleanupSyncLogs(t *testing.T) {
 
 	t.Run("handles non-existent client", func(t *testing.T) {
 		nonExistentClientID := "non-existent-cleanup"
-		err := repo.CleanupSyncLogs(ctx, nonExistentClientID, time.Now(), time.Time{})
+		err := repo.CleanupSyncLogs(ctx, nonExistentClientID, models.SourceESCO, time.Now(), time.Time{}, nil)
 		require.NoError(t, err)
 	})
 
@@ -293,11 +294,11 @@ func TestCleanupSyncLogs(t *testing.T) {
 		}
 
 		// Insert test data
-		err := repo.MarkClientForDates(ctx, clientID, dates)
+		err := repo.MarkClientForDates(ctx, clientID, models.SourceESCO, dates, nil)
 		require.NoError(t, err)
 
 		// Clean up with empty date range
-		err = repo.CleanupSyncLogs(ctx, clientID, time.Time{}, time.Time{})
+		err = repo.CleanupSyncLogs(ctx, clientID, models.SourceESCO, time.Time{}, time.Time{}, nil)
 		require.NoError(t, err)
 
 		// Verify all records remain
@@ -320,14 +321,14 @@ func TestCleanupSyncLogs(t *testing.T) {
 		}
 
 		// Insert test data for both clients
-		err := repo.MarkClientForDates(ctx, clientID1, dates)
+		err := repo.MarkClientForDates(ctx, clientID1, models.SourceESCO, dates, nil)
 		require.NoError(t, err)
-		err = repo.MarkClientForDates(ctx, clientID2, dates)
+		err = repo.MarkClientForDates(ctx, clientID2, models.SourceESCO, dates, nil)
 		require.NoError(t, err)
 
 		// Clean up logs for first client
 		cleanupDate := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
-		err = repo.CleanupSyncLogs(ctx, clientID1, cleanupDate, cleanupDate)
+		err = repo.CleanupSyncLogs(ctx, clientID1, models.SourceESCO, cleanupDate, cleanupDate, nil)
 		require.NoError(t, err)
 
 		// Verify first client's logs are cleaned up
@@ -351,3 +352,54 @@ func TestCleanupSyncLogs(t *testing.T) {
 		assert.Equal(t, 2, count2, "Expected all records to remain for second client")
 	})
 }
+
+func TestGetClientIDs(t *testing.T) {
+	_, repo := setupTest(t)
+
+	ctx := context.Background()
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	clientIDs := []string{"test-client-ids-1", "test-client-ids-2"}
+
+	for _, clientID := range clientIDs {
+		require.NoError(t, repo.MarkClientForDate(ctx, clientID, models.SourceESCO, date))
+	}
+	t.Cleanup(func() {
+		for _, clientID := range clientIDs {
+			_ = repo.CleanupSyncLogs(ctx, clientID, models.SourceESCO, date, date, nil)
+		}
+	})
+
+	storedClientIDs, err := repo.GetClientIDs(ctx)
+	require.NoError(t, err)
+	for _, clientID := range clientIDs {
+		assert.Contains(t, storedClientIDs, clientID)
+	}
+}
+
+func TestSyncLogsPerSource(t *testing.T) {
+	_, repo := setupTest(t)
+
+	ctx := context.Background()
+	clientID := "test-client-sources"
+	dates := []time.Time{
+		time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, repo.MarkClientForDates(ctx, clientID, models.SourceESCO, dates, nil))
+	require.NoError(t, repo.MarkClientForDates(ctx, clientID, "other", dates[:1], nil))
+	t.Cleanup(func() {
+		_ = repo.CleanupSyncLogs(ctx, clientID, models.SourceESCO, dates[0], dates[1], nil)
+		_ = repo.CleanupSyncLogs(ctx, clientID, "other", dates[0], dates[1], nil)
+	})
+
+	// Cleaning up a source keeps the dates synced from the others
+	require.NoError(t, repo.CleanupSyncLogs(ctx, clientID, models.SourceESCO, dates[0], dates[1], nil))
+
+	syncedDates, err := repo.GetSyncedDates(ctx, clientID, models.SourceESCO, dates[0], dates[1].AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Empty(t, syncedDates)
+
+	syncedDates, err = repo.GetSyncedDates(ctx, clientID, "other", dates[0], dates[1].AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Len(t, syncedDates, 1)
+}
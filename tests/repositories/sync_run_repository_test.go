@@ -0,0 +1,84 @@
+package repositories_test
+
+import (
+	"context"
+	"server/src/models"
+	"server/src/repositories"
+	"testing"
+	"time"
+
+	"server/tests/init_test"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncRunRepository(t *testing.T) {
+	db := init_test.SetupTestDB(t)
+	repo := repositories.NewSyncRunRepository(db)
+
+	ctx := context.Background()
+	clientID := "test-client-sync-run"
+
+	t.Cleanup(func() {
+		_, _ = db.Exec(ctx, "DELETE FROM sync_runs WHERE client_id = $1", clientID)
+	})
+
+	t.Run("Create, Finish and GetByClientID", func(t *testing.T) {
+		run := &models.SyncRun{
+			ClientID:    clientID,
+			StartDate:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:     time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+			TriggeredBy: "test",
+		}
+		err := repo.Create(ctx, run)
+		require.NoError(t, err)
+		assert.NotZero(t, run.ID)
+		assert.Equal(t, models.SyncRunRunning, run.Status)
+
+		run.Status = models.SyncRunCompleted
+		run.DatesSynced = 9
+		run.HoldingsCount = 18
+		run.TransactionsCount = 3
+		require.NoError(t, repo.Finish(ctx, run))
+		assert.NotNil(t, run.FinishedAt)
+
+		runs, err := repo.GetByClientID(ctx, clientID, 10)
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		assert.Equal(t, models.SyncRunCompleted, runs[0].Status)
+		assert.Equal(t, "test", runs[0].TriggeredBy)
+		assert.Equal(t, 9, runs[0].DatesSynced)
+		assert.Equal(t, 18, runs[0].HoldingsCount)
+		assert.Equal(t, 3, runs[0].TransactionsCount)
+		assert.Nil(t, runs[0].Error)
+		assert.NotNil(t, runs[0].FinishedAt)
+	})
+
+	t.Run("stores the error of failed runs and returns the latest first", func(t *testing.T) {
+		run := &models.SyncRun{
+			ClientID:    clientID,
+			StartDate:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:     time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC),
+			TriggeredBy: "test",
+		}
+		require.NoError(t, repo.Create(ctx, run))
+
+		errMessage := "esco unavailable"
+		run.Status = models.SyncRunFailed
+		run.Error = &errMessage
+		require.NoError(t, repo.Finish(ctx, run))
+
+		runs, err := repo.GetByClientID(ctx, clientID, 10)
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		assert.Equal(t, run.ID, runs[0].ID)
+		assert.Equal(t, models.SyncRunFailed, runs[0].Status)
+		require.NotNil(t, runs[0].Error)
+		assert.Equal(t, errMessage, *runs[0].Error)
+
+		runs, err = repo.GetByClientID(ctx, clientID, 1)
+		require.NoError(t, err)
+		assert.Len(t, runs, 1)
+	})
+}
@@ -53,6 +53,9 @@ func TestTransactionRepository(t *testing.T) {
 			TransactionType: "BUY",
 			Units:           10,
 			PricePerUnit:    100.0,
+			GrossValue:      995.0,
+			ExchangeFees:    3.0,
+			MarketFees:      2.0,
 			TotalValue:      1000.0,
 			Date:            time.Now(),
 		}
@@ -95,9 +98,63 @@ func TestTransactionRepository(t *testing.T) {
 		assert.Equal(t, transaction.TransactionType, transactions[0].TransactionType)
 		assert.Equal(t, transaction.Units, transactions[0].Units)
 		assert.Equal(t, transaction.PricePerUnit, transactions[0].PricePerUnit)
+		assert.Equal(t, transaction.GrossValue, transactions[0].GrossValue)
+		assert.Equal(t, transaction.ExchangeFees, transactions[0].ExchangeFees)
+		assert.Equal(t, transaction.MarketFees, transactions[0].MarketFees)
 		assert.Equal(t, transaction.TotalValue, transactions[0].TotalValue)
 	})
 
+	t.Run("Filter and group by transaction type", func(t *testing.T) {
+		ctx := context.Background()
+		clientID := "test-client-1"
+		date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		category := &models.AssetCategory{
+			Name:        "Test Type Category",
+			Description: "Test Description",
+		}
+		require.NoError(t, categoryRepo.Create(ctx, category, nil))
+		asset := &models.Asset{
+			ExternalID: "EXT-TYPE-001",
+			Name:       "Test Type Asset",
+			AssetType:  "BOND",
+			CategoryID: category.ID,
+			Currency:   "ARS",
+		}
+		require.NoError(t, assetRepo.Create(ctx, asset, nil))
+
+		for transactionType, value := range map[string]float64{
+			models.TransactionTypeBuy:    -1000,
+			models.TransactionTypeCoupon: 50,
+		} {
+			require.NoError(t, repo.Create(ctx, &models.Transaction{
+				ClientID:        clientID,
+				AssetID:         asset.ID,
+				TransactionType: transactionType,
+				Units:           10,
+				TotalValue:      value,
+				Date:            date,
+			}, nil))
+		}
+
+		transactions, err := repo.GetByClientIDs(ctx, []string{clientID}, date, date, []string{models.TransactionTypeCoupon})
+		require.NoError(t, err)
+		require.Len(t, transactions, 1)
+		assert.Equal(t, models.TransactionTypeCoupon, transactions[0].TransactionType)
+
+		transactions, err = repo.GetByClientIDs(ctx, []string{clientID}, date, date, nil)
+		require.NoError(t, err)
+		assert.Len(t, transactions, 2)
+
+		byType, err := repo.GetGroupedByTypeAndDate(ctx, []string{clientID}, date, date, nil)
+		require.NoError(t, err)
+		assert.Equal(t, -1000.0, byType[models.TransactionTypeBuy]["2024-03-01"])
+		assert.Equal(t, 50.0, byType[models.TransactionTypeCoupon]["2024-03-01"])
+
+		totals, err := repo.GetTotalByDate(ctx, []string{clientID}, date, date, []string{models.TransactionTypeBuy})
+		require.NoError(t, err)
+		assert.Equal(t, -1000.0, totals["2024-03-01"])
+	})
+
 	t.Run("GetByClientID for non-existent client", func(t *testing.T) {
 		ctx := context.Background()
 		nonExistentClientID := "non-existent-client"
@@ -145,7 +145,7 @@ func TestAccountService(t *testing.T) {
 		// Test GetMultiAccountStateWithTransactions
 		startDate := time.Now().AddDate(0, 0, -1)
 		endDate := time.Now().AddDate(0, 0, 1)
-		accountStates, err := accountService.GetMultiAccountStateWithTransactions(ctx, clientIDs, startDate, endDate, time.Hour*24)
+		accountStates, err := accountService.GetMultiAccountStateWithTransactions(ctx, clientIDs, startDate, endDate, time.Hour*24, nil)
 		require.NoError(t, err)
 		assert.Len(t, accountStates, 2)
 
@@ -194,7 +194,7 @@ func TestAccountService(t *testing.T) {
 		// Test GetMultiAccountStateByCategory
 		startDate := time.Now().AddDate(0, 0, -1)
 		endDate := time.Now().AddDate(0, 0, 1)
-		accountStateByCategory, err := accountService.GetMultiAccountStateByCategory(ctx, clientIDs, startDate, endDate, time.Hour*24)
+		accountStateByCategory, err := accountService.GetMultiAccountStateByCategory(ctx, clientIDs, startDate, endDate, time.Hour*24, nil, nil)
 		require.NoError(t, err)
 		assert.NotNil(t, accountStateByCategory)
 		assert.NotNil(t, accountStateByCategory.AssetsByCategory)
@@ -264,7 +264,7 @@ func TestAccountService(t *testing.T) {
 		require.NoError(t, err)
 		assert.Len(t, holdings, 2)
 
-		transactions, err := transactionRepo.GetByClientIDs(ctx, clientIDs, startDate, endDate)
+		transactions, err := transactionRepo.GetByClientIDs(ctx, clientIDs, startDate, endDate, nil)
 		require.NoError(t, err)
 		assert.Len(t, transactions, 2)
 
@@ -273,7 +273,7 @@ func TestAccountService(t *testing.T) {
 		require.NoError(t, err)
 		assert.Contains(t, categoryHoldings, category.Name)
 
-		categoryTransactions, err := transactionRepo.GetGroupedByCategoryAndDate(ctx, clientIDs, startDate, endDate)
+		categoryTransactions, err := transactionRepo.GetGroupedByCategoryAndDate(ctx, clientIDs, startDate, endDate, nil)
 		require.NoError(t, err)
 		assert.Contains(t, categoryTransactions, category.Name)
 
@@ -282,8 +282,41 @@ func TestAccountService(t *testing.T) {
 		require.NoError(t, err)
 		assert.NotEmpty(t, totalHoldings)
 
-		totalTransactions, err := transactionRepo.GetTotalByDate(ctx, clientIDs, startDate, endDate)
+		totalTransactions, err := transactionRepo.GetTotalByDate(ctx, clientIDs, startDate, endDate, nil)
 		require.NoError(t, err)
 		assert.NotEmpty(t, totalTransactions)
 	})
 }
+
+func TestAccountStateByCategoryTransactionTypes(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	holdingRepo := &fakeHoldingRepository{holdings: []models.Holding{
+		{ClientID: "test-client", Source: models.SourceESCO, AssetID: 1, Units: 10, Value: 1000, Date: day(2)},
+		{ClientID: "test-client", Source: models.SourceESCO, AssetID: 1, Units: 12, Value: 1250, Date: day(3)},
+	}}
+	transactionRepo := &fakeTransactionRepository{transactions: []models.Transaction{
+		{ClientID: "test-client", Source: models.SourceESCO, AssetID: 1, TransactionType: models.TransactionTypeBuy, Units: 2, TotalValue: 200, Date: day(3)},
+		{ClientID: "test-client", Source: models.SourceESCO, AssetID: 1, TransactionType: models.TransactionTypeDividend, TotalValue: -10, Date: day(3)},
+	}}
+	assetRepo := &fakeCategorizedAssetRepository{
+		fakeAssetRepository: fakeAssetRepository{assets: []models.Asset{
+			{ID: 1, ExternalID: "GGAL", Name: "Grupo Galicia", Currency: "Pesos"},
+		}},
+		categories: map[string]string{"GGAL": "Acciones"},
+	}
+	service := services.NewAccountService(holdingRepo, transactionRepo, assetRepo)
+	converter, err := services.NewCurrencyConverter(services.ReportCurrencyARS, nil)
+	require.NoError(t, err)
+
+	state, err := service.GetMultiAccountStateByCategory(ctx, []string{"test-client"}, day(2), day(3), 24*time.Hour, []string{models.TransactionTypeDividend}, converter)
+	require.NoError(t, err)
+
+	assert.Len(t, *state.TransactionsByType, 1, "only the requested types are grouped")
+	assert.Contains(t, *state.TransactionsByType, models.TransactionTypeDividend)
+	categoryTransactions := (*state.CategoryAssets)["Acciones"].Transactions
+	require.Len(t, categoryTransactions, 1)
+	assert.InDelta(t, 190.0, categoryTransactions[0].Value, 0.0001, "returns are calculated from every transaction")
+	assert.InDelta(t, 190.0, (*state.TotalTransactionsByDate)["2024-01-03"].Value, 0.0001)
+}
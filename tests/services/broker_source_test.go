@@ -0,0 +1,78 @@
+package services_test
+
+import (
+	"context"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/schemas"
+	"server/src/services"
+	"server/src/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBrokerSource records the accounts it is asked for and returns no data
+type fakeBrokerSource struct {
+	name      string
+	requested []string
+}
+
+func (s *fakeBrokerSource) Name() string {
+	return s.name
+}
+
+func (s *fakeBrokerSource) FetchAccountState(_ context.Context, _, accountID string, _, _ time.Time) (*schemas.AccountState, error) {
+	s.requested = append(s.requested, accountID)
+	return nil, nil
+}
+
+// sourceSyncLogRepository serves the synced dates of each source
+type sourceSyncLogRepository struct {
+	repositories.SyncLogRepository
+	syncedDates map[string][]time.Time
+}
+
+func (r *sourceSyncLogRepository) GetSyncedDates(_ context.Context, _, source string, _, _ time.Time) ([]time.Time, error) {
+	return r.syncedDates[source], nil
+}
+
+func TestSyncServiceBrokerSources(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	esco := &fakeBrokerSource{name: models.SourceESCO}
+	other := &fakeBrokerSource{name: "other"}
+	syncLogRepo := &sourceSyncLogRepository{syncedDates: map[string][]time.Time{
+		models.SourceESCO: {day(1), day(2)},
+		"other":           {day(1)},
+	}}
+	service := services.NewSyncService(nil, nil, nil, nil, nil, syncLogRepo, &fakeSyncRunRepository{}, esco, other)
+
+	assert.True(t, service.HasSource("other"))
+	assert.False(t, service.HasSource("unknown"))
+
+	t.Run("Dates are synced per source", func(t *testing.T) {
+		dates, err := service.GetDatesToSync(context.Background(), "token", "12345", day(1), day(4))
+		require.NoError(t, err)
+		assert.Equal(t, []time.Time{day(3)}, dates, "the first source is the default one")
+
+		dates, err = service.GetDatesToSync(utils.WithSyncSource(context.Background(), "other"), "token", "12345", day(1), day(4))
+		require.NoError(t, err)
+		assert.Equal(t, []time.Time{day(2), day(3)}, dates)
+	})
+
+	t.Run("Syncs fetch from the selected source", func(t *testing.T) {
+		require.NoError(t, service.ForceSyncDataFromAccount(context.Background(), "token", "12345", day(1), day(4)))
+		require.NoError(t, service.ForceSyncDataFromAccount(utils.WithSyncSource(context.Background(), "other"), "token", "67890", day(1), day(4)))
+		assert.Equal(t, []string{"12345"}, esco.requested)
+		assert.Equal(t, []string{"67890"}, other.requested)
+	})
+
+	t.Run("Unknown sources are rejected", func(t *testing.T) {
+		err := service.ForceSyncDataFromAccount(utils.WithSyncSource(context.Background(), "unknown"), "token", "12345", day(1), day(4))
+		var httpErr *utils.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, 400, httpErr.Code)
+	})
+}
@@ -0,0 +1,65 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCategoryRuleRepository struct {
+	repositories.CategoryRuleRepository
+	rules []*models.CategoryRule
+	err   error
+	loads int
+}
+
+func (r *fakeCategoryRuleRepository) GetAll(_ context.Context) ([]*models.CategoryRule, error) {
+	r.loads++
+	return r.rules, r.err
+}
+
+func TestCategoryRuleResolver(t *testing.T) {
+	repo := &fakeCategoryRuleRepository{rules: []*models.CategoryRule{
+		{CategoryName: "Bonos Soberanos", MatchType: models.CategoryRuleMatchPrefix, Pattern: "BONO"},
+		{CategoryName: "Bonos CER", MatchType: models.CategoryRuleMatchPrefix, Pattern: "BONO CER"},
+		{CategoryName: "Acciones", MatchType: models.CategoryRuleMatchRegex, Pattern: `^ACC\.? `},
+		{CategoryName: "Cedears", MatchType: models.CategoryRuleMatchRegex, Pattern: `CEDEAR`},
+		{CategoryName: "Letras", MatchType: models.CategoryRuleMatchExact, Pattern: "BONO LECAP S31E5"},
+	}}
+	resolver := services.NewCategoryRuleResolver(repo, services.MapCategoryResolver{"GD30": "Fallback"}, time.Hour)
+
+	assert.Equal(t, "Letras", resolver.Resolve("BONO LECAP S31E5"), "exact rules win over prefixes")
+	assert.Equal(t, "Bonos CER", resolver.Resolve("BONO CER TX26"), "the longest prefix wins")
+	assert.Equal(t, "Bonos Soberanos", resolver.Resolve("BONO GD30"))
+	assert.Equal(t, "Acciones", resolver.Resolve("ACC. GGAL"))
+	assert.Equal(t, "Cedears", resolver.Resolve("CEDEAR AAPL"))
+	assert.Equal(t, models.UnclassifiedCategory, resolver.Resolve("GD30"), "the fallback is not used while rules are stored")
+	assert.Equal(t, 1, repo.loads, "rules are cached until the ttl passes")
+
+	t.Run("Reload picks up new rules", func(t *testing.T) {
+		repo.rules = append(repo.rules, &models.CategoryRule{CategoryName: "Dolar Linked", MatchType: models.CategoryRuleMatchExact, Pattern: "GD30"})
+		require.NoError(t, resolver.Reload(context.Background()))
+		assert.Equal(t, "Dolar Linked", resolver.Resolve("GD30"))
+	})
+
+	t.Run("Falls back without stored rules", func(t *testing.T) {
+		resolver := services.NewCategoryRuleResolver(&fakeCategoryRuleRepository{}, services.MapCategoryResolver{"GD30": "Fallback"}, time.Hour)
+		assert.Equal(t, "Fallback", resolver.Resolve("GD30"))
+		assert.Equal(t, models.UnclassifiedCategory, resolver.Resolve("AL30"))
+	})
+
+	t.Run("Falls back when the rules cannot be loaded", func(t *testing.T) {
+		repo := &fakeCategoryRuleRepository{err: errors.New("connection refused")}
+		resolver := services.NewCategoryRuleResolver(repo, services.MapCategoryResolver{"GD30": "Fallback"}, time.Hour)
+		assert.Equal(t, "Fallback", resolver.Resolve("GD30"))
+		assert.Equal(t, "Fallback", resolver.Resolve("GD30"))
+		assert.Equal(t, 1, repo.loads, "a failed load is not retried before the ttl passes")
+	})
+}
@@ -0,0 +1,122 @@
+package services_test
+
+import (
+	"context"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeLatestHoldingRepository struct {
+	repositories.HoldingRepository
+	holdings []models.Holding
+}
+
+func (r *fakeLatestHoldingRepository) GetLatestByClientIDs(_ context.Context, _ []string, _ time.Time) ([]models.Holding, error) {
+	return r.holdings, nil
+}
+
+type fakeClientsTransactionRepository struct {
+	repositories.TransactionRepository
+	transactions []models.Transaction
+}
+
+func (r *fakeClientsTransactionRepository) GetByClientIDs(_ context.Context, _ []string, _, _ time.Time, _ []string) ([]models.Transaction, error) {
+	// Copied, as the service sorts them
+	return append([]models.Transaction(nil), r.transactions...), nil
+}
+
+func TestGetPnL(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	// Sales are stored with negative units and value, and returned newest first
+	transactionRepo := &fakeClientsTransactionRepository{transactions: []models.Transaction{
+		{ID: 3, ClientID: "test-client", AssetID: 1, TransactionType: models.TransactionTypeSell, Units: -15, TotalValue: -2250, Date: day(3)},
+		// Sells more units than were bought, the ones without cost realize nothing
+		{ID: 5, ClientID: "test-client", AssetID: 2, TransactionType: models.TransactionTypeSell, Units: -8, TotalValue: -1000, Date: day(2)},
+		{ID: 2, ClientID: "test-client", AssetID: 1, TransactionType: models.TransactionTypeBuy, Units: 10, TotalValue: 1200, Date: day(2)},
+		{ID: 4, ClientID: "test-client", AssetID: 2, TransactionType: models.TransactionTypeBuy, Units: 5, TotalValue: 500, Date: day(1)},
+		{ID: 1, ClientID: "test-client", AssetID: 1, TransactionType: models.TransactionTypeBuy, Units: 10, TotalValue: 1000, Date: day(1)},
+	}}
+	holdingRepo := &fakeLatestHoldingRepository{holdings: []models.Holding{
+		{ClientID: "test-client", AssetID: 1, Units: 5, Value: 800, Date: day(4)},
+	}}
+	assetRepo := &fakeAssetRepository{assets: []models.Asset{
+		{ID: 1, ExternalID: "GGAL", Name: "Grupo Galicia", Currency: "Pesos"},
+		{ID: 2, ExternalID: "AL30", Name: "Bono AL30", Currency: "USD"},
+	}}
+	service := services.NewCostBasisService(holdingRepo, transactionRepo, assetRepo)
+
+	t.Run("sells the oldest lots first with FIFO", func(t *testing.T) {
+		report, err := service.GetPnL(ctx, []string{"test-client"}, day(4), services.CostBasisMethodFIFO)
+		require.NoError(t, err)
+		require.Len(t, report.Assets, 2)
+
+		bond, stock := report.Assets[0], report.Assets[1]
+		assert.Equal(t, "GGAL", stock.ExternalID)
+		assert.Equal(t, 5.0, stock.Units)
+		require.Len(t, stock.OpenLots, 1)
+		assert.True(t, stock.OpenLots[0].Date.Equal(day(2)))
+		assert.InDelta(t, 600, stock.OpenLots[0].Cost, 0.0001)
+		assert.InDelta(t, 600, stock.CostBasis, 0.0001)
+		assert.InDelta(t, 120, stock.AverageCost, 0.0001)
+		require.Len(t, stock.Sales, 1)
+		assert.InDelta(t, 1600, stock.Sales[0].Cost, 0.0001)
+		assert.InDelta(t, 650, stock.RealizedPnL, 0.0001)
+		assert.InDelta(t, 800, stock.MarketValue, 0.0001)
+		assert.InDelta(t, 200, stock.UnrealizedPnL, 0.0001)
+		assert.InDelta(t, 100.0/3, stock.UnrealizedPnLPercentage, 0.0001)
+
+		assert.Equal(t, "AL30", bond.ExternalID)
+		assert.Zero(t, bond.Units)
+		assert.Empty(t, bond.OpenLots)
+		require.Len(t, bond.Sales, 1)
+		assert.Equal(t, 3.0, bond.Sales[0].UnmatchedUnits)
+		assert.InDelta(t, 1000.0*5/8-500, bond.RealizedPnL, 0.0001)
+		assert.Nil(t, bond.ValuationDate)
+
+		require.Len(t, report.Totals, 2)
+		assert.Equal(t, "Pesos", report.Totals[0].Currency)
+		assert.InDelta(t, 650, report.Totals[0].RealizedPnL, 0.0001)
+	})
+
+	t.Run("sells at the average cost with the weighted-average method", func(t *testing.T) {
+		report, err := service.GetPnL(ctx, []string{"test-client"}, day(4), services.CostBasisMethodAverage)
+		require.NoError(t, err)
+
+		stock := report.Assets[1]
+		assert.InDelta(t, 1650, stock.Sales[0].Cost, 0.0001)
+		assert.InDelta(t, 600, stock.RealizedPnL, 0.0001)
+		assert.InDelta(t, 550, stock.CostBasis, 0.0001)
+		assert.InDelta(t, 110, stock.AverageCost, 0.0001)
+		assert.InDelta(t, 250, stock.UnrealizedPnL, 0.0001)
+	})
+
+	t.Run("rejects unknown methods", func(t *testing.T) {
+		_, err := service.GetPnL(ctx, []string{"test-client"}, day(4), "lifo")
+		assert.Error(t, err)
+	})
+
+	t.Run("writes the positions and sales into XLSX sheets", func(t *testing.T) {
+		report, err := service.GetPnL(ctx, []string{"test-client"}, day(4), services.CostBasisMethodFIFO)
+		require.NoError(t, err)
+
+		file, err := service.GenerateXLSXPnL(ctx, report)
+		require.NoError(t, err)
+		rows, err := file.GetRows("Resultados")
+		require.NoError(t, err)
+		// Headers, the assets, a blank row and the totals of both currencies
+		require.Len(t, rows, 6)
+		assert.Equal(t, "GGAL", rows[2][1])
+		assert.Equal(t, "TOTAL", rows[4][0])
+		sales, err := file.GetRows("Ventas")
+		require.NoError(t, err)
+		require.Len(t, sales, 3)
+	})
+}
@@ -0,0 +1,122 @@
+package services_test
+
+import (
+	"context"
+	"server/src/models"
+	"server/src/schemas"
+	"server/src/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCategorizedAssetRepository returns the assets with the category of their external ID
+type fakeCategorizedAssetRepository struct {
+	fakeAssetRepository
+	categories map[string]string
+}
+
+func (r *fakeCategorizedAssetRepository) GetWithCategories(_ context.Context) ([]models.AssetWithCategory, error) {
+	assets := make([]models.AssetWithCategory, 0, len(r.assets))
+	for _, asset := range r.assets {
+		assets = append(assets, models.AssetWithCategory{
+			ID:           asset.ID,
+			ExternalID:   asset.ExternalID,
+			Name:         asset.Name,
+			Currency:     asset.Currency,
+			CategoryName: r.categories[asset.ExternalID],
+		})
+	}
+	return assets, nil
+}
+
+func TestCurrencyConverter(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	rates := []schemas.VariableValuation{
+		{Date: "2024-01-04", Value: 1250},
+		{Date: "2024-01-02", Value: 1000},
+	}
+
+	converter, err := services.NewCurrencyConverter(services.ReportCurrencyMEP, rates)
+	require.NoError(t, err)
+	assert.InDelta(t, 100.0, converter.Convert(100000, "Pesos", day(2)), 0.0001)
+	assert.InDelta(t, 100.0, converter.Convert(100000, "Pesos", day(3)), 0.0001, "days without rate use the last one")
+	assert.InDelta(t, 80.0, converter.Convert(100000, "Pesos", day(4)), 0.0001)
+	assert.InDelta(t, 100.0, converter.Convert(100000, "Pesos", day(1)), 0.0001, "days before every rate use the first one")
+	assert.Equal(t, 50.0, converter.Convert(50, "USD", day(4)), "dollars are not converted to dollars")
+
+	converter, err = services.NewCurrencyConverter(services.ReportCurrencyARS, rates)
+	require.NoError(t, err)
+	assert.Equal(t, 100000.0, converter.Convert(100000, "Pesos", day(4)))
+	assert.InDelta(t, 62500.0, converter.Convert(50, "USD", day(4)), 0.0001)
+
+	_, err = services.NewCurrencyConverter(services.ReportCurrencyCCL, nil)
+	assert.Error(t, err, "reports in dollars need rates")
+	_, err = services.NewCurrencyConverter("EUR", rates)
+	assert.Error(t, err)
+}
+
+func TestConvertedAccountStateByCategory(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	holdingRepo := &fakeHoldingRepository{holdings: []models.Holding{
+		{ClientID: "test-client", Source: models.SourceESCO, AssetID: 1, Units: 10, Value: 100000, Date: day(2)},
+		{ClientID: "test-client", Source: models.SourceESCO, AssetID: 1, Units: 12, Value: 150000, Date: day(4)},
+		{ClientID: "test-client", Source: models.SourceManual, AssetID: 2, Units: 1, Value: 100000, Date: day(2)},
+		{ClientID: "test-client", Source: models.SourceManual, AssetID: 2, Units: 1, Value: 125000, Date: day(4)},
+	}}
+	transactionRepo := &fakeTransactionRepository{transactions: []models.Transaction{
+		{ClientID: "test-client", Source: models.SourceESCO, AssetID: 1, TransactionType: models.TransactionTypeBuy, Units: 2, TotalValue: 25000, Date: day(4)},
+	}}
+	assetRepo := &fakeCategorizedAssetRepository{
+		fakeAssetRepository: fakeAssetRepository{assets: []models.Asset{
+			{ID: 1, ExternalID: "GGAL", Name: "Grupo Galicia", Currency: "Pesos"},
+			{ID: 2, ExternalID: "MANUAL-1", Name: "Departamento", Currency: "USD", ClientID: "test-client"},
+		}},
+		categories: map[string]string{"GGAL": "Acciones", "MANUAL-1": "Inmuebles"},
+	}
+	service := services.NewAccountService(holdingRepo, transactionRepo, assetRepo)
+	rates := []schemas.VariableValuation{
+		{Date: "2024-01-02", Value: 1000},
+		{Date: "2024-01-04", Value: 1250},
+	}
+
+	converter, err := services.NewCurrencyConverter(services.ReportCurrencyUSD, rates)
+	require.NoError(t, err)
+	state, err := service.GetMultiAccountStateByCategory(ctx, []string{"test-client"}, day(2), day(4), 24*time.Hour, nil, converter)
+	require.NoError(t, err)
+
+	totals := make(map[string]float64)
+	for dateStr, holding := range *state.TotalHoldingsByDate {
+		totals[dateStr] = holding.Value
+		assert.Equal(t, "USD", holding.Currency)
+	}
+	assert.InDeltaMapValues(t, map[string]float64{
+		"2024-01-02": 200,
+		"2024-01-03": 100,
+		"2024-01-04": 220,
+	}, totals, 0.0001, "each holding is converted with the rate of its date before being added up")
+
+	categoryAssets := *state.CategoryAssets
+	require.Contains(t, categoryAssets, "Acciones")
+	require.Len(t, categoryAssets["Acciones"].Transactions, 1)
+	assert.InDelta(t, 20.0, categoryAssets["Acciones"].Transactions[0].Value, 0.0001)
+	require.Contains(t, *state.TransactionsByType, models.TransactionTypeBuy)
+
+	require.Contains(t, categoryAssets, "Inmuebles")
+	manualHoldings := categoryAssets["Inmuebles"].Holdings
+	require.NotEmpty(t, manualHoldings)
+	for _, holding := range manualHoldings {
+		assert.InDelta(t, 100.0, holding.Value, 0.0001, "manual valuations are in pesos whatever the asset currency")
+	}
+
+	converter, err = services.NewCurrencyConverter(services.ReportCurrencyARS, rates)
+	require.NoError(t, err)
+	state, err = service.GetMultiAccountStateByCategory(ctx, []string{"test-client"}, day(2), day(4), 24*time.Hour, nil, converter)
+	require.NoError(t, err)
+	assert.InDelta(t, 275000.0, (*state.TotalHoldingsByDate)["2024-01-04"].Value, 0.0001, "manual valuations are not converted to pesos again")
+	assert.InDelta(t, 200000.0, (*state.TotalHoldingsByDate)["2024-01-02"].Value, 0.0001)
+}
@@ -2,8 +2,12 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"server/src/clients/esco"
+	"server/src/models"
 	"server/src/services"
 	"server/src/utils"
 	esco_test "server/tests/clients/esco"
@@ -45,7 +49,7 @@ func TestGetAccountByID(t *testing.T) {
 	ctx = utils.WithLogger(ctx, logger)
 
 	mockClient := setupMockClient(t)
-	service := services.NewESCOService(mockClient)
+	service := services.NewESCOService(mockClient, nil)
 
 	account, err := service.GetAccountByID(ctx, "token", "4014D4EFDD5DE27B")
 	if err != nil {
@@ -70,7 +74,7 @@ func TestGetAccountState(t *testing.T) {
 	date := time.Date(2024, 10, 8, 0, 0, 0, 0, time.UTC)
 
 	mockClient := setupMockClient(t)
-	service := services.NewESCOService(mockClient)
+	service := services.NewESCOService(mockClient, nil)
 
 	state, err := service.GetAccountState(ctx, "token", "4014D4EFDD5DE27B", date)
 	if err != nil {
@@ -111,7 +115,7 @@ func TestGetAccountStateWithTransactions(t *testing.T) {
 	endDate := startDate.Add(24 * time.Hour)
 
 	mockClient := setupMockClient(t)
-	service := services.NewESCOService(mockClient)
+	service := services.NewESCOService(mockClient, nil)
 
 	state, err := service.GetAccountStateWithTransactions(ctx, "token", "4014D4EFDD5DE27B", startDate, endDate, 24*time.Hour)
 	if err != nil {
@@ -157,7 +161,7 @@ func TestGetMultiAccountStateByCategory(t *testing.T) {
 	endDate := startDate.Add(24 * time.Hour)
 
 	mockClient := setupMockClient(t)
-	service := services.NewESCOService(mockClient)
+	service := services.NewESCOService(mockClient, nil)
 
 	state, err := service.GetMultiAccountStateByCategory(ctx, "token", []string{"4014D4EFDD5DE27B"}, startDate, endDate, 24*time.Hour)
 	if err != nil {
@@ -179,3 +183,316 @@ func TestGetMultiAccountStateByCategory(t *testing.T) {
 	}
 
 }
+
+// blockingESCOClient never answers GetEstadoCuenta until the request context is done
+type blockingESCOClient struct {
+	*esco_test.ESCOServiceClientMock
+}
+
+func (c *blockingESCOClient) GetEstadoCuenta(ctx context.Context, _, _, _, _, _ string, _ time.Time, _ bool) ([]esco.EstadoCuentaSchema, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestGetMultiAccountStateWithTransactionsCancellation(t *testing.T) {
+	logger := utils.NewLogger(logrus.InfoLevel, false, "")
+	ctx, cancel := context.WithTimeout(utils.WithLogger(context.Background(), logger), 50*time.Millisecond)
+	defer cancel()
+	startDate := time.Date(2024, 10, 8, 0, 0, 0, 0, time.UTC)
+	endDate := startDate.AddDate(0, 0, 5)
+
+	service := services.NewESCOService(&blockingESCOClient{setupMockClient(t)}, nil)
+
+	started := time.Now()
+	_, err := service.GetMultiAccountStateWithTransactions(ctx, "token", []string{"4014D4EFDD5DE27B", "4014D4EFDD5DE27B"}, startDate, endDate, 24*time.Hour)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Expected the call to return right after cancellation, took %s", elapsed)
+	}
+}
+
+// tradingESCOClient answers with the given boletos and cuenta corriente movements
+type tradingESCOClient struct {
+	*esco_test.ESCOServiceClientMock
+	boletos      []esco.Boleto
+	instrumentos []esco.Instrumentos
+}
+
+func (c *tradingESCOClient) GetBoletos(_ context.Context, _, _, _, _, _ string, _, _ time.Time, _ bool) ([]esco.Boleto, error) {
+	return c.boletos, nil
+}
+
+func (c *tradingESCOClient) GetCtaCteConsolidado(_ context.Context, _, _, _, _, _ string, _, _ time.Time, _ bool) ([]esco.Instrumentos, error) {
+	return c.instrumentos, nil
+}
+
+func TestGetAccountStateWithTransactionsBoletos(t *testing.T) {
+	ctx := context.Background()
+	logger := utils.NewLogger(logrus.InfoLevel, false, "")
+	ctx = utils.WithLogger(ctx, logger)
+	startDate := time.Date(2024, 10, 8, 0, 0, 0, 0, time.UTC)
+	endDate := startDate.Add(24 * time.Hour)
+
+	client := &tradingESCOClient{
+		ESCOServiceClientMock: setupMockClient(t),
+		boletos: []esco.Boleto{
+			{I: "YMCQO - ON YPF CL. 25 V13/02/26", FL: "2024/10/08", O: "CompraSENEBING", C: 100, PR: 10, B: -1000, DB: -5, DM: -1, N: -1006},
+		},
+		instrumentos: []esco.Instrumentos{
+			// The same trade as the boleto, listed by operation number
+			{FL: "2024/10/08", D: "Boleto / 1 / CSBNG / 1 / YMCQO / $", TI: "Instrumentos", C: 100, N: -1006},
+			// A trade without boleto in the range is kept
+			{FL: "2024/10/09", D: "Boleto / 2 / CSBNG / 1 / YMCQO / $", TI: "Instrumentos", C: 50, N: -500},
+		},
+	}
+	service := services.NewESCOService(client, nil)
+
+	state, err := service.GetAccountStateWithTransactions(ctx, "token", "4014D4EFDD5DE27B", startDate, endDate, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	asset, exists := (*state.Assets)["YMCQO"]
+	if !exists {
+		t.Fatal("Expected asset for YMCQO to exist")
+	}
+	if len(asset.Transactions) != 2 {
+		t.Fatalf("Expected the boleto and the trade without boleto, got %d transactions", len(asset.Transactions))
+	}
+	var units float64
+	for _, transaction := range asset.Transactions {
+		units += transaction.Units
+		if transaction.Date.Format("2006-01-02") == "2024-10-08" && transaction.PricePerUnit != 10 {
+			t.Errorf("Expected the trade of the boleto date to keep its price, got %f", transaction.PricePerUnit)
+		}
+	}
+	if units != 150 {
+		t.Errorf("Expected 150 units traded, got %f", units)
+	}
+}
+
+func TestParseExport(t *testing.T) {
+	mockClient := setupMockClient(t)
+	service := services.NewESCOService(mockClient, nil)
+
+	readExport := func(fileName string) []byte {
+		var data json.RawMessage
+		if err := mockClient.ReadMockResponse(fileName, &data); err != nil {
+			t.Fatalf("Failed to read export %s: %v", fileName, err)
+		}
+		return data
+	}
+
+	t.Run("estado de cuenta export", func(t *testing.T) {
+		date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		accountState, err := service.ParseExport(services.ESCOExportEstadoCuenta, readExport("estado_cuenta_4014D4EFDD5DE27B_date_response.json"), &date)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(*accountState.Assets) == 0 {
+			t.Fatal("Expected assets in account state")
+		}
+		for _, asset := range *accountState.Assets {
+			for _, holding := range asset.Holdings {
+				if !holding.DateRequested.Equal(date) {
+					t.Errorf("Expected holding requested on %s, got %s", date, holding.DateRequested)
+				}
+			}
+		}
+	})
+
+	t.Run("estado de cuenta export without date", func(t *testing.T) {
+		_, err := service.ParseExport(services.ESCOExportEstadoCuenta, readExport("estado_cuenta_4014D4EFDD5DE27B_date_response.json"), nil)
+		if err == nil {
+			t.Fatal("Expected an error when parsing estado de cuenta without date")
+		}
+	})
+
+	t.Run("boletos and liquidaciones exports", func(t *testing.T) {
+		for exportType, fileName := range map[services.ESCOExportType]string{
+			services.ESCOExportBoletos:       "boletos_response.json",
+			services.ESCOExportLiquidaciones: "liquidaciones_response.json",
+		} {
+			accountState, err := service.ParseExport(exportType, readExport(fileName), nil)
+			if err != nil {
+				t.Fatalf("Expected no error parsing %s, got %v", exportType, err)
+			}
+			transactions := 0
+			for _, asset := range *accountState.Assets {
+				transactions += len(asset.Transactions)
+			}
+			if transactions == 0 {
+				t.Errorf("Expected transactions parsed from %s export", exportType)
+			}
+		}
+	})
+
+	t.Run("unknown export type", func(t *testing.T) {
+		_, err := service.ParseExport(services.ESCOExportType("unknown"), []byte("[]"), nil)
+		if err == nil {
+			t.Fatal("Expected an error for an unknown export type")
+		}
+	})
+}
+
+func TestParseExportTransactionTypes(t *testing.T) {
+	mockClient := setupMockClient(t)
+	service := services.NewESCOService(mockClient, nil)
+
+	typesFromExport := func(exportType services.ESCOExportType, fileName string) map[string]int {
+		var data json.RawMessage
+		if err := mockClient.ReadMockResponse(fileName, &data); err != nil {
+			t.Fatalf("Failed to read export %s: %v", fileName, err)
+		}
+		accountState, err := service.ParseExport(exportType, data, nil)
+		if err != nil {
+			t.Fatalf("Expected no error parsing %s, got %v", exportType, err)
+		}
+		types := make(map[string]int)
+		for _, asset := range *accountState.Assets {
+			for _, transaction := range asset.Transactions {
+				types[transaction.Type]++
+			}
+		}
+		return types
+	}
+
+	t.Run("boletos are classified by operation", func(t *testing.T) {
+		types := typesFromExport(services.ESCOExportBoletos, "boletos_response.json")
+		if len(types) != 1 || types[models.TransactionTypeBuy] == 0 {
+			t.Errorf("Expected only buy transactions, got %v", types)
+		}
+	})
+
+	t.Run("liquidaciones are classified by operation type", func(t *testing.T) {
+		types := typesFromExport(services.ESCOExportLiquidaciones, "liquidaciones_response.json")
+		if len(types) != 1 || types[models.TransactionTypeSubscription] == 0 {
+			t.Errorf("Expected only subscription transactions, got %v", types)
+		}
+	})
+
+	t.Run("cuenta corriente movements are classified by description", func(t *testing.T) {
+		types := typesFromExport(services.ESCOExportCtaCte, "cte_corriente_response.json")
+		for _, expected := range []string{
+			models.TransactionTypeBuy,
+			models.TransactionTypeSubscription,
+			models.TransactionTypeRedemption,
+			models.TransactionTypeCoupon,
+			models.TransactionTypeAmortization,
+			models.TransactionTypeFee,
+		} {
+			if types[expected] == 0 {
+				t.Errorf("Expected %s transactions, got %v", expected, types)
+			}
+		}
+		for transactionType := range types {
+			if !models.IsValidTransactionType(transactionType) {
+				t.Errorf("Expected every transaction to be classified, got type %q", transactionType)
+			}
+		}
+	})
+
+	t.Run("every transaction type is produced from cuenta corriente movements", func(t *testing.T) {
+		movements := []byte(`[
+			{"FL": "2024/07/01", "I": "LECAP VTO. 14OCT24 - S14O4 /9262", "D": "Boleto / 1 / CSBNG / 1 / S14O4 / $", "TI": "Instrumentos", "C": 100, "N": -100},
+			{"FL": "2024/07/02", "I": "LECAP VTO. 14OCT24 - S14O4 /9262", "D": "Boleto / 2 / VSBNG / 1 / S14O4 / $", "TI": "Instrumentos", "C": -50, "N": 60},
+			{"FL": "2024/07/03", "I": "LECAP VTO. 14OCT24 - S14O4 /9262", "D": "Retiro de Títulos", "TI": "Instrumentos", "C": -10, "N": 0},
+			{"FL": "2024/07/04", "I": "Dolar Estadounidense - USD", "D": "Renta / AL30", "TI": "Monedas", "C": -5, "N": 5},
+			{"FL": "2024/07/05", "I": "Dolar Estadounidense - USD", "D": "Partial redemption with pool factor redu / AL30", "TI": "Monedas", "N": 20},
+			{"FL": "2024/07/06", "I": "FCI - MEGAQM", "D": "Liquidación de Suscripción", "TI": "Instrumentos", "C": 1000, "N": -1000},
+			{"FL": "2024/07/07", "I": "FCI - MEGAQM", "D": "Liquidación de Rescate", "TI": "Instrumentos", "C": -500, "N": 500},
+			{"FL": "2024/07/08", "I": "Dolar Estadounidense - USD", "D": "Dividend payment (DVCA) / KO", "TI": "Monedas", "N": 12.5, "N_S": "USD"},
+			{"FL": "2024/07/09", "I": "Pesos - $", "D": "Gasto de Custodia - Caja de Valores", "TI": "Monedas", "N": -1500, "N_S": "$"}
+		]`)
+		accountState, err := service.ParseExport(services.ESCOExportCtaCte, movements, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		types := make(map[string]int)
+		for _, asset := range *accountState.Assets {
+			for _, transaction := range asset.Transactions {
+				types[transaction.Type]++
+			}
+		}
+		for _, expected := range models.TransactionTypes {
+			if types[expected] != 1 {
+				t.Errorf("Expected a %s transaction, got %v", expected, types)
+			}
+		}
+
+		fees := (*accountState.Assets)["$"].Transactions
+		if len(fees) != 1 || fees[0].Units != -1500 || fees[0].Value != 0 {
+			t.Errorf("Expected the fee to take 1500 pesos out of the cash asset, got %+v", fees)
+		}
+	})
+}
+
+func TestParseCtaCtePartialRedemptions(t *testing.T) {
+	mockClient := setupMockClient(t)
+	service := services.NewESCOService(mockClient, nil)
+
+	movements := []byte(`[
+		{"FL": "2024/07/10", "I": "Dolar Estadounidense - USD", "D": "Partial redemption with pool factor redu / AL30", "TI": "Monedas", "N": 5935.64},
+		{"FL": "2024/07/10", "I": "Pesos - $", "D": "Partial redemption with pool factor redu / AL30", "TI": "Monedas", "N": -120.5}
+	]`)
+	accountState, err := service.ParseExport(services.ESCOExportCtaCte, movements, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(*accountState.Assets) != 1 {
+		t.Fatalf("Expected only the dollar redemption to be parsed, got %d assets", len(*accountState.Assets))
+	}
+	asset, ok := (*accountState.Assets)["USD"]
+	if !ok {
+		t.Fatalf("Expected the redemption to be kept on the USD asset")
+	}
+	if asset.CategoryKey != "MEP" {
+		t.Errorf("Expected the MEP category key, got %s", asset.CategoryKey)
+	}
+	if len(asset.Transactions) != 1 {
+		t.Fatalf("Expected 1 transaction, got %d", len(asset.Transactions))
+	}
+	transaction := asset.Transactions[0]
+	if transaction.Type != models.TransactionTypeAmortization {
+		t.Errorf("Expected an amortization, got %s", transaction.Type)
+	}
+	// The dollars received are units of the MEP asset, not a cash flow into the account
+	if transaction.Units != 5935.64 || transaction.Value != 0 {
+		t.Errorf("Expected 5935.64 units without value, got %f units and %f value", transaction.Units, transaction.Value)
+	}
+}
+
+func TestParseBoletosExecutionDetails(t *testing.T) {
+	mockClient := setupMockClient(t)
+	service := services.NewESCOService(mockClient, nil)
+
+	var data json.RawMessage
+	if err := mockClient.ReadMockResponse("boletos_response.json", &data); err != nil {
+		t.Fatalf("Failed to read export: %v", err)
+	}
+	accountState, err := service.ParseExport(services.ESCOExportBoletos, data, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	asset, exists := (*accountState.Assets)["GD30"]
+	if !exists || len(asset.Transactions) == 0 {
+		t.Fatal("Expected GD30 transactions")
+	}
+	transaction := asset.Transactions[0]
+	if transaction.PricePerUnit != 775.5 {
+		t.Errorf("Expected price per unit 775.5, got %f", transaction.PricePerUnit)
+	}
+	if transaction.GrossValue != 117499882.5 {
+		t.Errorf("Expected gross value 117499882.5, got %f", transaction.GrossValue)
+	}
+	if transaction.ExchangeFees != 0 || transaction.MarketFees != 0 {
+		t.Errorf("Expected no fees, got %f and %f", transaction.ExchangeFees, transaction.MarketFees)
+	}
+	if transaction.Value != transaction.GrossValue+transaction.ExchangeFees+transaction.MarketFees {
+		t.Errorf("Expected net value %f to be the gross value plus fees", transaction.Value)
+	}
+}
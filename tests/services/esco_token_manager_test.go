@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"server/src/clients/esco"
+	"server/src/schemas"
+	"server/src/services"
+	"testing"
+	"time"
+)
+
+type memoryCacheHandler struct {
+	values map[string][]byte
+}
+
+func newMemoryCacheHandler() *memoryCacheHandler {
+	return &memoryCacheHandler{values: map[string][]byte{}}
+}
+
+func (c *memoryCacheHandler) Set(key string, value interface{}, _ time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.values[key] = data
+	return nil
+}
+
+func (c *memoryCacheHandler) Get(key string, result interface{}) error {
+	data, ok := c.values[key]
+	if !ok {
+		return fmt.Errorf("key does not exist: %s", key)
+	}
+	return json.Unmarshal(data, result)
+}
+
+func (c *memoryCacheHandler) Delete(key string) error {
+	delete(c.values, key)
+	return nil
+}
+
+func (c *memoryCacheHandler) Exists(key string) (bool, error) {
+	_, ok := c.values[key]
+	return ok, nil
+}
+
+type fakeTokenClient struct {
+	esco.ESCOServiceClientI
+	expiresIn    int
+	logins       int
+	refreshes    int
+	refreshError error
+}
+
+func (c *fakeTokenClient) PostToken(_ context.Context, _, _ string) (*schemas.TokenResponse, error) {
+	c.logins++
+	return &schemas.TokenResponse{
+		AccessToken:  fmt.Sprintf("login-%d", c.logins),
+		RefreshToken: "refresh",
+		ExpiresIn:    c.expiresIn,
+	}, nil
+}
+
+func (c *fakeTokenClient) RefreshToken(_ context.Context, _ string) (*schemas.TokenResponse, error) {
+	c.refreshes++
+	if c.refreshError != nil {
+		return nil, c.refreshError
+	}
+	return &schemas.TokenResponse{
+		AccessToken:  fmt.Sprintf("refresh-%d", c.refreshes),
+		RefreshToken: "refresh",
+		ExpiresIn:    c.expiresIn,
+	}, nil
+}
+
+func TestESCOTokenManager(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("logs in once and reuses the cached token", func(t *testing.T) {
+		client := &fakeTokenClient{expiresIn: 3600}
+		manager := services.NewESCOTokenManager(client, newMemoryCacheHandler(), "user", "pass", 5*time.Minute)
+
+		for i := 0; i < 3; i++ {
+			token, err := manager.GetToken(ctx)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if token != "login-1" {
+				t.Errorf("Expected token login-1, got %s", token)
+			}
+		}
+		if client.logins != 1 || client.refreshes != 0 {
+			t.Errorf("Expected 1 login and no refreshes, got %d logins and %d refreshes", client.logins, client.refreshes)
+		}
+	})
+
+	t.Run("refreshes the token before it expires", func(t *testing.T) {
+		client := &fakeTokenClient{expiresIn: 60}
+		manager := services.NewESCOTokenManager(client, newMemoryCacheHandler(), "user", "pass", 5*time.Minute)
+
+		if _, err := manager.GetToken(ctx); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		token, err := manager.GetToken(ctx)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if token != "refresh-1" {
+			t.Errorf("Expected token refresh-1, got %s", token)
+		}
+	})
+
+	t.Run("logs in again when the refresh fails", func(t *testing.T) {
+		client := &fakeTokenClient{expiresIn: 60, refreshError: errors.New("invalid_grant")}
+		manager := services.NewESCOTokenManager(client, newMemoryCacheHandler(), "user", "pass", 5*time.Minute)
+
+		if _, err := manager.GetToken(ctx); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		token, err := manager.GetToken(ctx)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if token != "login-2" {
+			t.Errorf("Expected token login-2, got %s", token)
+		}
+	})
+
+	t.Run("fails when the service account is not configured", func(t *testing.T) {
+		manager := services.NewESCOTokenManager(&fakeTokenClient{}, newMemoryCacheHandler(), "", "", 0)
+
+		_, err := manager.GetToken(ctx)
+		if !errors.Is(err, services.ErrServiceAccountNotConfigured) {
+			t.Errorf("Expected ErrServiceAccountNotConfigured, got %v", err)
+		}
+	})
+}
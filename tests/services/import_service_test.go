@@ -0,0 +1,97 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"server/src/schemas"
+	"server/src/services"
+	esco_test "server/tests/clients/esco"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSyncService records the account states stored through it, and the dates of the ones stored
+// without being marked as synced. It syncs from the given sources.
+type fakeSyncService struct {
+	services.SyncServiceI
+	storedDates            [][]time.Time
+	storedTransactionDates [][]time.Time
+	sources                []string
+}
+
+func (s *fakeSyncService) HasSource(source string) bool {
+	return slices.Contains(s.sources, source)
+}
+
+func (s *fakeSyncService) StoreAccountState(_ context.Context, _ string, _ *schemas.AccountState, datesToSync []time.Time) error {
+	s.storedDates = append(s.storedDates, datesToSync)
+	return nil
+}
+
+func (s *fakeSyncService) StoreTransactions(_ context.Context, _ string, _ *schemas.AccountState, datesToSync []time.Time) error {
+	s.storedTransactionDates = append(s.storedTransactionDates, datesToSync)
+	return nil
+}
+
+func TestImportESCOExport(t *testing.T) {
+	ctx := context.Background()
+
+	workspaceRoot, err := os.Getwd()
+	require.NoError(t, err)
+	for {
+		if _, err := os.Stat(filepath.Join(workspaceRoot, "go.mod")); err == nil {
+			break
+		}
+		parent := filepath.Dir(workspaceRoot)
+		require.NotEqual(t, workspaceRoot, parent, "Could not find workspace root directory")
+		workspaceRoot = parent
+	}
+	mockClient, err := esco_test.NewMockClient(filepath.Join(workspaceRoot, "tests", "test_files", "clients", "esco"))
+	require.NoError(t, err)
+
+	readExport := func(fileName string) []byte {
+		var data json.RawMessage
+		require.NoError(t, mockClient.ReadMockResponse(fileName, &data))
+		return data
+	}
+
+	t.Run("stores an estado de cuenta export for its date", func(t *testing.T) {
+		syncService := &fakeSyncService{}
+		service := services.NewImportService(services.NewESCOService(mockClient, nil), syncService)
+
+		date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		result, err := service.ImportESCOExport(ctx, "test-client", services.ESCOExportEstadoCuenta, readExport("estado_cuenta_4014D4EFDD5DE27B_date_response.json"), &date)
+		require.NoError(t, err)
+		assert.NotZero(t, result.Holdings)
+		assert.Zero(t, result.Transactions)
+		require.Len(t, syncService.storedDates, 1)
+		assert.Equal(t, []time.Time{date}, syncService.storedDates[0])
+	})
+
+	t.Run("stores a boletos export for every settlement date without marking it as synced", func(t *testing.T) {
+		syncService := &fakeSyncService{}
+		service := services.NewImportService(services.NewESCOService(mockClient, nil), syncService)
+
+		result, err := service.ImportESCOExport(ctx, "test-client", services.ESCOExportBoletos, readExport("boletos_response.json"), nil)
+		require.NoError(t, err)
+		assert.NotZero(t, result.Transactions)
+		assert.Empty(t, syncService.storedDates)
+		require.Len(t, syncService.storedTransactionDates, 1)
+		assert.Len(t, syncService.storedTransactionDates[0], len(result.Dates))
+	})
+
+	t.Run("rejects invalid exports", func(t *testing.T) {
+		syncService := &fakeSyncService{}
+		service := services.NewImportService(services.NewESCOService(mockClient, nil), syncService)
+
+		_, err := service.ImportESCOExport(ctx, "test-client", services.ESCOExportBoletos, []byte("not json"), nil)
+		require.Error(t, err)
+		assert.Empty(t, syncService.storedDates)
+	})
+}
@@ -0,0 +1,100 @@
+package services_test
+
+import (
+	"context"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/schemas"
+	"server/src/services"
+	esco_test "server/tests/clients/esco"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSyncLogRepository serves sync logs from memory
+type fakeSyncLogRepository struct {
+	repositories.SyncLogRepository
+	syncedDates []time.Time
+}
+
+func (r *fakeSyncLogRepository) GetSyncedDates(_ context.Context, _, _ string, startDate, endDate time.Time) ([]time.Time, error) {
+	dates := []time.Time{}
+	for _, date := range r.syncedDates {
+		if !date.Before(startDate) && date.Before(endDate) {
+			dates = append(dates, date)
+		}
+	}
+	return dates, nil
+}
+
+func (r *fakeSyncLogRepository) GetLastSyncDate(_ context.Context, _ string) (*time.Time, error) {
+	if len(r.syncedDates) == 0 {
+		return nil, nil
+	}
+	last := r.syncedDates[len(r.syncedDates)-1]
+	return &last, nil
+}
+
+// fakeSyncRunRepository discards the sync run history
+type fakeSyncRunRepository struct {
+	repositories.SyncRunRepository
+}
+
+func (r *fakeSyncRunRepository) Create(_ context.Context, _ *models.SyncRun) error {
+	return nil
+}
+
+func (r *fakeSyncRunRepository) Finish(_ context.Context, _ *models.SyncRun) error {
+	return nil
+}
+
+func TestSyncAccountIncremental(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	setup := func(syncedDates []time.Time) (*services.IncrementalSyncService, *[][2]time.Time) {
+		syncLogRepo := &fakeSyncLogRepository{syncedDates: syncedDates}
+		requestedRanges := &[][2]time.Time{}
+		mockESCO := esco_test.NewMockESCOService(func(ctx context.Context, token, accountID string, startDate, endDate time.Time, interval time.Duration) (*schemas.AccountState, error) {
+			*requestedRanges = append(*requestedRanges, [2]time.Time{startDate, endDate})
+			return nil, nil
+		})
+		syncService := services.NewSyncService(nil, nil, nil, nil, nil, syncLogRepo, &fakeSyncRunRepository{}, services.NewESCOBrokerSource(mockESCO))
+		return services.NewIncrementalSyncService(syncService, syncLogRepo, nil, nil, 5), requestedRanges
+	}
+
+	t.Run("syncs the whole backfill window for a new account", func(t *testing.T) {
+		service, requestedRanges := setup(nil)
+
+		err := service.SyncAccountIncremental(ctx, "test-token", "test-client", day(11))
+		require.NoError(t, err)
+		assert.Equal(t, [][2]time.Time{{day(6), day(11)}}, *requestedRanges)
+	})
+
+	t.Run("backfills gaps and syncs up to yesterday", func(t *testing.T) {
+		service, requestedRanges := setup([]time.Time{day(6), day(8), day(9)})
+
+		err := service.SyncAccountIncremental(ctx, "test-token", "test-client", day(11))
+		require.NoError(t, err)
+		assert.Equal(t, [][2]time.Time{{day(7), day(8)}, {day(10), day(11)}}, *requestedRanges)
+	})
+
+	t.Run("catches up from the last sync when it is older than the window", func(t *testing.T) {
+		service, requestedRanges := setup([]time.Time{day(1)})
+
+		err := service.SyncAccountIncremental(ctx, "test-token", "test-client", day(11))
+		require.NoError(t, err)
+		assert.Equal(t, [][2]time.Time{{day(2), day(11)}}, *requestedRanges)
+	})
+
+	t.Run("does nothing when the account is up to date", func(t *testing.T) {
+		service, requestedRanges := setup([]time.Time{day(6), day(7), day(8), day(9), day(10)})
+
+		err := service.SyncAccountIncremental(ctx, "test-token", "test-client", day(11))
+		require.NoError(t, err)
+		assert.Empty(t, *requestedRanges)
+	})
+}
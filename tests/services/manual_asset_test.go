@@ -0,0 +1,93 @@
+package services_test
+
+import (
+	"context"
+	"server/src/models"
+	"server/src/services"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (r *fakeHoldingRepository) GetByClientIDs(_ context.Context, _ []string, startDate, endDate time.Time) ([]models.Holding, error) {
+	holdings := []models.Holding{}
+	for _, holding := range r.holdings {
+		if !holding.Date.Before(startDate) && !holding.Date.After(endDate) {
+			holdings = append(holdings, holding)
+		}
+	}
+	return holdings, nil
+}
+
+func (r *fakeHoldingRepository) GetLatestBeforeDate(_ context.Context, _ []string, source string, date time.Time) ([]models.Holding, error) {
+	latest := make(map[int]models.Holding)
+	for _, holding := range r.holdings {
+		if holding.Source != source || !holding.Date.Before(date) {
+			continue
+		}
+		if previous, exists := latest[holding.AssetID]; !exists || holding.Date.After(previous.Date) {
+			latest[holding.AssetID] = holding
+		}
+	}
+	holdings := []models.Holding{}
+	for _, holding := range latest {
+		holdings = append(holdings, holding)
+	}
+	return holdings, nil
+}
+
+func (r *fakeTransactionRepository) GetByClientIDs(_ context.Context, _ []string, _, _ time.Time, transactionTypes []string) ([]models.Transaction, error) {
+	if len(transactionTypes) == 0 {
+		return r.transactions, nil
+	}
+	transactions := make([]models.Transaction, 0, len(r.transactions))
+	for _, transaction := range r.transactions {
+		if slices.Contains(transactionTypes, transaction.TransactionType) {
+			transactions = append(transactions, transaction)
+		}
+	}
+	return transactions, nil
+}
+
+func TestManualAssetValuationsInAccountState(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	holdingRepo := &fakeHoldingRepository{holdings: []models.Holding{
+		{ClientID: "test-client", Source: models.SourceESCO, AssetID: 1, Units: 10, Value: 100, Date: day(3)},
+		{ClientID: "test-client", Source: models.SourceESCO, AssetID: 1, Units: 10, Value: 110, Date: day(4)},
+		// Valued before the requested range, revalued within it and sold afterwards
+		{ClientID: "test-client", Source: models.SourceManual, AssetID: 2, Units: 1, Value: 1000, Date: day(1)},
+		{ClientID: "test-client", Source: models.SourceManual, AssetID: 2, Units: 1, Value: 1200, Date: day(4)},
+		{ClientID: "test-client", Source: models.SourceManual, AssetID: 2, Date: day(6)},
+	}}
+	assetRepo := &fakeAssetRepository{assets: []models.Asset{
+		{ID: 1, ExternalID: "GGAL", Name: "Grupo Galicia"},
+		{ID: 2, ExternalID: "MANUAL-1", Name: "Departamento", Currency: "USD", ClientID: "test-client"},
+	}}
+	service := services.NewAccountService(holdingRepo, &fakeTransactionRepository{}, assetRepo)
+
+	accountStates, err := service.GetMultiAccountStateWithTransactions(ctx, []string{"test-client"}, day(2), day(7), 24*time.Hour, nil)
+	require.NoError(t, err)
+	require.Len(t, accountStates, 1)
+	assets := *accountStates[0].Assets
+
+	assert.False(t, assets["GGAL"].Manual)
+	manualAsset := assets["MANUAL-1"]
+	assert.True(t, manualAsset.Manual)
+
+	values := make(map[time.Time]float64)
+	for _, holding := range manualAsset.Holdings {
+		values[*holding.Date] = holding.Value
+	}
+	assert.Equal(t, map[time.Time]float64{
+		day(2): 1000,
+		day(3): 1000,
+		day(4): 1200,
+		day(5): 1200,
+		day(6): 0,
+	}, values, "the last valuation holds every day until the asset is valued again")
+}
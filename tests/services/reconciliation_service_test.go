@@ -0,0 +1,101 @@
+package services_test
+
+import (
+	"context"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/services"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHoldingRepository struct {
+	repositories.HoldingRepository
+	holdings []models.Holding
+}
+
+func (r *fakeHoldingRepository) GetByClientID(_ context.Context, _ string, _, _ time.Time) ([]models.Holding, error) {
+	return r.holdings, nil
+}
+
+type fakeTransactionRepository struct {
+	repositories.TransactionRepository
+	transactions []models.Transaction
+}
+
+func (r *fakeTransactionRepository) GetByClientID(_ context.Context, _ string, _, _ time.Time) ([]models.Transaction, error) {
+	return r.transactions, nil
+}
+
+type fakeAssetRepository struct {
+	repositories.AssetRepository
+	assets []models.Asset
+}
+
+func (r *fakeAssetRepository) GetByIDs(_ context.Context, _ []int) ([]models.Asset, error) {
+	return r.assets, nil
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	holdingRepo := &fakeHoldingRepository{holdings: []models.Holding{
+		{AssetID: 1, Units: 10, Date: day(1)},
+		{AssetID: 2, Units: 5, Date: day(1)},
+		{AssetID: 1, Units: 15, Date: day(2)},
+		{AssetID: 2, Units: 5, Date: day(2)},
+		{AssetID: 1, Units: 15, Date: day(3)},
+		{AssetID: 2, Units: 8, Date: day(3)},
+	}}
+	transactionRepo := &fakeTransactionRepository{transactions: []models.Transaction{
+		// Explains the change of asset 1 on day 2
+		{AssetID: 1, Units: 5, Date: day(2)},
+		// Asset 2 gains 3 units on day 3 without any transaction, and asset 3 is bought but never held
+		{AssetID: 3, Units: 2, Date: day(3)},
+	}}
+	assetRepo := &fakeAssetRepository{assets: []models.Asset{
+		{ID: 2, ExternalID: "AL30", Name: "Bono AL30"},
+		{ID: 3, ExternalID: "GD30", Name: "Bono GD30"},
+	}}
+	service := services.NewReconciliationService(holdingRepo, transactionRepo, assetRepo)
+
+	t.Run("lists assets whose holdings do not add up with the transactions", func(t *testing.T) {
+		report, err := service.Reconcile(ctx, "test-client", day(1), day(3), services.DefaultReconciliationTolerance)
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.DatesChecked)
+		require.Len(t, report.Discrepancies, 2)
+
+		assert.Equal(t, "AL30", report.Discrepancies[0].ExternalID)
+		assert.Equal(t, "Bono AL30", report.Discrepancies[0].AssetName)
+		assert.True(t, report.Discrepancies[0].Date.Equal(day(3)))
+		assert.Equal(t, 5.0, report.Discrepancies[0].ExpectedUnits)
+		assert.Equal(t, 8.0, report.Discrepancies[0].ActualUnits)
+		assert.Equal(t, 3.0, report.Discrepancies[0].Difference)
+
+		assert.Equal(t, "GD30", report.Discrepancies[1].ExternalID)
+		assert.Equal(t, 2.0, report.Discrepancies[1].ExpectedUnits)
+		assert.Equal(t, 0.0, report.Discrepancies[1].ActualUnits)
+	})
+
+	t.Run("ignores differences within the tolerance", func(t *testing.T) {
+		report, err := service.Reconcile(ctx, "test-client", day(1), day(3), 5)
+		require.NoError(t, err)
+		assert.Empty(t, report.Discrepancies)
+	})
+
+	t.Run("writes the discrepancies into an XLSX sheet", func(t *testing.T) {
+		report, err := service.Reconcile(ctx, "test-client", day(1), day(3), services.DefaultReconciliationTolerance)
+		require.NoError(t, err)
+
+		file, err := service.GenerateXLSXReconciliation(ctx, report)
+		require.NoError(t, err)
+		rows, err := file.GetRows("Conciliacion")
+		require.NoError(t, err)
+		require.Len(t, rows, 3)
+		assert.Equal(t, "AL30", rows[1][2])
+	})
+}
@@ -0,0 +1,145 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"server/src/clients/esco"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/schemas"
+	"server/src/services"
+	esco_test "server/tests/clients/esco"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeESCOPayloadRepository struct {
+	repositories.ESCOPayloadRepository
+	payloads []*models.ESCOPayload
+}
+
+func (r *fakeESCOPayloadRepository) GetLatestByAccount(_ context.Context, _ string, _ []string, _, _ time.Time) ([]*models.ESCOPayload, error) {
+	return r.payloads, nil
+}
+
+// fakeReplaceSyncService records the date ranges replaced through it
+type fakeReplaceSyncService struct {
+	services.SyncServiceI
+	replacedDates [][]time.Time
+	replacedState *schemas.AccountState
+}
+
+func (s *fakeReplaceSyncService) ReplaceAccountState(_ context.Context, _ string, accountState *schemas.AccountState, datesToSync []time.Time) error {
+	s.replacedDates = append(s.replacedDates, datesToSync)
+	s.replacedState = accountState
+	return nil
+}
+
+func TestReplayESCOPayloads(t *testing.T) {
+	ctx := context.Background()
+
+	workspaceRoot, err := os.Getwd()
+	require.NoError(t, err)
+	for {
+		if _, err := os.Stat(filepath.Join(workspaceRoot, "go.mod")); err == nil {
+			break
+		}
+		parent := filepath.Dir(workspaceRoot)
+		require.NotEqual(t, workspaceRoot, parent, "Could not find workspace root directory")
+		workspaceRoot = parent
+	}
+	mockClient, err := esco_test.NewMockClient(filepath.Join(workspaceRoot, "tests", "test_files", "clients", "esco"))
+	require.NoError(t, err)
+	escoService := services.NewESCOService(mockClient, nil)
+
+	var estado json.RawMessage
+	require.NoError(t, mockClient.ReadMockResponse("estado_cuenta_4014D4EFDD5DE27B_date_response.json", &estado))
+	var ctaCte json.RawMessage
+	require.NoError(t, mockClient.ReadMockResponse("cte_corriente_response.json", &ctaCte))
+
+	day := func(d int) *time.Time {
+		date := time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+	estadoHoldings, err := escoService.ParseExport(services.ESCOExportEstadoCuenta, estado, day(1))
+	require.NoError(t, err)
+	holdingsPerDay := 0
+	for _, asset := range *estadoHoldings.Assets {
+		holdingsPerDay += len(asset.Holdings)
+	}
+	require.NotZero(t, holdingsPerDay)
+
+	// Payloads come most recent first, the empty estado of day 1 is the latest fetch of that date
+	payloadRepository := &fakeESCOPayloadRepository{payloads: []*models.ESCOPayload{
+		{ID: 5, Endpoint: esco.EndpointGetEstadoCuenta, StartDate: day(1), EndDate: day(1), Payload: []byte("[]")},
+		{ID: 4, Endpoint: esco.EndpointGetCtaCteConsolidado, StartDate: day(1), EndDate: day(5), Payload: ctaCte},
+		{ID: 3, Endpoint: esco.EndpointGetEstadoCuenta, StartDate: day(4), EndDate: day(4), Payload: estado},
+		{ID: 2, Endpoint: esco.EndpointGetEstadoCuenta, StartDate: day(2), EndDate: day(2), Payload: estado},
+		{ID: 1, Endpoint: esco.EndpointGetEstadoCuenta, StartDate: day(1), EndDate: day(1), Payload: estado},
+	}}
+
+	t.Run("replaces every contiguous range of archived dates", func(t *testing.T) {
+		syncService := &fakeReplaceSyncService{}
+		service := services.NewReplayService(payloadRepository, escoService, syncService)
+
+		result, err := service.ReplayESCOPayloads(ctx, "test-client", *day(1), *day(5), false)
+		require.NoError(t, err)
+		assert.True(t, result.Stored)
+		assert.Equal(t, 5, result.Payloads)
+		assert.Equal(t, 2*holdingsPerDay, result.Holdings)
+		require.Len(t, result.Dates, 3)
+
+		require.Len(t, syncService.replacedDates, 2)
+		assert.Equal(t, []time.Time{*day(1), *day(2)}, syncService.replacedDates[0])
+		assert.Equal(t, []time.Time{*day(4)}, syncService.replacedDates[1])
+	})
+
+	t.Run("keeps the boleto of a trade over its cuenta corriente movement", func(t *testing.T) {
+		boletos := []byte(`[{"T": "Título Públicos", "I": "GD30 - BONOS REP. ARG. U$S STEP UP V.09/07/30", "F": "2024/01/02", "FL": "2024/01/02",
+			"O": "CompraSENEBING", "C": 100, "PR": 10, "B": -1000, "DB": -5, "DM": -1, "N": -1006, "N_S": "$"}]`)
+		movements := []byte(`[
+			{"FL": "2024/01/02", "I": "Pesos - $", "D": "Boleto / 9798 / Compra / 100 / GD30", "TI": "Instrumentos", "C": 100, "N": -1000},
+			{"FL": "2024/01/03", "I": "Pesos - $", "D": "Boleto / 9799 / Compra / 50 / GD30", "TI": "Instrumentos", "C": 50, "N": -500}
+		]`)
+		syncService := &fakeReplaceSyncService{}
+		service := services.NewReplayService(&fakeESCOPayloadRepository{payloads: []*models.ESCOPayload{
+			{ID: 3, Endpoint: esco.EndpointGetBoletos, StartDate: day(1), EndDate: day(5), Payload: boletos},
+			{ID: 2, Endpoint: esco.EndpointGetCtaCteConsolidado, StartDate: day(1), EndDate: day(5), Payload: movements},
+			{ID: 1, Endpoint: esco.EndpointGetEstadoCuenta, StartDate: day(2), EndDate: day(2), Payload: estado},
+		}}, escoService, syncService)
+
+		_, err := service.ReplayESCOPayloads(ctx, "test-client", *day(1), *day(5), false)
+		require.NoError(t, err)
+		require.NotNil(t, syncService.replacedState)
+
+		asset, ok := (*syncService.replacedState.Assets)["GD30"]
+		require.True(t, ok)
+		require.Len(t, asset.Transactions, 2)
+		for _, transaction := range asset.Transactions {
+			if transaction.Date.Day() == 2 {
+				// The boleto, with its price and fees
+				assert.Equal(t, 10.0, transaction.PricePerUnit)
+				assert.Equal(t, 5.0, transaction.ExchangeFees)
+			} else {
+				// Only in the cuenta corriente, so it is kept
+				assert.Equal(t, 50.0, transaction.Units)
+			}
+		}
+	})
+
+	t.Run("does not store anything on a dry run", func(t *testing.T) {
+		syncService := &fakeReplaceSyncService{}
+		service := services.NewReplayService(payloadRepository, escoService, syncService)
+
+		result, err := service.ReplayESCOPayloads(ctx, "test-client", *day(1), *day(5), true)
+		require.NoError(t, err)
+		assert.False(t, result.Stored)
+		assert.Len(t, result.Dates, 3)
+		assert.Empty(t, syncService.replacedDates)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"server/src/models"
@@ -316,6 +317,294 @@ func TestCalculateFinalIntervalReturn(t *testing.T) {
 	assert.InDelta(t, expectedReturn, finalReturn, 0.001)
 }
 
+func TestCalculateMoneyWeightedReturn(t *testing.T) {
+	service := &services.ReportService{}
+	startDate := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	depositDate := time.Date(2023, 7, 2, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	asset := schemas.Asset{
+		ID:       "1",
+		Category: "STOCKS",
+		Holdings: []schemas.Holding{
+			{DateRequested: &startDate, Value: 1000.0, Units: 10},
+			{DateRequested: &endDate, Value: 1100.0, Units: 10},
+		},
+	}
+
+	// Without cash flows the money-weighted return is the growth of the value
+	periodReturn, annualizedReturn, err := service.CalculateMoneyWeightedReturn([]schemas.Asset{asset}, startDate, endDate)
+	require.NoError(t, err)
+	assert.InDelta(t, 10.0, periodReturn, 0.0001)
+	assert.InDelta(t, 10.0, annualizedReturn, 0.0001)
+
+	// A deposit halfway weighs in for the part of the period it was invested
+	asset.Holdings = []schemas.Holding{
+		{DateRequested: &startDate, Value: 1000.0, Units: 10},
+		{DateRequested: &depositDate, Value: 2000.0, Units: 20},
+		{DateRequested: &endDate, Value: 2100.0, Units: 20},
+	}
+	asset.Transactions = []schemas.Transaction{
+		{Date: &depositDate, Value: 1000.0, Units: 10},
+	}
+	periodReturn, _, err = service.CalculateMoneyWeightedReturn([]schemas.Asset{asset}, startDate, endDate)
+	require.NoError(t, err)
+	rate := periodReturn / 100
+	elapsed := depositDate.Sub(startDate).Hours() / endDate.Sub(startDate).Hours()
+	assert.InDelta(t, 2100.0, 1000*(1+rate)+1000*math.Pow(1+rate, 1-elapsed), 0.001)
+	assert.Greater(t, periodReturn, 0.0)
+	assert.Less(t, periodReturn, 10.0)
+
+	// Without value at the start there is no money invested to calculate a return
+	asset.Holdings = nil
+	asset.Transactions = nil
+	_, _, err = service.CalculateMoneyWeightedReturn([]schemas.Asset{asset}, startDate, endDate)
+	assert.Error(t, err)
+}
+
+func TestCalculateRealReturns(t *testing.T) {
+	service := &services.ReportService{}
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+
+	assetReturnsByCategory := map[string][]schemas.AssetReturn{
+		"STOCKS": {{
+			ID:       "GGAL",
+			Category: "STOCKS",
+			ReturnsByDateRange: []schemas.ReturnByDate{
+				{StartDate: day(3, 31), EndDate: day(4, 15), ReturnPercentage: 10},
+			},
+		}},
+	}
+	categoryAssetsReturn := map[string]schemas.AssetReturn{
+		"STOCKS": {ID: "STOCKS", ReturnsByDateRange: assetReturnsByCategory["STOCKS"][0].ReturnsByDateRange},
+	}
+	accountsReport := &schemas.AccountsReports{
+		AssetsReturnByCategory: &assetReturnsByCategory,
+		CategoryAssetsReturn:   &categoryAssetsReturn,
+		TotalReturns: []schemas.ReturnByDate{
+			{StartDate: day(3, 31), EndDate: day(4, 15), ReturnPercentage: 10},
+			{StartDate: day(4, 15), EndDate: day(4, 30), ReturnPercentage: 0},
+		},
+	}
+	// Monthly inflation is published at the end of each month
+	inflation := &schemas.VariableWithValuationResponse{
+		Valuations: []schemas.VariableValuation{
+			{Date: "2024-03-31", Value: 11},
+			{Date: "2024-04-30", Value: 8.8},
+		},
+	}
+
+	service.CalculateRealReturns(accountsReport, inflation)
+
+	// Half of April deflates by half of its inflation, compounded daily
+	halfMonthInflation := math.Pow(1.088, 15.0/30)
+	require.Len(t, accountsReport.TotalRealReturns, 2)
+	assert.InDelta(t, (1.1/halfMonthInflation-1)*100, accountsReport.TotalRealReturns[0].ReturnPercentage, 0.0001)
+	assert.InDelta(t, (1/halfMonthInflation-1)*100, accountsReport.TotalRealReturns[1].ReturnPercentage, 0.0001)
+	assert.InDelta(t, (1.1/1.088-1)*100, accountsReport.RealReturn, 0.0001)
+
+	assetReturn := (*accountsReport.AssetsReturnByCategory)["STOCKS"][0]
+	require.Len(t, assetReturn.RealReturnsByDateRange, 1)
+	assert.InDelta(t, (1.1/halfMonthInflation-1)*100, assetReturn.RealReturn, 0.0001)
+	assert.InDelta(t, (1.1/halfMonthInflation-1)*100, (*accountsReport.CategoryAssetsReturn)["STOCKS"].RealReturn, 0.0001)
+
+	// Without inflation the report is left unchanged
+	accountsReport.TotalRealReturns = nil
+	service.CalculateRealReturns(accountsReport, nil)
+	assert.Nil(t, accountsReport.TotalRealReturns)
+}
+
+func TestCalculateBenchmarks(t *testing.T) {
+	service := &services.ReportService{}
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, time.UTC) }
+
+	accountsReport := &schemas.AccountsReports{
+		TotalReturns: []schemas.ReturnByDate{
+			{StartDate: day(1, 1), EndDate: day(1, 2), ReturnPercentage: 2},
+			{StartDate: day(1, 2), EndDate: day(1, 3), ReturnPercentage: -1},
+			{StartDate: day(1, 3), EndDate: day(1, 5), ReturnPercentage: 3},
+		},
+	}
+	benchmarks := []schemas.BenchmarkSeries{
+		// Levels carry to the days without one, and the report starts with the last level before it
+		{ID: "5", Name: "USD", Kind: services.BenchmarkKindLevel, Valuations: []schemas.VariableValuation{
+			{Date: "2023-12-31", Value: 100},
+			{Date: "2024-01-02", Value: 101},
+			{Date: "2024-01-05", Value: 102.01},
+		}},
+		// A nominal annual rate of 36.5% accrues 0.1% a day
+		{ID: "PLAZO FIJO", Name: "PLAZO FIJO", Kind: services.BenchmarkKindAnnual, Valuations: []schemas.VariableValuation{
+			{Date: "2023-12-29", Value: 36.5},
+		}},
+		// Benchmarks without values are left out
+		{ID: "EMPTY", Name: "EMPTY", Kind: services.BenchmarkKindLevel},
+	}
+
+	service.CalculateBenchmarks(accountsReport, benchmarks)
+
+	require.Len(t, accountsReport.Benchmarks, 2)
+	usd := accountsReport.Benchmarks[0]
+	require.Len(t, usd.Returns, 3)
+	assert.InDelta(t, 1, usd.Returns[0].ReturnPercentage, 0.0001)
+	assert.InDelta(t, 0, usd.Returns[1].ReturnPercentage, 0.0001)
+	assert.InDelta(t, 1, usd.Returns[2].ReturnPercentage, 0.0001)
+	assert.InDelta(t, 2.01, usd.CumulativeReturn, 0.0001)
+	assert.Equal(t, day(1, 1), usd.CumulativeReturns[2].StartDate)
+
+	assert.InDelta(t, 1, usd.ExcessReturns[0].ReturnPercentage, 0.0001)
+	assert.InDelta(t, -1, usd.ExcessReturns[1].ReturnPercentage, 0.0001)
+	assert.InDelta(t, 2, usd.ExcessReturns[2].ReturnPercentage, 0.0001)
+	assert.InDelta(t, (1.02*0.99-1.01)*100, usd.CumulativeExcessReturns[1].ReturnPercentage, 0.0001)
+	assert.InDelta(t, (1.02*0.99*1.03-1.0201)*100, usd.CumulativeExcessReturn, 0.0001)
+	assert.InDelta(t, math.Sqrt(7.0/3), usd.TrackingError, 0.0001)
+	assert.InDelta(t, 200.0/3, usd.HitRatio, 0.0001)
+
+	fixedTerm := accountsReport.Benchmarks[1]
+	assert.InDelta(t, 0.1, fixedTerm.Returns[0].ReturnPercentage, 0.0001)
+	assert.InDelta(t, (math.Pow(1.001, 4)-1)*100, fixedTerm.CumulativeReturn, 0.0001)
+	assert.InDelta(t, 200.0/3, fixedTerm.HitRatio, 0.0001)
+}
+
+func TestCalculateRiskMetrics(t *testing.T) {
+	service := &services.ReportService{}
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	dailyReturns := []schemas.ReturnByDate{
+		{StartDate: day(1), EndDate: day(2), ReturnPercentage: 10},
+		{StartDate: day(2), EndDate: day(3), ReturnPercentage: -20},
+		{StartDate: day(3), EndDate: day(4), ReturnPercentage: 5},
+		{StartDate: day(4), EndDate: day(5), ReturnPercentage: 10},
+	}
+	periodReturns := []schemas.ReturnByDate{
+		{StartDate: day(1), EndDate: day(3), ReturnPercentage: -12},
+		{StartDate: day(3), EndDate: day(5), ReturnPercentage: 15.5},
+	}
+	assetReturnsByCategory := map[string][]schemas.AssetReturn{
+		"STOCKS": {{ID: "GGAL", Category: "STOCKS", ReturnsByDateRange: periodReturns, DailyReturns: dailyReturns}},
+	}
+	categoryAssetsReturn := map[string]schemas.AssetReturn{
+		"STOCKS": {ID: "STOCKS", ReturnsByDateRange: periodReturns},
+	}
+	accountsReport := &schemas.AccountsReports{
+		AssetsReturnByCategory: &assetReturnsByCategory,
+		CategoryAssetsReturn:   &categoryAssetsReturn,
+		TotalReturns:           periodReturns,
+		TotalDailyReturns:      dailyReturns,
+	}
+	// A nominal annual rate of 36.5% accrues 0.1% a day
+	riskFreeRates := []schemas.VariableValuation{{Date: "2023-12-29", Value: 36.5}}
+
+	service.CalculateRiskMetrics(accountsReport, riskFreeRates)
+
+	risk := accountsReport.Risk
+	assert.InDelta(t, math.Sqrt(206.25)*math.Sqrt(365), risk.Volatility, 0.0001)
+	assert.InDelta(t, -20, risk.MaxDrawdown, 0.0001)
+	require.NotNil(t, risk.MaxDrawdownPeak)
+	require.NotNil(t, risk.MaxDrawdownTrough)
+	assert.Equal(t, day(2), *risk.MaxDrawdownPeak)
+	assert.Equal(t, day(3), *risk.MaxDrawdownTrough)
+	assert.InDelta(t, 1.15/math.Sqrt(206.25)*math.Sqrt(365), risk.SharpeRatio, 0.0001)
+	assert.InDelta(t, 1.15/10.05*math.Sqrt(365), risk.SortinoRatio, 0.0001)
+	require.NotNil(t, risk.BestPeriod)
+	require.NotNil(t, risk.WorstPeriod)
+	assert.Equal(t, day(5), risk.BestPeriod.EndDate)
+	assert.Equal(t, day(3), risk.WorstPeriod.EndDate)
+
+	assert.Equal(t, risk, (*accountsReport.AssetsReturnByCategory)["STOCKS"][0].Risk)
+
+	// Without daily returns only the best and worst periods are known
+	categoryRisk := (*accountsReport.CategoryAssetsReturn)["STOCKS"].Risk
+	assert.Zero(t, categoryRisk.Volatility)
+	assert.Nil(t, categoryRisk.MaxDrawdownPeak)
+	require.NotNil(t, categoryRisk.BestPeriod)
+	assert.InDelta(t, 15.5, categoryRisk.BestPeriod.ReturnPercentage, 0.0001)
+
+	// Without risk-free rate the ratios measure the returns themselves
+	service.CalculateRiskMetrics(accountsReport, nil)
+	assert.InDelta(t, 1.25/math.Sqrt(206.25)*math.Sqrt(365), accountsReport.Risk.SharpeRatio, 0.0001)
+}
+
+func TestCalculateAttribution(t *testing.T) {
+	service := &services.ReportService{}
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	categoryAssetsReturn := map[string]schemas.AssetReturn{
+		"STOCKS": {ID: "STOCKS", ContributionsByDateRange: []schemas.ContributionByDate{
+			{StartDate: day(1), EndDate: day(2), Weight: 0.6, ReturnPercentage: 5, Contribution: 3},
+			{StartDate: day(2), EndDate: day(3), Weight: 0.5, ReturnPercentage: -2, Contribution: -1},
+		}},
+		"BONDS": {ID: "BONDS", ContributionsByDateRange: []schemas.ContributionByDate{
+			{StartDate: day(1), EndDate: day(2), Weight: 0.4, ReturnPercentage: 1, Contribution: 0.4},
+			{StartDate: day(2), EndDate: day(3), Weight: 0.5, ReturnPercentage: 2, Contribution: 1},
+		}},
+	}
+	accountsReport := &schemas.AccountsReports{
+		CategoryAssetsReturn: &categoryAssetsReturn,
+		TotalReturns: []schemas.ReturnByDate{
+			{StartDate: day(1), EndDate: day(2), ReturnPercentage: 3.4},
+			{StartDate: day(2), EndDate: day(3), ReturnPercentage: 0},
+		},
+	}
+	target := &schemas.TargetAllocationResponse{
+		Name: "MODERADO",
+		Categories: []schemas.TargetAllocationCategory{
+			{Category: "STOCKS", Weight: 50},
+			{Category: "BONDS", Weight: 30, Benchmark: "BONOS", BenchmarkKind: services.BenchmarkKindLevel},
+			{Category: "CASH", Weight: 20, Benchmark: "PLAZO FIJO", BenchmarkKind: services.BenchmarkKindAnnual},
+		},
+	}
+	categoryBenchmarks := map[string]schemas.BenchmarkSeries{
+		"BONDS": {ID: "BONOS", Name: "BONOS", Kind: services.BenchmarkKindLevel, Valuations: []schemas.VariableValuation{
+			{Date: "2023-12-31", Value: 100},
+			{Date: "2024-01-02", Value: 102},
+		}},
+		// A nominal annual rate of 36.5% accrues 0.1% a day
+		"CASH": {ID: "PLAZO FIJO", Name: "PLAZO FIJO", Kind: services.BenchmarkKindAnnual, Valuations: []schemas.VariableValuation{
+			{Date: "2023-12-29", Value: 36.5},
+		}},
+	}
+
+	service.CalculateAttribution(accountsReport, target, categoryBenchmarks)
+
+	attribution := accountsReport.Attribution
+	require.NotNil(t, attribution)
+	assert.Equal(t, "MODERADO", attribution.TargetAllocation)
+	require.Len(t, attribution.Periods, 2)
+
+	// Categories without benchmark take their own return as target
+	first := attribution.Periods[0]
+	assert.InDelta(t, 0.5*5+0.3*2+0.2*0.1, first.TargetReturn, 0.0001)
+	require.Len(t, first.Categories, 3)
+	bonds, cash, stocks := first.Categories[0], first.Categories[1], first.Categories[2]
+	assert.Equal(t, "BONDS", bonds.Category)
+	assert.InDelta(t, 0.1*(2-3.12), bonds.Allocation, 0.0001)
+	assert.InDelta(t, 0.3*(1-2), bonds.Selection, 0.0001)
+	assert.InDelta(t, 0.1*(1-2), bonds.Interaction, 0.0001)
+	// Categories the portfolio does not hold only weigh in through the allocation effect
+	assert.Equal(t, "CASH", cash.Category)
+	assert.InDelta(t, -0.2*(0.1-3.12), cash.Allocation, 0.0001)
+	assert.Zero(t, cash.Selection)
+	assert.Zero(t, cash.Interaction)
+	assert.Equal(t, "STOCKS", stocks.Category)
+	assert.InDelta(t, 0.1*(5-3.12), stocks.Allocation, 0.0001)
+	assert.Zero(t, stocks.Selection)
+
+	// The effects of every period add up to the difference with the target return
+	for _, period := range attribution.Periods {
+		var effects float64
+		for _, category := range period.Categories {
+			effects += category.Allocation + category.Selection + category.Interaction
+		}
+		assert.InDelta(t, period.PortfolioReturn-period.TargetReturn, effects, 0.0001)
+	}
+
+	require.Len(t, attribution.Categories, 3)
+	totalStocks := attribution.Categories[2]
+	assert.InDelta(t, 0.55, totalStocks.PortfolioWeight, 0.0001)
+	assert.InDelta(t, 0.5, totalStocks.TargetWeight, 0.0001)
+	assert.InDelta(t, (1.05*0.98-1)*100, totalStocks.PortfolioReturn, 0.0001)
+}
+
 func TestCollapseReturnsByInterval(t *testing.T) {
 	service := &services.ReportService{}
 
@@ -0,0 +1,131 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"server/src/models"
+	"server/src/schemas"
+	"server/src/services"
+	"server/src/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestImportSpreadsheetDryRun(t *testing.T) {
+	ctx := context.Background()
+	service := services.NewSpreadsheetImportService(nil, nil, nil, nil, nil, &fakeSyncService{sources: []string{"synced-broker"}})
+
+	t.Run("validates the holdings of a csv and reports invalid rows", func(t *testing.T) {
+		data := []byte("Fecha;Especie;Cantidad;Valuación\n" +
+			"15/01/2024;GGAL;10;1.234,50\n" +
+			"15/01/2024;GGAL;5;100,50\n" +
+			"\n" +
+			"16/01/2024;AL30;abc;10\n" +
+			"2024-01-17;;1;10\n")
+		mapping := &schemas.SpreadsheetMapping{
+			Kind:             services.SpreadsheetHoldings,
+			Delimiter:        ";",
+			DateFormat:       "02/01/2006",
+			DecimalSeparator: ",",
+			Columns: map[string]string{
+				"date":    "Fecha",
+				"assetID": "Especie",
+				"units":   "Cantidad",
+				"value":   "Valuación",
+			},
+		}
+
+		response, err := service.ImportSpreadsheet(ctx, "test-client", "", services.SpreadsheetCSV, data, mapping, true)
+		require.NoError(t, err)
+		assert.Equal(t, models.SourceSpreadsheet, response.Source)
+		assert.True(t, response.DryRun)
+		assert.Equal(t, 4, response.Rows)
+		assert.Equal(t, 2, response.ImportedRows)
+		assert.Equal(t, 1, response.Assets)
+		assert.Equal(t, 1, response.Holdings, "holdings of the same asset and date are added up")
+		require.Len(t, response.Dates, 1)
+		assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), response.Dates[0].Time)
+
+		require.Len(t, response.Errors, 3)
+		assert.Equal(t, schemas.SpreadsheetRowError{Row: 5, Column: "Cantidad", Message: "invalid number abc"}, response.Errors[0])
+		assert.Equal(t, schemas.SpreadsheetRowError{Row: 6, Column: "Especie", Message: "asset id is required"}, response.Errors[1])
+		assert.Equal(t, 6, response.Errors[2].Row)
+		assert.Equal(t, "Fecha", response.Errors[2].Column)
+	})
+
+	t.Run("validates the transactions of an xlsx", func(t *testing.T) {
+		file := excelize.NewFile()
+		defer file.Close()
+		rows := [][]interface{}{
+			{"Movimientos"},
+			{"Fecha", "Ticker", "Operación", "Cantidad", "Precio", "Importe"},
+			{time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), "GGAL", "Compra", 10, 123.45, -1234.5},
+			{"2024-01-16", "GGAL", "Venta", 5, 130, 650},
+			{"2024-01-16", "AL30", "Canje", 1, 1, 1},
+		}
+		for i, row := range rows {
+			cell, err := excelize.CoordinatesToCellName(1, i+1)
+			require.NoError(t, err)
+			require.NoError(t, file.SetSheetRow("Sheet1", cell, &row))
+		}
+		var buffer bytes.Buffer
+		require.NoError(t, file.Write(&buffer))
+
+		mapping := &schemas.SpreadsheetMapping{
+			Kind:      services.SpreadsheetTransactions,
+			HeaderRow: 2,
+			Columns: map[string]string{
+				"date":    "Fecha",
+				"assetID": "Ticker",
+				"type":    "Operación",
+				"units":   "Cantidad",
+				"price":   "Precio",
+				"value":   "Importe",
+			},
+			TransactionTypes: map[string]string{
+				"Compra": models.TransactionTypeBuy,
+				"Venta":  models.TransactionTypeSell,
+			},
+		}
+
+		response, err := service.ImportSpreadsheet(ctx, "test-client", "other-broker", services.SpreadsheetXLSX, buffer.Bytes(), mapping, true)
+		require.NoError(t, err)
+		assert.Equal(t, "other-broker", response.Source)
+		assert.Equal(t, 3, response.Rows)
+		assert.Equal(t, 2, response.ImportedRows)
+		assert.Equal(t, 2, response.Transactions)
+		require.Len(t, response.Dates, 2)
+		assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), response.Dates[0].Time)
+		assert.Equal(t, []schemas.SpreadsheetRowError{{Row: 5, Column: "Operación", Message: "unknown transaction type Canje"}}, response.Errors)
+	})
+
+	t.Run("rejects mappings without the required columns", func(t *testing.T) {
+		mapping := &schemas.SpreadsheetMapping{
+			Kind:    services.SpreadsheetHoldings,
+			Columns: map[string]string{"date": "Fecha", "assetID": "Especie", "units": "Cantidad"},
+		}
+
+		_, err := service.ImportSpreadsheet(ctx, "test-client", "", services.SpreadsheetCSV, []byte("Fecha,Especie,Cantidad\n"), mapping, true)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects the sources of synced brokers and manual assets", func(t *testing.T) {
+		mapping := &schemas.SpreadsheetMapping{
+			Kind:    services.SpreadsheetHoldings,
+			Columns: map[string]string{"date": "Fecha", "assetID": "Especie", "units": "Cantidad", "value": "Valuación"},
+		}
+		data := []byte("Fecha;Especie;Cantidad;Valuación\n15/01/2024;GGAL;10;1.234,50\n")
+
+		for _, source := range []string{models.SourceESCO, models.SourceManual, "synced-broker"} {
+			_, err := service.ImportSpreadsheet(ctx, "test-client", source, services.SpreadsheetCSV, data, mapping, true)
+			var httpErr *utils.HTTPError
+			require.ErrorAs(t, err, &httpErr, "source %s", source)
+			assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+		}
+	})
+}
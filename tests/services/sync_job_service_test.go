@@ -0,0 +1,89 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"server/src/models"
+	"server/src/repositories"
+	"server/src/services"
+	"server/src/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSyncJobRepository keeps the created sync jobs in memory
+type fakeSyncJobRepository struct {
+	repositories.SyncJobRepository
+	created []*models.SyncJob
+}
+
+func (r *fakeSyncJobRepository) Create(_ context.Context, job *models.SyncJob) error {
+	job.ID = len(r.created) + 1
+	r.created = append(r.created, job)
+	return nil
+}
+
+// fakeTokenManager hands out a fixed token when configured
+type fakeTokenManager struct {
+	configured bool
+}
+
+func (m *fakeTokenManager) GetToken(_ context.Context) (string, error) {
+	if !m.configured {
+		return "", services.ErrServiceAccountNotConfigured
+	}
+	return "service-account-token", nil
+}
+
+func (m *fakeTokenManager) Configured() bool {
+	return m.configured
+}
+
+func TestSubmitSyncJob(t *testing.T) {
+	ctx := context.Background()
+	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Queues a pending job", func(t *testing.T) {
+		repo := &fakeSyncJobRepository{}
+		service := services.NewSyncJobService(repo, &fakeSyncService{}, &fakeTokenManager{configured: true}, 0, 0)
+
+		job, err := service.SubmitSyncJob(ctx, "123", startDate, endDate, true)
+		require.NoError(t, err)
+		require.Len(t, repo.created, 1)
+		assert.Equal(t, models.SyncJobPending, job.Status)
+		assert.True(t, job.Force)
+	})
+
+	t.Run("Syncs a single day when the end date equals the start date", func(t *testing.T) {
+		repo := &fakeSyncJobRepository{}
+		service := services.NewSyncJobService(repo, &fakeSyncService{}, &fakeTokenManager{configured: true}, 0, 0)
+
+		job, err := service.SubmitSyncJob(ctx, "123", startDate, startDate, false)
+		require.NoError(t, err)
+		assert.Equal(t, startDate, job.StartDate)
+		assert.Equal(t, startDate.AddDate(0, 0, 1), job.EndDate)
+	})
+
+	t.Run("Rejects jobs without a service account", func(t *testing.T) {
+		for name, tokenManager := range map[string]services.ESCOTokenManagerI{
+			"no token manager":       nil,
+			"no service account set": &fakeTokenManager{},
+		} {
+			t.Run(name, func(t *testing.T) {
+				repo := &fakeSyncJobRepository{}
+				service := services.NewSyncJobService(repo, &fakeSyncService{}, tokenManager, 0, 0)
+
+				_, err := service.SubmitSyncJob(ctx, "123", startDate, endDate, false)
+				require.Error(t, err)
+				httpErr, ok := err.(*utils.HTTPError)
+				require.True(t, ok)
+				assert.Equal(t, http.StatusServiceUnavailable, httpErr.Code)
+				assert.Empty(t, repo.created)
+			})
+		}
+	})
+}
@@ -26,6 +26,7 @@ type testSyncService struct {
 func setupTest(t *testing.T, holdingRepo repositories.HoldingRepository, transactionRepo repositories.TransactionRepository, assetRepo repositories.AssetRepository, assetCategoryRepo repositories.AssetCategoryRepository) (*testSyncService, *esco_test.ESCOServiceClientMock) {
 	db := init_test.SetupTestDB(t)
 	syncLogRepo := repositories.NewSyncLogRepository(db)
+	syncRunRepo := repositories.NewSyncRunRepository(db)
 
 	// Setup mock ESCO service
 	workspaceRoot, err := os.Getwd()
@@ -50,14 +51,16 @@ func setupTest(t *testing.T, holdingRepo repositories.HoldingRepository, transac
 		t.Fatalf("Failed to create mock client: %v", err)
 	}
 
-	escoService := services.NewESCOService(mockClient)
+	escoService := services.NewESCOService(mockClient, nil)
 	service := services.NewSyncService(
+		db,
 		holdingRepo,
 		transactionRepo,
 		assetRepo,
 		assetCategoryRepo,
 		syncLogRepo,
-		escoService,
+		syncRunRepo,
+		services.NewESCOBrokerSource(escoService),
 	)
 
 	return &testSyncService{
@@ -76,6 +79,7 @@ func TestSyncDataFromAccount(t *testing.T) {
 	assetRepo := repositories.NewAssetRepository(db)
 	assetCategoryRepo := repositories.NewAssetCategoryRepository(db)
 	syncLogRepo := repositories.NewSyncLogRepository(db)
+	syncRunRepo := repositories.NewSyncRunRepository(db)
 
 	ctx := context.Background()
 	token := "test-token"
@@ -98,12 +102,14 @@ func TestSyncDataFromAccount(t *testing.T) {
 
 		// Initialize service with mocked dependencies
 		service := services.NewSyncService(
+			db,
 			holdingRepo,
 			transactionRepo,
 			assetRepo,
 			assetCategoryRepo,
 			syncLogRepo,
-			mockESCO,
+			syncRunRepo,
+			services.NewESCOBrokerSource(mockESCO),
 		)
 
 		// Execute sync
@@ -159,7 +165,7 @@ func TestSyncDataFromAccount(t *testing.T) {
 		assert.Len(t, transactions, 0) // No transactions in 2024 date range in mock data
 
 		// Verify sync logs were created
-		syncedDates, err := syncLogRepo.GetSyncedDates(ctx, accountID, startDate, endDate)
+		syncedDates, err := syncLogRepo.GetSyncedDates(ctx, accountID, models.SourceESCO, startDate, endDate)
 		require.NoError(t, err)
 		assert.Len(t, syncedDates, 19) // Should have all dates from start to end
 	})
@@ -178,12 +184,12 @@ func TestSyncDataFromAccount(t *testing.T) {
 		for date := startDate; date.Before(endDate); date = date.AddDate(0, 0, 1) {
 			dates = append(dates, date)
 		}
-		err := syncLogRepo.MarkClientForDates(ctx, accountID, dates)
+		err := syncLogRepo.MarkClientForDates(ctx, accountID, models.SourceESCO, dates, nil)
 		require.NoError(t, err)
 
 		// Cleanup sync logs after test
 		defer func() {
-			err = syncLogRepo.CleanupSyncLogs(ctx, accountID, startDate, endDate)
+			err = syncLogRepo.CleanupSyncLogs(ctx, accountID, models.SourceESCO, startDate, endDate, nil)
 			require.NoError(t, err)
 		}()
 
@@ -195,12 +201,14 @@ func TestSyncDataFromAccount(t *testing.T) {
 
 		// Initialize service with mocked dependencies
 		service := services.NewSyncService(
+			db,
 			holdingRepo,
 			transactionRepo,
 			assetRepo,
 			assetCategoryRepo,
 			syncLogRepo,
-			mockESCO,
+			syncRunRepo,
+			services.NewESCOBrokerSource(mockESCO),
 		)
 
 		// Execute sync
@@ -219,6 +227,7 @@ func TestStoreAccountStateWithDateFiltering(t *testing.T) {
 	assetRepo := repositories.NewAssetRepository(db)
 	assetCategoryRepo := repositories.NewAssetCategoryRepository(db)
 	syncLogRepo := repositories.NewSyncLogRepository(db)
+	syncRunRepo := repositories.NewSyncRunRepository(db)
 
 	// Setup mock ESCO service
 	mockESCO := esco_test.NewMockESCOService(func(ctx context.Context, token, accountID string, startDate, endDate time.Time, interval time.Duration) (*schemas.AccountState, error) {
@@ -226,12 +235,14 @@ func TestStoreAccountStateWithDateFiltering(t *testing.T) {
 	})
 
 	service := services.NewSyncService(
+		db,
 		holdingRepo,
 		transactionRepo,
 		assetRepo,
 		assetCategoryRepo,
 		syncLogRepo,
-		mockESCO,
+		syncRunRepo,
+		services.NewESCOBrokerSource(mockESCO),
 	)
 
 	ctx := context.Background()
@@ -374,4 +385,242 @@ func TestStoreAccountStateWithDateFiltering(t *testing.T) {
 		require.NoError(t, err)
 		assert.Len(t, transactions, 1, "Should only have 1 transaction with valid date")
 	})
+
+	t.Run("storing the same state twice leaves the same rows", func(t *testing.T) {
+		accountID := fmt.Sprintf("test-account-4-%d", time.Now().UnixNano())
+
+		// Cleanup after test
+		defer func() {
+			init_test.CleanupTestData(t, db, accountID)
+		}()
+
+		date1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		date2 := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+		newAccountState := func() *schemas.AccountState {
+			return &schemas.AccountState{
+				Assets: &map[string]schemas.Asset{
+					"asset4": {
+						ID:           "asset4",
+						Category:     "Test Category",
+						Type:         "STOCK",
+						Denomination: "USD",
+						Holdings: []schemas.Holding{
+							{DateRequested: &date1, Value: 100.0, Units: 10},
+							{DateRequested: &date2, Value: 110.0, Units: 10},
+						},
+						Transactions: []schemas.Transaction{
+							{Date: &date1, Value: 10.0, Units: 1},
+							{Date: &date1, Value: 5.0, Units: 0.5},
+							{Date: &date2, Value: 11.0, Units: 1},
+						},
+					},
+				},
+			}
+		}
+
+		for i := 0; i < 2; i++ {
+			err := service.StoreAccountState(ctx, accountID, newAccountState(), []time.Time{date1, date2})
+			require.NoError(t, err)
+		}
+
+		holdings, err := holdingRepo.GetByClientID(ctx, accountID, date1, date2)
+		require.NoError(t, err)
+		assert.Len(t, holdings, 2)
+
+		transactions, err := transactionRepo.GetByClientID(ctx, accountID, date1, date2)
+		require.NoError(t, err)
+		require.Len(t, transactions, 2, "Same-day transactions should be stored as a single row")
+		for _, transaction := range transactions {
+			if transaction.Date.Equal(date1) {
+				assert.Equal(t, 15.0, transaction.TotalValue)
+				assert.Equal(t, 1.5, transaction.Units)
+			}
+		}
+	})
+
+	t.Run("an empty state marks the dates as synced and clears them on replace", func(t *testing.T) {
+		accountID := fmt.Sprintf("test-account-5-%d", time.Now().UnixNano())
+
+		// Cleanup after test
+		defer func() {
+			init_test.CleanupTestData(t, db, accountID)
+		}()
+
+		date1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		date2 := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+		accountState := &schemas.AccountState{
+			Assets: &map[string]schemas.Asset{
+				"asset5": {
+					ID:           "asset5",
+					Category:     "Test Category",
+					Type:         "STOCK",
+					Denomination: "USD",
+					Holdings: []schemas.Holding{
+						{DateRequested: &date1, Value: 100.0, Units: 10},
+					},
+				},
+			},
+		}
+		require.NoError(t, service.StoreAccountState(ctx, accountID, accountState, []time.Time{date1}))
+
+		// The account no longer holds anything on either date
+		require.NoError(t, service.ReplaceAccountState(ctx, accountID, schemas.NewAccountState(), []time.Time{date1, date2}))
+
+		holdings, err := holdingRepo.GetByClientID(ctx, accountID, date1, date2)
+		require.NoError(t, err)
+		assert.Empty(t, holdings)
+
+		syncedDates, err := syncLogRepo.GetSyncedDates(ctx, accountID, models.SourceESCO, date1, date2.AddDate(0, 0, 1))
+		require.NoError(t, err)
+		assert.Len(t, syncedDates, 2)
+	})
+}
+
+func TestForceSyncDataFromAccount(t *testing.T) {
+	// Setup test database connection
+	db := init_test.SetupTestDB(t)
+
+	// Create repository instances
+	holdingRepo := repositories.NewHoldingRepository(db)
+	transactionRepo := repositories.NewTransactionRepository(db)
+	assetRepo := repositories.NewAssetRepository(db)
+	assetCategoryRepo := repositories.NewAssetCategoryRepository(db)
+	syncLogRepo := repositories.NewSyncLogRepository(db)
+	syncRunRepo := repositories.NewSyncRunRepository(db)
+
+	ctx := context.Background()
+	accountID := fmt.Sprintf("test-account-force-%d", time.Now().UnixNano())
+	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	date1 := startDate
+	date2 := startDate.AddDate(0, 0, 1)
+
+	defer func() {
+		init_test.CleanupTestData(t, db, accountID)
+		_ = syncLogRepo.CleanupSyncLogs(ctx, accountID, models.SourceESCO, startDate, endDate, nil)
+	}()
+
+	// The first fetch returns two assets, the corrected one only keeps the first with new values
+	fetches := 0
+	mockESCO := esco_test.NewMockESCOService(func(ctx context.Context, token, accountID string, startDate, endDate time.Time, interval time.Duration) (*schemas.AccountState, error) {
+		fetches++
+		assets := map[string]schemas.Asset{
+			"asset-force-1": {
+				ID:           "asset-force-1",
+				Category:     "Test Category",
+				Type:         "STOCK",
+				Denomination: "USD",
+				Holdings: []schemas.Holding{
+					{DateRequested: &date1, Value: 100.0 * float64(fetches), Units: 10},
+					{DateRequested: &date2, Value: 110.0 * float64(fetches), Units: 10},
+				},
+			},
+		}
+		if fetches == 1 {
+			assets["asset-force-2"] = schemas.Asset{
+				ID:           "asset-force-2",
+				Category:     "Test Category",
+				Type:         "STOCK",
+				Denomination: "USD",
+				Holdings: []schemas.Holding{
+					{DateRequested: &date1, Value: 50.0, Units: 5},
+				},
+				Transactions: []schemas.Transaction{
+					{Date: &date1, Value: 5.0, Units: 1},
+				},
+			}
+		}
+		return &schemas.AccountState{Assets: &assets}, nil
+	})
+
+	service := services.NewSyncService(
+		db,
+		holdingRepo,
+		transactionRepo,
+		assetRepo,
+		assetCategoryRepo,
+		syncLogRepo,
+		syncRunRepo,
+		services.NewESCOBrokerSource(mockESCO),
+	)
+
+	err := service.SyncDataFromAccount(ctx, "test-token", accountID, startDate, endDate)
+	require.NoError(t, err)
+
+	// A regular sync skips the dates already synced
+	err = service.SyncDataFromAccount(ctx, "test-token", accountID, startDate, endDate)
+	require.NoError(t, err)
+	assert.Equal(t, 1, fetches)
+
+	err = service.ForceSyncDataFromAccount(ctx, "test-token", accountID, startDate, endDate)
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetches)
+
+	holdings, err := holdingRepo.GetByClientID(ctx, accountID, date1, date2)
+	require.NoError(t, err)
+	require.Len(t, holdings, 2, "Holdings of the asset missing from the corrected data should be deleted")
+	for _, holding := range holdings {
+		if holding.Date.Equal(date1) {
+			assert.Equal(t, 200.0, holding.Value)
+		} else {
+			assert.Equal(t, 220.0, holding.Value)
+		}
+	}
+
+	transactions, err := transactionRepo.GetByClientID(ctx, accountID, date1, date2)
+	require.NoError(t, err)
+	assert.Len(t, transactions, 0)
+
+	syncedDates, err := syncLogRepo.GetSyncedDates(ctx, accountID, models.SourceESCO, startDate, endDate)
+	require.NoError(t, err)
+	assert.Len(t, syncedDates, 2)
+
+	runs, err := service.GetSyncRuns(ctx, accountID, 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	assert.Equal(t, models.SyncRunCompleted, runs[0].Status)
+	assert.Equal(t, 2, runs[0].HoldingsCount)
+}
+
+// cancellingBrokerSource cancels the sync while fetching, as a caller going away would
+type cancellingBrokerSource struct {
+	cancel context.CancelFunc
+}
+
+func (s *cancellingBrokerSource) Name() string {
+	return models.SourceESCO
+}
+
+func (s *cancellingBrokerSource) FetchAccountState(ctx context.Context, _, _ string, _, _ time.Time) (*schemas.AccountState, error) {
+	s.cancel()
+	return nil, ctx.Err()
+}
+
+// finishedSyncRunRepository records the runs finished and whether their context was still usable
+type finishedSyncRunRepository struct {
+	fakeSyncRunRepository
+	finished    []*models.SyncRun
+	finishedErr error
+}
+
+func (r *finishedSyncRunRepository) Finish(ctx context.Context, run *models.SyncRun) error {
+	r.finished = append(r.finished, run)
+	r.finishedErr = ctx.Err()
+	return ctx.Err()
+}
+
+func TestSyncRunFinishedAfterCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	syncRunRepo := &finishedSyncRunRepository{}
+	service := services.NewSyncService(nil, nil, nil, nil, nil, nil, syncRunRepo, &cancellingBrokerSource{cancel: cancel})
+
+	err := service.ForceSyncDataFromAccount(ctx, "token", "12345", day(1), day(2))
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, syncRunRepo.finished, 1)
+	assert.Equal(t, models.SyncRunFailed, syncRunRepo.finished[0].Status)
+	assert.NoError(t, syncRunRepo.finishedErr, "the run is finished with a context the caller cannot cancel")
 }
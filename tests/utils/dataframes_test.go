@@ -105,3 +105,16 @@ func TestUnionDataFramesByIndex(t *testing.T) {
 		})
 	}
 }
+
+func TestSortDataFrameColumns(t *testing.T) {
+	df := dataframe.ReadCSV(strings.NewReader(`TOTAL,B-2,DateRequested,TOTAL REAL,A-1
+1,2,2024-01-01,3,4`))
+
+	sorted := utils.SortDataFrameColumns(&df, []string{"DateRequested"}, []string{"TOTAL REAL", "TOTAL"})
+
+	want := []string{"DateRequested", "A-1", "B-2", "TOTAL REAL", "TOTAL"}
+	got := sorted.Names()
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("columns = %v, want %v", got, want)
+	}
+}
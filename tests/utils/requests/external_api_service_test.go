@@ -0,0 +1,225 @@
+package requests_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"server/src/config"
+	"server/src/utils"
+	"server/src/utils/requests"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestService(threshold int) *requests.ExternalAPIService {
+	return requests.NewExternalAPIService(nil, config.HTTPClientConfig{
+		Timeout:          time.Second,
+		MaxRetries:       2,
+		BaseBackoff:      time.Millisecond,
+		MaxBackoff:       50 * time.Millisecond,
+		BreakerThreshold: threshold,
+		BreakerCooldown:  time.Minute,
+	})
+}
+
+func TestExternalAPIServiceRetries(t *testing.T) {
+	t.Run("retries idempotent calls until the upstream recovers", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		resp, err := newTestService(10).Get(context.Background(), server.URL, "", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("expected status 200, got %d", resp.StatusCode)
+		}
+		if calls != 3 {
+			t.Errorf("expected 3 calls, got %d", calls)
+		}
+	})
+
+	t.Run("returns the last response when retries run out", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		resp, err := newTestService(10).Get(context.Background(), server.URL, "", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusBadGateway {
+			t.Errorf("expected status 502, got %d", resp.StatusCode)
+		}
+		if calls != 3 {
+			t.Errorf("expected 3 calls, got %d", calls)
+		}
+	})
+
+	t.Run("does not retry non idempotent calls", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		resp, err := newTestService(10).Post(context.Background(), server.URL, "", nil, map[string]string{"key": "value"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer resp.Body.Close()
+		if calls != 1 {
+			t.Errorf("expected 1 call, got %d", calls)
+		}
+	})
+
+	t.Run("does not retry client errors", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		resp, err := newTestService(10).Get(context.Background(), server.URL, "", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer resp.Body.Close()
+		if calls != 1 {
+			t.Errorf("expected 1 call, got %d", calls)
+		}
+	})
+
+	t.Run("honours Retry-After on 429", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		resp, err := newTestService(10).Get(context.Background(), server.URL, "", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || calls != 2 {
+			t.Errorf("expected status 200 after 2 calls, got %d after %d", resp.StatusCode, calls)
+		}
+	})
+
+	t.Run("does not wait for a Retry-After longer than the max backoff", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		resp, err := newTestService(10).Get(context.Background(), server.URL, "", nil)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusTooManyRequests || calls != 1 {
+			t.Errorf("expected status 429 after 1 call, got %d after %d", resp.StatusCode, calls)
+		}
+	})
+}
+
+func TestExternalAPIServiceCircuitBreaker(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	service := newTestService(3)
+	resp, err := service.Get(context.Background(), server.URL, "", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+
+	_, err = service.Get(context.Background(), server.URL, "", nil)
+	var httpErr *utils.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected a 503 error from the open circuit, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected no calls while the circuit is open, got %d", calls)
+	}
+}
+
+func TestExternalAPIServiceCircuitBreakerCancelledTrial(t *testing.T) {
+	var healthy, blocking int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&blocking) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		if atomic.LoadInt32(&healthy) == 0 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	service := requests.NewExternalAPIService(nil, config.HTTPClientConfig{
+		Timeout:          time.Second,
+		MaxRetries:       -1,
+		BreakerThreshold: 1,
+		BreakerCooldown:  20 * time.Millisecond,
+	})
+	resp, err := service.Get(context.Background(), server.URL, "", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	time.Sleep(30 * time.Millisecond)
+
+	// The trial call after the cooldown is cancelled by its caller
+	atomic.StoreInt32(&blocking, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err = service.Get(ctx, server.URL, "", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the trial call to time out, got %v", err)
+	}
+
+	atomic.StoreInt32(&blocking, 0)
+	atomic.StoreInt32(&healthy, 1)
+	resp, err = service.Get(context.Background(), server.URL, "", nil)
+	if err != nil {
+		t.Fatalf("expected the next call to be let through, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d", resp.StatusCode)
+	}
+}
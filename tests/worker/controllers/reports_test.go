@@ -7,12 +7,14 @@ import (
 
 	"server/src/models"
 	"server/src/worker/controllers"
+
+	"github.com/sirupsen/logrus"
 )
 
 var ch = make(chan bool, 1)
 
 // Mock function that sends a value to a channel when called
-func mockSendReportByEmail(reportSchedule *models.ReportSchedule) error {
+func mockSendReportByEmail(_ context.Context, _ string, reportSchedule *models.ReportSchedule) error {
 	// Send a value to the channel to indicate the function was called
 	ch <- true
 	return nil
@@ -20,7 +22,7 @@ func mockSendReportByEmail(reportSchedule *models.ReportSchedule) error {
 
 func TestScheduleReport(t *testing.T) {
 
-	c := controllers.NewController(nil)
+	c := controllers.NewController(logrus.New(), nil, nil, nil, nil)
 
 	reportSchedule := &models.ReportSchedule{
 		ID:       1,
@@ -48,7 +50,7 @@ func TestScheduleReport(t *testing.T) {
 }
 
 func TestScheduleReport_ErrorCreatingTask(t *testing.T) {
-	c := controllers.NewController(nil)
+	c := controllers.NewController(logrus.New(), nil, nil, nil, nil)
 
 	reportSchedule := &models.ReportSchedule{
 		ID:       1,
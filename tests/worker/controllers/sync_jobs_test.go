@@ -0,0 +1,88 @@
+package controllers_test
+
+import (
+	"context"
+	"server/src/services"
+	"server/src/worker/controllers"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type mockSyncJobService struct {
+	services.SyncJobServiceI
+	calls    chan bool
+	requeued bool
+}
+
+func (m *mockSyncJobService) RunNextSyncJob(_ context.Context) (bool, error) {
+	m.calls <- true
+	return false, nil
+}
+
+func (m *mockSyncJobService) RequeueInterruptedSyncJobs(_ context.Context) error {
+	m.requeued = true
+	return nil
+}
+
+func TestStartSyncJobRunner(t *testing.T) {
+	syncJobService := &mockSyncJobService{calls: make(chan bool, 10)}
+	c := controllers.NewController(logrus.New(), nil, syncJobService, nil, nil)
+
+	err := c.StartSyncJobRunner(logrus.New(), time.Second, 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer c.SyncJobRunner.Cancel()
+	if !syncJobService.requeued {
+		t.Errorf("Expected the jobs left running to be requeued before polling")
+	}
+
+	select {
+	case <-syncJobService.calls:
+		// Runner polled for pending jobs as expected
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected sync job runner to poll within 5 seconds, but it did not")
+	}
+}
+
+type mockIncrementalSyncService struct {
+	services.IncrementalSyncServiceI
+	calls chan bool
+}
+
+func (m *mockIncrementalSyncService) RunIncrementalSync(_ context.Context) error {
+	m.calls <- true
+	return nil
+}
+
+func TestStartIncrementalSyncScheduler(t *testing.T) {
+	incrementalSyncService := &mockIncrementalSyncService{calls: make(chan bool, 10)}
+	c := controllers.NewController(logrus.New(), nil, nil, incrementalSyncService, nil)
+
+	err := c.StartIncrementalSyncScheduler(logrus.New(), "@every 1s")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer c.IncrementalSyncScheduler.Cancel()
+
+	select {
+	case <-incrementalSyncService.calls:
+		// Incremental sync ran as expected
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected incremental sync to run within 5 seconds, but it did not")
+	}
+}
+
+func TestStartIncrementalSyncScheduler_Disabled(t *testing.T) {
+	c := controllers.NewController(logrus.New(), nil, nil, &mockIncrementalSyncService{}, nil)
+
+	err := c.StartIncrementalSyncScheduler(logrus.New(), "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if c.IncrementalSyncScheduler != nil {
+		t.Fatalf("Expected no scheduler to be created for an empty cron spec")
+	}
+}